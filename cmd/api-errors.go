@@ -108,6 +108,13 @@ const (
 	ErrInvalidQuerySignatureAlgo
 	ErrInvalidQueryParams
 	ErrBucketAlreadyOwnedByYou
+	ErrInvalidCannedACL
+	ErrObjectLocked
+	ErrObjectLockNotEnabled
+	ErrObjectLockConfigurationNotFound
+	ErrObjectLockInvalidHeaders
+	ErrPastObjectLockRetainDate
+	ErrNoSuchObjectLockConfiguration
 	// Add new error codes here.
 
 	// Bucket notification related errors.
@@ -134,6 +141,9 @@ const (
 	ErrPolicyNesting
 	ErrInvalidObjectName
 	ErrServerNotInitialized
+	ErrInvalidBitRotAlgorithm
+	ErrIndexNotEnabled
+	ErrInvalidSearchQuery
 	// Add new extended error codes here.
 	// Please open a https://github.com/minio/minio/issues before adding
 	// new error codes here.
@@ -465,6 +475,41 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "Your previous request to create the named bucket succeeded and you already own it.",
 		HTTPStatusCode: http.StatusConflict,
 	},
+	ErrInvalidCannedACL: {
+		Code:           "InvalidArgument",
+		Description:    "The canned ACL is not supported, valid values are private, public-read, public-read-write and authenticated-read.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrObjectLocked: {
+		Code:           "AccessDenied",
+		Description:    "Object is WORM protected and cannot be overwritten or deleted.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrObjectLockNotEnabled: {
+		Code:           "InvalidRequest",
+		Description:    "Bucket is missing ObjectLockConfiguration.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrObjectLockConfigurationNotFound: {
+		Code:           "ObjectLockConfigurationNotFoundError",
+		Description:    "Object Lock configuration does not exist for this bucket.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrObjectLockInvalidHeaders: {
+		Code:           "InvalidArgument",
+		Description:    "x-amz-object-lock-retain-until-date and x-amz-object-lock-mode must both be supplied with valid values.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrPastObjectLockRetainDate: {
+		Code:           "InvalidArgument",
+		Description:    "The retain until date must be in the future.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrNoSuchObjectLockConfiguration: {
+		Code:           "NoSuchObjectLockConfiguration",
+		Description:    "The specified object does not have a ObjectLock configuration.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
 
 	/// Bucket notification related errors.
 	ErrEventNotification: {
@@ -556,6 +601,21 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "Server not initialized, please try again.",
 		HTTPStatusCode: http.StatusServiceUnavailable,
 	},
+	ErrInvalidBitRotAlgorithm: {
+		Code:           "XMinioInvalidBitrotAlgorithm",
+		Description:    "Bit-rot algorithm set in X-Minio-Bitrot-Algorithm is not supported.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrIndexNotEnabled: {
+		Code:           "XMinioIndexNotEnabled",
+		Description:    "Bucket is not indexed, searches are only served from the index of a bucket.",
+		HTTPStatusCode: http.StatusNotImplemented,
+	},
+	ErrInvalidSearchQuery: {
+		Code:           "XMinioInvalidSearchQuery",
+		Description:    "Sizes of a search must be non negative integers and times must be in RFC3339 format.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 	// Add your error structure here.
 }
 
@@ -573,6 +633,10 @@ func toAPIErrorCode(err error) (apiErr APIErrorCode) {
 		apiErr = ErrSignatureDoesNotMatch
 	case errContentSHA256Mismatch:
 		apiErr = ErrContentSHA256Mismatch
+	case errAccessDenied:
+		apiErr = ErrAccessDenied
+	case errIndexNotEnabled:
+		apiErr = ErrIndexNotEnabled
 	}
 	if apiErr != ErrNone {
 		// If there was a match in the above switch case.
@@ -617,6 +681,10 @@ func toAPIErrorCode(err error) (apiErr APIErrorCode) {
 		apiErr = ErrNoSuchUpload
 	case PartTooSmall:
 		apiErr = ErrEntityTooSmall
+	case ObjectLocked:
+		apiErr = ErrObjectLocked
+	case NotImplemented:
+		apiErr = ErrNotImplemented
 	default:
 		apiErr = ErrInternalError
 	}
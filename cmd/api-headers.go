@@ -76,6 +76,9 @@ func setObjectHeaders(w http.ResponseWriter, objInfo ObjectInfo, contentRange *h
 
 	// Set all other user defined metadata.
 	for k, v := range objInfo.UserDefined {
+		if isInternalMetaKey(k) {
+			continue
+		}
 		w.Header().Set(k, v)
 	}
 
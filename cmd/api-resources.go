@@ -17,8 +17,10 @@
 package cmd
 
 import (
+	"errors"
 	"net/url"
 	"strconv"
+	"time"
 )
 
 // Parse bucket url queries
@@ -53,6 +55,44 @@ func getListObjectsV2Args(values url.Values) (prefix, token, startAfter, delimit
 	return
 }
 
+// errInvalidSearchQuery - a size or a time of a search can't be parsed.
+var errInvalidSearchQuery = errors.New("Invalid search query")
+
+// Parse bucket url queries for ?search
+func getSearchObjectsArgs(values url.Values) (query objectQuery, marker string, maxkeys int, err error) {
+	query.Prefix = values.Get("prefix")
+	query.Suffix = values.Get("suffix")
+	query.ContentType = values.Get("content-type")
+	query.MaxSize = -1
+	if value := values.Get("min-size"); value != "" {
+		if query.MinSize, err = strconv.ParseInt(value, 10, 64); err != nil || query.MinSize < 0 {
+			return objectQuery{}, "", 0, errInvalidSearchQuery
+		}
+	}
+	if value := values.Get("max-size"); value != "" {
+		if query.MaxSize, err = strconv.ParseInt(value, 10, 64); err != nil || query.MaxSize < 0 {
+			return objectQuery{}, "", 0, errInvalidSearchQuery
+		}
+	}
+	if value := values.Get("modified-after"); value != "" {
+		if query.ModifiedAfter, err = time.Parse(time.RFC3339, value); err != nil {
+			return objectQuery{}, "", 0, errInvalidSearchQuery
+		}
+	}
+	if value := values.Get("modified-before"); value != "" {
+		if query.ModifiedBefore, err = time.Parse(time.RFC3339, value); err != nil {
+			return objectQuery{}, "", 0, errInvalidSearchQuery
+		}
+	}
+	marker = values.Get("marker")
+	if values.Get("max-keys") != "" {
+		maxkeys, _ = strconv.Atoi(values.Get("max-keys"))
+	} else {
+		maxkeys = maxObjectList
+	}
+	return query, marker, maxkeys, nil
+}
+
 // Parse bucket url queries for ?uploads
 func getBucketMultipartResources(values url.Values) (prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int, encodingType string) {
 	prefix = values.Get("prefix")
@@ -33,6 +33,18 @@ func registerAPIRouter(mux *router.Router, api objectAPIHandlers) {
 
 	/// Object operations
 
+	// GetObjectACL
+	bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectACLHandler).Queries("acl", "")
+	// PutObjectACL
+	bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectACLHandler).Queries("acl", "")
+	// GetObjectRetention
+	bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectRetentionHandler).Queries("retention", "")
+	// PutObjectRetention
+	bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectRetentionHandler).Queries("retention", "")
+	// GetObjectLegalHold
+	bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectLegalHoldHandler).Queries("legal-hold", "")
+	// PutObjectLegalHold
+	bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectLegalHoldHandler).Queries("legal-hold", "")
 	// HeadObject
 	bucket.Methods("HEAD").Path("/{object:.+}").HandlerFunc(api.HeadObjectHandler)
 	// PutObjectPart
@@ -58,6 +70,10 @@ func registerAPIRouter(mux *router.Router, api objectAPIHandlers) {
 
 	// GetBucketLocation
 	bucket.Methods("GET").HandlerFunc(api.GetBucketLocationHandler).Queries("location", "")
+	// GetBucketACL
+	bucket.Methods("GET").HandlerFunc(api.GetBucketACLHandler).Queries("acl", "")
+	// GetBucketObjectLockConfig
+	bucket.Methods("GET").HandlerFunc(api.GetBucketObjectLockConfigHandler).Queries("object-lock", "")
 	// GetBucketPolicy
 	bucket.Methods("GET").HandlerFunc(api.GetBucketPolicyHandler).Queries("policy", "")
 	// GetBucketNotification
@@ -66,10 +82,16 @@ func registerAPIRouter(mux *router.Router, api objectAPIHandlers) {
 	bucket.Methods("GET").HandlerFunc(api.ListenBucketNotificationHandler).Queries("notificationARN", "{notificationARN:.*}")
 	// ListMultipartUploads
 	bucket.Methods("GET").HandlerFunc(api.ListMultipartUploadsHandler).Queries("uploads", "")
+	// SearchObjects
+	bucket.Methods("GET").HandlerFunc(api.SearchObjectsHandler).Queries("search", "")
 	// ListObjectsV2
 	bucket.Methods("GET").HandlerFunc(api.ListObjectsV2Handler).Queries("list-type", "2")
 	// ListObjectsV1 (Legacy)
 	bucket.Methods("GET").HandlerFunc(api.ListObjectsV1Handler)
+	// PutBucketACL
+	bucket.Methods("PUT").HandlerFunc(api.PutBucketACLHandler).Queries("acl", "")
+	// PutBucketObjectLockConfig
+	bucket.Methods("PUT").HandlerFunc(api.PutBucketObjectLockConfigHandler).Queries("object-lock", "")
 	// PutBucketPolicy
 	bucket.Methods("PUT").HandlerFunc(api.PutBucketPolicyHandler).Queries("policy", "")
 	// PutBucketNotification
@@ -154,6 +154,15 @@ func checkAuth(r *http.Request) APIErrorCode {
 	return isReqAuthenticated(r)
 }
 
+// checkAuthWithPolicy - same as checkAuth, except that anonymous
+// requests are allowed when the bucket policy grants them the action.
+func checkAuthWithPolicy(r *http.Request, bucket, action string) APIErrorCode {
+	if getRequestAuthType(r) == authTypeAnonymous {
+		return enforceBucketPolicy(bucket, action, r)
+	}
+	return checkAuth(r)
+}
+
 // authHandler - handles all the incoming authorization headers and validates them if possible.
 type authHandler struct {
 	handler http.Handler
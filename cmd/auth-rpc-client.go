@@ -86,6 +86,7 @@ type authConfig struct {
 	address     string // Network address path of RPC server.
 	path        string // Network path for HTTP dial.
 	loginMethod string // RPC service name for authenticating using JWT
+	secureConn  bool   // Make TLS connections to the RPC server.
 }
 
 // AuthRPCClient is a wrapper type for RPCClient which provides JWT based authentication across reconnects.
@@ -103,7 +104,7 @@ func newAuthClient(cfg *authConfig) *AuthRPCClient {
 		// Save the config.
 		config: cfg,
 		// Initialize a new reconnectable rpc client.
-		rpc: newClient(cfg.address, cfg.path),
+		rpc: newClient(cfg.address, cfg.path, cfg.secureConn),
 		// Allocated auth client not logged in yet.
 		isLoggedIn: false,
 	}
@@ -169,6 +169,14 @@ func benchmarkPutObject(b *testing.B, instanceType string, objSize int) {
 	runPutObjectBenchmark(b, objLayer, objSize)
 }
 
+// runs the put object benchmark on XL with objects checksummed with
+// the bit-rot algorithm.
+func benchmarkPutObjectBitRot(b *testing.B, algo string, objSize int) {
+	defer func(algo string) { globalBitRotAlgo = algo }(globalBitRotAlgo)
+	globalBitRotAlgo = algo
+	benchmarkPutObject(b, "XL", objSize)
+}
+
 // creates XL/FS backend setup, obtains the object layer and runs parallel benchmark for put object.
 func benchmarkPutObjectParallel(b *testing.B, instanceType string, objSize int) {
 	// create a temp XL/FS backend.
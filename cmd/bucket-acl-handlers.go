@@ -0,0 +1,254 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	mux "github.com/gorilla/mux"
+)
+
+// Grantee - container for the grantee of an ACL grant.
+type Grantee struct {
+	XMLNS       string `xml:"xmlns:xsi,attr"`
+	XMLXSI      string `xml:"xsi:type,attr"`
+	ID          string `xml:"ID,omitempty"`
+	DisplayName string `xml:"DisplayName,omitempty"`
+	URI         string `xml:"URI,omitempty"`
+}
+
+// Grant - container for a grantee and the permission granted.
+type Grant struct {
+	Grantee    Grantee
+	Permission string
+}
+
+// AccessControlPolicy - format for Get bucket and object ACL response.
+type AccessControlPolicy struct {
+	XMLName           xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ AccessControlPolicy" json:"-"`
+	Owner             Owner
+	AccessControlList struct {
+		Grants []Grant `xml:"Grant"`
+	}
+}
+
+// Group URIs used as grantees by canned ACLs.
+const (
+	allUsersGroupURI           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	authenticatedUsersGroupURI = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+// generateAccessControlPolicyResponse - lists the grants of a canned ACL.
+func generateAccessControlPolicyResponse(acl string) AccessControlPolicy {
+	const xmlnsXSI = "http://www.w3.org/2001/XMLSchema-instance"
+	groupGrant := func(uri, permission string) Grant {
+		return Grant{
+			Grantee:    Grantee{XMLNS: xmlnsXSI, XMLXSI: "Group", URI: uri},
+			Permission: permission,
+		}
+	}
+
+	policy := AccessControlPolicy{}
+	policy.Owner = Owner{ID: newgo, DisplayName: newgo}
+	grants := []Grant{{
+		Grantee:    Grantee{XMLNS: xmlnsXSI, XMLXSI: "CanonicalUser", ID: newgo, DisplayName: newgo},
+		Permission: "FULL_CONTROL",
+	}}
+	switch acl {
+	case cannedACLPublicRead:
+		grants = append(grants, groupGrant(allUsersGroupURI, "READ"))
+	case cannedACLPublicReadWrite:
+		grants = append(grants, groupGrant(allUsersGroupURI, "READ"), groupGrant(allUsersGroupURI, "WRITE"))
+	case cannedACLAuthenticatedRead:
+		grants = append(grants, groupGrant(authenticatedUsersGroupURI, "READ"))
+	}
+	policy.AccessControlList.Grants = grants
+	return policy
+}
+
+// getRequestCannedACL - validates the canned ACL of a Put ACL request,
+// grant based ACLs sent in the request body are not supported.
+func getRequestCannedACL(r *http.Request) (string, APIErrorCode) {
+	if r.Header.Get("X-Amz-Acl") == "" && r.ContentLength != 0 {
+		return "", ErrNotImplemented
+	}
+	acl, ok := getCannedACL(r)
+	if !ok {
+		return "", ErrInvalidCannedACL
+	}
+	return acl, ErrNone
+}
+
+// GetBucketACLHandler - GET Bucket acl
+// -----------------
+// This operation uses the acl subresource to return the canned
+// ACL of a bucket as its list of grants.
+func (api objectAPIHandlers) GetBucketACLHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if s3Error := checkAuthWithPolicy(r, bucket, "s3:GetBucketAcl"); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	acl, err := readBucketACL(bucket, objAPI)
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	encodedSuccessResponse := encodeResponse(generateAccessControlPolicyResponse(acl))
+	setCommonHeaders(w)
+	writeSuccessResponse(w, encodedSuccessResponse)
+}
+
+// PutBucketACLHandler - PUT Bucket acl
+// -----------------
+// This operation uses the acl subresource to set the canned ACL
+// given in 'x-amz-acl' header on a bucket.
+func (api objectAPIHandlers) PutBucketACLHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if s3Error := checkAuthWithPolicy(r, bucket, "s3:PutBucketAcl"); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	acl, s3Error := getRequestCannedACL(r)
+	if s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if err := writeBucketACL(bucket, acl, objAPI); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Success.
+	writeSuccessResponse(w, nil)
+}
+
+// GetObjectACLHandler - GET Object acl
+// -----------------
+// This operation uses the acl subresource to return the canned
+// ACL of an object as its list of grants.
+func (api objectAPIHandlers) GetObjectACLHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	if s3Error := checkAuthWithPolicy(r, bucket, "s3:GetObjectAcl"); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	objInfo, err := objAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		errorIf(err, "Unable to fetch object info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	acl := objInfo.UserDefined[objectACLMetaKey]
+	if acl == "" {
+		acl = cannedACLPrivate
+	}
+	encodedSuccessResponse := encodeResponse(generateAccessControlPolicyResponse(acl))
+	setCommonHeaders(w)
+	writeSuccessResponse(w, encodedSuccessResponse)
+}
+
+// PutObjectACLHandler - PUT Object acl
+// -----------------
+// This operation uses the acl subresource to set the canned ACL
+// given in 'x-amz-acl' header on an object.
+func (api objectAPIHandlers) PutObjectACLHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	if s3Error := checkAuthWithPolicy(r, bucket, "s3:PutObjectAcl"); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	acl, s3Error := getRequestCannedACL(r)
+	if s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	objInfo, err := objAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		errorIf(err, "Unable to fetch object info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	metadata := make(map[string]string)
+	for k, v := range objInfo.UserDefined {
+		metadata[k] = v
+	}
+	setObjectACLMetadata(metadata, acl)
+
+	if err = updateObjectMetadata(objAPI, objInfo, metadata); err != nil {
+		errorIf(err, "Unable to update object ACL.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Success.
+	writeSuccessResponse(w, nil)
+}
+
+// setObjectACLMetadata - saves the canned ACL in the object metadata,
+// private being the default it is not saved.
+func setObjectACLMetadata(metadata map[string]string, acl string) {
+	if acl == cannedACLPrivate {
+		delete(metadata, objectACLMetaKey)
+		return
+	}
+	metadata[objectACLMetaKey] = acl
+}
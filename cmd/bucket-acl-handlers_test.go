@@ -0,0 +1,194 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests validate the grants listed for canned ACLs.
+func TestGenerateAccessControlPolicyResponse(t *testing.T) {
+	testCases := []struct {
+		acl         string
+		groupGrants []string
+	}{
+		{cannedACLPrivate, nil},
+		{cannedACLPublicRead, []string{allUsersGroupURI + " READ"}},
+		{cannedACLPublicReadWrite, []string{allUsersGroupURI + " READ", allUsersGroupURI + " WRITE"}},
+		{cannedACLAuthenticatedRead, []string{authenticatedUsersGroupURI + " READ"}},
+	}
+	for i, testCase := range testCases {
+		policy := generateAccessControlPolicyResponse(testCase.acl)
+		grants := policy.AccessControlList.Grants
+		if len(grants) != len(testCase.groupGrants)+1 {
+			t.Fatalf("Test %d: Expected %d grants, but found %d", i+1, len(testCase.groupGrants)+1, len(grants))
+		}
+		// Owner always has full control.
+		if grants[0].Permission != "FULL_CONTROL" || grants[0].Grantee.XMLXSI != "CanonicalUser" {
+			t.Errorf("Test %d: Expected owner to have FULL_CONTROL, but found %#v", i+1, grants[0])
+		}
+		for j, groupGrant := range testCase.groupGrants {
+			grant := grants[j+1]
+			if grant.Grantee.URI+" "+grant.Permission != groupGrant {
+				t.Errorf("Test %d: Expected grant `%s`, but found `%s %s`", i+1, groupGrant, grant.Grantee.URI, grant.Permission)
+			}
+		}
+	}
+}
+
+// Wrapper for calling ACL handler tests for both XL multiple disks and single node setup.
+func TestACLHandlers(t *testing.T) {
+	ExecObjectLayerTest(t, testACLHandlers)
+}
+
+// testACLHandlers - Tests validate Get/Put bucket and object ACLs and
+// that canned ACLs are enforced for anonymous requests.
+func testACLHandlers(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucketName := getRandomBucketName()
+	objectName := "test-object"
+	if err := obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+	if _, err := obj.PutObject(bucketName, objectName, int64(len("hello")), bytes.NewReader([]byte("hello")), nil); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+
+	// Register all the API end points.
+	apiRouter := initTestAPIEndPoints(obj, []string{"All"})
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+
+	testCases := []struct {
+		method    string
+		url       string
+		acl       string
+		body      []byte
+		anonymous bool
+		// expected Response.
+		expectedRespStatus int
+	}{
+		// Test cases 1-3.
+		// Private bucket and object deny anonymous requests.
+		{"GET", getGetObjectURL("", bucketName, objectName), "", nil, true, http.StatusForbidden},
+		{"GET", getListObjectsV1URL("", bucketName, ""), "", nil, true, http.StatusForbidden},
+		{"PUT", getPutObjectURL("", bucketName, "anon-object"), "", []byte("anon"), true, http.StatusForbidden},
+		// Test cases 4-5.
+		// Object readable by everyone once it is public-read.
+		{"PUT", getACLURL("", bucketName, objectName), cannedACLPublicRead, nil, false, http.StatusOK},
+		{"GET", getGetObjectURL("", bucketName, objectName), "", nil, true, http.StatusOK},
+		// Test cases 6-8.
+		// Bucket listing allowed with public-read, but not uploads.
+		{"PUT", getACLURL("", bucketName, ""), cannedACLPublicRead, nil, false, http.StatusOK},
+		{"GET", getListObjectsV1URL("", bucketName, ""), "", nil, true, http.StatusOK},
+		{"PUT", getPutObjectURL("", bucketName, "anon-object"), "", []byte("anon"), true, http.StatusForbidden},
+		// Test cases 9-10.
+		// Uploads allowed with public-read-write.
+		{"PUT", getACLURL("", bucketName, ""), cannedACLPublicReadWrite, nil, false, http.StatusOK},
+		{"PUT", getPutObjectURL("", bucketName, "anon-object"), "", []byte("anon"), true, http.StatusOK},
+		// Test cases 11-12.
+		// Setting it back to private denies again.
+		{"PUT", getACLURL("", bucketName, objectName), cannedACLPrivate, nil, false, http.StatusOK},
+		{"GET", getGetObjectURL("", bucketName, objectName), "", nil, true, http.StatusForbidden},
+		// Test cases 13-14.
+		// Unsupported canned ACLs and grants in the body.
+		{"PUT", getACLURL("", bucketName, ""), "public-everything", nil, false, http.StatusBadRequest},
+		{"PUT", getACLURL("", bucketName, ""), "", []byte("<AccessControlPolicy/>"), false, http.StatusNotImplemented},
+		// Test case 15.
+		// Upload with a canned ACL.
+		{"PUT", getPutObjectURL("", bucketName, "public-object"), cannedACLPublicRead, []byte("public"), false, http.StatusOK},
+		// Test case 16.
+		// ACLs cannot be read anonymously.
+		{"GET", getACLURL("", bucketName, objectName), "", nil, true, http.StatusForbidden},
+	}
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		var req *http.Request
+		if testCase.anonymous {
+			req, err = newTestRequest(testCase.method, testCase.url, int64(len(testCase.body)), bytes.NewReader(testCase.body))
+		} else {
+			req, err = newTestSignedRequest(testCase.method, testCase.url, int64(len(testCase.body)), bytes.NewReader(testCase.body),
+				credentials.AccessKeyID, credentials.SecretAccessKey)
+		}
+		if err != nil {
+			t.Fatalf("Test %d: %s: Failed to create HTTP request: <ERROR> %v", i+1, instanceType, err)
+		}
+		if testCase.acl != "" {
+			// Header is not signed, not part of the signature v4 canonical headers for tests.
+			req.Header.Set("x-amz-acl", testCase.acl)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedRespStatus {
+			t.Errorf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, testCase.expectedRespStatus, rec.Code)
+		}
+	}
+
+	// Uploaded object is public-read, and the ACL is not sent back as a header.
+	for _, object := range []string{"public-object"} {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequest("GET", getACLURL("", bucketName, object), 0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		policy := AccessControlPolicy{}
+		if err = xml.Unmarshal(rec.Body.Bytes(), &policy); err != nil {
+			t.Fatalf("%s: Unable to parse ACL response: %v", instanceType, err)
+		}
+		if len(policy.AccessControlList.Grants) != 2 || policy.AccessControlList.Grants[1].Grantee.URI != allUsersGroupURI {
+			t.Errorf("%s: Expected %s to be public-read, but found %s", instanceType, object, rec.Body.String())
+		}
+
+		rec = httptest.NewRecorder()
+		req, err = newTestRequest("HEAD", getHeadObjectURL("", bucketName, object), 0, nil)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: Expected anonymous HEAD on %s to succeed, but found `%d`", instanceType, object, rec.Code)
+		}
+		if rec.Header().Get(objectACLMetaKey) != "" {
+			t.Errorf("%s: Expected %s header not to be sent", instanceType, objectACLMetaKey)
+		}
+	}
+
+	// An explicit Deny in the bucket policy overrides the ACLs.
+	policyDoc := fmt.Sprintf(`{"Version":"2012-10-17","Statement":[{"Action":["s3:ListBucket"],"Effect":"Deny","Principal":{"AWS":["*"]},"Resource":["arn:aws:s3:::%s"],"Sid":""}]}`, bucketName)
+	if err = writeBucketPolicy(bucketName, obj, bytes.NewReader([]byte(policyDoc)), int64(len(policyDoc))); err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	rec := httptest.NewRecorder()
+	req, err := newTestRequest("GET", getListObjectsV1URL("", bucketName, ""), 0, nil)
+	if err != nil {
+		t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("%s: Expected the response status to be `%d`, but instead found `%d`", instanceType, http.StatusForbidden, rec.Code)
+	}
+}
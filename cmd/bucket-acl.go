@@ -0,0 +1,179 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const (
+	// Bucket ACL is saved as 'buckets/<bucket>/acl.json' in the meta bucket.
+	bucketACLJSON = "acl.json"
+
+	// Object ACL is saved in the object metadata under this key,
+	// it is never sent back as part of the object headers.
+	objectACLMetaKey = "X-Minio-Internal-Acl"
+
+	// Canned ACLs.
+	cannedACLPrivate           = "private"
+	cannedACLPublicRead        = "public-read"
+	cannedACLPublicReadWrite   = "public-read-write"
+	cannedACLAuthenticatedRead = "authenticated-read"
+)
+
+// isValidCannedACL - is the canned ACL one of the supported ones.
+func isValidCannedACL(acl string) bool {
+	switch acl {
+	case cannedACLPrivate, cannedACLPublicRead, cannedACLPublicReadWrite, cannedACLAuthenticatedRead:
+		return true
+	}
+	return false
+}
+
+// getCannedACL - returns the canned ACL in 'x-amz-acl' header of the
+// request, 'private' if not set. Returns false for unsupported ACLs.
+func getCannedACL(r *http.Request) (string, bool) {
+	acl := r.Header.Get("X-Amz-Acl")
+	if acl == "" {
+		return cannedACLPrivate, true
+	}
+	return acl, isValidCannedACL(acl)
+}
+
+// Actions granted to anonymous requests by a canned bucket ACL.
+var bucketACLActions = map[string][]string{
+	cannedACLPublicRead: {"s3:ListBucket", "s3:ListBucketMultipartUploads"},
+	cannedACLPublicReadWrite: {"s3:ListBucket", "s3:ListBucketMultipartUploads",
+		"s3:PutObject", "s3:DeleteObject", "s3:AbortMultipartUpload",
+		"s3:ListMultipartUploadParts"},
+}
+
+// Actions granted to anonymous requests by a canned object ACL.
+var objectACLActions = map[string][]string{
+	cannedACLPublicRead:      {"s3:GetObject"},
+	cannedACLPublicReadWrite: {"s3:GetObject"},
+}
+
+// isActionAllowedByACL - verifies if anonymous requests are granted the
+// action by the bucket ACL, or by the object ACL for object reads.
+func isActionAllowedByACL(bucket, object, action string, objAPI ObjectLayer) bool {
+	var acl string
+	var actions map[string][]string
+	if action == "s3:GetObject" {
+		if object == "" {
+			return false
+		}
+		objInfo, err := objAPI.GetObjectInfo(bucket, object)
+		if err != nil {
+			return false
+		}
+		acl, actions = objInfo.UserDefined[objectACLMetaKey], objectACLActions
+	} else {
+		var err error
+		if acl, err = readBucketACL(bucket, objAPI); err != nil {
+			return false
+		}
+		actions = bucketACLActions
+	}
+	for _, allowed := range actions[acl] {
+		if allowed == action {
+			return true
+		}
+	}
+	return false
+}
+
+// bucketACL - format of the saved bucket ACL.
+type bucketACL struct {
+	ACL string `json:"acl"`
+}
+
+// readBucketACL - reads the canned ACL of the bucket, buckets without
+// a saved ACL are private.
+func readBucketACL(bucket string, objAPI ObjectLayer) (string, error) {
+	// Verify if bucket actually exists
+	if err := isBucketExist(bucket, objAPI); err != nil {
+		return "", err
+	}
+
+	aclPath := pathJoin(bucketConfigPrefix, bucket, bucketACLJSON)
+	objInfo, err := objAPI.GetObjectInfo(minioMetaBucket, aclPath)
+	err = errorCause(err)
+	if err != nil {
+		if _, ok := err.(ObjectNotFound); ok {
+			return cannedACLPrivate, nil
+		}
+		errorIf(err, "Unable to load ACL for the bucket %s.", bucket)
+		return "", err
+	}
+	var buffer bytes.Buffer
+	if err = objAPI.GetObject(minioMetaBucket, aclPath, 0, objInfo.Size, &buffer); err != nil {
+		errorIf(err, "Unable to load ACL for the bucket %s.", bucket)
+		return "", errorCause(err)
+	}
+	acl := bucketACL{}
+	if err = json.Unmarshal(buffer.Bytes(), &acl); err != nil {
+		return "", err
+	}
+	return acl.ACL, nil
+}
+
+// writeBucketACL - saves the canned ACL of the bucket, private buckets
+// have their saved ACL removed.
+func writeBucketACL(bucket string, acl string, objAPI ObjectLayer) error {
+	// Verify if bucket actually exists
+	if err := isBucketExist(bucket, objAPI); err != nil {
+		return err
+	}
+	if acl == cannedACLPrivate {
+		return removeBucketACL(bucket, objAPI)
+	}
+
+	aclBytes, err := json.Marshal(bucketACL{ACL: acl})
+	if err != nil {
+		return err
+	}
+	aclPath := pathJoin(bucketConfigPrefix, bucket, bucketACLJSON)
+	if _, err = objAPI.PutObject(minioMetaBucket, aclPath, int64(len(aclBytes)), bytes.NewReader(aclBytes), nil); err != nil {
+		errorIf(err, "Unable to set ACL for the bucket %s", bucket)
+		return errorCause(err)
+	}
+	return nil
+}
+
+// removeBucketACL - removes the saved ACL of the bucket, if any.
+func removeBucketACL(bucket string, objAPI ObjectLayer) error {
+	aclPath := pathJoin(bucketConfigPrefix, bucket, bucketACLJSON)
+	if err := objAPI.DeleteObject(minioMetaBucket, aclPath); err != nil {
+		err = errorCause(err)
+		if _, ok := err.(ObjectNotFound); ok {
+			return nil
+		}
+		errorIf(err, "Unable to remove ACL on bucket %s.", bucket)
+		return err
+	}
+	return nil
+}
+
+// isInternalMetaKey - metadata keys minio keeps for itself which are
+// not sent back as object headers.
+func isInternalMetaKey(key string) bool {
+	return strings.HasPrefix(http.CanonicalHeaderKey(key), "X-Minio-Internal-")
+}
@@ -77,7 +77,7 @@ func (api objectAPIHandlers) ListObjectsV2Handler(w http.ResponseWriter, r *http
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:ListBucket", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:ListBucket", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -144,7 +144,7 @@ func (api objectAPIHandlers) ListObjectsV1Handler(w http.ResponseWriter, r *http
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:ListBucket", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:ListBucket", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -180,3 +180,67 @@ func (api objectAPIHandlers) ListObjectsV1Handler(w http.ResponseWriter, r *http
 	// Write success response.
 	writeSuccessResponse(w, encodeResponse(response))
 }
+
+// SearchObjectsHandler - GET Bucket ?search, a Minio extension.
+// --------------------------
+// Lists the objects of an indexed bucket matching a name prefix and
+// suffix, a content type, a size range and a modification time range,
+// from the index of the bucket. The response is the one of ListObjectsV1.
+func (api objectAPIHandlers) SearchObjectsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypeAnonymous:
+		// Searches list the objects of the bucket.
+		if s3Error := enforceBucketPolicy(bucket, "s3:ListBucket", r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	case authTypeSigned, authTypePresigned:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			errorIf(errSignatureMismatch, dumpRequest(r))
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	searcher, ok := objectAPI.(objectSearcher)
+	if !ok {
+		writeErrorResponse(w, r, ErrIndexNotEnabled, r.URL.Path)
+		return
+	}
+
+	// Extract all the search query params to their native values.
+	query, marker, maxKeys, err := getSearchObjectsArgs(r.URL.Query())
+	if err != nil {
+		writeErrorResponse(w, r, ErrInvalidSearchQuery, r.URL.Path)
+		return
+	}
+	if s3Error := listObjectsValidateArgs(query.Prefix, marker, "", maxKeys); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	listObjectsInfo, err := searcher.SearchObjects(bucket, query, marker, maxKeys)
+	if err != nil {
+		errorIf(err, "Unable to search objects.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	response := generateListObjectsV1Response(bucket, query.Prefix, marker, "", maxKeys, listObjectsInfo)
+	// Write headers
+	setCommonHeaders(w)
+	// Write success response.
+	writeSuccessResponse(w, encodeResponse(response))
+}
@@ -19,10 +19,12 @@ package cmd
 import (
 	"encoding/xml"
 	"io"
+	"net"
 	"net/http"
-	"net/url"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	mux "github.com/gorilla/mux"
 	"github.com/minio/minio-go/pkg/set"
@@ -30,9 +32,15 @@ import (
 
 // http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
 // Enforces bucket policies for a bucket for a given tatusaction.
-func enforceBucketPolicy(bucket string, action string, reqURL *url.URL) (s3Error APIErrorCode) {
-	// Fetch bucket policy, if policy is not set return access denied.
-	policy, err := readBucketPolicy(bucket, newObjectLayerFn())
+// Requests not decided by any of the policy statements are then
+// verified against the canned ACLs of the bucket and the object.
+func enforceBucketPolicy(bucket string, action string, r *http.Request) (s3Error APIErrorCode) {
+	if ownerOnlyActions.Contains(action) {
+		return ErrAccessDenied
+	}
+	objAPI := newObjectLayerFn()
+	// Fetch bucket policy, if policy is not set only ACLs apply.
+	policy, err := readBucketPolicy(bucket, objAPI)
 	if err != nil {
 		err = errorCause(err)
 		switch err.(type) {
@@ -43,31 +51,74 @@ func enforceBucketPolicy(bucket string, action string, reqURL *url.URL) (s3Error
 			// For no bucket found we return NoSuchBucket instead.
 			return ErrNoSuchBucket
 		case BucketPolicyNotFound:
-			// For no bucket policy found, return AccessDenied, since
-			// anonymous requests are not allowed without bucket policies.
-			return ErrAccessDenied
+			// For no bucket policy found, anonymous requests
+			// are only allowed if granted by ACLs.
+			policy = &bucketPolicy{}
+		default:
+			errorIf(err, "Unable to read bucket policy.")
+			// Return internal error for any other errors so that we can investigate.
+			return ErrInternalError
 		}
-		errorIf(err, "Unable to read bucket policy.")
-		// Return internal error for any other errors so that we can investigate.
-		return ErrInternalError
 	}
 
 	// Construct resource in 'arn:aws:s3:::examplebucket/object' format.
-	resource := AWSResourcePrefix + strings.TrimSuffix(strings.TrimPrefix(reqURL.Path, "/"), "/")
+	resourcePath := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	resource := AWSResourcePrefix + resourcePath
 
 	// Get conditions for policy verification.
-	conditionKeyMap := make(map[string]set.StringSet)
-	for queryParam := range reqURL.Query() {
-		conditionKeyMap[queryParam] = set.CreateStringSet(reqURL.Query().Get(queryParam))
-	}
+	conditionKeyMap := getConditionKeyMap(r)
 
 	// Validate action, resource and conditions with current policy statements.
-	if !bucketPolicyEvalStatements(action, resource, conditionKeyMap, policy.Statements) {
+	allowed, statement := bucketPolicyEvalStatement(action, resource, conditionKeyMap, policy.Statements)
+	if allowed {
+		return ErrNone
+	}
+	// Explicitly denied by a policy statement.
+	if statement != nil {
+		return ErrAccessDenied
+	}
+
+	object := strings.TrimPrefix(strings.TrimPrefix(resourcePath, bucket), "/")
+	if !isActionAllowedByACL(bucket, object, action, objAPI) {
 		return ErrAccessDenied
 	}
 	return ErrNone
 }
 
+// getConditionKeyMap - collects the values bucket policy conditions are
+// evaluated against, query params of the 's3:' condition keys are keyed
+// by their names and request properties by their 'aws:' condition keys.
+// Other query params are left out, so that they can't set the values of
+// 'aws:' condition keys.
+func getConditionKeyMap(r *http.Request) map[string]set.StringSet {
+	conditionKeyMap := make(map[string]set.StringSet)
+	query := r.URL.Query()
+	for conditionKey := range supportedConditionsKey {
+		if !strings.HasPrefix(conditionKey, "s3:") {
+			continue
+		}
+		queryParam := strings.TrimPrefix(conditionKey, "s3:")
+		if _, ok := query[queryParam]; ok {
+			conditionKeyMap[queryParam] = set.CreateStringSet(query.Get(queryParam))
+		}
+	}
+
+	sourceIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		sourceIP = r.RemoteAddr
+	}
+	conditionKeyMap["aws:SourceIp"] = set.CreateStringSet(sourceIP)
+	conditionKeyMap["aws:CurrentTime"] = set.CreateStringSet(time.Now().UTC().Format(time.RFC3339))
+	conditionKeyMap["aws:SecureTransport"] = set.CreateStringSet(strconv.FormatBool(r.TLS != nil || globalIsSSL))
+	if referer := r.Referer(); referer != "" {
+		conditionKeyMap["aws:Referer"] = set.CreateStringSet(referer)
+	}
+	if userAgent := r.UserAgent(); userAgent != "" {
+		conditionKeyMap["aws:UserAgent"] = set.CreateStringSet(userAgent)
+	}
+	return conditionKeyMap
+}
+
 // GetBucketLocationHandler - GET Bucket location.
 // -------------------------
 // This operation returns bucket location.
@@ -88,7 +139,7 @@ func (api objectAPIHandlers) GetBucketLocationHandler(w http.ResponseWriter, r *
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:GetBucketLocation", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:GetBucketLocation", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -144,7 +195,7 @@ func (api objectAPIHandlers) ListMultipartUploadsHandler(w http.ResponseWriter,
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/mpuAndPermissions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:ListBucketMultipartUploads", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:ListBucketMultipartUploads", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -195,11 +246,13 @@ func (api objectAPIHandlers) ListBucketsHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// List buckets does not support bucket policies, no need to enforce it.
-	if s3Error := checkAuth(r); s3Error != ErrNone {
-		errorIf(errSignatureMismatch, dumpRequest(r))
-		writeErrorResponse(w, r, s3Error, r.URL.Path)
-		return
+	isAnonymous := getRequestAuthType(r) == authTypeAnonymous
+	if !isAnonymous {
+		if s3Error := checkAuth(r); s3Error != ErrNone {
+			errorIf(errSignatureMismatch, dumpRequest(r))
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
 	}
 
 	// Invoke the list buckets.
@@ -210,6 +263,17 @@ func (api objectAPIHandlers) ListBucketsHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	// Anonymous requests only list the buckets whose bucket
+	// policy grants s3:ListAllMyBuckets, and are denied when
+	// none does.
+	if isAnonymous {
+		bucketsInfo = filterBucketsByPolicy(bucketsInfo, "s3:ListAllMyBuckets", r)
+		if len(bucketsInfo) == 0 {
+			writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+			return
+		}
+	}
+
 	// Generate response.
 	response := generateListBucketsResponse(bucketsInfo)
 	encodedSuccessResponse := encodeResponse(response)
@@ -219,6 +283,23 @@ func (api objectAPIHandlers) ListBucketsHandler(w http.ResponseWriter, r *http.R
 	writeSuccessResponse(w, encodedSuccessResponse)
 }
 
+// filterBucketsByPolicy - returns the buckets whose bucket policy
+// allows the action for the request.
+func filterBucketsByPolicy(bucketsInfo []BucketInfo, action string, r *http.Request) []BucketInfo {
+	var allowed []BucketInfo
+	for _, bucketInfo := range bucketsInfo {
+		// Evaluate the policy as if the request was made on the bucket.
+		url := *r.URL
+		url.Path = "/" + bucketInfo.Name
+		req := *r
+		req.URL = &url
+		if enforceBucketPolicy(bucketInfo.Name, action, &req) == ErrNone {
+			allowed = append(allowed, bucketInfo)
+		}
+	}
+	return allowed
+}
+
 // DeleteMultipleObjectsHandler - deletes multiple objects.
 func (api objectAPIHandlers) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -237,7 +318,7 @@ func (api objectAPIHandlers) DeleteMultipleObjectsHandler(w http.ResponseWriter,
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:DeleteObject", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:DeleteObject", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -289,6 +370,11 @@ func (api objectAPIHandlers) DeleteMultipleObjectsHandler(w http.ResponseWriter,
 		wg.Add(1)
 		go func(i int, obj ObjectIdentifier) {
 			defer wg.Done()
+			// Objects under retention or legal hold cannot be deleted.
+			if dErr := enforceObjectLock(objectAPI, bucket, obj.ObjectName, r); dErr != nil {
+				dErrs[i] = dErr
+				return
+			}
 			dErr := objectAPI.DeleteObject(bucket, obj.ObjectName)
 			if dErr != nil {
 				dErrs[i] = dErr
@@ -374,6 +460,13 @@ func (api objectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Validate the canned ACL before creating the bucket.
+	acl, ok := getCannedACL(r)
+	if !ok {
+		writeErrorResponse(w, r, ErrInvalidCannedACL, r.URL.Path)
+		return
+	}
+
 	// Proceed to creating a bucket.
 	err := objectAPI.MakeBucket(bucket)
 	if err != nil {
@@ -381,6 +474,22 @@ func (api objectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
 		return
 	}
+
+	// Save the canned ACL of the bucket.
+	if err = writeBucketACL(bucket, acl, objectAPI); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Enable object lock on the bucket if requested, it can also
+	// be enabled later through the object-lock subresource.
+	if r.Header.Get("X-Amz-Bucket-Object-Lock-Enabled") == "true" {
+		config := &ObjectLockConfiguration{ObjectLockEnabled: "Enabled"}
+		if err = writeObjectLockConfig(bucket, config, objectAPI); err != nil {
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	}
 	// Make sure to add Location information here only for bucket
 	w.Header().Set("Location", getLocation(r))
 	writeSuccessResponse(w, nil)
@@ -432,10 +541,23 @@ func (api objectAPIHandlers) PostPolicyBucketHandler(w http.ResponseWriter, r *h
 		writeErrorResponse(w, r, apiErr, r.URL.Path)
 		return
 	}
+	// Verify if the shared link, if any, is still active.
+	if shareID := formValues[sharedLinkIDKey]; shareID != "" {
+		if !isSharedLinkActive(bucket, object, "POST", shareID, objectAPI) {
+			writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+			return
+		}
+	}
 
 	// Save metadata.
 	metadata := make(map[string]string)
-	// Nothing to store right now.
+
+	// Save default retention of the bucket, an existing locked
+	// object cannot be overwritten.
+	if s3Error := applyObjectLock(objectAPI, bucket, object, r, metadata); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
 
 	objInfo, err := objectAPI.PutObject(bucket, object, -1, fileBody, metadata)
 	if err != nil {
@@ -488,7 +610,7 @@ func (api objectAPIHandlers) HeadBucketHandler(w http.ResponseWriter, r *http.Re
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:ListBucket", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:ListBucket", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -536,8 +658,19 @@ func (api objectAPIHandlers) DeleteBucketHandler(w http.ResponseWriter, r *http.
 	// Delete bucket access policy, if present - ignore any errors.
 	removeBucketPolicy(bucket, objectAPI)
 
+	// Delete bucket ACL, if present - ignore any errors.
+	removeBucketACL(bucket, objectAPI)
+
+	// Delete object lock config, if present - ignore any errors.
+	removeObjectLockConfig(bucket, objectAPI)
+
+	// Delete shared links, if present - ignore any errors.
+	removeSharedLinks(bucket, objectAPI)
+
 	// Delete notification config, if present - ignore any errors.
 	removeNotificationConfig(bucket, objectAPI)
+	globalEventNotifier.RemoveBucketNotificationConfig(bucket)
+	go updateBucketNotificationOnPeers(bucket)
 
 	// Write success response.
 	writeSuccessNoContent(w)
@@ -45,13 +45,14 @@ func (api objectAPIHandlers) GetBucketNotificationHandler(w http.ResponseWriter,
 		return
 	}
 
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
 	// Validate request authorization.
-	if s3Error := checkAuth(r); s3Error != ErrNone {
+	if s3Error := checkAuthWithPolicy(r, bucket, "s3:GetBucketNotification"); s3Error != ErrNone {
 		writeErrorResponse(w, r, s3Error, r.URL.Path)
 		return
 	}
-	vars := mux.Vars(r)
-	bucket := vars["bucket"]
 	// Attempt to successfully load notification config.
 	nConfig, err := loadNotificationConfig(bucket, objAPI)
 	if err != nil && err != errNoSuchNotifications {
@@ -155,6 +156,9 @@ func (api objectAPIHandlers) PutBucketNotificationHandler(w http.ResponseWriter,
 	// Set bucket notification config.
 	globalEventNotifier.SetBucketNotificationConfig(bucket, &notificationCfg)
 
+	// Other servers reload the configuration they cached.
+	go updateBucketNotificationOnPeers(bucket)
+
 	// Success.
 	writeSuccessResponse(w, nil)
 }
@@ -223,13 +227,14 @@ func (api objectAPIHandlers) ListenBucketNotificationHandler(w http.ResponseWrit
 		return
 	}
 
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
 	// Validate request authorization.
-	if s3Error := checkAuth(r); s3Error != ErrNone {
+	if s3Error := checkAuthWithPolicy(r, bucket, "s3:ListenBucketNotification"); s3Error != ErrNone {
 		writeErrorResponse(w, r, s3Error, r.URL.Path)
 		return
 	}
-	vars := mux.Vars(r)
-	bucket := vars["bucket"]
 
 	// Get notification ARN.
 	topicARN := r.URL.Query().Get("notificationARN")
@@ -271,6 +276,12 @@ func (api objectAPIHandlers) ListenBucketNotificationHandler(w http.ResponseWrit
 	// Remove sns listener after the writer has closed or the client disconnected.
 	defer globalEventNotifier.RemoveSNSTarget(topicARN, nEventCh)
 
+	// Register the listener on all the peers in a distributed setup,
+	// events on other servers are forwarded to this server.
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	go registerListenerOnPeers(topicARN, doneCh)
+
 	// Start sending bucket notifications.
 	sendBucketNotification(w, nEventCh)
 }
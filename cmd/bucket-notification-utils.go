@@ -26,9 +26,16 @@ var suppportedEventTypes = map[string]struct{}{
 	"s3:ObjectCreated:Post":                    {},
 	"s3:ObjectCreated:Copy":                    {},
 	"s3:ObjectCreated:CompleteMultipartUpload": {},
-	// Object removed event types.
-	"s3:ObjectRemoved:*":      {},
-	"s3:ObjectRemoved:Delete": {},
+	// Object removed event types. Buckets are not versioned so delete
+	// markers are never created, the event type is accepted so that
+	// configurations written for AWS S3 remain valid.
+	"s3:ObjectRemoved:*":                   {},
+	"s3:ObjectRemoved:Delete":              {},
+	"s3:ObjectRemoved:DeleteMarkerCreated": {},
+	// Object accessed event types.
+	"s3:ObjectAccessed:*":    {},
+	"s3:ObjectAccessed:Get":  {},
+	"s3:ObjectAccessed:Head": {},
 }
 
 // checkEvent - checks if an event is supported.
@@ -147,14 +154,6 @@ func isMinioSNSConfigured(topicARN string, topicConfigs []topicConfig) bool {
 	return false
 }
 
-// Validate if we recognize the queue type.
-func isValidQueue(sqsARN arnSQS) bool {
-	amqpQ := isAMQPQueue(sqsARN)       // Is amqp queue?.
-	elasticQ := isElasticQueue(sqsARN) // Is elastic queue?.
-	redisQ := isRedisQueue(sqsARN)     // Is redis queue?.
-	return amqpQ || elasticQ || redisQ
-}
-
 // Validate if we recognize the topic type.
 func isValidTopic(topicARN arnTopic) bool {
 	return isMinioSNS(topicARN) // Is minio topic?.
@@ -165,17 +164,7 @@ func isValidQueueID(queueARN string) bool {
 	// Unmarshals QueueARN into structured object.
 	sqsARN := unmarshalSqsARN(queueARN)
 	// Is Queue identifier valid?.
-	if isAMQPQueue(sqsARN) { // AMQP eueue.
-		amqpN := serverConfig.GetAMQPNotifyByID(sqsARN.AccountID)
-		return amqpN.Enable && amqpN.URL != ""
-	} else if isElasticQueue(sqsARN) { // Elastic queue.
-		elasticN := serverConfig.GetElasticSearchNotifyByID(sqsARN.AccountID)
-		return elasticN.Enable && elasticN.URL != ""
-	} else if isRedisQueue(sqsARN) { // Redis queue.
-		redisN := serverConfig.GetRedisNotifyByID(sqsARN.AccountID)
-		return redisN.Enable && redisN.Addr != ""
-	}
-	return false
+	return isValidQueueTarget(sqsARN)
 }
 
 // Check - validates queue configuration and returns error if any.
@@ -346,23 +335,19 @@ func unmarshalTopicARN(topicARN string) arnTopic {
 
 // Unmarshals input value of AWS ARN format into minioSqs object.
 // Returned value represents minio sqs types, currently supported are
-// - amqp
-// - elasticsearch
-// - redis
+// all the types registered in notifyTargets.
 func unmarshalSqsARN(queueARN string) (mSqs arnSQS) {
 	mSqs = arnSQS{}
 	if !strings.HasPrefix(queueARN, minioSqs+serverConfig.GetRegion()+":") {
 		return mSqs
 	}
 	sqsType := strings.TrimPrefix(queueARN, minioSqs+serverConfig.GetRegion()+":")
-	switch {
-	case strings.HasSuffix(sqsType, queueTypeAMQP):
-		mSqs.Type = queueTypeAMQP
-	case strings.HasSuffix(sqsType, queueTypeElastic):
-		mSqs.Type = queueTypeElastic
-	case strings.HasSuffix(sqsType, queueTypeRedis):
-		mSqs.Type = queueTypeRedis
-	} // Add more queues here.
+	for queueType := range notifyTargets {
+		if strings.HasSuffix(sqsType, ":"+queueType) {
+			mSqs.Type = queueType
+			break
+		}
+	}
 	mSqs.AccountID = strings.TrimSuffix(sqsType, ":"+mSqs.Type)
 	return mSqs
 }
@@ -237,6 +237,23 @@ func TestValidEvents(t *testing.T) {
 			},
 			errCode: ErrNone,
 		},
+		// Return success for object accessed and delete marker events.
+		{
+			events: []string{
+				"s3:ObjectAccessed:*",
+				"s3:ObjectAccessed:Get",
+				"s3:ObjectAccessed:Head",
+				"s3:ObjectRemoved:DeleteMarkerCreated",
+			},
+			errCode: ErrNone,
+		},
+		// Return error for unknown object accessed event.
+		{
+			events: []string{
+				"s3:ObjectAccessed:Put",
+			},
+			errCode: ErrEventNotification,
+		},
 		// Return error for empty event list.
 		{
 			events:  []string{""},
@@ -477,6 +494,31 @@ func TestUnmarshalSqsARN(t *testing.T) {
 			queueARN: "arn:minio:sqs:us-east-1:1:amqp",
 			Type:     "amqp",
 		},
+		// Valid webhook queue arn.
+		{
+			queueARN: "arn:minio:sqs:us-east-1:1:webhook",
+			Type:     "webhook",
+		},
+		// Valid kafka queue arn.
+		{
+			queueARN: "arn:minio:sqs:us-east-1:1:kafka",
+			Type:     "kafka",
+		},
+		// Valid nats queue arn.
+		{
+			queueARN: "arn:minio:sqs:us-east-1:1:nats",
+			Type:     "nats",
+		},
+		// Valid postgresql queue arn.
+		{
+			queueARN: "arn:minio:sqs:us-east-1:1:postgresql",
+			Type:     "postgresql",
+		},
+		// Valid mysql queue arn.
+		{
+			queueARN: "arn:minio:sqs:us-east-1:1:mysql",
+			Type:     "mysql",
+		},
 		// Invalid empty queue arn.
 		{
 			queueARN: "",
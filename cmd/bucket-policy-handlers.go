@@ -21,7 +21,10 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 
 	mux "github.com/gorilla/mux"
 	"github.com/mf-00/minio/pkg/wildcard"
@@ -34,18 +37,25 @@ const maxAccessPolicySize = 20 * 1024 // 20KiB.
 // Verify if a given action is valid for the url path based on the
 // existing bucket access policy.
 func bucketPolicyEvalStatements(action string, resource string, conditions map[string]set.StringSet, statements []policyStatement) bool {
-	for _, statement := range statements {
+	allowed, _ := bucketPolicyEvalStatement(action, resource, conditions, statements)
+	return allowed
+}
+
+// bucketPolicyEvalStatement - same as bucketPolicyEvalStatements, also
+// returns the statement which decided the result, nil when none match.
+func bucketPolicyEvalStatement(action string, resource string, conditions map[string]set.StringSet, statements []policyStatement) (bool, *policyStatement) {
+	for i, statement := range statements {
 		if bucketPolicyMatchStatement(action, resource, conditions, statement) {
 			if statement.Effect == "Allow" {
-				return true
+				return true, &statements[i]
 			}
 			// Do not uncomment kept here for readability.
 			// else statement.Effect == "Deny"
-			return false
+			return false, &statements[i]
 		}
 	}
 	// None match so deny.
-	return false
+	return false, nil
 }
 
 // Verify if action, resource and conditions match input policy statement.
@@ -89,36 +99,106 @@ func bucketPolicyResourceMatch(resource string, statement policyStatement) bool
 
 // Verify if given condition matches with policy statement.
 func bucketPolicyConditionMatch(conditions map[string]set.StringSet, statement policyStatement) bool {
-	// Supports following conditions.
-	// - StringEquals
-	// - StringNotEquals
-	//
-	// Supported applicable condition keys for each conditions.
-	// - s3:prefix
-	// - s3:max-keys
-	var conditionMatches = true
+	// Every condition type and every key under it should match for
+	// the statement to apply, each key matches if any one of its
+	// policy values matches the request value.
 	for condition, conditionKeyVal := range statement.Conditions {
-		if condition == "StringEquals" {
-			if !conditionKeyVal["s3:prefix"].Equals(conditions["prefix"]) {
-				conditionMatches = false
-				break
-			}
-			if !conditionKeyVal["s3:max-keys"].Equals(conditions["max-keys"]) {
-				conditionMatches = false
-				break
+		for key, policyValues := range conditionKeyVal {
+			// Condition keys 's3:prefix', 's3:max-keys' are looked
+			// up by their query param names 'prefix', 'max-keys'.
+			requestValues := conditions[strings.TrimPrefix(key, "s3:")]
+			if !conditionTypeMatch(condition, policyValues, requestValues) {
+				return false
 			}
-		} else if condition == "StringNotEquals" {
-			if !conditionKeyVal["s3:prefix"].Equals(conditions["prefix"]) {
-				conditionMatches = false
-				break
-			}
-			if !conditionKeyVal["s3:max-keys"].Equals(conditions["max-keys"]) {
-				conditionMatches = false
-				break
+		}
+	}
+	return true
+}
+
+// conditionTypeMatch - verifies request values for a condition key
+// against the policy values as per the condition type. Negated
+// condition types match when the key is absent from the request.
+func conditionTypeMatch(condition string, policyValues, requestValues set.StringSet) bool {
+	switch condition {
+	case "StringEquals":
+		return anyConditionMatch(policyValues, requestValues, stringEqualsMatch)
+	case "StringNotEquals":
+		return !anyConditionMatch(policyValues, requestValues, stringEqualsMatch)
+	case "StringLike":
+		return anyConditionMatch(policyValues, requestValues, wildcard.MatchSimple)
+	case "StringNotLike":
+		return !anyConditionMatch(policyValues, requestValues, wildcard.MatchSimple)
+	case "IpAddress":
+		return anyConditionMatch(policyValues, requestValues, ipAddressMatch)
+	case "NotIpAddress":
+		return !anyConditionMatch(policyValues, requestValues, ipAddressMatch)
+	case "DateLessThan":
+		return anyConditionMatch(policyValues, requestValues, dateLessThanMatch)
+	case "DateGreaterThan":
+		return anyConditionMatch(policyValues, requestValues, dateGreaterThanMatch)
+	case "Bool":
+		return anyConditionMatch(policyValues, requestValues, strings.EqualFold)
+	}
+	// Unknown condition types are rejected while parsing the policy,
+	// never match them here either.
+	return false
+}
+
+// anyConditionMatch - returns true if any request value matches any
+// of the policy values using the match function.
+func anyConditionMatch(policyValues, requestValues set.StringSet, match func(policyValue, requestValue string) bool) bool {
+	for requestValue := range requestValues {
+		for policyValue := range policyValues {
+			if match(policyValue, requestValue) {
+				return true
 			}
 		}
 	}
-	return conditionMatches
+	return false
+}
+
+// Match function for 'StringEquals' conditions.
+func stringEqualsMatch(policyValue, requestValue string) bool {
+	return policyValue == requestValue
+}
+
+// Match function for 'IpAddress' conditions, policy value is either
+// an IP address or a CIDR block such as '192.168.1.0/24'.
+func ipAddressMatch(policyValue, requestValue string) bool {
+	ip := net.ParseIP(requestValue)
+	if ip == nil {
+		return false
+	}
+	if _, ipNet, err := net.ParseCIDR(policyValue); err == nil {
+		return ipNet.Contains(ip)
+	}
+	return ip.Equal(net.ParseIP(policyValue))
+}
+
+// Match function for 'DateLessThan' conditions, dates are in RFC3339.
+func dateLessThanMatch(policyValue, requestValue string) bool {
+	policyTime, err := time.Parse(time.RFC3339, policyValue)
+	if err != nil {
+		return false
+	}
+	requestTime, err := time.Parse(time.RFC3339, requestValue)
+	if err != nil {
+		return false
+	}
+	return requestTime.Before(policyTime)
+}
+
+// Match function for 'DateGreaterThan' conditions, dates are in RFC3339.
+func dateGreaterThanMatch(policyValue, requestValue string) bool {
+	policyTime, err := time.Parse(time.RFC3339, policyValue)
+	if err != nil {
+		return false
+	}
+	requestTime, err := time.Parse(time.RFC3339, requestValue)
+	if err != nil {
+		return false
+	}
+	return requestTime.After(policyTime)
 }
 
 // PutBucketPolicyHandler - PUT Bucket policy
@@ -262,6 +342,11 @@ func (api objectAPIHandlers) GetBucketPolicyHandler(w http.ResponseWriter, r *ht
 		// For all unknown auth types return error.
 		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
 		return
+	case authTypeAnonymous:
+		if s3Error := enforceBucketPolicy(bucket, "s3:GetBucketPolicy", r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
 	case authTypePresigned, authTypeSigned:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
@@ -83,6 +83,124 @@ func TestBucketPolicyResourceMatch(t *testing.T) {
 	}
 }
 
+// Tests validate Bucket policy condition matcher.
+func TestBucketPolicyConditionMatch(t *testing.T) {
+	// generates statement with a single condition.
+	generateStatement := func(condition, key string, values ...string) policyStatement {
+		statement := policyStatement{}
+		statement.Conditions = map[string]map[string]set.StringSet{
+			condition: {key: set.CreateStringSet(values...)},
+		}
+		return statement
+	}
+
+	// condition key values as collected from a request.
+	requestConditions := map[string]set.StringSet{
+		"prefix":              set.CreateStringSet("Asia/"),
+		"aws:SourceIp":        set.CreateStringSet("192.168.1.10"),
+		"aws:CurrentTime":     set.CreateStringSet("2016-11-01T10:00:00Z"),
+		"aws:SecureTransport": set.CreateStringSet("false"),
+		"aws:Referer":         set.CreateStringSet("http://www.example.com/index.html"),
+		"aws:UserAgent":       set.CreateStringSet("Minio (linux; amd64) minio-go/2.0.1"),
+	}
+
+	testCases := []struct {
+		statement      policyStatement
+		expectedResult bool
+	}{
+		// Test case - 1.
+		// Statement without conditions always matches.
+		{policyStatement{}, true},
+		// Test cases 2-5.
+		{generateStatement("StringEquals", "s3:prefix", "Asia/"), true},
+		{generateStatement("StringEquals", "s3:prefix", "Europe/"), false},
+		{generateStatement("StringNotEquals", "s3:prefix", "Asia/"), false},
+		{generateStatement("StringNotEquals", "s3:prefix", "Europe/"), true},
+		// Test cases 6-7.
+		// Key absent from the request.
+		{generateStatement("StringEquals", "s3:max-keys", "10"), false},
+		{generateStatement("StringNotEquals", "s3:max-keys", "10"), true},
+		// Test cases 8-11.
+		{generateStatement("StringLike", "aws:Referer", "http://*.example.com/*"), true},
+		{generateStatement("StringLike", "aws:Referer", "http://*.example.org/*"), false},
+		{generateStatement("StringNotLike", "aws:UserAgent", "*minio-go*"), false},
+		{generateStatement("StringLike", "aws:UserAgent", "*minio-go*", "*aws-sdk*"), true},
+		// Test cases 12-16.
+		{generateStatement("IpAddress", "aws:SourceIp", "192.168.1.0/24"), true},
+		{generateStatement("IpAddress", "aws:SourceIp", "192.168.1.10"), true},
+		{generateStatement("IpAddress", "aws:SourceIp", "10.0.0.0/8"), false},
+		{generateStatement("NotIpAddress", "aws:SourceIp", "10.0.0.0/8"), true},
+		{generateStatement("NotIpAddress", "aws:SourceIp", "192.168.0.0/16"), false},
+		// Test cases 17-20.
+		{generateStatement("DateGreaterThan", "aws:CurrentTime", "2016-10-01T00:00:00Z"), true},
+		{generateStatement("DateGreaterThan", "aws:CurrentTime", "2016-12-01T00:00:00Z"), false},
+		{generateStatement("DateLessThan", "aws:CurrentTime", "2016-12-01T00:00:00Z"), true},
+		{generateStatement("DateLessThan", "aws:CurrentTime", "2016-10-01T00:00:00Z"), false},
+		// Test cases 21-22.
+		{generateStatement("Bool", "aws:SecureTransport", "false"), true},
+		{generateStatement("Bool", "aws:SecureTransport", "true"), false},
+		// Test case - 23.
+		// All conditions of a statement should match.
+		{policyStatement{Conditions: map[string]map[string]set.StringSet{
+			"IpAddress":    {"aws:SourceIp": set.CreateStringSet("192.168.1.0/24")},
+			"StringEquals": {"s3:prefix": set.CreateStringSet("Europe/")},
+		}}, false},
+	}
+	for i, testCase := range testCases {
+		actualResult := bucketPolicyConditionMatch(requestConditions, testCase.statement)
+		if testCase.expectedResult != actualResult {
+			t.Errorf("Test %d: Expected condition match to be `%v`, but instead found it to be `%v`", i+1, testCase.expectedResult, actualResult)
+		}
+	}
+}
+
+// Tests validate condition keys collected from the request.
+func TestGetConditionKeyMap(t *testing.T) {
+	req, err := http.NewRequest("GET", "http://127.0.0.1:9000/bucket?prefix=Asia/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.RemoteAddr = "192.168.1.10:51234"
+	req.Header.Set("Referer", "http://www.example.com/")
+	req.Header.Set("User-Agent", "minio-go")
+
+	conditions := getConditionKeyMap(req)
+	expected := map[string]string{
+		"prefix":              "Asia/",
+		"aws:SourceIp":        "192.168.1.10",
+		"aws:SecureTransport": "false",
+		"aws:Referer":         "http://www.example.com/",
+		"aws:UserAgent":       "minio-go",
+	}
+	for key, value := range expected {
+		if !conditions[key].Equals(set.CreateStringSet(value)) {
+			t.Errorf("Expected condition key %s to be %s, but found %v", key, value, conditions[key])
+		}
+	}
+	if conditions["aws:CurrentTime"].IsEmpty() {
+		t.Error("Expected condition key aws:CurrentTime to be set")
+	}
+
+	// Query params can't set the values of 'aws:' condition keys.
+	req, err = http.NewRequest("GET", "http://127.0.0.1:9000/bucket?aws:UserAgent=x&aws:Referer=https://trusted/&aws:SourceIp=10.0.0.1&max-keys=10", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.RemoteAddr = "192.168.1.10:51234"
+	conditions = getConditionKeyMap(req)
+	for _, key := range []string{"aws:UserAgent", "aws:Referer"} {
+		if _, ok := conditions[key]; ok {
+			t.Errorf("Expected condition key %s to be unset, but found %v", key, conditions[key])
+		}
+	}
+	if !conditions["aws:SourceIp"].Equals(set.CreateStringSet("192.168.1.10")) {
+		t.Errorf("Expected condition key aws:SourceIp to be 192.168.1.10, but found %v", conditions["aws:SourceIp"])
+	}
+	if !conditions["max-keys"].Equals(set.CreateStringSet("10")) {
+		t.Errorf("Expected condition key max-keys to be 10, but found %v", conditions["max-keys"])
+	}
+}
+
 // TestBucketPolicyActionMatch - Test validates whether given action on the
 // bucket/object matches the allowed actions in policyStatement.
 // This test preserves the allowed actions for all 3 sets of policies, that is read-write,read-only, write-only.
@@ -541,3 +659,129 @@ func testDeleteBucketPolicyHandler(obj ObjectLayer, instanceType string, t TestE
 
 	}
 }
+
+// Wrapper for calling anonymous bucket level action tests for both XL multiple disks and single node setup.
+func TestBucketPolicyAnonymousBucketActions(t *testing.T) {
+	ExecObjectLayerTest(t, testBucketPolicyAnonymousBucketActions)
+}
+
+// testBucketPolicyAnonymousBucketActions - Test validates that bucket level
+// actions such as s3:GetBucketPolicy and s3:ListAllMyBuckets are enforced
+// for anonymous requests.
+func testBucketPolicyAnonymousBucketActions(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	// get random bucket names, only the first one is granted access.
+	bucketName := getRandomBucketName()
+	otherBucketName := getRandomBucketName()
+	for _, bucket := range []string{bucketName, otherBucketName} {
+		if err := obj.MakeBucket(bucket); err != nil {
+			// failed to create newbucket, abort.
+			t.Fatalf("%s : %s", instanceType, err)
+		}
+	}
+	apiRouter := initTestAPIEndPoints(obj, []string{"GetBucketPolicy", "ListBuckets"})
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	// Anonymous requests are denied before the policy is set.
+	testAnonymousRequest := func(i int, url string, expectedRespStatus int) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, err := newTestRequest("GET", url, 0, nil)
+		if err != nil {
+			t.Fatalf("Test %d: %s: Failed to create HTTP request: <ERROR> %v", i, instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != expectedRespStatus {
+			t.Errorf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i, instanceType, expectedRespStatus, rec.Code)
+		}
+		return rec
+	}
+	testAnonymousRequest(1, getGetPolicyURL("", bucketName), http.StatusForbidden)
+	testAnonymousRequest(2, getListBucketURL(""), http.StatusForbidden)
+
+	policyDoc := fmt.Sprintf(`{"Version":"2012-10-17","Statement":[{"Action":["s3:GetBucketPolicy","s3:ListAllMyBuckets"],"Effect":"Allow","Principal":{"AWS":["*"]},"Resource":["arn:aws:s3:::%s"],"Sid":""}]}`, bucketName)
+	if err = writeBucketPolicy(bucketName, obj, bytes.NewReader([]byte(policyDoc)), int64(len(policyDoc))); err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+
+	rec := testAnonymousRequest(3, getGetPolicyURL("", bucketName), http.StatusOK)
+	if !bytes.Equal([]byte(policyDoc), rec.Body.Bytes()) {
+		t.Errorf("Test 3: %s: Expected the policy to be `%s`, but instead found `%s`", instanceType, policyDoc, rec.Body.String())
+	}
+	testAnonymousRequest(4, getGetPolicyURL("", otherBucketName), http.StatusForbidden)
+
+	// Only the bucket granting s3:ListAllMyBuckets is listed.
+	rec = testAnonymousRequest(5, getListBucketURL(""), http.StatusOK)
+	if !bytes.Contains(rec.Body.Bytes(), []byte(bucketName)) || bytes.Contains(rec.Body.Bytes(), []byte(otherBucketName)) {
+		t.Errorf("Test 5: %s: Expected only %s to be listed, but instead found `%s`", instanceType, bucketName, rec.Body.String())
+	}
+}
+
+// Wrapper for calling anonymous owner only action tests for both XL multiple disks and single node setup.
+func TestBucketPolicyAnonymousOwnerOnlyActions(t *testing.T) {
+	ExecObjectLayerTest(t, testBucketPolicyAnonymousOwnerOnlyActions)
+}
+
+// testBucketPolicyAnonymousOwnerOnlyActions - Test validates that
+// anonymous requests cannot change bucket policies or delete the
+// bucket, even with a policy granting all actions.
+func testBucketPolicyAnonymousOwnerOnlyActions(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucketName := getRandomBucketName()
+	if err := obj.MakeBucket(bucketName); err != nil {
+		// failed to create newbucket, abort.
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+	apiRouter := initTestAPIEndPoints(obj, []string{"PutBucketPolicy", "DeleteBucketPolicy", "DeleteBucket"})
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	policyDoc := fmt.Sprintf(`{"Version":"2012-10-17","Statement":[{"Action":["s3:*"],"Effect":"Allow","Principal":{"AWS":["*"]},"Resource":["arn:aws:s3:::%s","arn:aws:s3:::%s/*"],"Sid":""}]}`, bucketName, bucketName)
+	if err = writeBucketPolicy(bucketName, obj, bytes.NewReader([]byte(policyDoc)), int64(len(policyDoc))); err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+
+	newPolicyDoc := fmt.Sprintf(`{"Version":"2012-10-17","Statement":[{"Action":["s3:GetObject"],"Effect":"Allow","Principal":{"AWS":["*"]},"Resource":["arn:aws:s3:::%s/*"],"Sid":""}]}`, bucketName)
+	testCases := []struct {
+		method string
+		url    string
+		body   []byte
+	}{
+		{"PUT", getPutPolicyURL("", bucketName), []byte(newPolicyDoc)},
+		{"DELETE", getDeletePolicyURL("", bucketName), nil},
+		{"DELETE", getDeleteBucketURL("", bucketName), nil},
+	}
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		req, err := newTestRequest(testCase.method, testCase.url, int64(len(testCase.body)), bytes.NewReader(testCase.body))
+		if err != nil {
+			t.Fatalf("Test %d: %s: Failed to create HTTP request: <ERROR> %v", i+1, instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, http.StatusForbidden, rec.Code)
+		}
+	}
+
+	// Neither the policy nor the bucket were changed.
+	policyReader, err := readBucketPolicyJSON(bucketName, obj)
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	policy, err := ioutil.ReadAll(policyReader)
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if !bytes.Equal(policy, []byte(policyDoc)) {
+		t.Errorf("%s: Expected the policy to be `%s`, but instead found `%s`", instanceType, policyDoc, policy)
+	}
+	if _, err = obj.GetBucketInfo(bucketName); err != nil {
+		t.Errorf("%s: Expected the bucket to exist, got %v", instanceType, err)
+	}
+}
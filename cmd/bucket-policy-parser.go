@@ -23,9 +23,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"path"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/minio/minio-go/pkg/set"
 )
@@ -38,14 +40,35 @@ const (
 // supportedActionMap - lists all the actions supported by minio.
 var supportedActionMap = set.CreateStringSet("*", "*", "s3:*", "s3:GetObject",
 	"s3:ListBucket", "s3:PutObject", "s3:GetBucketLocation", "s3:DeleteObject",
-	"s3:AbortMultipartUpload", "s3:ListBucketMultipartUploads", "s3:ListMultipartUploadParts")
+	"s3:AbortMultipartUpload", "s3:ListBucketMultipartUploads", "s3:ListMultipartUploadParts",
+	"s3:GetBucketPolicy", "s3:PutBucketPolicy", "s3:DeleteBucketPolicy",
+	"s3:GetBucketNotification", "s3:PutBucketNotification", "s3:ListenBucketNotification",
+	"s3:DeleteBucket", "s3:ListAllMyBuckets", "s3:GetBucketAcl", "s3:PutBucketAcl",
+	"s3:GetObjectAcl", "s3:PutObjectAcl", "s3:GetBucketObjectLockConfiguration",
+	"s3:PutBucketObjectLockConfiguration", "s3:GetObjectRetention", "s3:PutObjectRetention",
+	"s3:GetObjectLegalHold", "s3:PutObjectLegalHold")
+
+// ownerOnlyActions - actions changing the configuration of a bucket,
+// policies may name them but anonymous requests are never allowed
+// them, not even by wildcard actions.
+var ownerOnlyActions = set.CreateStringSet("s3:PutBucketPolicy", "s3:DeleteBucketPolicy",
+	"s3:PutBucketNotification", "s3:DeleteBucket", "s3:PutBucketAcl",
+	"s3:PutBucketObjectLockConfiguration")
 
 // supported Conditions type.
-var supportedConditionsType = set.CreateStringSet("StringEquals", "StringNotEquals")
+var supportedConditionsType = set.CreateStringSet("StringEquals", "StringNotEquals",
+	"StringLike", "StringNotLike", "IpAddress", "NotIpAddress",
+	"DateLessThan", "DateGreaterThan", "Bool")
 
 // Validate s3:prefix, s3:max-keys are present if not
 // supported keys for the conditions.
-var supportedConditionsKey = set.CreateStringSet("s3:prefix", "s3:max-keys")
+var supportedConditionsKey = set.CreateStringSet("s3:prefix", "s3:max-keys",
+	"aws:SourceIp", "aws:CurrentTime", "aws:SecureTransport", "aws:Referer",
+	"aws:UserAgent")
+
+// Condition types whose values are compared as exact strings, the same
+// value under more than one of them makes the policy ambiguous.
+var stringEqualityConditionsType = set.CreateStringSet("StringEquals", "StringNotEquals")
 
 // supportedEffectMap - supported effects.
 var supportedEffectMap = set.CreateStringSet("Allow", "Deny")
@@ -200,6 +223,15 @@ func isValidConditions(conditions map[string]map[string]set.StringSet) (err erro
 				err = fmt.Errorf("Unsupported condition key '%s', please validate your policy document.", conditionType)
 				return err
 			}
+			for conditionValue := range value {
+				if !isValidConditionValue(conditionType, conditionValue) {
+					err = fmt.Errorf("Invalid condition value '%s' for condition type '%s', please validate your policy document.", conditionValue, conditionType)
+					return err
+				}
+			}
+			if !stringEqualityConditionsType.Contains(conditionType) {
+				continue
+			}
 			conditionVal, ok := conditionKeyVal[key]
 			if ok && !value.Intersection(conditionVal).IsEmpty() {
 				err = fmt.Errorf("Ambigious condition values for key '%s', please validate your policy document.", key)
@@ -211,11 +243,41 @@ func isValidConditions(conditions map[string]map[string]set.StringSet) (err erro
 	return nil
 }
 
+// isValidConditionValue - validates the format of a condition value
+// for condition types which do not compare plain strings.
+func isValidConditionValue(conditionType, value string) bool {
+	switch conditionType {
+	case "IpAddress", "NotIpAddress":
+		if _, _, err := net.ParseCIDR(value); err == nil {
+			return true
+		}
+		return net.ParseIP(value) != nil
+	case "DateLessThan", "DateGreaterThan":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "Bool":
+		return value == "true" || value == "false"
+	}
+	return true
+}
+
 // List of actions for which prefixes are not allowed.
 var invalidPrefixActions = set.StringSet{
-	"s3:GetBucketLocation":          {},
-	"s3:ListBucket":                 {},
-	"s3:ListBucketMultipartUploads": {},
+	"s3:GetBucketLocation":                {},
+	"s3:ListBucket":                       {},
+	"s3:ListBucketMultipartUploads":       {},
+	"s3:GetBucketPolicy":                  {},
+	"s3:PutBucketPolicy":                  {},
+	"s3:DeleteBucketPolicy":               {},
+	"s3:GetBucketNotification":            {},
+	"s3:PutBucketNotification":            {},
+	"s3:ListenBucketNotification":         {},
+	"s3:DeleteBucket":                     {},
+	"s3:ListAllMyBuckets":                 {},
+	"s3:GetBucketAcl":                     {},
+	"s3:PutBucketAcl":                     {},
+	"s3:GetBucketObjectLockConfiguration": {},
+	"s3:PutBucketObjectLockConfiguration": {},
 	// Add actions which do not honor prefixes.
 }
 
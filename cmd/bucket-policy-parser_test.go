@@ -199,6 +199,13 @@ func TestIsValidActions(t *testing.T) {
 			"s3:PutObject", "s3:GetBucketLocation", "s3:DeleteObject",
 			"s3:AbortMultipartUpload", "s3:ListBucketMultipartUploads",
 			"s3:ListMultipartUploadParts"}...), nil, true},
+		// Test Case - 5.
+		// Bucket level actions.
+		{set.CreateStringSet([]string{
+			"s3:GetBucketPolicy", "s3:PutBucketPolicy", "s3:DeleteBucketPolicy",
+			"s3:GetBucketNotification", "s3:PutBucketNotification",
+			"s3:ListenBucketNotification", "s3:DeleteBucket",
+			"s3:ListAllMyBuckets"}...), nil, true},
 	}
 	for i, testCase := range testCases {
 		err := isValidActions(testCase.actions)
@@ -421,6 +428,14 @@ func TestIsValidConditions(t *testing.T) {
 		generateConditions("StringEquals", "s3:max-keys", "100"),
 		generateConditions("StringNotEquals", "s3:prefix", "Asia/"),
 		generateConditions("StringNotEquals", "s3:max-keys", "100"),
+		generateConditions("IpAddress", "aws:SourceIp", "192.168.1.0/24"),
+		generateConditions("NotIpAddress", "aws:SourceIp", "10.1.1.1"),
+		generateConditions("DateGreaterThan", "aws:CurrentTime", "2016-10-01T00:00:00Z"),
+		generateConditions("Bool", "aws:SecureTransport", "true"),
+		generateConditions("StringLike", "aws:Referer", "http://*.example.com/*"),
+		generateConditions("IpAddress", "aws:SourceIp", "192.168.1"),
+		generateConditions("DateLessThan", "aws:CurrentTime", "2016-10-01"),
+		generateConditions("Bool", "aws:SecureTransport", "yes"),
 	}
 
 	testCases := []struct {
@@ -466,6 +481,25 @@ func TestIsValidConditions(t *testing.T) {
 		{testConditions[10], nil, true},
 		// Test case 10.
 		{testConditions[11], nil, true},
+		// Test cases 13-17.
+		// Test cases with valid conditions of the newer condition types.
+		{testConditions[12], nil, true},
+		{testConditions[13], nil, true},
+		{testConditions[14], nil, true},
+		{testConditions[15], nil, true},
+		{testConditions[16], nil, true},
+		// Test case - 18.
+		// Test case with an invalid IP address.
+		{testConditions[17], fmt.Errorf("Invalid condition value '192.168.1' for condition type 'IpAddress', " +
+			"please validate your policy document."), false},
+		// Test case - 19.
+		// Test case with a date not in RFC3339 format.
+		{testConditions[18], fmt.Errorf("Invalid condition value '2016-10-01' for condition type 'DateLessThan', " +
+			"please validate your policy document."), false},
+		// Test case - 20.
+		// Test case with an invalid boolean.
+		{testConditions[19], fmt.Errorf("Invalid condition value 'yes' for condition type 'Bool', " +
+			"please validate your policy document."), false},
 	}
 	for i, testCase := range testCases {
 		actualErr := isValidConditions(testCase.inputCondition)
@@ -0,0 +1,143 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Interval at which the certs directory is checked for changes.
+const certsReloadInterval = 10 * time.Second
+
+// certManager - serves the TLS certificates found in the certs
+// directory, certificates are reloaded whenever they change on disk.
+// Since certificates are looked up on every TLS handshake, already
+// established connections are not affected by a reload.
+type certManager struct {
+	sync.RWMutex
+	certsPath  string
+	certs      []tls.Certificate
+	nameToCert map[string]*tls.Certificate
+	modTime    time.Time
+}
+
+// newCertManager - loads all the certificates from certsPath.
+func newCertManager(certsPath string) (*certManager, error) {
+	m := &certManager{certsPath: certsPath}
+	if err := m.reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Returns the latest modification time of all the files in the certs
+// directory, any file added, removed or replaced changes it.
+func getCertsModTime(certsPath string) (modTime time.Time, err error) {
+	err = filepath.Walk(certsPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+		return nil
+	})
+	return modTime, err
+}
+
+// reload - loads all the certificates again, the previously loaded
+// certificates are kept if the new ones cannot be loaded.
+func (m *certManager) reload() error {
+	modTime, err := getCertsModTime(m.certsPath)
+	if err != nil {
+		return err
+	}
+	certs, err := loadX509KeyPairs(m.certsPath)
+	if err != nil {
+		return err
+	}
+	nameToCert := make(map[string]*tls.Certificate)
+	for i := range certs {
+		cert := &certs[i]
+		if len(cert.Leaf.Subject.CommonName) > 0 {
+			nameToCert[strings.ToLower(cert.Leaf.Subject.CommonName)] = cert
+		}
+		for _, name := range cert.Leaf.DNSNames {
+			nameToCert[strings.ToLower(name)] = cert
+		}
+	}
+
+	m.Lock()
+	m.certs = certs
+	m.nameToCert = nameToCert
+	m.modTime = modTime
+	m.Unlock()
+	return nil
+}
+
+// isModified - returns true if the certs directory changed since the last reload.
+func (m *certManager) isModified() bool {
+	modTime, err := getCertsModTime(m.certsPath)
+	if err != nil {
+		return false
+	}
+	m.RLock()
+	defer m.RUnlock()
+	return !modTime.Equal(m.modTime)
+}
+
+// watch - reloads the certificates whenever the certs directory
+// changes, until doneCh is closed.
+func (m *certManager) watch(interval time.Duration, doneCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !m.isModified() {
+				continue
+			}
+			errorIf(m.reload(), "Unable to reload certificates from %s.", m.certsPath)
+		case <-doneCh:
+			return
+		}
+	}
+}
+
+// GetCertificate - returns the certificate matching the server name
+// requested through SNI, falls back to the default certificate.
+func (m *certManager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	name := strings.TrimSuffix(strings.ToLower(hello.ServerName), ".")
+	if cert, ok := m.nameToCert[name]; ok {
+		return cert, nil
+	}
+	// Try the wildcard certificate for the parent domain.
+	if labels := strings.SplitN(name, ".", 2); len(labels) == 2 {
+		if cert, ok := m.nameToCert["*."+labels[1]]; ok {
+			return cert, nil
+		}
+	}
+	return &m.certs[0], nil
+}
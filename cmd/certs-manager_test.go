@@ -0,0 +1,155 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Generates a self-signed certificate for hosts in dir.
+func generateTestCertIn(t *testing.T, dir string, hosts ...string) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	err := generateSelfSignedCert(hosts, time.Hour, filepath.Join(dir, globalMinioCertFile), filepath.Join(dir, globalMinioKeyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Tests certificate selection by SNI and reloading of certificates.
+func TestCertManager(t *testing.T) {
+	certsPath, err := ioutil.TempDir("", "minio-certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(certsPath)
+
+	// No default certificate.
+	if _, err = newCertManager(certsPath); err == nil {
+		t.Fatal("Expected to fail without a default certificate")
+	}
+
+	generateTestCertIn(t, certsPath, "localhost", "127.0.0.1")
+	generateTestCertIn(t, filepath.Join(certsPath, "example.com"), "minio.example.com")
+	generateTestCertIn(t, filepath.Join(certsPath, "wildcard"), "*.minio.io")
+
+	m, err := newCertManager(certsPath)
+	if err != nil {
+		t.Fatalf("Unable to load certificates: %s", err)
+	}
+
+	testCases := []struct {
+		serverName   string
+		expectedName string
+	}{
+		{"", "localhost"},
+		{"localhost", "localhost"},
+		{"unknown.host", "localhost"},
+		{"MINIO.example.com", "minio.example.com"},
+		{"play.minio.io", "*.minio.io"},
+		{"play.minio.io.", "*.minio.io"},
+	}
+	for i, testCase := range testCases {
+		cert, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: testCase.serverName})
+		if err != nil {
+			t.Fatalf("Test %d: Unexpected error: %s", i+1, err)
+		}
+		if cert.Leaf.DNSNames[0] != testCase.expectedName {
+			t.Errorf("Test %d: Expected certificate for %s, got %s", i+1, testCase.expectedName, cert.Leaf.DNSNames[0])
+		}
+	}
+
+	if m.isModified() {
+		t.Fatal("Expected certificates to be unmodified")
+	}
+
+	// Replace the default certificate, make sure its modtime differs.
+	generateTestCertIn(t, certsPath, "minio.local")
+	future := time.Now().Add(time.Minute)
+	if err = os.Chtimes(filepath.Join(certsPath, globalMinioCertFile), future, future); err != nil {
+		t.Fatal(err)
+	}
+	if !m.isModified() {
+		t.Fatal("Expected certificates to be modified")
+	}
+	if err = m.reload(); err != nil {
+		t.Fatalf("Unable to reload certificates: %s", err)
+	}
+	cert, err := m.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Leaf.DNSNames[0] != "minio.local" {
+		t.Errorf("Expected the reloaded certificate, got %s", cert.Leaf.DNSNames[0])
+	}
+
+	// Broken certificates keep the previously loaded ones.
+	if err = ioutil.WriteFile(filepath.Join(certsPath, globalMinioCertFile), []byte("invalid"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.reload(); err == nil {
+		t.Fatal("Expected reload of an invalid certificate to fail")
+	}
+	if cert, _ = m.GetCertificate(&tls.ClientHelloInfo{}); cert.Leaf.DNSNames[0] != "minio.local" {
+		t.Errorf("Expected the previous certificate to be served, got %s", cert.Leaf.DNSNames[0])
+	}
+}
+
+// Tests loading of custom CAs.
+func TestLoadRootCAs(t *testing.T) {
+	caPath, err := ioutil.TempDir("", "minio-cas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(caPath)
+
+	// Missing CA directory is not an error.
+	if _, err = loadRootCAs(filepath.Join(caPath, "missing")); err != nil {
+		t.Fatalf("Unexpected error for a missing CA directory: %s", err)
+	}
+
+	// Self-signed certificates act as their own CA.
+	serverPath := filepath.Join(caPath, "server")
+	generateTestCertIn(t, serverPath, "minio.local")
+	data, err := ioutil.ReadFile(filepath.Join(serverPath, globalMinioCertFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(caPath, "minio.crt"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	rootCAs, err := loadRootCAs(caPath)
+	if err != nil {
+		t.Fatalf("Unable to load CAs: %s", err)
+	}
+
+	// A certificate issued by the custom CA is trusted.
+	certs, err := loadX509KeyPairs(serverPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = certs[0].Leaf.Verify(x509.VerifyOptions{DNSName: "minio.local", Roots: rootCAs}); err != nil {
+		t.Fatalf("Expected the certificate to be trusted: %s", err)
+	}
+}
@@ -17,6 +17,9 @@
 package cmd
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 )
@@ -48,6 +51,11 @@ func mustGetCertsPath() string {
 	return certsPath
 }
 
+// mustGetCertsCAPath must get the path of the CA certificates trusted for peer connections.
+func mustGetCertsCAPath() string {
+	return filepath.Join(mustGetCertsPath(), globalMinioCertsCADir)
+}
+
 // mustGetCertFile must get cert file.
 func mustGetCertFile() string {
 	return filepath.Join(mustGetCertsPath(), globalMinioCertFile)
@@ -87,3 +95,71 @@ func isSSL() bool {
 	}
 	return false
 }
+
+// loadRootCAs - returns the system CAs along with all the CA
+// certificates found in caPath, a missing caPath is not an error.
+func loadRootCAs(caPath string) (*x509.CertPool, error) {
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		// System CAs are not available on all platforms.
+		rootCAs = x509.NewCertPool()
+	}
+	caFiles, err := ioutil.ReadDir(caPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return rootCAs, nil
+		}
+		return nil, err
+	}
+	for _, caFile := range caFiles {
+		if !caFile.Mode().IsRegular() {
+			continue
+		}
+		caCert, err := ioutil.ReadFile(filepath.Join(caPath, caFile.Name()))
+		if err != nil {
+			return nil, err
+		}
+		rootCAs.AppendCertsFromPEM(caCert)
+	}
+	return rootCAs, nil
+}
+
+// loadX509KeyPairs - loads the default key pair from certsPath and
+// the additional key pairs present in its sub-directories, each of
+// them holding a public.crt and private.key. The default key pair
+// is always the first one.
+func loadX509KeyPairs(certsPath string) ([]tls.Certificate, error) {
+	var certs []tls.Certificate
+	loadKeyPair := func(dir string) error {
+		cert, err := tls.LoadX509KeyPair(filepath.Join(dir, globalMinioCertFile), filepath.Join(dir, globalMinioKeyFile))
+		if err != nil {
+			return err
+		}
+		// Parse the leaf certificate to select certificates by server name.
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return err
+		}
+		certs = append(certs, cert)
+		return nil
+	}
+	if err := loadKeyPair(certsPath); err != nil {
+		return nil, err
+	}
+	entries, err := ioutil.ReadDir(certsPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == globalMinioCertsCADir {
+			continue
+		}
+		dir := filepath.Join(certsPath, entry.Name())
+		if _, err = os.Stat(filepath.Join(dir, globalMinioCertFile)); os.IsNotExist(err) {
+			continue
+		}
+		if err = loadKeyPair(dir); err != nil {
+			return nil, err
+		}
+	}
+	return certs, nil
+}
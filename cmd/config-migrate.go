@@ -50,6 +50,10 @@ func migrateConfig() error {
 	if err := migrateV6ToV7(); err != nil {
 		return err
 	}
+	// Migrate version '7' to '8'.
+	if err := migrateV7ToV8(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -331,8 +335,8 @@ func migrateV6ToV7() error {
 	}
 
 	// Save only the new fields, ignore the rest.
-	srvConfig := &serverConfigV7{}
-	srvConfig.Version = globalMinioConfigVersion
+	srvConfig := &configV7{}
+	srvConfig.Version = "7"
 	srvConfig.Credential = cv6.Credential
 	srvConfig.Region = cv6.Region
 	if srvConfig.Region == "" {
@@ -378,3 +382,72 @@ func migrateV6ToV7() error {
 	console.Println("Migration from version ‘" + cv6.Version + "’ to ‘" + srvConfig.Version + "’ completed successfully.")
 	return nil
 }
+
+// Version '7' to '8' migrates config, adds new notification targets webhook,
+// kafka, nats, postgresql and mysql.
+func migrateV7ToV8() error {
+	cv7, err := loadConfigV7()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("Unable to load config version ‘7’. %v", err)
+	}
+	if cv7.Version != "7" {
+		return nil
+	}
+
+	// Save only the new fields, ignore the rest.
+	srvConfig := &serverConfigV8{}
+	srvConfig.Version = "8"
+	srvConfig.Credential = cv7.Credential
+	srvConfig.Region = cv7.Region
+	if srvConfig.Region == "" {
+		// Region needs to be set for AWS Signature Version 4.
+		srvConfig.Region = defaultRegion
+	}
+	srvConfig.Logger.Console = cv7.Logger.Console
+	srvConfig.Logger.File = cv7.Logger.File
+	srvConfig.Logger.Syslog = cv7.Logger.Syslog
+	srvConfig.Notify.AMQP = make(map[string]amqpNotify)
+	srvConfig.Notify.ElasticSearch = make(map[string]elasticSearchNotify)
+	srvConfig.Notify.Redis = make(map[string]redisNotify)
+	if len(cv7.Notify.AMQP) == 0 {
+		srvConfig.Notify.AMQP["1"] = amqpNotify{}
+	} else {
+		srvConfig.Notify.AMQP = cv7.Notify.AMQP
+	}
+	if len(cv7.Notify.ElasticSearch) == 0 {
+		srvConfig.Notify.ElasticSearch["1"] = elasticSearchNotify{}
+	} else {
+		srvConfig.Notify.ElasticSearch = cv7.Notify.ElasticSearch
+	}
+	if len(cv7.Notify.Redis) == 0 {
+		srvConfig.Notify.Redis["1"] = redisNotify{}
+	} else {
+		srvConfig.Notify.Redis = cv7.Notify.Redis
+	}
+	// New notification targets start with a disabled default entry.
+	srvConfig.Notify.Webhook = map[string]webhookNotify{"1": {}}
+	srvConfig.Notify.Kafka = map[string]kafkaNotify{"1": {}}
+	srvConfig.Notify.NATS = map[string]natsNotify{"1": {}}
+	srvConfig.Notify.PostgreSQL = map[string]postgreSQLNotify{"1": {}}
+	srvConfig.Notify.MySQL = map[string]mySQLNotify{"1": {}}
+
+	qc, err := quick.New(srvConfig)
+	if err != nil {
+		return fmt.Errorf("Unable to initialize the quick config. %v", err)
+	}
+	configFile, err := getConfigFile()
+	if err != nil {
+		return fmt.Errorf("Unable to get config file. %v", err)
+	}
+
+	err = qc.Save(configFile)
+	if err != nil {
+		return fmt.Errorf("Failed to migrate config from ‘"+cv7.Version+"’ to ‘"+srvConfig.Version+"’ failed. %v", err)
+	}
+
+	console.Println("Migration from version ‘" + cv7.Version + "’ to ‘" + srvConfig.Version + "’ completed successfully.")
+	return nil
+}
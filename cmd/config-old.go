@@ -287,7 +287,7 @@ type configV6 struct {
 	Logger logger `json:"logger"`
 
 	// Notification queue configuration.
-	Notify notifier `json:"notify"`
+	Notify notifierV1 `json:"notify"`
 }
 
 // loadConfigV6 load config version '6'.
@@ -310,3 +310,47 @@ func loadConfigV6() (*configV6, error) {
 	}
 	return c, nil
 }
+
+// Notifier represents collection of supported notification queues in version
+// '6' and '7'.
+type notifierV1 struct {
+	AMQP          map[string]amqpNotify          `json:"amqp"`
+	ElasticSearch map[string]elasticSearchNotify `json:"elasticsearch"`
+	Redis         map[string]redisNotify         `json:"redis"`
+}
+
+// configV7 server configuration version '7'.
+type configV7 struct {
+	Version string `json:"version"`
+
+	// S3 API configuration.
+	Credential credential `json:"credential"`
+	Region     string     `json:"region"`
+
+	// Additional error logging configuration.
+	Logger logger `json:"logger"`
+
+	// Notification queue configuration.
+	Notify notifierV1 `json:"notify"`
+}
+
+// loadConfigV7 load config version '7'.
+func loadConfigV7() (*configV7, error) {
+	configFile, err := getConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = os.Stat(configFile); err != nil {
+		return nil, err
+	}
+	c := &configV7{}
+	c.Version = "7"
+	qc, err := quick.New(c)
+	if err != nil {
+		return nil, err
+	}
+	if err := qc.Load(configFile); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
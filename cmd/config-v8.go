@@ -23,8 +23,8 @@ import (
 	"github.com/mf-00/minio/pkg/quick"
 )
 
-// serverConfigV7 server configuration version '7'.
-type serverConfigV7 struct {
+// serverConfigV8 server configuration version '8'.
+type serverConfigV8 struct {
 	Version string `json:"version"`
 
 	// S3 API configuration.
@@ -45,7 +45,7 @@ type serverConfigV7 struct {
 func initConfig() error {
 	if !isConfigFileExists() {
 		// Initialize server config.
-		srvCfg := &serverConfigV7{}
+		srvCfg := &serverConfigV8{}
 		srvCfg.Version = globalMinioConfigVersion
 		srvCfg.Region = defaultRegion
 		srvCfg.Credential = mustGenAccessKeys()
@@ -63,6 +63,16 @@ func initConfig() error {
 		srvCfg.Notify.ElasticSearch["1"] = elasticSearchNotify{}
 		srvCfg.Notify.Redis = make(map[string]redisNotify)
 		srvCfg.Notify.Redis["1"] = redisNotify{}
+		srvCfg.Notify.Webhook = make(map[string]webhookNotify)
+		srvCfg.Notify.Webhook["1"] = webhookNotify{}
+		srvCfg.Notify.Kafka = make(map[string]kafkaNotify)
+		srvCfg.Notify.Kafka["1"] = kafkaNotify{}
+		srvCfg.Notify.NATS = make(map[string]natsNotify)
+		srvCfg.Notify.NATS["1"] = natsNotify{}
+		srvCfg.Notify.PostgreSQL = make(map[string]postgreSQLNotify)
+		srvCfg.Notify.PostgreSQL["1"] = postgreSQLNotify{}
+		srvCfg.Notify.MySQL = make(map[string]mySQLNotify)
+		srvCfg.Notify.MySQL["1"] = mySQLNotify{}
 		srvCfg.rwMutex = &sync.RWMutex{}
 
 		// Create config path.
@@ -84,7 +94,7 @@ func initConfig() error {
 	if _, err = os.Stat(configFile); err != nil {
 		return err
 	}
-	srvCfg := &serverConfigV7{}
+	srvCfg := &serverConfigV8{}
 	srvCfg.Version = globalMinioConfigVersion
 	srvCfg.rwMutex = &sync.RWMutex{}
 	qc, err := quick.New(srvCfg)
@@ -103,10 +113,10 @@ func initConfig() error {
 }
 
 // serverConfig server config.
-var serverConfig *serverConfigV7
+var serverConfig *serverConfigV8
 
 // GetVersion get current config version.
-func (s serverConfigV7) GetVersion() string {
+func (s serverConfigV8) GetVersion() string {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Version
@@ -114,135 +124,230 @@ func (s serverConfigV7) GetVersion() string {
 
 /// Logger related.
 
-func (s *serverConfigV7) SetAMQPNotifyByID(accountID string, amqpn amqpNotify) {
+func (s *serverConfigV8) SetAMQPNotifyByID(accountID string, amqpn amqpNotify) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Notify.AMQP[accountID] = amqpn
 }
 
-func (s serverConfigV7) GetAMQP() map[string]amqpNotify {
+func (s serverConfigV8) GetAMQP() map[string]amqpNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.AMQP
 }
 
 // GetAMQPNotify get current AMQP logger.
-func (s serverConfigV7) GetAMQPNotifyByID(accountID string) amqpNotify {
+func (s serverConfigV8) GetAMQPNotifyByID(accountID string) amqpNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.AMQP[accountID]
 }
 
-func (s *serverConfigV7) SetElasticSearchNotifyByID(accountID string, esNotify elasticSearchNotify) {
+func (s *serverConfigV8) SetElasticSearchNotifyByID(accountID string, esNotify elasticSearchNotify) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Notify.ElasticSearch[accountID] = esNotify
 }
 
-func (s serverConfigV7) GetElasticSearch() map[string]elasticSearchNotify {
+func (s serverConfigV8) GetElasticSearch() map[string]elasticSearchNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.ElasticSearch
 }
 
 // GetElasticSearchNotify get current ElasicSearch logger.
-func (s serverConfigV7) GetElasticSearchNotifyByID(accountID string) elasticSearchNotify {
+func (s serverConfigV8) GetElasticSearchNotifyByID(accountID string) elasticSearchNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.ElasticSearch[accountID]
 }
 
-func (s *serverConfigV7) SetRedisNotifyByID(accountID string, rNotify redisNotify) {
+func (s *serverConfigV8) SetRedisNotifyByID(accountID string, rNotify redisNotify) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Notify.Redis[accountID] = rNotify
 }
 
-func (s serverConfigV7) GetRedis() map[string]redisNotify {
+func (s serverConfigV8) GetRedis() map[string]redisNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.Redis
 }
 
 // GetRedisNotify get current Redis logger.
-func (s serverConfigV7) GetRedisNotifyByID(accountID string) redisNotify {
+func (s serverConfigV8) GetRedisNotifyByID(accountID string) redisNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.Redis[accountID]
 }
 
+func (s *serverConfigV8) SetWebhookNotifyByID(accountID string, whNotify webhookNotify) {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	s.Notify.Webhook[accountID] = whNotify
+}
+
+func (s serverConfigV8) GetWebhook() map[string]webhookNotify {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.Notify.Webhook
+}
+
+// GetWebhookNotifyByID get current Webhook logger.
+func (s serverConfigV8) GetWebhookNotifyByID(accountID string) webhookNotify {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.Notify.Webhook[accountID]
+}
+
+func (s *serverConfigV8) SetKafkaNotifyByID(accountID string, kNotify kafkaNotify) {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	s.Notify.Kafka[accountID] = kNotify
+}
+
+func (s serverConfigV8) GetKafka() map[string]kafkaNotify {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.Notify.Kafka
+}
+
+// GetKafkaNotifyByID get current Kafka logger.
+func (s serverConfigV8) GetKafkaNotifyByID(accountID string) kafkaNotify {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.Notify.Kafka[accountID]
+}
+
+func (s *serverConfigV8) SetNATSNotifyByID(accountID string, natsN natsNotify) {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	s.Notify.NATS[accountID] = natsN
+}
+
+func (s serverConfigV8) GetNATS() map[string]natsNotify {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.Notify.NATS
+}
+
+// GetNATSNotifyByID get current NATS logger.
+func (s serverConfigV8) GetNATSNotifyByID(accountID string) natsNotify {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.Notify.NATS[accountID]
+}
+
+func (s *serverConfigV8) SetPostgreSQLNotifyByID(accountID string, pgNotify postgreSQLNotify) {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	s.Notify.PostgreSQL[accountID] = pgNotify
+}
+
+func (s serverConfigV8) GetPostgreSQL() map[string]postgreSQLNotify {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.Notify.PostgreSQL
+}
+
+// GetPostgreSQLNotifyByID get current PostgreSQL logger.
+func (s serverConfigV8) GetPostgreSQLNotifyByID(accountID string) postgreSQLNotify {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.Notify.PostgreSQL[accountID]
+}
+
+func (s *serverConfigV8) SetMySQLNotifyByID(accountID string, msqlNotify mySQLNotify) {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	s.Notify.MySQL[accountID] = msqlNotify
+}
+
+func (s serverConfigV8) GetMySQL() map[string]mySQLNotify {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.Notify.MySQL
+}
+
+// GetMySQLNotifyByID get current MySQL logger.
+func (s serverConfigV8) GetMySQLNotifyByID(accountID string) mySQLNotify {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.Notify.MySQL[accountID]
+}
+
 // SetFileLogger set new file logger.
-func (s *serverConfigV7) SetFileLogger(flogger fileLogger) {
+func (s *serverConfigV8) SetFileLogger(flogger fileLogger) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Logger.File = flogger
 }
 
 // GetFileLogger get current file logger.
-func (s serverConfigV7) GetFileLogger() fileLogger {
+func (s serverConfigV8) GetFileLogger() fileLogger {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Logger.File
 }
 
 // SetConsoleLogger set new console logger.
-func (s *serverConfigV7) SetConsoleLogger(clogger consoleLogger) {
+func (s *serverConfigV8) SetConsoleLogger(clogger consoleLogger) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Logger.Console = clogger
 }
 
 // GetConsoleLogger get current console logger.
-func (s serverConfigV7) GetConsoleLogger() consoleLogger {
+func (s serverConfigV8) GetConsoleLogger() consoleLogger {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Logger.Console
 }
 
 // SetSyslogLogger set new syslog logger.
-func (s *serverConfigV7) SetSyslogLogger(slogger syslogLogger) {
+func (s *serverConfigV8) SetSyslogLogger(slogger syslogLogger) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Logger.Syslog = slogger
 }
 
 // GetSyslogLogger get current syslog logger.
-func (s *serverConfigV7) GetSyslogLogger() syslogLogger {
+func (s *serverConfigV8) GetSyslogLogger() syslogLogger {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Logger.Syslog
 }
 
 // SetRegion set new region.
-func (s *serverConfigV7) SetRegion(region string) {
+func (s *serverConfigV8) SetRegion(region string) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Region = region
 }
 
 // GetRegion get current region.
-func (s serverConfigV7) GetRegion() string {
+func (s serverConfigV8) GetRegion() string {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Region
 }
 
 // SetCredentials set new credentials.
-func (s *serverConfigV7) SetCredential(creds credential) {
+func (s *serverConfigV8) SetCredential(creds credential) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Credential = creds
 }
 
 // GetCredentials get current credentials.
-func (s serverConfigV7) GetCredential() credential {
+func (s serverConfigV8) GetCredential() credential {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Credential
 }
 
 // Save config.
-func (s serverConfigV7) Save() error {
+func (s serverConfigV8) Save() error {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 
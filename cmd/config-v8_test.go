@@ -60,6 +60,41 @@ func TestServerConfig(t *testing.T) {
 		t.Errorf("Expecting Redis config %#v found %#v", redisNotify{}, savedNotifyCfg3)
 	}
 
+	// Set new webhook notification id.
+	serverConfig.SetWebhookNotifyByID("2", webhookNotify{})
+	savedNotifyCfg4 := serverConfig.GetWebhookNotifyByID("2")
+	if !reflect.DeepEqual(savedNotifyCfg4, webhookNotify{}) {
+		t.Errorf("Expecting Webhook config %#v found %#v", webhookNotify{}, savedNotifyCfg4)
+	}
+
+	// Set new kafka notification id.
+	serverConfig.SetKafkaNotifyByID("2", kafkaNotify{})
+	savedNotifyCfg5 := serverConfig.GetKafkaNotifyByID("2")
+	if !reflect.DeepEqual(savedNotifyCfg5, kafkaNotify{}) {
+		t.Errorf("Expecting Kafka config %#v found %#v", kafkaNotify{}, savedNotifyCfg5)
+	}
+
+	// Set new nats notification id.
+	serverConfig.SetNATSNotifyByID("2", natsNotify{})
+	savedNotifyCfg6 := serverConfig.GetNATSNotifyByID("2")
+	if !reflect.DeepEqual(savedNotifyCfg6, natsNotify{}) {
+		t.Errorf("Expecting NATS config %#v found %#v", natsNotify{}, savedNotifyCfg6)
+	}
+
+	// Set new postgresql notification id.
+	serverConfig.SetPostgreSQLNotifyByID("2", postgreSQLNotify{})
+	savedNotifyCfg7 := serverConfig.GetPostgreSQLNotifyByID("2")
+	if !reflect.DeepEqual(savedNotifyCfg7, postgreSQLNotify{}) {
+		t.Errorf("Expecting PostgreSQL config %#v found %#v", postgreSQLNotify{}, savedNotifyCfg7)
+	}
+
+	// Set new mysql notification id.
+	serverConfig.SetMySQLNotifyByID("2", mySQLNotify{})
+	savedNotifyCfg8 := serverConfig.GetMySQLNotifyByID("2")
+	if !reflect.DeepEqual(savedNotifyCfg8, mySQLNotify{}) {
+		t.Errorf("Expecting MySQL config %#v found %#v", mySQLNotify{}, savedNotifyCfg8)
+	}
+
 	// Set new console logger.
 	serverConfig.SetConsoleLogger(consoleLogger{
 		Enable: true,
@@ -0,0 +1,70 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var cacheCmd = cli.Command{
+	Name:   "cache",
+	Usage:  "Usage statistics of the object cache in the node.",
+	Action: cacheControl,
+	Flags:  globalFlags,
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} http://localhost:9000/
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+
+EXAMPLES:
+  1. Print hit ratio and size of the object cache:
+    $ minio control {{.Name}} http://localhost:9000/
+`,
+}
+
+// "minio control cache" entry point.
+func cacheControl(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(c, "cache", 1)
+	}
+
+	parsedURL, err := url.Parse(c.Args()[0])
+	fatalIf(err, "Unable to parse URL.")
+
+	client := newControllerClient(parsedURL.Host, parsedURL.Scheme == "https")
+	defer client.Close()
+
+	reply := CacheStatsReply{}
+	err = client.Call("Controller.CacheStatsHandler", &GenericArgs{}, &reply)
+	fatalIf(err, "Unable to get object cache statistics from %s.", parsedURL.Host)
+
+	stats := reply.Stats
+	console.Println(fmt.Sprintf("Hit ratio: %.2f%% (%d hits, %d from disk, %d misses)",
+		stats.HitRatio()*100, stats.Hits, stats.DiskHits, stats.Misses))
+	console.Println(fmt.Sprintf("Blocks: %d, Memory: %s, Disk: %s, Evictions: %d",
+		stats.Blocks, humanize.IBytes(stats.MemorySize), humanize.IBytes(stats.DiskSize), stats.Evictions))
+}
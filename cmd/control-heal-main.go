@@ -90,6 +90,7 @@ func healControl(ctx *cli.Context) {
 		address:     parsedURL.Host,
 		path:        path.Join(reservedBucket, controlPath),
 		loginMethod: "Controller.LoginHandler",
+		secureConn:  parsedURL.Scheme == "https",
 	}
 	client := newAuthClient(authCfg)
 
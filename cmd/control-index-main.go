@@ -0,0 +1,69 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var indexCmd = cli.Command{
+	Name:   "index",
+	Usage:  "Rebuild the object index of a bucket.",
+	Action: indexControl,
+	Flags:  globalFlags,
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} rebuild http://localhost:9000/BUCKET
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+
+EXAMPLES:
+  1. Index the objects of a bucket created before MINIO_INDEX_DIR was set:
+    $ minio control {{.Name}} rebuild http://localhost:9000/photos
+`,
+}
+
+// "minio control index" entry point.
+func indexControl(c *cli.Context) {
+	if len(c.Args()) != 2 || c.Args().Get(0) != "rebuild" {
+		cli.ShowCommandHelpAndExit(c, "index", 1)
+	}
+
+	parsedURL, err := url.Parse(c.Args().Get(1))
+	fatalIf(err, "Unable to parse URL.")
+
+	bucket := strings.Trim(parsedURL.Path, slashSeparator)
+	if bucket == "" {
+		cli.ShowCommandHelpAndExit(c, "index", 1)
+	}
+
+	client := newControllerClient(parsedURL.Host, parsedURL.Scheme == "https")
+	defer client.Close()
+
+	err = client.Call("Controller.RebuildIndexHandler", &RebuildIndexArgs{Bucket: bucket}, &GenericReply{})
+	fatalIf(err, "Unable to rebuild the index of %s.", bucket)
+
+	console.Println("Rebuilt the index of " + bucket + ".")
+}
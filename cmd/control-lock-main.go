@@ -129,6 +129,7 @@ func lockControl(c *cli.Context) {
 		address:     parsedURL.Host,
 		path:        path.Join(reservedBucket, controlPath),
 		loginMethod: "Controller.LoginHandler",
+		secureConn:  parsedURL.Scheme == "https",
 	}
 	client := newAuthClient(authCfg)
 
@@ -28,6 +28,11 @@ var controlCmd = cli.Command{
 		lockCmd,
 		healCmd,
 		shutdownCmd,
+		serviceCmd,
+		notifyCmd,
+		policyCmd,
+		cacheCmd,
+		indexCmd,
 	},
 	CustomHelpTemplate: `NAME:
    {{.Name}} - {{.Usage}}
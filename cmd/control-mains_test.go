@@ -141,3 +141,28 @@ func TestControlMain(t *testing.T) {
 			err.Error())
 	}
 }
+
+// Test to call serviceControl() in control-service-main.go
+func TestControlServiceMain(t *testing.T) {
+	// create cli app for testing
+	app := cli.NewApp()
+	app.Commands = []cli.Command{controlCmd}
+
+	// start test server
+	testServer := StartTestServer(t, "XL")
+
+	// schedule cleanup at the end
+	defer testServer.Stop()
+
+	// fetch http server endpoint
+	url := testServer.Server.URL
+
+	// create args to call
+	args := []string{"./minio", "control", "service", "status", url}
+
+	// run app
+	err := app.Run(args)
+	if err != nil {
+		t.Errorf("Control-Service-Main test failed with - %s", err.Error())
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var notifyCmd = cli.Command{
+	Name:   "notify",
+	Usage:  "Status of the event notification queues in the node.",
+	Action: notifyControl,
+	Flags:  globalFlags,
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} http://localhost:9000/
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+
+EXAMPLES:
+  1. Print pending events and delivery failures of all the notification targets:
+    $ minio control {{.Name}} http://localhost:9000/
+`,
+}
+
+// "minio control notify" entry point.
+func notifyControl(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(c, "notify", 1)
+	}
+
+	parsedURL, err := url.Parse(c.Args()[0])
+	fatalIf(err, "Unable to parse URL.")
+
+	client := newControllerClient(parsedURL.Host, parsedURL.Scheme == "https")
+	defer client.Close()
+
+	reply := NotifyStatusReply{}
+	err = client.Call("Controller.NotifyStatusHandler", &GenericArgs{}, &reply)
+	fatalIf(err, "Unable to get notification queue status from %s.", parsedURL.Host)
+
+	if len(reply.Queues) == 0 {
+		console.Println("No notification targets configured.")
+		return
+	}
+	for _, queue := range reply.Queues {
+		msg := fmt.Sprintf("%s - Pending: %d, Failures: %d", colorBold(queue.QueueARN),
+			queue.Pending, queue.Failures)
+		if queue.LastError != "" {
+			msg += ", Last error: " + queue.LastError
+		}
+		console.Println(msg)
+	}
+}
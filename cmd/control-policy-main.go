@@ -0,0 +1,121 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var policyFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "action",
+		Value: "s3:GetObject",
+		Usage: "Action to be simulated.",
+	},
+	cli.StringSliceFlag{
+		Name:  "context",
+		Value: &cli.StringSlice{},
+		Usage: "Request context as KEY=VALUE, such as aws:SourceIp=192.168.1.10 or prefix=photos/.",
+	},
+}
+
+var policyCmd = cli.Command{
+	Name:   "policy",
+	Usage:  "Simulate an anonymous request against the bucket policy.",
+	Action: policyControl,
+	Flags:  append(policyFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} [--action ACTION] [--context KEY=VALUE...] http://localhost:9000/BUCKET[/OBJECT]
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+
+EXAMPLES:
+  1. Check if anonymous downloads of an object are allowed:
+    $ minio control {{.Name}} --action s3:GetObject http://localhost:9000/photos/2016/beach.jpg
+
+  2. Check if listing a prefix is allowed from a given address:
+    $ minio control {{.Name}} --action s3:ListBucket --context prefix=2016/ \
+        --context aws:SourceIp=192.168.1.10 http://localhost:9000/photos
+`,
+}
+
+// "minio control policy" entry point.
+func policyControl(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(c, "policy", 1)
+	}
+
+	parsedURL, err := url.Parse(c.Args()[0])
+	fatalIf(err, "Unable to parse URL.")
+
+	// Parse bucket and object from url.URL.Path
+	var bucket, object string
+	splits := strings.SplitN(strings.TrimPrefix(parsedURL.Path, slashSeparator), slashSeparator, 2)
+	bucket = splits[0]
+	if len(splits) == 2 {
+		object = splits[1]
+	}
+	if bucket == "" {
+		fatalIf(errInvalidArgument, "Bucket name is required.")
+	}
+
+	context := make(map[string]string)
+	for _, keyValue := range c.StringSlice("context") {
+		tokens := strings.SplitN(keyValue, "=", 2)
+		if len(tokens) != 2 {
+			fatalIf(errInvalidArgument, "Request context %s is not in KEY=VALUE format.", keyValue)
+		}
+		context[tokens[0]] = tokens[1]
+	}
+
+	client := newControllerClient(parsedURL.Host, parsedURL.Scheme == "https")
+	defer client.Close()
+
+	args := &PolicySimulateArgs{
+		Bucket:  bucket,
+		Object:  object,
+		Action:  c.String("action"),
+		Context: context,
+	}
+	reply := PolicySimulateReply{}
+	err = client.Call("Controller.PolicySimulateHandler", args, &reply)
+	fatalIf(err, "Unable to simulate bucket policy on %s.", parsedURL.Host)
+
+	result := "Denied"
+	if reply.Allowed {
+		result = "Allowed"
+	}
+	if reply.OwnerOnly {
+		console.Println(fmt.Sprintf("%s - only allowed to the bucket owner.", colorBold(result)))
+		return
+	}
+	if reply.Statement == "" {
+		console.Println(fmt.Sprintf("%s - no statement matched, denied by default.", colorBold(result)))
+		return
+	}
+	console.Println(fmt.Sprintf("%s - decided by statement: %s", colorBold(result), reply.Statement))
+}
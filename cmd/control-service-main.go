@@ -0,0 +1,91 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var serviceCmd = cli.Command{
+	Name:   "service",
+	Usage:  "Restart, stop or get status of all the servers in a deployment.",
+	Action: serviceControl,
+	Flags:  globalFlags,
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} [status|restart|stop] http://localhost:9000/
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+
+EXAMPLES:
+  1. Print uptime and version of all the servers:
+    $ minio control {{.Name}} status http://localhost:9000/
+
+  2. Restart all the servers one after the other:
+    $ minio control {{.Name}} restart http://localhost:9000/
+
+  3. Stop all the servers:
+    $ minio control {{.Name}} stop http://localhost:9000/
+`,
+}
+
+// callService - applies the service signal on all the servers of the
+// deployment which the server at address is part of.
+func callService(address string, secureConn bool, signal serviceSignal, local bool) (ServiceReply, error) {
+	client := newControllerClient(address, secureConn)
+	defer client.Close()
+
+	reply := ServiceReply{}
+	err := client.Call("Controller.ServiceHandler", &ServiceArgs{Signal: signal, Local: local}, &reply)
+	return reply, err
+}
+
+// "minio control service" entry point.
+func serviceControl(c *cli.Context) {
+	if len(c.Args()) != 2 {
+		cli.ShowCommandHelpAndExit(c, "service", 1)
+	}
+
+	signal := serviceSignal(c.Args().Get(0))
+	if !signal.isValid() {
+		cli.ShowCommandHelpAndExit(c, "service", 1)
+	}
+
+	parsedURL, err := url.Parse(c.Args().Get(1))
+	fatalIf(err, "Unable to parse URL.")
+
+	reply, err := callService(parsedURL.Host, parsedURL.Scheme == "https", signal, false)
+	fatalIf(err, "Service %s of Minio servers via %s failed.", signal, parsedURL.Host)
+
+	for _, status := range reply.Servers {
+		if status.Error != "" {
+			console.Println(colorBold(status.Address) + " - " + status.Error)
+			continue
+		}
+		console.Println(fmt.Sprintf("%s - Version: %s, Uptime: %s", colorBold(status.Address),
+			status.Version, status.Uptime-status.Uptime%time.Second))
+	}
+}
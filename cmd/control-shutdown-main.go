@@ -69,6 +69,7 @@ func shutdownControl(c *cli.Context) {
 		address:     parsedURL.Host,
 		path:        path.Join(reservedBucket, controlPath),
 		loginMethod: "Controller.LoginHandler",
+		secureConn:  parsedURL.Scheme == "https",
 	}
 	client := newAuthClient(authCfg)
 
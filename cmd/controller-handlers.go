@@ -16,7 +16,18 @@
 
 package cmd
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/mf-00/minio/pkg/objcache"
+	"github.com/minio/minio-go/pkg/set"
+)
 
 // errServerNotInitialized - server not initialized.
 var errServerNotInitialized = errors.New("Server not initialized, please try again.")
@@ -143,13 +154,190 @@ func (c *controllerAPIHandlers) ShutdownHandler(args *ShutdownArgs, reply *Gener
 		return errInvalidToken
 	}
 	if args.Restart {
-		globalShutdownSignalCh <- shutdownRestart
+		c.sendShutdownSignal(shutdownRestart)
 	} else {
-		globalShutdownSignalCh <- shutdownHalt
+		c.sendShutdownSignal(shutdownHalt)
+	}
+	return nil
+}
+
+// Time given to an RPC reply to reach the caller before the
+// requested shutdown or restart of the server begins.
+const shutdownSignalDelay = 500 * time.Millisecond
+
+// sendShutdownSignal - signals the server in the background after
+// shutdownSignalDelay, so that the reply of the current RPC is sent
+// before the server stops serving it.
+func (c *controllerAPIHandlers) sendShutdownSignal(signal shutdownSignal) {
+	go func() {
+		time.Sleep(shutdownSignalDelay)
+		c.ShutdownSignalCh <- signal
+	}()
+}
+
+// serviceSignal - represents the action requested through "minio control service".
+type serviceSignal string
+
+const (
+	serviceStatus  serviceSignal = "status"
+	serviceRestart serviceSignal = "restart"
+	serviceStop    serviceSignal = "stop"
+)
+
+// isValid - returns true if the service signal is one of the supported actions.
+func (s serviceSignal) isValid() bool {
+	switch s {
+	case serviceStatus, serviceRestart, serviceStop:
+		return true
+	}
+	return false
+}
+
+// errUnsupportedServiceSignal - service signal is not supported.
+var errUnsupportedServiceSignal = errors.New("Unsupported service signal, should be one of status, restart or stop.")
+
+// Maximum time to wait for a restarted peer to come back online
+// before moving on to the next peer during a rolling restart.
+const serviceRestartTimeout = 2 * time.Minute
+
+// ServiceArgs - argument for Service RPC.
+type ServiceArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Action to be performed, one of status, restart or stop.
+	Signal serviceSignal
+
+	// Set when the call is forwarded by a peer, the signal
+	// is then only applied to the receiving server.
+	Local bool
+}
+
+// ServerStatus - status of a single server in the deployment.
+type ServerStatus struct {
+	Address string
+	Version string
+	Uptime  time.Duration
+	Error   string
+}
+
+// ServiceReply - reply by Service RPC, contains the status of
+// every server the signal was applied to.
+type ServiceReply struct {
+	Servers []ServerStatus
+}
+
+// ServiceHandler - applies the service signal on all the servers of
+// the deployment, peers are handled first and this server is the last.
+func (c *controllerAPIHandlers) ServiceHandler(args *ServiceArgs, reply *ServiceReply) error {
+	if !isRPCTokenValid(args.Token) {
+		return errInvalidToken
+	}
+	if !args.Signal.isValid() {
+		return errUnsupportedServiceSignal
+	}
+	if !args.Local {
+		for _, peer := range getPeerAddrs(srvConfig) {
+			reply.Servers = append(reply.Servers, servicePeer(peer, args.Signal))
+		}
+	}
+	reply.Servers = append(reply.Servers, ServerStatus{
+		Address: srvConfig.serverAddr,
+		Version: Version,
+		Uptime:  time.Since(globalBootTime),
+	})
+	switch args.Signal {
+	case serviceRestart:
+		c.sendShutdownSignal(shutdownRestart)
+	case serviceStop:
+		c.sendShutdownSignal(shutdownHalt)
 	}
 	return nil
 }
 
+// getPeerAddrs - returns the unique network addresses of all the
+// remote servers present in the disk list of a distributed setup,
+// or the peers configured explicitly.
+func getPeerAddrs(srvCmdConfig serverCmdConfig) (peers []string) {
+	if len(srvCmdConfig.peers) > 0 {
+		return srvCmdConfig.peers
+	}
+	if !isDistributedSetup(srvCmdConfig.disks) {
+		return nil
+	}
+	port := strconv.Itoa(getPort(srvCmdConfig.serverAddr))
+	seen := make(map[string]struct{})
+	for _, disk := range srvCmdConfig.disks {
+		if isLocalStorage(disk) {
+			continue
+		}
+		host, _, err := splitNetPath(disk)
+		if err != nil {
+			continue
+		}
+		peer := net.JoinHostPort(host, port)
+		if _, ok := seen[peer]; ok {
+			continue
+		}
+		seen[peer] = struct{}{}
+		peers = append(peers, peer)
+	}
+	return peers
+}
+
+// newControllerClient - returns a new authenticated controller rpc client for the server at address.
+func newControllerClient(address string, secureConn bool) *AuthRPCClient {
+	cred := serverConfig.GetCredential()
+	return newAuthClient(&authConfig{
+		accessKey:   cred.AccessKeyID,
+		secretKey:   cred.SecretAccessKey,
+		address:     address,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Controller.LoginHandler",
+		secureConn:  secureConn,
+	})
+}
+
+// callPeerService - applies the service signal only on the given peer.
+func callPeerService(peer string, signal serviceSignal) (ServerStatus, error) {
+	client := newControllerClient(peer, globalIsSSL)
+	defer client.Close()
+
+	reply := ServiceReply{}
+	if err := client.Call("Controller.ServiceHandler", &ServiceArgs{Signal: signal, Local: true}, &reply); err != nil {
+		return ServerStatus{}, err
+	}
+	if len(reply.Servers) != 1 {
+		return ServerStatus{}, fmt.Errorf("Unexpected service reply from %s", peer)
+	}
+	status := reply.Servers[0]
+	status.Address = peer
+	return status, nil
+}
+
+// servicePeer - applies the service signal on a peer, a restarted
+// peer is waited upon until it is back online so that only one
+// server of the deployment is down at any point in time.
+func servicePeer(peer string, signal serviceSignal) ServerStatus {
+	status, err := callPeerService(peer, signal)
+	if err != nil {
+		return ServerStatus{Address: peer, Error: err.Error()}
+	}
+	if signal != serviceRestart {
+		return status
+	}
+
+	restartedAt := time.Now().UTC()
+	for time.Since(restartedAt) < serviceRestartTimeout {
+		time.Sleep(time.Second)
+		status, err = callPeerService(peer, serviceStatus)
+		if err == nil && status.Uptime < time.Since(restartedAt) {
+			return status
+		}
+	}
+	return ServerStatus{Address: peer, Error: "Server did not come back online after restart."}
+}
+
 func (c *controllerAPIHandlers) TryInitHandler(args *GenericArgs, reply *GenericReply) error {
 	go func() {
 		globalWakeupCh <- struct{}{}
@@ -172,3 +360,158 @@ func (c *controllerAPIHandlers) LockInfo(arg *GenericArgs, reply *SystemLockStat
 	*reply = lockInfo
 	return nil
 }
+
+// NotifyStatusReply - reply with delivery status of the event queues
+// of all the notification targets.
+type NotifyStatusReply struct {
+	Queues []NotifyQueueStatus
+}
+
+// NotifyStatusHandler - RPC control handler for `minio control notify`.
+// Returns delivery status of the event queues of this server.
+func (c *controllerAPIHandlers) NotifyStatusHandler(args *GenericArgs, reply *NotifyStatusReply) error {
+	if !isRPCTokenValid(args.Token) {
+		return errInvalidToken
+	}
+	if globalEventNotifier == nil {
+		return errServerNotInitialized
+	}
+	*reply = NotifyStatusReply{Queues: globalEventNotifier.GetQueueStatus()}
+	return nil
+}
+
+// errCacheNotEnabled - object cache is not used by the server.
+var errCacheNotEnabled = errors.New("Object cache is only enabled in XL mode with a non zero MINIO_CACHE_SIZE.")
+
+// CacheStatsReply - reply with the usage statistics of the object cache.
+type CacheStatsReply struct {
+	Stats objcache.Stats
+}
+
+// CacheStatsHandler - RPC control handler for `minio control cache`.
+// Returns hits, misses and sizes of the object cache of this server.
+func (c *controllerAPIHandlers) CacheStatsHandler(args *GenericArgs, reply *CacheStatsReply) error {
+	if !isRPCTokenValid(args.Token) {
+		return errInvalidToken
+	}
+	objAPI := c.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+	// The object cache is kept by the backend of the index.
+	if index, ok := objAPI.(indexObjects); ok {
+		objAPI = index.ObjectLayer
+	}
+	xl, ok := objAPI.(xlObjects)
+	if !ok || !xl.objCacheEnabled {
+		return errCacheNotEnabled
+	}
+	*reply = CacheStatsReply{Stats: xl.objCache.Stats()}
+	return nil
+}
+
+// errIndexNotConfigured - object index is not used by the server.
+var errIndexNotConfigured = errors.New("Object index is only enabled with MINIO_INDEX_DIR set.")
+
+// RebuildIndexArgs - argument for RebuildIndex RPC.
+type RebuildIndexArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Bucket whose index is rebuilt.
+	Bucket string
+}
+
+// RebuildIndexHandler - RPC control handler for `minio control index rebuild`.
+// Regenerates the index of the bucket from the metadata of its objects.
+func (c *controllerAPIHandlers) RebuildIndexHandler(args *RebuildIndexArgs, reply *GenericReply) error {
+	if !isRPCTokenValid(args.Token) {
+		return errInvalidToken
+	}
+	objAPI := c.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+	index, ok := objAPI.(indexObjects)
+	if !ok {
+		return errIndexNotConfigured
+	}
+	return errorCause(index.RebuildIndex(args.Bucket))
+}
+
+// PolicySimulateArgs - argument for PolicySimulate RPC.
+type PolicySimulateArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Bucket whose policy is evaluated.
+	Bucket string
+
+	// Object name, empty for bucket level actions.
+	Object string
+
+	// Action such as "s3:GetObject".
+	Action string
+
+	// Request context such as "aws:SourceIp" or "prefix" mapped
+	// to the values the conditions are evaluated against.
+	Context map[string]string
+}
+
+// PolicySimulateReply - reply by PolicySimulate RPC.
+type PolicySimulateReply struct {
+	// Allowed is true if an anonymous request would be allowed.
+	Allowed bool
+
+	// Statement which decided the result in JSON, empty when no
+	// statement matched and the request is denied by default.
+	Statement string
+
+	// OwnerOnly is true for actions only ever allowed to the bucket
+	// owner, whatever the policy.
+	OwnerOnly bool
+}
+
+// PolicySimulateHandler - RPC control handler for `minio control policy`.
+// Evaluates the bucket policy for the given action, object and request
+// context and reports whether the request is allowed and why.
+func (c *controllerAPIHandlers) PolicySimulateHandler(args *PolicySimulateArgs, reply *PolicySimulateReply) error {
+	objAPI := c.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+	if !isRPCTokenValid(args.Token) {
+		return errInvalidToken
+	}
+	if !supportedActionMap.Contains(args.Action) {
+		return fmt.Errorf("Unsupported action %s", args.Action)
+	}
+	if ownerOnlyActions.Contains(args.Action) {
+		reply.OwnerOnly = true
+		return nil
+	}
+	policy, err := readBucketPolicy(args.Bucket, objAPI)
+	if err != nil {
+		return err
+	}
+
+	resource := AWSResourcePrefix + args.Bucket
+	if args.Object != "" {
+		resource = resource + "/" + args.Object
+	}
+	conditions := make(map[string]set.StringSet)
+	for key, value := range args.Context {
+		conditions[key] = set.CreateStringSet(value)
+	}
+
+	allowed, statement := bucketPolicyEvalStatement(args.Action, resource, conditions, policy.Statements)
+	reply.Allowed = allowed
+	if statement != nil {
+		statementBytes, err := json.Marshal(statement)
+		if err != nil {
+			return err
+		}
+		reply.Statement = string(statementBytes)
+	}
+	return nil
+}
@@ -39,4 +39,7 @@ func registerControllerRPCRouter(mux *router.Router, ctrlHandlers *controllerAPI
 // Handler for object healing.
 type controllerAPIHandlers struct {
 	ObjectAPI func() ObjectLayer
+
+	// Receives the shutdown and restart signals requested by RPCs.
+	ShutdownSignalCh chan<- shutdownSignal
 }
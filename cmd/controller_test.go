@@ -17,12 +17,17 @@
 package cmd
 
 import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
 	"path"
 	"strconv"
 	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	router "github.com/gorilla/mux"
 )
 
 // API suite container common to both FS and XL.
@@ -395,3 +400,239 @@ func (s *TestRPCControllerSuite) testControllerListObjectsHealH(t *testing.T) {
 			err.Error())
 	}
 }
+
+func TestControllerServiceH(t *testing.T) {
+	//setup code
+	s := &TestRPCControllerSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	//run test
+	s.testControllerServiceH(t)
+
+	//teardown code
+	s.TearDownSuite(t)
+}
+
+func (s *TestRPCControllerSuite) testControllerServiceH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	// Status of a single node setup should only contain this server.
+	reply := &ServiceReply{}
+	err := client.Call("Controller.ServiceHandler", &ServiceArgs{Signal: serviceStatus}, reply)
+	if err != nil {
+		t.Fatalf("Controller.ServiceHandler - status failed with <ERROR> %s", err.Error())
+	}
+	if len(reply.Servers) != 1 {
+		t.Fatalf("Controller.ServiceHandler - expected status of 1 server, got %d", len(reply.Servers))
+	}
+	if reply.Servers[0].Version != Version {
+		t.Errorf("Controller.ServiceHandler - expected version %s, got %s", Version, reply.Servers[0].Version)
+	}
+
+	// Unsupported signals are rejected.
+	err = client.Call("Controller.ServiceHandler", &ServiceArgs{Signal: "reboot"}, &ServiceReply{})
+	if err == nil || err.Error() != errUnsupportedServiceSignal.Error() {
+		t.Errorf("Controller.ServiceHandler - expected <ERROR> %s, got %v", errUnsupportedServiceSignal, err)
+	}
+
+	// Stop is replied to before the server is signalled, signals
+	// are sent on a channel of the test instead of stopping it.
+	signalCh := make(chan shutdownSignal, 1)
+	mux := router.NewRouter()
+	registerControllerRPCRouter(mux, &controllerAPIHandlers{
+		ObjectAPI:        func() ObjectLayer { return s.testServer.Obj },
+		ShutdownSignalCh: signalCh,
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	stopAuthConf := *s.testAuthConf
+	stopAuthConf.address = ts.Listener.Addr().String()
+	stopClient := newAuthClient(&stopAuthConf)
+	defer stopClient.Close()
+
+	reply = &ServiceReply{}
+	err = stopClient.Call("Controller.ServiceHandler", &ServiceArgs{Signal: serviceStop, Local: true}, reply)
+	if err != nil {
+		t.Fatalf("Controller.ServiceHandler - stop failed with <ERROR> %s", err.Error())
+	}
+	if len(reply.Servers) != 1 {
+		t.Fatalf("Controller.ServiceHandler - expected stop reply of 1 server, got %d", len(reply.Servers))
+	}
+	select {
+	case <-signalCh:
+		t.Fatal("Controller.ServiceHandler - server signalled before the reply was sent")
+	default:
+	}
+	select {
+	case signal := <-signalCh:
+		if signal != shutdownHalt {
+			t.Errorf("Controller.ServiceHandler - expected halt signal, got %v", signal)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Controller.ServiceHandler - timed out waiting for the stop signal")
+	}
+}
+
+// Tests the peer addresses found in the server arguments.
+func TestGetPeerAddrs(t *testing.T) {
+	testCases := []struct {
+		srvCmdConfig serverCmdConfig
+		peers        []string
+	}{
+		// Single node setup has no peers.
+		{serverCmdConfig{serverAddr: ":9000", disks: []string{"/mnt/disk1"}}, nil},
+		// Peers of a distributed setup listen on the port of this
+		// server.
+		{serverCmdConfig{serverAddr: ":9000", disks: []string{
+			"localhost:/mnt/disk1",
+			"10.1.10.1:/mnt/disk2",
+			"10.1.10.1:/mnt/disk3",
+			"10.1.10.2:/mnt/disk4",
+		}}, []string{"10.1.10.1:9000", "10.1.10.2:9000"}},
+		// Peers configured explicitly keep their own ports.
+		{serverCmdConfig{serverAddr: ":9000", peers: []string{"10.1.10.1:9001", "10.1.10.2:9002"}},
+			[]string{"10.1.10.1:9001", "10.1.10.2:9002"}},
+	}
+	for i, testCase := range testCases {
+		peers := getPeerAddrs(testCase.srvCmdConfig)
+		if strings.Join(peers, ",") != strings.Join(testCase.peers, ",") {
+			t.Errorf("Test %d: Expected peers %v, got %v", i+1, testCase.peers, peers)
+		}
+	}
+}
+
+func TestControllerPolicySimulateH(t *testing.T) {
+	//setup code
+	s := &TestRPCControllerSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	//run test
+	s.testControllerPolicySimulateH(t)
+
+	//teardown code
+	s.TearDownSuite(t)
+}
+
+func (s *TestRPCControllerSuite) testControllerPolicySimulateH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	err := s.testServer.Obj.MakeBucket("testbucket")
+	if err != nil {
+		t.Fatalf("Controller.PolicySimulateH - create bucket failed with <ERROR> %s", err)
+	}
+
+	policyDoc := `{"Version":"2012-10-17","Statement":[` +
+		`{"Sid":"AllowGet","Action":["s3:GetObject"],"Effect":"Allow","Principal":{"AWS":["*"]},"Resource":["arn:aws:s3:::testbucket/public/*"]},` +
+		`{"Sid":"DenyOffice","Action":["s3:GetObject"],"Effect":"Deny","Principal":{"AWS":["*"]},"Resource":["arn:aws:s3:::testbucket/public/*"],` +
+		`"Condition":{"IpAddress":{"aws:SourceIp":["10.0.0.0/8"]}}}]}`
+	err = writeBucketPolicy("testbucket", s.testServer.Obj, strings.NewReader(policyDoc), int64(len(policyDoc)))
+	if err != nil {
+		t.Fatalf("Controller.PolicySimulateH - write bucket policy failed with <ERROR> %s", err)
+	}
+
+	testCases := []struct {
+		object          string
+		action          string
+		context         map[string]string
+		expectedAllowed bool
+		expectedSid     string
+	}{
+		// Allowed by the Allow statement.
+		{"public/a.txt", "s3:GetObject", map[string]string{"aws:SourceIp": "192.168.1.10"}, true, "AllowGet"},
+		// Denied by the Deny statement.
+		{"public/a.txt", "s3:GetObject", map[string]string{"aws:SourceIp": "10.1.1.1"}, false, "DenyOffice"},
+		// Denied by default, no statement matches.
+		{"private/a.txt", "s3:GetObject", nil, false, ""},
+		{"public/a.txt", "s3:PutObject", nil, false, ""},
+	}
+	for i, testCase := range testCases {
+		args := &PolicySimulateArgs{
+			Bucket:  "testbucket",
+			Object:  testCase.object,
+			Action:  testCase.action,
+			Context: testCase.context,
+		}
+		reply := &PolicySimulateReply{}
+		if err = client.Call("Controller.PolicySimulateHandler", args, reply); err != nil {
+			t.Fatalf("Test %d: Controller.PolicySimulateHandler failed with <ERROR> %s", i+1, err)
+		}
+		if reply.Allowed != testCase.expectedAllowed {
+			t.Errorf("Test %d: Expected allowed to be %v, but found %v", i+1, testCase.expectedAllowed, reply.Allowed)
+		}
+		if testCase.expectedSid == "" && reply.Statement != "" {
+			t.Errorf("Test %d: Expected no statement to match, but found %s", i+1, reply.Statement)
+		}
+		if !strings.Contains(reply.Statement, `"Sid":"`+testCase.expectedSid+`"`) {
+			if testCase.expectedSid != "" {
+				t.Errorf("Test %d: Expected statement %s to decide, but found %s", i+1, testCase.expectedSid, reply.Statement)
+			}
+		}
+	}
+
+	// Owner only actions are never allowed, even by wildcard actions.
+	policyDoc = `{"Version":"2012-10-17","Statement":[{"Action":["s3:*"],"Effect":"Allow","Principal":{"AWS":["*"]},"Resource":["arn:aws:s3:::testbucket"]}]}`
+	err = writeBucketPolicy("testbucket", s.testServer.Obj, strings.NewReader(policyDoc), int64(len(policyDoc)))
+	if err != nil {
+		t.Fatalf("Controller.PolicySimulateH - write bucket policy failed with <ERROR> %s", err)
+	}
+	ownerReply := &PolicySimulateReply{}
+	args := &PolicySimulateArgs{Bucket: "testbucket", Action: "s3:DeleteBucket"}
+	if err = client.Call("Controller.PolicySimulateHandler", args, ownerReply); err != nil {
+		t.Fatalf("Controller.PolicySimulateHandler failed with <ERROR> %s", err)
+	}
+	if ownerReply.Allowed || !ownerReply.OwnerOnly {
+		t.Errorf("Expected s3:DeleteBucket to be only allowed to the owner, found allowed %v, owner only %v", ownerReply.Allowed, ownerReply.OwnerOnly)
+	}
+
+	// Unsupported actions are rejected.
+	args = &PolicySimulateArgs{Bucket: "testbucket", Action: "s3:Explode"}
+	if err = client.Call("Controller.PolicySimulateHandler", args, &PolicySimulateReply{}); err == nil {
+		t.Error("Expected Controller.PolicySimulateHandler to fail for unsupported action")
+	}
+}
+
+func TestControllerCacheStatsH(t *testing.T) {
+	//setup code
+	s := &TestRPCControllerSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	//run test
+	s.testControllerCacheStatsH(t)
+
+	//teardown code
+	s.TearDownSuite(t)
+}
+
+func (s *TestRPCControllerSuite) testControllerCacheStatsH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	xl, ok := s.testServer.Obj.(xlObjects)
+	if !ok || !xl.objCacheEnabled {
+		t.Skip("Object cache is not enabled")
+	}
+	if err := xl.MakeBucket("testbucket"); err != nil {
+		t.Fatalf("Controller.CacheStatsH - create bucket failed with <ERROR> %s", err)
+	}
+	data := []byte("hello")
+	if _, err := xl.PutObject("testbucket", "object", int64(len(data)), bytes.NewReader(data), nil); err != nil {
+		t.Fatalf("Controller.CacheStatsH - put object failed with <ERROR> %s", err)
+	}
+	before := xl.objCache.Stats()
+	if err := xl.GetObject("testbucket", "object", 1, 3, ioutil.Discard); err != nil {
+		t.Fatalf("Controller.CacheStatsH - get object failed with <ERROR> %s", err)
+	}
+
+	reply := &CacheStatsReply{}
+	if err := client.Call("Controller.CacheStatsHandler", &GenericArgs{}, reply); err != nil {
+		t.Fatalf("Controller.CacheStatsHandler failed with <ERROR> %s", err)
+	}
+	if reply.Stats.Hits != before.Hits+1 {
+		t.Errorf("Controller.CacheStatsHandler - expected %d hits, got %d", before.Hits+1, reply.Stats.Hits)
+	}
+	if reply.Stats.Blocks == 0 || reply.Stats.MemorySize == 0 {
+		t.Errorf("Controller.CacheStatsHandler - expected cached blocks, got %#v", reply.Stats)
+	}
+}
@@ -27,17 +27,40 @@ import (
 
 // erasureCreateFile - writes an entire stream by erasure coding to
 // all the disks, writes also calculate individual block's checksum
-// for future bit-rot protection.
+// for future bit-rot protection. Blocks are written to the disks while
+// the next block is read and encoded.
 func erasureCreateFile(disks []StorageAPI, volume, path string, reader io.Reader, blockSize int64, dataBlocks int, parityBlocks int, algo string, writeQuorum int) (bytesWritten int64, checkSums []string, err error) {
-	// Allocated blockSized buffer for reading.
-	buf := make([]byte, blockSize)
+	if !isValidBitRotAlgo(algo) {
+		return 0, nil, traceError(errBitRotAlgoInvalid)
+	}
+
+	rs, err := reedsolomon.New(dataBlocks, parityBlocks)
+	if err != nil {
+		return 0, nil, traceError(err)
+	}
+
+	// Blocks are read into pooled buffers which also hold their
+	// parity, so that they are encoded without copies. One buffer is
+	// being written while the other one is filled.
+	pool := getErasureBufPool(getChunkSize(blockSize, dataBlocks) * int64(dataBlocks+parityBlocks))
+	bufs, err := pool.GetN(2, nil)
+	if err != nil {
+		return 0, nil, traceError(err)
+	}
+	defer pool.Put(bufs[0])
+	defer pool.Put(bufs[1])
 
 	hashWriters := newHashWriters(len(disks), algo)
+	writer := newParallelWriter(disks, volume, path, hashWriters, writeQuorum)
+	// Closed before the buffers go back to the pool, so that no
+	// writes are pending on them.
+	defer writer.Close()
 
 	// Read until io.EOF, erasure codes data and writes to all disks.
-	for {
+	for i := 0; ; i++ {
+		buf := bufs[i%2]
 		var blocks [][]byte
-		n, rErr := io.ReadFull(reader, buf)
+		n, rErr := io.ReadFull(reader, buf[:blockSize])
 		// FIXME: this is a bug in Golang, n == 0 and err ==
 		// io.ErrUnexpectedEOF for io.ReadFull function.
 		if n == 0 && rErr == io.ErrUnexpectedEOF {
@@ -49,8 +72,7 @@ func erasureCreateFile(disks []StorageAPI, volume, path string, reader io.Reader
 			// data. Will create a 0byte file instead.
 			if bytesWritten == 0 {
 				blocks = make([][]byte, len(disks))
-				rErr = appendFile(disks, volume, path, blocks, hashWriters, writeQuorum)
-				if rErr != nil {
+				if rErr = writer.Write(blocks); rErr != nil {
 					return 0, nil, rErr
 				}
 			} // else we have reached EOF after few reads, no need to
@@ -63,19 +85,24 @@ func erasureCreateFile(disks []StorageAPI, volume, path string, reader io.Reader
 		if n > 0 {
 			// Returns encoded blocks.
 			var enErr error
-			blocks, enErr = encodeData(buf[0:n], dataBlocks, parityBlocks)
+			blocks, enErr = encodeBlocks(rs, buf, n, dataBlocks, parityBlocks)
 			if enErr != nil {
 				return 0, nil, enErr
 			}
 
-			// Write to all disks.
-			if err = appendFile(disks, volume, path, blocks, hashWriters, writeQuorum); err != nil {
+			// Write to all disks, in the background.
+			if err = writer.Write(blocks); err != nil {
 				return 0, nil, err
 			}
 			bytesWritten += int64(n)
 		}
 	}
 
+	// Wait for the last block and close the files.
+	if err = writer.Close(); err != nil {
+		return 0, nil, err
+	}
+
 	checkSums = make([]string, len(disks))
 	for i := range checkSums {
 		checkSums[i] = hex.EncodeToString(hashWriters[i].Sum(nil))
@@ -83,6 +110,29 @@ func erasureCreateFile(disks []StorageAPI, volume, path string, reader io.Reader
 	return bytesWritten, checkSums, nil
 }
 
+// encodeBlocks - encodes the first n bytes of buf in place, buf holds
+// the data and parity blocks of the largest block. Returns the data
+// blocks followed by the parity blocks, all sliced from buf.
+func encodeBlocks(rs reedsolomon.Encoder, buf []byte, n int, dataBlocks, parityBlocks int) ([][]byte, error) {
+	chunkSize := (n + dataBlocks - 1) / dataBlocks
+	// Zero the padding of the last data block, parity blocks are
+	// overwritten by the encoder.
+	padding := buf[n : chunkSize*dataBlocks]
+	for i := range padding {
+		padding[i] = 0
+	}
+	blocks := make([][]byte, dataBlocks+parityBlocks)
+	for i := range blocks {
+		blocks[i] = buf[i*chunkSize : (i+1)*chunkSize]
+	}
+
+	// Encode parity blocks using data blocks.
+	if err := rs.Encode(blocks); err != nil {
+		return nil, traceError(err)
+	}
+	return blocks, nil
+}
+
 // encodeData - encodes incoming data buffer into
 // dataBlocks+parityBlocks returns a 2 dimensional byte array.
 func encodeData(dataBuffer []byte, dataBlocks, parityBlocks int) ([][]byte, error) {
@@ -107,38 +157,125 @@ func encodeData(dataBuffer []byte, dataBlocks, parityBlocks int) ([][]byte, erro
 	return blocks, nil
 }
 
-// appendFile - append data buffer at path.
-func appendFile(disks []StorageAPI, volume, path string, enBlocks [][]byte, hashWriters []hash.Hash, writeQuorum int) (err error) {
-	var wg = &sync.WaitGroup{}
-	var wErrs = make([]error, len(disks))
+// fileCreator - implemented by disks which can keep a file open while
+// it is written, other disks append each block with AppendFile.
+type fileCreator interface {
+	CreateFile(volume, path string) (io.WriteCloser, error)
+}
+
+// createFile - opens a writer of the file at path on disk.
+func createFile(disk StorageAPI, volume, path string) (io.WriteCloser, error) {
+	if creator, ok := disk.(fileCreator); ok {
+		return creator.CreateFile(volume, path)
+	}
+	return appendFileWriter{disk, volume, path}, nil
+}
+
+// appendFileWriter - writes to a file by appending each buffer to it.
+type appendFileWriter struct {
+	disk   StorageAPI
+	volume string
+	path   string
+}
+
+func (w appendFileWriter) Write(buf []byte) (int, error) {
+	if err := w.disk.AppendFile(w.volume, w.path, buf); err != nil {
+		return 0, err
+	}
+	return len(buf), nil
+}
+
+func (w appendFileWriter) Close() error {
+	return nil
+}
+
+// parallelWriter - writes erasure coded blocks to their disks in
+// parallel. Write returns as soon as the writes of a block are started
+// and waits for the writes of the previous block, a disk is no longer
+// written to once one of its writes failed.
+type parallelWriter struct {
+	disks       []StorageAPI
+	volume      string
+	path        string
+	files       []io.WriteCloser
+	hashWriters []hash.Hash
+	errs        []error
+	writeQuorum int
+	wg          sync.WaitGroup
+	closed      bool
+}
+
+func newParallelWriter(disks []StorageAPI, volume, path string, hashWriters []hash.Hash, writeQuorum int) *parallelWriter {
+	return &parallelWriter{
+		disks:       disks,
+		volume:      volume,
+		path:        path,
+		files:       make([]io.WriteCloser, len(disks)),
+		hashWriters: hashWriters,
+		errs:        make([]error, len(disks)),
+		writeQuorum: writeQuorum,
+	}
+}
+
+// Write - starts writing the blocks, enBlocks must not be modified
+// until the next call to Write or Close returns.
+func (p *parallelWriter) Write(enBlocks [][]byte) error {
+	// Wait for the previous block.
+	p.wg.Wait()
+
+	// Do we have write quorum?.
+	if !isDiskQuorum(p.errs, p.writeQuorum) {
+		return traceError(errXLWriteQuorum)
+	}
+
 	// Write encoded data to quorum disks in parallel.
-	for index, disk := range disks {
-		if disk == nil {
+	for index, disk := range p.disks {
+		if disk == nil || p.errs[index] != nil {
 			continue
 		}
-		wg.Add(1)
+		p.wg.Add(1)
 		// Write encoded data in routine.
 		go func(index int, disk StorageAPI) {
-			defer wg.Done()
-			wErr := disk.AppendFile(volume, path, enBlocks[index])
-			if wErr != nil {
-				wErrs[index] = traceError(wErr)
+			defer p.wg.Done()
+			// Files are created with their first block.
+			if p.files[index] == nil {
+				file, err := createFile(disk, p.volume, p.path)
+				if err != nil {
+					p.errs[index] = traceError(err)
+					return
+				}
+				p.files[index] = file
+			}
+			if _, err := p.files[index].Write(enBlocks[index]); err != nil {
+				p.errs[index] = traceError(err)
 				return
 			}
 
 			// Calculate hash for each blocks.
-			hashWriters[index].Write(enBlocks[index])
-
-			// Successfully wrote.
-			wErrs[index] = nil
+			p.hashWriters[index].Write(enBlocks[index])
 		}(index, disk)
 	}
+	return nil
+}
 
-	// Wait for all the appends to finish.
-	wg.Wait()
+// Close - waits for the last block and closes all the files, returns
+// an error if they are not written on quorum disks.
+func (p *parallelWriter) Close() error {
+	p.wg.Wait()
+	if !p.closed {
+		p.closed = true
+		for index, file := range p.files {
+			if file == nil {
+				continue
+			}
+			if err := file.Close(); err != nil && p.errs[index] == nil {
+				p.errs[index] = traceError(err)
+			}
+		}
+	}
 
 	// Do we have write quorum?.
-	if !isDiskQuorum(wErrs, writeQuorum) {
+	if !isDiskQuorum(p.errs, p.writeQuorum) {
 		return traceError(errXLWriteQuorum)
 	}
 	return nil
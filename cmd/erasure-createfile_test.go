@@ -19,6 +19,7 @@ package cmd
 import (
 	"bytes"
 	"crypto/rand"
+	"io"
 	"testing"
 
 	"github.com/klauspost/reedsolomon"
@@ -33,6 +34,10 @@ func (a AppendDiskDown) AppendFile(volume string, path string, buf []byte) error
 	return errFaultyDisk
 }
 
+func (a AppendDiskDown) CreateFile(volume string, path string) (io.WriteCloser, error) {
+	return nil, errFaultyDisk
+}
+
 // Test erasureCreateFile()
 func TestErasureCreateFile(t *testing.T) {
 	// Initialize environment needed for the test.
@@ -55,7 +60,7 @@ func TestErasureCreateFile(t *testing.T) {
 		t.Fatal(err)
 	}
 	// Test when all disks are up.
-	size, _, err := erasureCreateFile(disks, "testbucket", "testobject1", bytes.NewReader(data), blockSize, dataBlocks, parityBlocks, bitRotAlgo, dataBlocks+1)
+	size, _, err := erasureCreateFile(disks, "testbucket", "testobject1", bytes.NewReader(data), blockSize, dataBlocks, parityBlocks, blake2bAlgo, dataBlocks+1)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -68,7 +73,7 @@ func TestErasureCreateFile(t *testing.T) {
 	disks[5] = AppendDiskDown{disks[5].(*posix)}
 
 	// Test when two disks are down.
-	size, _, err = erasureCreateFile(disks, "testbucket", "testobject2", bytes.NewReader(data), blockSize, dataBlocks, parityBlocks, bitRotAlgo, dataBlocks+1)
+	size, _, err = erasureCreateFile(disks, "testbucket", "testobject2", bytes.NewReader(data), blockSize, dataBlocks, parityBlocks, blake2bAlgo, dataBlocks+1)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -82,7 +87,7 @@ func TestErasureCreateFile(t *testing.T) {
 	disks[8] = AppendDiskDown{disks[8].(*posix)}
 	disks[9] = AppendDiskDown{disks[9].(*posix)}
 
-	size, _, err = erasureCreateFile(disks, "testbucket", "testobject3", bytes.NewReader(data), blockSize, dataBlocks, parityBlocks, bitRotAlgo, dataBlocks+1)
+	size, _, err = erasureCreateFile(disks, "testbucket", "testobject3", bytes.NewReader(data), blockSize, dataBlocks, parityBlocks, blake2bAlgo, dataBlocks+1)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -92,7 +97,7 @@ func TestErasureCreateFile(t *testing.T) {
 
 	// 1 more disk down. 7 disk down in total. Should return quorum error.
 	disks[10] = AppendDiskDown{disks[10].(*posix)}
-	_, _, err = erasureCreateFile(disks, "testbucket", "testobject4", bytes.NewReader(data), blockSize, dataBlocks, parityBlocks, bitRotAlgo, dataBlocks+1)
+	_, _, err = erasureCreateFile(disks, "testbucket", "testobject4", bytes.NewReader(data), blockSize, dataBlocks, parityBlocks, blake2bAlgo, dataBlocks+1)
 	if errorCause(err) != errXLWriteQuorum {
 		t.Errorf("erasureCreateFile return value: expected errXLWriteQuorum, got %s", err)
 	}
@@ -201,3 +206,71 @@ func TestErasureEncode(t *testing.T) {
 		}
 	}
 }
+
+// Tests encoding blocks in place matches encoding copies of them.
+func TestEncodeBlocks(t *testing.T) {
+	dataBlocks, parityBlocks := 6, 4
+	blockSize := 1000
+	rs, err := reedsolomon.New(dataBlocks, parityBlocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, blockSize)
+	if _, err = rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, getChunkSize(int64(blockSize), dataBlocks)*int64(dataBlocks+parityBlocks))
+	for _, n := range []int{1, 5, 6, 7, 999, 1000} {
+		// Stale data left in the buffer by previous blocks is not encoded.
+		if _, err = rand.Read(buf); err != nil {
+			t.Fatal(err)
+		}
+		copy(buf, data[:n])
+		blocks, err := encodeBlocks(rs, buf, n, dataBlocks, parityBlocks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := encodeData(append([]byte{}, data[:n]...), dataBlocks, parityBlocks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(blocks) != len(expected) {
+			t.Fatalf("n=%d: expected %d blocks, got %d", n, len(expected), len(blocks))
+		}
+		for i := range blocks {
+			if !bytes.Equal(blocks[i], expected[i]) {
+				t.Fatalf("n=%d: block %d does not match", n, i)
+			}
+		}
+	}
+}
+
+// Benchmarks erasureCreateFile(), allocations are reported.
+func BenchmarkErasureCreateFile(b *testing.B) {
+	dataBlocks, parityBlocks := 8, 8
+	blockSize := int64(blockSizeV1)
+	setup, err := newErasureTestSetup(dataBlocks, parityBlocks, blockSize)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer setup.Remove()
+
+	data := make([]byte, 3*blockSize)
+	if _, err = rand.Read(data); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, err = erasureCreateFile(setup.disks, "testbucket", "testobject", bytes.NewReader(data), blockSize, dataBlocks, parityBlocks, blake2bAlgo, dataBlocks+1)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StopTimer()
+		for _, disk := range setup.disks {
+			disk.DeleteFile("testbucket", "testobject")
+		}
+		b.StartTimer()
+	}
+}
@@ -16,15 +16,35 @@
 
 package cmd
 
-import "encoding/hex"
+import (
+	"encoding/hex"
+
+	"github.com/klauspost/reedsolomon"
+)
 
 // Heals the erasure coded file. reedsolomon.Reconstruct() is used to reconstruct the missing parts.
 func erasureHealFile(latestDisks []StorageAPI, outDatedDisks []StorageAPI, volume, path, healBucket, healPath string, size int64, blockSize int64, dataBlocks int, parityBlocks int, algo string) (checkSums []string, err error) {
 	var offset int64
 	remainingSize := size
 
+	// Healed parts are checksummed with the algorithm of the part.
+	if algo == "" {
+		algo = blake2bAlgo
+	}
+	if !isValidBitRotAlgo(algo) {
+		return nil, traceError(errBitRotAlgoInvalid)
+	}
+
+	rs, err := reedsolomon.New(dataBlocks, parityBlocks)
+	if err != nil {
+		return nil, traceError(err)
+	}
+
+	// Chunks are read into pooled buffers.
+	pool := getErasureBufPool(getChunkSize(blockSize, dataBlocks))
+
 	// Hash for bitrot protection.
-	hashWriters := newHashWriters(len(outDatedDisks), bitRotAlgo)
+	hashWriters := newHashWriters(len(outDatedDisks), algo)
 
 	for remainingSize > 0 {
 		curBlockSize := blockSize
@@ -37,6 +57,10 @@ func erasureHealFile(latestDisks []StorageAPI, outDatedDisks []StorageAPI, volum
 
 		// Memory for reading data from disks and reconstructing missing data using erasure coding.
 		enBlocks := make([][]byte, len(latestDisks))
+		bufs, err := pool.GetN(len(latestDisks), nil)
+		if err != nil {
+			return nil, traceError(err)
+		}
 
 		// Read data from the latest disks.
 		// FIXME: no need to read from all the disks. dataBlocks+1 is enough.
@@ -44,16 +68,17 @@ func erasureHealFile(latestDisks []StorageAPI, outDatedDisks []StorageAPI, volum
 			if disk == nil {
 				continue
 			}
-			enBlocks[index] = make([]byte, curEncBlockSize)
-			_, err := disk.ReadFile(volume, path, offset, enBlocks[index])
+			enBlocks[index] = bufs[index][:curEncBlockSize]
+			_, err = disk.ReadFile(volume, path, offset, enBlocks[index])
 			if err != nil {
 				enBlocks[index] = nil
 			}
 		}
 
 		// Reconstruct missing data.
-		err := decodeData(enBlocks, dataBlocks, parityBlocks)
+		err = decodeBlocks(rs, enBlocks)
 		if err != nil {
+			putBufs(pool, bufs)
 			return nil, err
 		}
 
@@ -62,12 +87,15 @@ func erasureHealFile(latestDisks []StorageAPI, outDatedDisks []StorageAPI, volum
 			if disk == nil {
 				continue
 			}
-			err := disk.AppendFile(healBucket, healPath, enBlocks[index])
+			err = disk.AppendFile(healBucket, healPath, enBlocks[index])
 			if err != nil {
+				putBufs(pool, bufs)
 				return nil, traceError(err)
 			}
 			hashWriters[index].Write(enBlocks[index])
 		}
+		// Buffers are reused by the next block.
+		putBufs(pool, bufs)
 		remainingSize -= curBlockSize
 		offset += curEncBlockSize
 	}
@@ -110,8 +110,9 @@ func getReadDisks(orderedDisks []StorageAPI, index int, dataBlocks int) (readDis
 	return nil, 0, traceError(errXLReadQuorum)
 }
 
-// parallelRead - reads chunks in parallel from the disks specified in []readDisks.
-func parallelRead(volume, path string, readDisks []StorageAPI, orderedDisks []StorageAPI, enBlocks [][]byte, blockOffset int64, curChunkSize int64, bitRotVerify func(diskIndex int) bool, pool *bpool.BytePool) {
+// parallelRead - reads chunks in parallel from the disks specified in []readDisks,
+// the chunk of each disk is read into its buffer of bufs.
+func parallelRead(volume, path string, readDisks []StorageAPI, orderedDisks []StorageAPI, enBlocks [][]byte, blockOffset int64, curChunkSize int64, bitRotVerify func(diskIndex int) bool, bufs [][]byte) {
 	// WaitGroup to synchronise the read go-routines.
 	wg := &sync.WaitGroup{}
 
@@ -132,15 +133,8 @@ func parallelRead(volume, path string, readDisks []StorageAPI, orderedDisks []St
 				return
 			}
 
-			buf, err := pool.Get()
-			if err != nil {
-				errorIf(err, "unable to get buffer from byte pool")
-				orderedDisks[index] = nil
-				return
-			}
-			buf = buf[:curChunkSize]
-
-			_, err = readDisks[index].ReadFile(volume, path, blockOffset, buf)
+			buf := bufs[index][:curChunkSize]
+			_, err := readDisks[index].ReadFile(volume, path, blockOffset, buf)
 			if err != nil {
 				orderedDisks[index] = nil
 				return
@@ -172,6 +166,11 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 	// chunkSize is the amount of data that needs to be read from each disk at a time.
 	chunkSize := getChunkSize(blockSize, dataBlocks)
 
+	rs, err := reedsolomon.New(dataBlocks, parityBlocks)
+	if err != nil {
+		return 0, traceError(err)
+	}
+
 	// bitRotVerify verifies if the file on a particular disk doesn't have bitrot
 	// by verifying the hash of the contents of the file.
 	bitRotVerify := func() func(diskIndex int) bool {
@@ -207,13 +206,16 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 	// need to read parity disks. If one of the data disk is missing we need to read DataBlocks+1 number
 	// of disks. Once read, we Reconstruct() missing data if needed and write it to the given writer.
 	for block := startBlock; block <= endBlock; block++ {
-		// Mark all buffers as unused at the start of the loop so that the buffers
-		// can be reused.
-		pool.Reset()
-
 		// Each element of enBlocks holds curChunkSize'd amount of data read from its corresponding disk.
 		enBlocks := make([][]byte, len(disks))
 
+		// Buffers of all the disks are taken at once, so that reads
+		// don't wait for buffers while holding some.
+		bufs, err := pool.GetN(len(disks), nil)
+		if err != nil {
+			return bytesWritten, traceError(err)
+		}
+
 		if ((offset + bytesWritten) / blockSize) == (totalLength / blockSize) {
 			// This is the last block for which curBlockSize and curChunkSize can change.
 			// For ex. if totalLength is 15M and blockSize is 10MB, curBlockSize for
@@ -241,13 +243,14 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 				return bytesWritten, err
 			}
 			// Issue a parallel read across the disks specified in readDisks.
-			parallelRead(volume, path, readDisks, disks, enBlocks, blockOffset, curChunkSize, bitRotVerify, pool)
+			parallelRead(volume, path, readDisks, disks, enBlocks, blockOffset, curChunkSize, bitRotVerify, bufs)
 			if isSuccessDecodeBlocks(enBlocks, dataBlocks) {
 				// If enough blocks are available to do rs.Reconstruct()
 				break
 			}
 			if nextIndex == len(disks) {
 				// No more disks to read from.
+				putBufs(pool, bufs)
 				return bytesWritten, traceError(errXLReadQuorum)
 			}
 			// We do not have enough enough data blocks to reconstruct the data
@@ -257,7 +260,8 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 		// If we have all the data blocks no need to decode, continue to write.
 		if !isSuccessDataBlocks(enBlocks, dataBlocks) {
 			// Reconstruct the missing data blocks.
-			if err := decodeData(enBlocks, dataBlocks, parityBlocks); err != nil {
+			if err := decodeBlocks(rs, enBlocks); err != nil {
+				putBufs(pool, bufs)
 				return bytesWritten, err
 			}
 		}
@@ -282,6 +286,8 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 
 		// Write data blocks.
 		n, err := writeDataBlocks(writer, enBlocks, dataBlocks, enBlocksOffset, enBlocksLength)
+		// Buffers are reused by the next block.
+		putBufs(pool, bufs)
 		if err != nil {
 			return bytesWritten, err
 		}
@@ -312,6 +318,10 @@ func isValidBlock(disk StorageAPI, volume, path, checkSum, checkSumAlgo string)
 	}
 	// Read everything for a given block and calculate hash.
 	hashWriter := newHash(checkSumAlgo)
+	if hashWriter == nil {
+		errorIf(traceError(errBitRotAlgoInvalid), "Unable to verify checksum %s/%s with %s", volume, path, checkSumAlgo)
+		return false
+	}
 	hashBytes, err := hashSum(disk, volume, path, hashWriter)
 	if err != nil {
 		errorIf(err, "Unable to calculate checksum %s/%s", volume, path)
@@ -327,9 +337,14 @@ func decodeData(enBlocks [][]byte, dataBlocks, parityBlocks int) error {
 	if err != nil {
 		return traceError(err)
 	}
+	return decodeBlocks(rs, enBlocks)
+}
 
+// decodeBlocks - decode encoded blocks with an initialized reedsolomon,
+// missing blocks are allocated.
+func decodeBlocks(rs reedsolomon.Encoder, enBlocks [][]byte) error {
 	// Reconstruct encoded blocks.
-	err = rs.Reconstruct(enBlocks)
+	err := rs.Reconstruct(enBlocks)
 	if err != nil {
 		return traceError(err)
 	}
@@ -17,15 +17,58 @@
 package cmd
 
 import (
-	"bytes"
+	"encoding/hex"
 	"errors"
 	"hash"
 	"io"
+	"sync"
 
 	"github.com/klauspost/reedsolomon"
+	"github.com/mf-00/minio/pkg/bpool"
 	"github.com/minio/blake2b-simd"
+	"github.com/minio/highwayhash"
+	"github.com/minio/sha256-simd"
 )
 
+// Bit-rot algorithms, the one used for a part is recorded with its
+// checksums in `xl.json`.
+const (
+	blake2bAlgo     = "blake2b"
+	sha256Algo      = "sha256"
+	highwayHashAlgo = "highwayhash"
+	// Add new algorithms here.
+)
+
+// Algorithm used for new objects unless set otherwise.
+const defaultBitRotAlgo = blake2bAlgo
+
+// bitRotAlgorithms - hashes of all the supported bit-rot algorithms.
+var bitRotAlgorithms = map[string]func() hash.Hash{
+	blake2bAlgo:     blake2b.New512,
+	sha256Algo:      sha256.New,
+	highwayHashAlgo: newHighwayHash256,
+}
+
+// HighwayHash is a keyed hash, bit-rot checksums only need it to
+// detect corruption so all servers use the same fixed key.
+var highwayHashKey, _ = hex.DecodeString("4be734fa8e238acd263e83e6bb968552040f935da39f441497e09d1322de36a0")
+
+// newHighwayHash256 - returns a 256 bit HighwayHash keyed with highwayHashKey.
+func newHighwayHash256() hash.Hash {
+	h, err := highwayhash.New(highwayHashKey)
+	fatalIf(err, "Unable to initialize HighwayHash.")
+	return h
+}
+
+// errBitRotAlgoInvalid - the bit-rot algorithm is not supported.
+var errBitRotAlgoInvalid = errors.New("Bit-rot algorithm is not supported.")
+
+// isValidBitRotAlgo - returns true if the bit-rot algorithm is supported.
+func isValidBitRotAlgo(algo string) bool {
+	_, ok := bitRotAlgorithms[algo]
+	return ok
+}
+
 // newHashWriters - inititialize a slice of hashes for the disk count.
 func newHashWriters(diskCount int, algo string) []hash.Hash {
 	hashWriters := make([]hash.Hash, diskCount)
@@ -35,16 +78,18 @@ func newHashWriters(diskCount int, algo string) []hash.Hash {
 	return hashWriters
 }
 
-// newHash - gives you a newly allocated hash depending on the input algorithm.
+// newHash - gives you a newly allocated hash depending on the input
+// algorithm, returns nil for unsupported algorithms. Checksums saved
+// without their algorithm are blake2b.
 func newHash(algo string) hash.Hash {
-	switch algo {
-	case "blake2b":
-		return blake2b.New512()
-	// Add new hashes here.
-	default:
-		// Default to blake2b.
-		return blake2b.New512()
+	if algo == "" {
+		algo = blake2bAlgo
+	}
+	newHashFn, ok := bitRotAlgorithms[algo]
+	if !ok {
+		return nil
 	}
+	return newHashFn()
 }
 
 // hashSum calculates the hash of the entire path and returns.
@@ -112,17 +157,17 @@ func writeDataBlocks(dst io.Writer, enBlocks [][]byte, dataBlocks int, offset in
 		}
 		// We have written all the blocks, write the last remaining block.
 		if write < int64(len(block)) {
-			n, err := io.Copy(dst, bytes.NewReader(block[:write]))
+			n, err := writeBlock(dst, block[:write])
 			if err != nil {
-				return 0, traceError(err)
+				return 0, err
 			}
 			totalWritten += n
 			break
 		}
 		// Copy the block.
-		n, err := io.Copy(dst, bytes.NewReader(block))
+		n, err := writeBlock(dst, block)
 		if err != nil {
-			return 0, traceError(err)
+			return 0, err
 		}
 
 		// Decrement output size.
@@ -136,6 +181,48 @@ func writeDataBlocks(dst io.Writer, enBlocks [][]byte, dataBlocks int, offset in
 	return totalWritten, nil
 }
 
+// Bytes of erasure buffers allocated by each pool, reads, writes and
+// heals wait for buffers once all of them are in use.
+const erasureBufPoolBytes = 256 * 1024 * 1024 // 256MiB.
+
+// erasureBufPools - pools of erasure buffers by their size, shared by
+// all the reads, writes and heals of the same erasure layout.
+var erasureBufPools = struct {
+	sync.Mutex
+	pools map[int64]*bpool.BytePool
+}{pools: make(map[int64]*bpool.BytePool)}
+
+// getErasureBufPool - returns the pool of erasure buffers of the size.
+func getErasureBufPool(size int64) *bpool.BytePool {
+	erasureBufPools.Lock()
+	defer erasureBufPools.Unlock()
+	pool, ok := erasureBufPools.pools[size]
+	if !ok {
+		pool = bpool.NewBytePool(size, int(erasureBufPoolBytes/size))
+		erasureBufPools.pools[size] = pool
+	}
+	return pool
+}
+
+// putBufs - returns the buffers taken with GetN to the pool.
+func putBufs(pool *bpool.BytePool, bufs [][]byte) {
+	for _, buf := range bufs {
+		pool.Put(buf)
+	}
+}
+
+// writeBlock - writes the block to dst without copying it.
+func writeBlock(dst io.Writer, block []byte) (int64, error) {
+	n, err := dst.Write(block)
+	if err != nil {
+		return int64(n), traceError(err)
+	}
+	if n != len(block) {
+		return int64(n), traceError(io.ErrShortWrite)
+	}
+	return int64(n), nil
+}
+
 // chunkSize is roughly BlockSize/DataBlocks.
 // chunkSize is calculated such that chunkSize*DataBlocks accommodates BlockSize bytes.
 // So chunkSize*DataBlocks can be slightly larger than BlockSize if BlockSize is not divisible by
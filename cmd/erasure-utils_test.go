@@ -26,13 +26,63 @@ import (
 // Test validates the number hash writers returned.
 func TestNewHashWriters(t *testing.T) {
 	diskNum := 8
-	hashWriters := newHashWriters(diskNum, bitRotAlgo)
+	hashWriters := newHashWriters(diskNum, blake2bAlgo)
 	if len(hashWriters) != diskNum {
 		t.Errorf("Expected %d hashWriters, but instead got %d", diskNum, len(hashWriters))
 	}
 
 }
 
+// Tests the hashes of the bit-rot algorithms.
+func TestNewHash(t *testing.T) {
+	testCases := []struct {
+		algo string
+		size int
+	}{
+		{blake2bAlgo, 64},
+		{sha256Algo, 32},
+		{highwayHashAlgo, 32},
+		// Checksums saved without their algorithm are blake2b.
+		{"", 64},
+	}
+	for i, testCase := range testCases {
+		h := newHash(testCase.algo)
+		if h == nil {
+			t.Fatalf("Test %d: expected a hash for %q", i+1, testCase.algo)
+		}
+		if h.Size() != testCase.size {
+			t.Errorf("Test %d: expected hash of %d bytes, got %d", i+1, testCase.size, h.Size())
+		}
+	}
+	if h := newHash("md5"); h != nil {
+		t.Errorf("Expected no hash for an unsupported algorithm")
+	}
+}
+
+// Benchmarks the bit-rot algorithms on erasure blocks.
+func benchmarkBitRotHash(b *testing.B, algo string) {
+	block := bytes.Repeat([]byte("a"), blockSizeV1)
+	b.SetBytes(int64(len(block)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h := newHash(algo)
+		h.Write(block)
+		h.Sum(nil)
+	}
+}
+
+func BenchmarkBitRotHashBlake2b(b *testing.B) {
+	benchmarkBitRotHash(b, blake2bAlgo)
+}
+
+func BenchmarkBitRotHashSha256(b *testing.B) {
+	benchmarkBitRotHash(b, sha256Algo)
+}
+
+func BenchmarkBitRotHashHighwayHash(b *testing.B) {
+	benchmarkBitRotHash(b, highwayHashAlgo)
+}
+
 // Tests validate the output of getChunkSize.
 func TestGetChunkSize(t *testing.T) {
 	// Refer to comments on getChunkSize() for details.
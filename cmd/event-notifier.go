@@ -23,6 +23,7 @@ import (
 	"net"
 	"net/url"
 	"path"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -36,7 +37,11 @@ type eventNotifier struct {
 	// Collection of 'bucket' and notification config.
 	notificationConfigs map[string]*notificationConfig
 	snsTargets          map[string][]chan []NotificationEvent
-	queueTargets        map[string]*logrus.Logger
+	queueTargets        map[string]*notifyQueue
+
+	// Peers with listeners by sns ARN along with the time of their
+	// last registration, events are forwarded to these peers.
+	remoteSNSTargets map[string]map[string]time.Time
 }
 
 // Represents data to be sent with notification event.
@@ -77,8 +82,8 @@ func newNotificationEvent(event eventData) NotificationEvent {
 	}
 
 	escapedObj := url.QueryEscape(event.ObjInfo.Name)
-	// For delete object event type, we do not need to set ETag and Size.
-	if event.Type == ObjectRemovedDelete {
+	// For delete object event types, we do not need to set ETag and Size.
+	if event.Type == ObjectRemovedDelete || event.Type == ObjectRemovedDeleteMarkerCreated {
 		nEvent.S3.Object = objectMeta{
 			Key:       escapedObj,
 			Sequencer: sequencer,
@@ -97,10 +102,26 @@ func newNotificationEvent(event eventData) NotificationEvent {
 }
 
 // Fetch the saved queue target.
-func (en eventNotifier) GetQueueTarget(queueARN string) *logrus.Logger {
+func (en eventNotifier) GetQueueTarget(queueARN string) *notifyQueue {
 	return en.queueTargets[queueARN]
 }
 
+// Returns delivery status of all the queue targets.
+func (en eventNotifier) GetQueueStatus() []NotifyQueueStatus {
+	var queueStatus []NotifyQueueStatus
+	for _, queue := range en.queueTargets {
+		queueStatus = append(queueStatus, queue.status())
+	}
+	return queueStatus
+}
+
+// Stops delivery workers of all the queue targets.
+func (en eventNotifier) closeQueueTargets() {
+	for _, queue := range en.queueTargets {
+		queue.close()
+	}
+}
+
 func (en eventNotifier) GetSNSTarget(snsARN string) []chan []NotificationEvent {
 	en.rwMutex.RLock()
 	defer en.rwMutex.RUnlock()
@@ -137,6 +158,47 @@ func (en *eventNotifier) RemoveSNSTarget(snsARN string, listenerCh chan []Notifi
 	}
 }
 
+// Add or refresh the listener registration of a peer for an input sns ARN.
+func (en *eventNotifier) AddRemoteSNSTarget(snsARN string, peer string) {
+	en.rwMutex.Lock()
+	defer en.rwMutex.Unlock()
+	if en.remoteSNSTargets == nil {
+		en.remoteSNSTargets = make(map[string]map[string]time.Time)
+	}
+	if en.remoteSNSTargets[snsARN] == nil {
+		en.remoteSNSTargets[snsARN] = make(map[string]time.Time)
+	}
+	en.remoteSNSTargets[snsARN][peer] = time.Now().UTC()
+}
+
+// Returns the peers with listeners for an input sns ARN, registrations
+// not refreshed within listenerRegistrationExpiry are removed.
+func (en *eventNotifier) GetRemoteSNSTargets(snsARN string) (peers []string) {
+	en.rwMutex.Lock()
+	defer en.rwMutex.Unlock()
+	for peer, lastSeen := range en.remoteSNSTargets[snsARN] {
+		if time.Now().UTC().Sub(lastSeen) > listenerRegistrationExpiry {
+			delete(en.remoteSNSTargets[snsARN], peer)
+			continue
+		}
+		peers = append(peers, peer)
+	}
+	if len(en.remoteSNSTargets[snsARN]) == 0 {
+		delete(en.remoteSNSTargets, snsARN)
+	}
+	return peers
+}
+
+// Remove the listener registration of a peer for an input sns ARN.
+func (en *eventNotifier) RemoveRemoteSNSTarget(snsARN string, peer string) {
+	en.rwMutex.Lock()
+	defer en.rwMutex.Unlock()
+	delete(en.remoteSNSTargets[snsARN], peer)
+	if len(en.remoteSNSTargets[snsARN]) == 0 {
+		delete(en.remoteSNSTargets, snsARN)
+	}
+}
+
 // Returns true if bucket notification is set for the bucket, false otherwise.
 func (en *eventNotifier) IsBucketNotificationSet(bucket string) bool {
 	if en == nil {
@@ -167,6 +229,16 @@ func (en *eventNotifier) SetBucketNotificationConfig(bucket string, notification
 	return nil
 }
 
+// Remove the notification config of a bucket.
+func (en *eventNotifier) RemoveBucketNotificationConfig(bucket string) {
+	if en == nil {
+		return
+	}
+	en.rwMutex.Lock()
+	defer en.rwMutex.Unlock()
+	delete(en.notificationConfigs, bucket)
+}
+
 // eventNotify notifies an event to relevant targets based on their
 // bucket notification configs.
 func eventNotify(event eventData) {
@@ -177,6 +249,8 @@ func eventNotify(event eventData) {
 	//  - s3:ObjectCreated:Copy
 	//  - s3:ObjectCreated:CompleteMultipartUpload
 	//  - s3:ObjectRemoved:Delete
+	//  - s3:ObjectAccessed:Get
+	//  - s3:ObjectAccessed:Head
 
 	nConfig := globalEventNotifier.GetBucketNotificationConfig(event.Bucket)
 	// No bucket notifications enabled, drop the event notification.
@@ -201,13 +275,15 @@ func eventNotify(event eventData) {
 		eventMatch := eventMatch(eventType, qConfig.Events)
 		ruleMatch := filterRuleMatch(objectName, qConfig.Filter.Key.FilterRules)
 		if eventMatch && ruleMatch {
-			targetLog := globalEventNotifier.GetQueueTarget(qConfig.QueueARN)
-			if targetLog != nil {
-				targetLog.WithFields(logrus.Fields{
-					"Key":       path.Join(event.Bucket, objectName),
-					"EventType": eventType,
-					"Records":   notificationEvent,
-				}).Info()
+			queue := globalEventNotifier.GetQueueTarget(qConfig.QueueARN)
+			if queue != nil {
+				// Event is delivered asynchronously by the queue.
+				err := queue.enqueue(queuedEvent{
+					Key:       path.Join(event.Bucket, objectName),
+					EventType: eventType,
+					Records:   notificationEvent,
+				})
+				errorIf(err, "Unable to queue event for %s.", qConfig.QueueARN)
 			}
 		}
 	}
@@ -220,6 +296,10 @@ func eventNotify(event eventData) {
 			for _, listener := range targetListeners {
 				listener <- notificationEvent
 			}
+			// Forward the event to peers with listeners, in order.
+			for _, peer := range globalEventNotifier.GetRemoteSNSTargets(topicConfig.TopicARN) {
+				forwardEventToPeer(peer, topicConfig.TopicARN, notificationEvent)
+			}
 		}
 	}
 }
@@ -293,93 +373,41 @@ func loadAllBucketNotifications(objAPI ObjectLayer) (map[string]*notificationCon
 	return configs, nil
 }
 
-// Loads all queue targets, initializes each queueARNs depending on their config.
-// Each instance of queueARN registers its own logrus to communicate with the
-// queue service. QueueARN once initialized is not initialized again for the
-// same queueARN, instead previous connection is used.
-func loadAllQueueTargets() (map[string]*logrus.Logger, error) {
-	queueTargets := make(map[string]*logrus.Logger)
-	// Load all amqp targets, initialize their respective loggers.
-	for accountID, amqpN := range serverConfig.GetAMQP() {
-		if !amqpN.Enable {
-			continue
-		}
-		// Construct the queue ARN for AMQP.
-		queueARN := minioSqs + serverConfig.GetRegion() + ":" + accountID + ":" + queueTypeAMQP
-		// Queue target if already initialized we move to the next ARN.
-		_, ok := queueTargets[queueARN]
-		if ok {
-			continue
-		}
-		// Using accountID we can now initialize a new AMQP logrus instance.
-		amqpLog, err := newAMQPNotify(accountID)
-		if err != nil {
-			// Encapsulate network error to be more informative.
-			if _, ok := err.(net.Error); ok {
-				return nil, &net.OpError{
-					Op:  "Connecting to " + queueARN,
-					Net: "tcp",
-					Err: err,
-				}
-			}
-			return nil, err
-		}
-		queueTargets[queueARN] = amqpLog
-	}
-	// Load redis targets, initialize their respective loggers.
-	for accountID, redisN := range serverConfig.GetRedis() {
-		if !redisN.Enable {
-			continue
-		}
-		// Construct the queue ARN for Redis.
-		queueARN := minioSqs + serverConfig.GetRegion() + ":" + accountID + ":" + queueTypeRedis
-		// Queue target if already initialized we move to the next ARN.
-		_, ok := queueTargets[queueARN]
-		if ok {
-			continue
-		}
-		// Using accountID we can now initialize a new Redis logrus instance.
-		redisLog, err := newRedisNotify(accountID)
-		if err != nil {
-			// Encapsulate network error to be more informative.
-			if _, ok := err.(net.Error); ok {
-				return nil, &net.OpError{
-					Op:  "Connecting to " + queueARN,
-					Net: "tcp",
-					Err: err,
-				}
+// Loads all queue targets, returns the function connecting to the queue
+// service of each enabled queueARN. Targets are connected by the delivery
+// workers of their queues, so that unreachable targets do not keep the
+// server from starting.
+func loadAllQueueTargets() map[string]func() (*logrus.Logger, error) {
+	queueTargets := make(map[string]func() (*logrus.Logger, error))
+	// Load all the enabled targets of every type.
+	for queueType, target := range notifyTargets {
+		for _, accountID := range target.enabledIDs() {
+			// Construct the queue ARN for the target.
+			queueARN := minioSqs + serverConfig.GetRegion() + ":" + accountID + ":" + queueType
+			// Queue target if already loaded we move to the next ARN.
+			if _, ok := queueTargets[queueARN]; ok {
+				continue
 			}
-			return nil, err
-		}
-		queueTargets[queueARN] = redisLog
-	}
-	// Load elastic targets, initialize their respective loggers.
-	for accountID, elasticN := range serverConfig.GetElasticSearch() {
-		if !elasticN.Enable {
-			continue
-		}
-		// Construct the queue ARN for Elastic.
-		queueARN := minioSqs + serverConfig.GetRegion() + ":" + accountID + ":" + queueTypeElastic
-		_, ok := queueTargets[queueARN]
-		if ok {
-			continue
-		}
-		// Using accountID we can now initialize a new ElasticSearch logrus instance.
-		elasticLog, err := newElasticNotify(accountID)
-		if err != nil {
-			// Encapsulate network error to be more informative.
-			if _, ok := err.(net.Error); ok {
-				return nil, &net.OpError{
-					Op: "Connecting to " + queueARN, Net: "tcp",
-					Err: err,
+			target, accountID := target, accountID
+			queueTargets[queueARN] = func() (*logrus.Logger, error) {
+				// Using accountID we can now initialize a new logrus instance.
+				targetLog, err := target.newNotify(accountID)
+				if err != nil {
+					// Encapsulate network error to be more informative.
+					if _, ok := err.(net.Error); ok {
+						return nil, &net.OpError{
+							Op:  "Connecting to " + queueARN,
+							Net: "tcp",
+							Err: err,
+						}
+					}
+					return nil, err
 				}
+				return targetLog, nil
 			}
-			return nil, err
 		}
-		queueTargets[queueARN] = elasticLog
 	}
-	// Successfully initialized queue targets.
-	return queueTargets, nil
+	return queueTargets
 }
 
 // Global instance of event notification queue.
@@ -397,18 +425,38 @@ func initEventNotifier(objAPI ObjectLayer) error {
 		return err
 	}
 
-	// Initializes all queue targets.
-	queueTargets, err := loadAllQueueTargets()
+	configPath, err := getConfigPath()
 	if err != nil {
 		return err
 	}
 
+	// Open durable queues for all the targets, events queued before
+	// a restart are delivered by their workers.
+	queueTargets := make(map[string]*notifyQueue)
+	for queueARN, newTarget := range loadAllQueueTargets() {
+		queue, qerr := newNotifyQueue(queueARN, filepath.Join(configPath, notifyQueueDir), newTarget)
+		if qerr != nil {
+			return qerr
+		}
+		queueTargets[queueARN] = queue
+	}
+
+	// Stop delivery workers of any previous event notifier before
+	// starting new ones, they share the same on disk queues.
+	if globalEventNotifier != nil {
+		globalEventNotifier.closeQueueTargets()
+	}
+	for _, queue := range queueTargets {
+		go queue.run()
+	}
+
 	// Inititalize event notifier queue.
 	globalEventNotifier = &eventNotifier{
 		rwMutex:             &sync.RWMutex{},
 		notificationConfigs: configs,
 		queueTargets:        queueTargets,
 		snsTargets:          make(map[string][]chan []NotificationEvent),
+		remoteSNSTargets:    make(map[string]map[string]time.Time),
 	}
 
 	return nil
@@ -177,7 +177,7 @@ func TestInitEventNotifierFaultyDisks(t *testing.T) {
 
 	// Write a notification.xml in the disk
 	notificationXML := "<NotificationConfiguration>"
-	notificationXML += "<TopicConfiguration><Event>s3:ObjectRemoved:*</Event><Event>s3:ObjectRemoved:*</Event><Topic>" + listenARN + "</Topic></TopicConfiguration>"
+	notificationXML += "<TopicConfiguration><Event>s3:ObjectRemoved:*</Event><Event>s3:ObjectAccessed:*</Event><Topic>" + listenARN + "</Topic></TopicConfiguration>"
 	notificationXML += "<QueueConfiguration><Event>s3:ObjectRemoved:*</Event><Event>s3:ObjectRemoved:*</Event><Queue>" + queueARN + "</Queue></QueueConfiguration>"
 	notificationXML += "</NotificationConfiguration>"
 	if err := fsstorage.AppendFile(minioMetaBucket, bucketConfigPrefix+"/"+bucketName+"/"+bucketNotificationConfig, []byte(notificationXML)); err != nil {
@@ -193,6 +193,39 @@ func TestInitEventNotifierFaultyDisks(t *testing.T) {
 	}
 }
 
+// Tests that the event notifier is initialized with the unreachable
+// target, events for it are queued and fail to be delivered.
+func testInitEventNotifierUnreachable(t *testing.T, obj ObjectLayer, queueARN string) {
+	savedBackoff := notifyRetryMinBackoff
+	notifyRetryMinBackoff = 10 * time.Millisecond
+	defer func() { notifyRetryMinBackoff = savedBackoff }()
+
+	if err := initEventNotifier(obj); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	defer func() {
+		globalEventNotifier.closeQueueTargets()
+		globalEventNotifier = nil
+	}()
+
+	queue := globalEventNotifier.GetQueueTarget(queueARN)
+	if queue == nil {
+		t.Fatalf("Expected queue target %s", queueARN)
+	}
+	if err := queue.enqueue(queuedEvent{Key: "bucket/object", EventType: "s3:ObjectCreated:Put"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	for i := 0; queue.status().Failures == 0; i++ {
+		if i == 500 {
+			t.Fatalf("Expected failed deliveries to %s", queueARN)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status := queue.status(); status.Pending != 1 {
+		t.Fatalf("Expected 1 pending event, got %d", status.Pending)
+	}
+}
+
 // InitEventNotifierWithAMQP - tests InitEventNotifier when AMQP is not prepared
 func TestInitEventNotifierWithAMQP(t *testing.T) {
 	// initialize the server and obtain the credentials and root.
@@ -215,9 +248,7 @@ func TestInitEventNotifierWithAMQP(t *testing.T) {
 	}
 
 	serverConfig.SetAMQPNotifyByID("1", amqpNotify{Enable: true})
-	if err := initEventNotifier(fs); err == nil {
-		t.Fatal("AMQP config didn't fail.")
-	}
+	testInitEventNotifierUnreachable(t, fs, "arn:minio:sqs:us-east-1:1:amqp")
 }
 
 // InitEventNotifierWithElasticSearch - test InitEventNotifier when ElasticSearch is not ready
@@ -242,9 +273,7 @@ func TestInitEventNotifierWithElasticSearch(t *testing.T) {
 	}
 
 	serverConfig.SetElasticSearchNotifyByID("1", elasticSearchNotify{Enable: true})
-	if err := initEventNotifier(fs); err == nil {
-		t.Fatal("ElasticSearch config didn't fail.")
-	}
+	testInitEventNotifierUnreachable(t, fs, "arn:minio:sqs:us-east-1:1:elasticsearch")
 }
 
 // InitEventNotifierWithRedis - test InitEventNotifier when Redis is not ready
@@ -269,9 +298,7 @@ func TestInitEventNotifierWithRedis(t *testing.T) {
 	}
 
 	serverConfig.SetRedisNotifyByID("1", redisNotify{Enable: true})
-	if err := initEventNotifier(fs); err == nil {
-		t.Fatal("Redis config didn't fail.")
-	}
+	testInitEventNotifierUnreachable(t, fs, "arn:minio:sqs:us-east-1:1:redis")
 }
 
 // TestListenBucketNotification - test Listen Bucket Notification process
@@ -312,7 +339,7 @@ func TestListenBucketNotification(t *testing.T) {
 
 	// Create and store notification.xml with listen and queue notification configured
 	notificationXML := "<NotificationConfiguration>"
-	notificationXML += "<TopicConfiguration><Event>s3:ObjectRemoved:*</Event><Event>s3:ObjectRemoved:*</Event><Topic>" + listenARN + "</Topic></TopicConfiguration>"
+	notificationXML += "<TopicConfiguration><Event>s3:ObjectRemoved:*</Event><Event>s3:ObjectAccessed:*</Event><Topic>" + listenARN + "</Topic></TopicConfiguration>"
 	notificationXML += "<QueueConfiguration><Event>s3:ObjectRemoved:*</Event><Event>s3:ObjectRemoved:*</Event><Queue>" + queueARN + "</Queue></QueueConfiguration>"
 	notificationXML += "</NotificationConfiguration>"
 	if err := storage.AppendFile(minioMetaBucket, bucketConfigPrefix+"/"+bucketName+"/"+bucketNotificationConfig, []byte(notificationXML)); err != nil {
@@ -380,4 +407,33 @@ func TestListenBucketNotification(t *testing.T) {
 	case <-time.After(30 * time.Second):
 		break
 	}
+
+	// Fire an object accessed event notification.
+	go eventNotify(eventData{
+		Type:   ObjectAccessedHead,
+		Bucket: bucketName,
+		ObjInfo: ObjectInfo{
+			Bucket: bucketName,
+			Name:   objectName,
+			Size:   1,
+		},
+		ReqParams: map[string]string{
+			"sourceIPAddress": "localhost:1337",
+		},
+	})
+
+	select {
+	case n := <-nEventCh:
+		if len(n) == 0 {
+			t.Fatal("Unexpected error occured")
+		}
+		if n[0].EventName != ObjectAccessedHead.String() {
+			t.Fatalf("Received wrong event name in notification, expected %s, received %s", ObjectAccessedHead, n[0].EventName)
+		}
+		if n[0].S3.Object.Size != 1 {
+			t.Fatalf("Expected object size to be set for accessed event")
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("Timed out waiting for object accessed event")
+	}
 }
@@ -168,6 +168,8 @@ func isPartsSame(uploadedParts []objectPartInfo, completeParts []completePart) b
 var extendedHeaders = []string{
 	"X-Amz-Meta-",
 	"X-Minio-Meta-",
+	"X-Minio-Internal-",
+	"X-Amz-Object-Lock-",
 	// Add new extended headers.
 }
 
@@ -273,6 +273,14 @@ func (fs fsObjects) NewMultipartUpload(bucket, object string, meta map[string]st
 	if !IsValidObjectName(object) {
 		return "", traceError(ObjectNameInvalid{Bucket: bucket, Object: object})
 	}
+	// No metadata is set, allocate a new one.
+	if meta == nil {
+		meta = make(map[string]string)
+	}
+	// Parts are compressed if the object is compressible.
+	setCompressionMetadata(bucket, object, meta)
+	// No bit-rot checksums in FS mode.
+	delete(meta, bitRotAlgoMetaKey)
 	return fs.newMultipartUpload(bucket, object, meta)
 }
 
@@ -415,12 +423,18 @@ func (fs fsObjects) PutObjectPart(bucket, object, uploadID string, partID int, s
 	opsID := getOpsID()
 
 	nsMutex.RLock(minioMetaBucket, uploadIDPath, opsID)
-	// Just check if the uploadID exists to avoid copy if it doesn't.
-	uploadIDExists := fs.isUploadIDExists(bucket, object, uploadID)
+	// Just check if the uploadID exists to avoid copy if it doesn't,
+	// fs.json is read in place of a stat as parts of compressed
+	// uploads are compressed too.
+	uploadMeta, err := readFSMetadata(fs.storage, minioMetaBucket, pathJoin(uploadIDPath, fsMetaJSONFile))
 	nsMutex.RUnlock(minioMetaBucket, uploadIDPath, opsID)
-	if !uploadIDExists {
+	if err != nil {
+		if errorCause(err) != errFileNotFound {
+			errorIf(err, "Unable to access upload id"+uploadIDPath)
+		}
 		return "", traceError(InvalidUploadID{UploadID: uploadID})
 	}
+	compressed := isCompressed(uploadMeta.Meta)
 
 	partSuffix := fmt.Sprintf("object%d", partID)
 	tmpPartPath := path.Join(tmpMetaPrefix, uploadID+"."+getUUID()+"."+partSuffix)
@@ -438,17 +452,28 @@ func (fs fsObjects) PutObjectPart(bucket, object, uploadID string, partID int, s
 		limitDataReader = data
 	}
 
-	teeReader := io.TeeReader(limitDataReader, md5Writer)
+	var reader io.Reader = io.TeeReader(limitDataReader, md5Writer)
+	var compressedReader *compressReader
+	if compressed {
+		compressedReader = newCompressReader(reader)
+		reader = compressedReader
+	}
 	bufSize := int64(readSizeV1)
 	if size > 0 && bufSize > size {
 		bufSize = size
 	}
 	buf := make([]byte, int(bufSize))
-	bytesWritten, cErr := fsCreateFile(fs.storage, teeReader, buf, minioMetaBucket, tmpPartPath)
+	bytesWritten, cErr := fsCreateFile(fs.storage, reader, buf, minioMetaBucket, tmpPartPath)
 	if cErr != nil {
 		fs.storage.DeleteFile(minioMetaBucket, tmpPartPath)
 		return "", toObjectErr(cErr, minioMetaBucket, tmpPartPath)
 	}
+	// Size of the part as stored.
+	partSize := bytesWritten
+	if compressed {
+		// Sizes are checked before compression.
+		bytesWritten = compressedReader.actualSize
+	}
 	// Should return IncompleteBody{} error when reader has fewer
 	// bytes than specified in request header.
 	if bytesWritten < size {
@@ -494,7 +519,12 @@ func (fs fsObjects) PutObjectPart(bucket, object, uploadID string, partID int, s
 	if err != nil {
 		return "", toObjectErr(err, minioMetaBucket, fsMetaPath)
 	}
-	fsMeta.AddObjectPart(partID, partSuffix, newMD5Hex, size)
+	fsMeta.AddObjectPart(partID, partSuffix, newMD5Hex, partSize)
+	if compressed {
+		part := &fsMeta.Parts[fsMeta.ObjectPartIndex(partID)]
+		part.ActualSize = bytesWritten
+		part.CompressIndex = compressedReader.index.String()
+	}
 
 	partPath := path.Join(mpartMetaPrefix, bucket, object, uploadID, partSuffix)
 	err = fs.storage.RenameFile(minioMetaBucket, tmpPartPath, minioMetaBucket, partPath)
@@ -537,11 +567,15 @@ func (fs fsObjects) listObjectParts(bucket, object, uploadID string, partNumberM
 		if err != nil {
 			return ListPartsInfo{}, toObjectErr(traceError(err), minioMetaBucket, partNamePath)
 		}
+		size := fi.Size
+		if part.ActualSize > 0 {
+			size = part.ActualSize
+		}
 		result.Parts = append(result.Parts, partInfo{
 			PartNumber:   part.Number,
 			ETag:         part.ETag,
 			LastModified: fi.ModTime,
-			Size:         fi.Size,
+			Size:         size,
 		})
 		count--
 		if count == 0 {
@@ -654,6 +688,11 @@ func (fs fsObjects) CompleteMultipartUpload(bucket string, object string, upload
 		return "", toObjectErr(err, minioMetaBucket, fsMetaPath)
 	}
 
+	// Hold write lock on the destination while the object and its
+	// metadata are replaced.
+	nsMutex.Lock(bucket, object, opsID)
+	defer nsMutex.Unlock(bucket, object, opsID)
+
 	fsAppendMeta, err := readFSMetadata(fs.storage, minioMetaBucket, fsAppendMetaPath)
 	if err == nil && isPartsSame(fsAppendMeta.Parts, parts) {
 		fsAppendDataPath := getFSAppendDataPath(uploadID)
@@ -678,10 +717,10 @@ func (fs fsObjects) CompleteMultipartUpload(bucket string, object string, upload
 				return "", traceError(BadDigest{})
 			}
 			// All parts except the last part has to be atleast 5MB.
-			if (i < len(parts)-1) && !isMinAllowedPartSize(fsMeta.Parts[partIdx].Size) {
+			if (i < len(parts)-1) && !isMinAllowedPartSize(fsMeta.Parts[partIdx].actualSize()) {
 				return "", traceError(PartTooSmall{
 					PartNumber: part.PartNumber,
-					PartSize:   fsMeta.Parts[partIdx].Size,
+					PartSize:   fsMeta.Parts[partIdx].actualSize(),
 					PartETag:   part.ETag,
 				})
 			}
@@ -726,6 +765,25 @@ func (fs fsObjects) CompleteMultipartUpload(bucket string, object string, upload
 		}
 	}
 
+	if isCompressed(fsMeta.Meta) {
+		// Save the size of the object before compression, and its
+		// index merged from the indexes of the parts.
+		var actualSize int64
+		var objectParts []objectPartInfo
+		for _, part := range parts {
+			if partIdx := fsMeta.ObjectPartIndex(part.PartNumber); partIdx != -1 {
+				actualSize += fsMeta.Parts[partIdx].actualSize()
+				objectParts = append(objectParts, fsMeta.Parts[partIdx])
+			}
+		}
+		fsMeta.Meta[actualSizeMetaKey] = strconv.FormatInt(actualSize, 10)
+		index, err := partsCompressIndex(objectParts)
+		if err != nil {
+			return "", toObjectErr(traceError(err), bucket, object)
+		}
+		setCompressIndex(fsMeta.Meta, index)
+	}
+
 	// No need to save part info, since we have concatenated all parts.
 	fsMeta.Parts = nil
 
@@ -24,6 +24,7 @@ import (
 	"os"
 	"path"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/mf-00/minio/pkg/mimedb"
@@ -34,6 +35,10 @@ type fsObjects struct {
 	storage      StorageAPI
 	physicalDisk string
 
+	// Set when the disk is shared with other servers, the
+	// temporary files of the other servers are left alone.
+	shared bool
+
 	// List pool management.
 	listPool *treeWalkPool
 }
@@ -68,6 +73,16 @@ func loadFormatFS(storageDisk StorageAPI) (format formatConfigV1, err error) {
 
 // newFSObjects - initialize new fs object layer.
 func newFSObjects(disk string) (ObjectLayer, error) {
+	return initFSObjects(disk, false)
+}
+
+// newSharedFSObjects - initialize new fs object layer on a disk shared
+// with other servers, name space locks must be shared with them too.
+func newSharedFSObjects(disk string) (ObjectLayer, error) {
+	return initFSObjects(disk, true)
+}
+
+func initFSObjects(disk string, shared bool) (ObjectLayer, error) {
 	storage, err := newStorageAPI(disk)
 	if err != nil && err != errDiskNotFound {
 		return nil, err
@@ -85,10 +100,21 @@ func newFSObjects(disk string) (ObjectLayer, error) {
 	}
 
 	// Runs house keeping code, like creating minioMetaBucket, cleaning up tmp files etc.
-	if err = fsHouseKeeping(storage); err != nil {
+	if shared {
+		err = fsSharedHouseKeeping(storage)
+	} else {
+		err = fsHouseKeeping(storage)
+	}
+	if err != nil {
 		return nil, err
 	}
 
+	// Hold the lock so that servers sharing the disk do not create
+	// format.json at the same time.
+	opsID := getOpsID()
+	nsMutex.Lock(minioMetaBucket, fsFormatJSONFile, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, fsFormatJSONFile, opsID)
+
 	// loading format.json from minioMetaBucket.
 	// Note: The format.json content is ignored, reserved for future use.
 	format, err := loadFormatFS(storage)
@@ -110,6 +136,7 @@ func newFSObjects(disk string) (ObjectLayer, error) {
 	fs := fsObjects{
 		storage:      storage,
 		physicalDisk: disk,
+		shared:       shared,
 		listPool:     newTreeWalkPool(globalLookupTimeout),
 	}
 
@@ -119,6 +146,10 @@ func newFSObjects(disk string) (ObjectLayer, error) {
 
 // Should be called when process shuts down.
 func (fs fsObjects) Shutdown() error {
+	// Other servers may still be using the disk.
+	if fs.shared {
+		return nil
+	}
 	// List if there are any multipart entries.
 	_, err := fs.storage.ListDir(minioMetaBucket, mpartMetaPrefix)
 	if err != errFileNotFound {
@@ -251,6 +282,49 @@ func (fs fsObjects) GetObject(bucket, object string, offset int64, length int64,
 		return toObjectErr(traceError(errUnexpected), bucket, object)
 	}
 
+	// generates random string on setting MINIO_DEBUG=lock, else returns empty string.
+	// used for instrumentation on locks.
+	opsID := getOpsID()
+
+	// Lock the object before reading.
+	nsMutex.RLock(bucket, object, opsID)
+	defer nsMutex.RUnlock(bucket, object, opsID)
+
+	fsMeta, err := readFSMetadata(fs.storage, minioMetaBucket, path.Join(bucketMetaPrefix, bucket, object, fsMetaJSONFile))
+	// Ignore error if the metadata file is not found, other errors must be returned.
+	if err != nil && errorCause(err) != errFileNotFound {
+		return toObjectErr(err, bucket, object)
+	}
+	if !isCompressed(fsMeta.Meta) {
+		return fs.readObject(bucket, object, offset, length, writer)
+	}
+
+	// Compressed objects are read from the closest frame indexed
+	// before the range, frames before the range are skipped without
+	// being decompressed.
+	actualSize, err := getActualSize(fsMeta.Meta)
+	if err != nil {
+		return toObjectErr(traceError(err), bucket, object)
+	}
+	if offset > actualSize || length > actualSize || offset+length > actualSize {
+		return traceError(InvalidRange{offset, length, actualSize})
+	}
+	index, err := getCompressIndex(fsMeta.Meta)
+	if err != nil {
+		return toObjectErr(traceError(err), bucket, object)
+	}
+	err = decompressRange(writer, offset, length, index, func(compressedOffset int64, compressedWriter io.Writer) error {
+		fi, sErr := fs.storage.StatFile(bucket, object)
+		if sErr != nil {
+			return toObjectErr(traceError(sErr), bucket, object)
+		}
+		return fs.readObject(bucket, object, compressedOffset, fi.Size-compressedOffset, compressedWriter)
+	})
+	return toObjectErr(err, bucket, object)
+}
+
+// readObject - writes length bytes at offset of the object as stored.
+func (fs fsObjects) readObject(bucket, object string, offset int64, length int64, writer io.Writer) (err error) {
 	// Stat the file to get file size.
 	fi, err := fs.storage.StatFile(bucket, object)
 	if err != nil {
@@ -331,6 +405,14 @@ func (fs fsObjects) getObjectInfo(bucket, object string) (ObjectInfo, error) {
 		fsMeta.Meta = make(map[string]string)
 	}
 
+	// Size of compressed objects is the one before compression.
+	size := fi.Size
+	if isCompressed(fsMeta.Meta) {
+		if size, err = getActualSize(fsMeta.Meta); err != nil {
+			return ObjectInfo{}, toObjectErr(traceError(err), bucket, object)
+		}
+	}
+
 	// Guess content-type from the extension if possible.
 	if fsMeta.Meta["content-type"] == "" {
 		if objectExt := path.Ext(object); objectExt != "" {
@@ -345,7 +427,7 @@ func (fs fsObjects) getObjectInfo(bucket, object string) (ObjectInfo, error) {
 		Bucket:          bucket,
 		Name:            object,
 		ModTime:         fi.ModTime,
-		Size:            fi.Size,
+		Size:            size,
 		IsDir:           fi.Mode.IsDir(),
 		MD5Sum:          fsMeta.Meta["md5Sum"],
 		ContentType:     fsMeta.Meta["content-type"],
@@ -364,6 +446,13 @@ func (fs fsObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
 	if !IsValidObjectName(object) {
 		return ObjectInfo{}, traceError(ObjectNameInvalid{Bucket: bucket, Object: object})
 	}
+
+	// generates random string on setting MINIO_DEBUG=lock, else returns empty string.
+	// used for instrumentation on locks.
+	opsID := getOpsID()
+
+	nsMutex.RLock(bucket, object, opsID)
+	defer nsMutex.RUnlock(bucket, object, opsID)
 	return fs.getObjectInfo(bucket, object)
 }
 
@@ -383,6 +472,9 @@ func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.
 	if metadata == nil {
 		metadata = make(map[string]string)
 	}
+	compressed := setCompressionMetadata(bucket, object, metadata)
+	// No bit-rot checksums in FS mode.
+	delete(metadata, bitRotAlgoMetaKey)
 
 	uniqueID := getUUID()
 
@@ -410,6 +502,9 @@ func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.
 		if err != nil {
 			return ObjectInfo{}, toObjectErr(traceError(err), bucket, object)
 		}
+		if compressed {
+			metadata[actualSizeMetaKey] = "0"
+		}
 	} else {
 		// Allocate a buffer to Read() from request body
 		bufSize := int64(readSizeV1)
@@ -417,13 +512,24 @@ func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.
 			bufSize = size
 		}
 		buf := make([]byte, int(bufSize))
-		teeReader := io.TeeReader(limitDataReader, md5Writer)
+		var reader io.Reader = io.TeeReader(limitDataReader, md5Writer)
+		var compressedReader *compressReader
+		if compressed {
+			compressedReader = newCompressReader(reader)
+			reader = compressedReader
+		}
 		var bytesWritten int64
-		bytesWritten, err = fsCreateFile(fs.storage, teeReader, buf, minioMetaBucket, tempObj)
+		bytesWritten, err = fsCreateFile(fs.storage, reader, buf, minioMetaBucket, tempObj)
 		if err != nil {
 			fs.storage.DeleteFile(minioMetaBucket, tempObj)
 			return ObjectInfo{}, toObjectErr(traceError(err), bucket, object)
 		}
+		if compressed {
+			// Sizes are checked before compression.
+			bytesWritten = compressedReader.actualSize
+			metadata[actualSizeMetaKey] = strconv.FormatInt(bytesWritten, 10)
+			setCompressIndex(metadata, compressedReader.index)
+		}
 
 		// Should return IncompleteBody{} error when reader has fewer
 		// bytes than specified in request header.
@@ -460,6 +566,14 @@ func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.
 		}
 	}
 
+	// generates random string on setting MINIO_DEBUG=lock, else returns empty string.
+	// used for instrumentation on locks.
+	opsID := getOpsID()
+
+	// Lock the object while it is replaced along with its metadata.
+	nsMutex.Lock(bucket, object, opsID)
+	defer nsMutex.Unlock(bucket, object, opsID)
+
 	// Entire object was written to the temp location, now it's safe to rename it to the actual location.
 	err = fs.storage.RenameFile(minioMetaBucket, tempObj, bucket, object)
 	if err != nil {
@@ -476,6 +590,12 @@ func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.
 		if err = writeFSMetadata(fs.storage, minioMetaBucket, fsMetaPath, fsMeta); err != nil {
 			return ObjectInfo{}, toObjectErr(traceError(err), bucket, object)
 		}
+	} else {
+		// Remove metadata left over by an earlier version of the object.
+		err = fs.storage.DeleteFile(minioMetaBucket, path.Join(bucketMetaPrefix, bucket, object, fsMetaJSONFile))
+		if err != nil && err != errFileNotFound {
+			return ObjectInfo{}, toObjectErr(traceError(err), bucket, object)
+		}
 	}
 	objInfo, err = fs.getObjectInfo(bucket, object)
 	if err == nil {
@@ -495,6 +615,14 @@ func (fs fsObjects) DeleteObject(bucket, object string) error {
 	if !IsValidObjectName(object) {
 		return traceError(ObjectNameInvalid{Bucket: bucket, Object: object})
 	}
+
+	// generates random string on setting MINIO_DEBUG=lock, else returns empty string.
+	// used for instrumentation on locks.
+	opsID := getOpsID()
+
+	nsMutex.Lock(bucket, object, opsID)
+	defer nsMutex.Unlock(bucket, object, opsID)
+
 	err := fs.storage.DeleteFile(minioMetaBucket, path.Join(bucketMetaPrefix, bucket, object, fsMetaJSONFile))
 	if err != nil && err != errFileNotFound {
 		return toObjectErr(traceError(err), bucket, object)
@@ -529,6 +657,11 @@ func (fs fsObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKey
 		// Object name needs to be full path.
 		fileInfo.Name = entry
 		fileInfo.MD5Sum = fsMeta.Meta["md5Sum"]
+		if isCompressed(fsMeta.Meta) {
+			if fileInfo.Size, err = getActualSize(fsMeta.Meta); err != nil {
+				return FileInfo{}, traceError(err)
+			}
+		}
 		return
 	}
 
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // TestNewFS - tests initialization of all input disks
@@ -101,6 +102,46 @@ func TestFSShutdown(t *testing.T) {
 
 }
 
+// TestSharedFS - servers sharing a disk leave the temporary files in use
+// by the others and the disk itself alone.
+func TestSharedFS(t *testing.T) {
+	disk := filepath.Join(os.TempDir(), "minio-"+nextSuffix())
+	defer removeAll(disk)
+	obj, err := newSharedFSObjects(disk)
+	if err != nil {
+		t.Fatal("Cannot create a new FS object: ", err)
+	}
+	fs := obj.(fsObjects)
+
+	for _, name := range []string{"recent", "expired"} {
+		if err = fs.storage.AppendFile(minioMetaBucket, pathJoin(tmpMetaPrefix, name), []byte("data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expiredTime := time.Now().Add(-2 * fsSharedTmpExpiry)
+	if err = os.Chtimes(filepath.Join(disk, minioMetaBucket, tmpMetaPrefix, "expired"), expiredTime, expiredTime); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another server starts on the disk.
+	if _, err = newSharedFSObjects(disk); err != nil {
+		t.Fatal("Cannot create a new FS object: ", err)
+	}
+	if _, err = fs.storage.StatFile(minioMetaBucket, pathJoin(tmpMetaPrefix, "recent")); err != nil {
+		t.Fatal("Temporary file in use should not be removed: ", err)
+	}
+	if _, err = fs.storage.StatFile(minioMetaBucket, pathJoin(tmpMetaPrefix, "expired")); err != errFileNotFound {
+		t.Fatal("Expected expired temporary file to be removed, got: ", err)
+	}
+
+	if err = obj.Shutdown(); err != nil {
+		t.Fatal("Cannot shutdown the FS object: ", err)
+	}
+	if _, err = fs.storage.StatFile(minioMetaBucket, fsFormatJSONFile); err != nil {
+		t.Fatal("Disk should be left to the other servers on shutdown: ", err)
+	}
+}
+
 // TestFSLoadFormatFS - test loadFormatFS with healty and faulty disks
 func TestFSLoadFormatFS(t *testing.T) {
 	// Prepare for testing
@@ -0,0 +1,367 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/mf-00/minio/pkg/wildcard"
+)
+
+// Name of the file holding the data of a cached object, its metadata
+// is saved next to it in `xl.json`.
+const cacheDataFile = "part.1"
+
+// cacheObjects - an object layer keeping a copy of the objects read
+// from or written to a backend object layer on local disks. Cached
+// copies are only served while their ETag and size match the ones of
+// the backend object, errors of the cache disks are never returned.
+type cacheObjects struct {
+	// All the calls not involving the cache are passed through.
+	ObjectLayer
+
+	// Disks objects are cached on, an object always goes to the
+	// same disk.
+	disks []StorageAPI
+
+	// Objects matching any of these patterns are never cached,
+	// patterns are matched against "bucket/object".
+	exclude []string
+}
+
+// newCacheObjects - returns an object layer caching the objects of the
+// backend on the directories.
+func newCacheObjects(backend ObjectLayer, dirs []string, exclude []string) (ObjectLayer, error) {
+	var disks []StorageAPI
+	for _, dir := range dirs {
+		disk, err := newPosix(dir)
+		if err != nil {
+			return nil, err
+		}
+		// Left over temporary files of a previous run are removed.
+		if err = disk.MakeVol(minioMetaBucket); err != nil && err != errVolumeExists {
+			return nil, err
+		}
+		if err = cleanupDir(disk, minioMetaBucket, tmpMetaPrefix); err != nil {
+			return nil, errorCause(err)
+		}
+		disks = append(disks, disk)
+	}
+	return cacheObjects{
+		ObjectLayer: backend,
+		disks:       disks,
+		exclude:     exclude,
+	}, nil
+}
+
+// isExcluded - tells if the object should not be cached.
+func (c cacheObjects) isExcluded(bucket, object string) bool {
+	if bucket == minioMetaBucket {
+		return true
+	}
+	for _, pattern := range c.exclude {
+		if wildcard.Match(pattern, pathJoin(bucket, object)) {
+			return true
+		}
+	}
+	return false
+}
+
+// getDisk - returns the disk the object is cached on.
+func (c cacheObjects) getDisk(bucket, object string) StorageAPI {
+	return c.disks[hashOrder(pathJoin(bucket, object), len(c.disks))[0]-1]
+}
+
+// GetObject - serves the object from the cache if the cached copy is
+// up to date, otherwise reads it from the backend. Whole objects read
+// from the backend are cached on the way.
+func (c cacheObjects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer) error {
+	if c.isExcluded(bucket, object) {
+		return c.ObjectLayer.GetObject(bucket, object, startOffset, length, writer)
+	}
+	objInfo, err := c.ObjectLayer.GetObjectInfo(bucket, object)
+	if err != nil {
+		return err
+	}
+	if startOffset < 0 || length < 0 || startOffset+length > objInfo.Size {
+		return traceError(InvalidRange{startOffset, length, objInfo.Size})
+	}
+
+	disk := c.getDisk(bucket, object)
+	xlMeta, err := readXLMeta(disk, bucket, object)
+	if err == nil && isCacheValid(xlMeta, objInfo) {
+		n, rErr := c.readCache(disk, bucket, object, startOffset, length, writer)
+		if rErr == nil {
+			return nil
+		}
+		if n != 0 {
+			// Bytes already written cannot be taken back.
+			return rErr
+		}
+		// Data of the cached copy is missing, serve the backend
+		// copy and cache it again.
+		errorIf(rErr, "Unable to read the cached copy of %s/%s.", bucket, object)
+	}
+
+	if startOffset != 0 || length != objInfo.Size {
+		// Partial reads are not cached.
+		return c.ObjectLayer.GetObject(bucket, object, startOffset, length, writer)
+	}
+	cw := newCacheWriter(disk)
+	if err = c.ObjectLayer.GetObject(bucket, object, 0, length, io.MultiWriter(writer, cw)); err != nil {
+		cw.abort()
+		return err
+	}
+	cw.commit(bucket, object, objInfo)
+	return nil
+}
+
+// readCache - writes length bytes of the cached copy from startOffset
+// on, returns the number of bytes written.
+func (c cacheObjects) readCache(disk StorageAPI, bucket, object string, startOffset, length int64, writer io.Writer) (int64, error) {
+	buf := make([]byte, readSizeV1)
+	dataPath := path.Join(object, cacheDataFile)
+	var written int64
+	for written < length {
+		curLength := int64(len(buf))
+		if length-written < curLength {
+			curLength = length - written
+		}
+		n, err := disk.ReadFile(bucket, dataPath, startOffset+written, buf[:curLength])
+		if n != curLength {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return written, traceError(err)
+		}
+		if _, err = writer.Write(buf[:n]); err != nil {
+			return written, traceError(err)
+		}
+		written += n
+	}
+	return written, nil
+}
+
+// isCacheValid - tells if the cached copy holds the current version of
+// the backend object. Objects the backend has no ETag for, like FS
+// objects without metadata, are compared by modification time.
+func isCacheValid(xlMeta xlMetaV1, objInfo ObjectInfo) bool {
+	if !xlMeta.IsValid() || xlMeta.Stat.Size != objInfo.Size {
+		return false
+	}
+	if objInfo.MD5Sum == "" {
+		return !objInfo.ModTime.IsZero() && xlMeta.Stat.ModTime.Equal(objInfo.ModTime)
+	}
+	return xlMeta.Meta["md5Sum"] == objInfo.MD5Sum
+}
+
+// PutObject - writes the object to the backend, keeping a copy in the
+// cache.
+func (c cacheObjects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string) (ObjectInfo, error) {
+	if c.isExcluded(bucket, object) {
+		return c.ObjectLayer.PutObject(bucket, object, size, data, metadata)
+	}
+	disk := c.getDisk(bucket, object)
+	c.invalidate(disk, bucket, object)
+
+	cw := newCacheWriter(disk)
+	objInfo, err := c.ObjectLayer.PutObject(bucket, object, size, io.TeeReader(data, cw), metadata)
+	if err != nil {
+		cw.abort()
+		return ObjectInfo{}, err
+	}
+	cw.commit(bucket, object, objInfo)
+	return objInfo, nil
+}
+
+// DeleteObject - deletes the object from the backend and the cache.
+func (c cacheObjects) DeleteObject(bucket, object string) error {
+	if err := c.ObjectLayer.DeleteObject(bucket, object); err != nil {
+		return err
+	}
+	if !c.isExcluded(bucket, object) {
+		c.invalidate(c.getDisk(bucket, object), bucket, object)
+	}
+	return nil
+}
+
+// CompleteMultipartUpload - completes the upload on the backend, the
+// previous version of the object is removed from the cache.
+func (c cacheObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (string, error) {
+	md5Sum, err := c.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+	if err != nil {
+		return "", err
+	}
+	if !c.isExcluded(bucket, object) {
+		c.invalidate(c.getDisk(bucket, object), bucket, object)
+	}
+	return md5Sum, nil
+}
+
+// invalidate - removes the cached copy of an object.
+func (c cacheObjects) invalidate(disk StorageAPI, bucket, object string) {
+	// The metadata goes first, the data is never served without it.
+	for _, file := range []string{xlMetaJSONFile, cacheDataFile} {
+		err := disk.DeleteFile(bucket, path.Join(object, file))
+		if err != nil && err != errFileNotFound && err != errVolumeNotFound {
+			errorIf(err, "Unable to remove the cached copy of %s/%s.", bucket, object)
+		}
+	}
+}
+
+// cacheWriter - writes a copy of an object to temporary files of a
+// cache disk. Writes never fail, once the disk returns an error the
+// copy is dropped.
+type cacheWriter struct {
+	disk    StorageAPI
+	tmpData string // Temporary data file.
+	tmpMeta string // Temporary `xl.json`.
+	buf     *bufio.Writer
+	md5     hash.Hash
+	size    int64
+	failed  bool
+}
+
+// newCacheWriter - returns a writer of a new copy on the disk.
+func newCacheWriter(disk StorageAPI) *cacheWriter {
+	tmpPath := path.Join(tmpMetaPrefix, getUUID())
+	cw := &cacheWriter{
+		disk:    disk,
+		tmpData: tmpPath + "." + cacheDataFile,
+		tmpMeta: tmpPath + "." + xlMetaJSONFile,
+		md5:     md5.New(),
+	}
+	cw.buf = bufio.NewWriterSize(diskAppender{cw}, readSizeV1)
+	return cw
+}
+
+// diskAppender - appends the data buffered by a cache writer to its
+// temporary file.
+type diskAppender struct {
+	cw *cacheWriter
+}
+
+func (d diskAppender) Write(p []byte) (int, error) {
+	err := d.cw.disk.AppendFile(minioMetaBucket, d.cw.tmpData, p)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Write - buffers the data, it is appended to the disk in large writes.
+func (cw *cacheWriter) Write(p []byte) (int, error) {
+	if cw.failed {
+		return len(p), nil
+	}
+	if _, err := cw.buf.Write(p); err != nil {
+		cw.fail(err)
+		return len(p), nil
+	}
+	cw.md5.Write(p)
+	cw.size += int64(len(p))
+	return len(p), nil
+}
+
+// fail - drops the copy.
+func (cw *cacheWriter) fail(err error) {
+	if err != errDiskFull {
+		errorIf(err, "Unable to write to the cache.")
+	}
+	cw.failed = true
+	cw.abort()
+}
+
+// abort - removes the temporary files left.
+func (cw *cacheWriter) abort() {
+	_ = cw.disk.DeleteFile(minioMetaBucket, cw.tmpData)
+	_ = cw.disk.DeleteFile(minioMetaBucket, cw.tmpMeta)
+}
+
+// commit - moves the copy in place, as the cached copy of the backend
+// object. Copies which do not match the object are dropped.
+func (cw *cacheWriter) commit(bucket, object string, objInfo ObjectInfo) {
+	defer cw.abort()
+	if cw.failed {
+		return
+	}
+	if err := cw.buf.Flush(); err != nil {
+		cw.fail(err)
+		return
+	}
+	if cw.size != objInfo.Size {
+		return
+	}
+	// ETags of multipart objects are not the md5sum of their data,
+	// other objects may have been replaced while being read. Objects
+	// without an ETag are cached with the md5sum of their data.
+	md5Sum := hex.EncodeToString(cw.md5.Sum(nil))
+	if objInfo.MD5Sum == "" {
+		objInfo.MD5Sum = md5Sum
+	} else if !strings.Contains(objInfo.MD5Sum, "-") && md5Sum != objInfo.MD5Sum {
+		return
+	}
+	if cw.size == 0 {
+		// Empty objects have no data file to rename.
+		if err := cw.disk.AppendFile(minioMetaBucket, cw.tmpData, nil); err != nil {
+			return
+		}
+	}
+
+	xlMeta := newXLMetaV1(object, 1, 0)
+	xlMeta.Stat.Size = objInfo.Size
+	xlMeta.Stat.ModTime = objInfo.ModTime
+	xlMeta.Meta = make(map[string]string)
+	for k, v := range objInfo.UserDefined {
+		xlMeta.Meta[k] = v
+	}
+	xlMeta.Meta["md5Sum"] = objInfo.MD5Sum
+	if objInfo.ContentType != "" {
+		xlMeta.Meta["content-type"] = objInfo.ContentType
+	}
+	xlMeta.AddObjectPart(1, cacheDataFile, objInfo.MD5Sum, objInfo.Size)
+	metadataBytes, err := json.Marshal(&xlMeta)
+	if err != nil {
+		return
+	}
+	if err = cw.disk.AppendFile(minioMetaBucket, cw.tmpMeta, metadataBytes); err != nil {
+		return
+	}
+
+	if err = cw.disk.MakeVol(bucket); err != nil && err != errVolumeExists {
+		return
+	}
+	// The previous copy is invalidated before the data is replaced,
+	// the metadata is moved last.
+	if err = cw.disk.DeleteFile(bucket, path.Join(object, xlMetaJSONFile)); err != nil && err != errFileNotFound {
+		return
+	}
+	if err = cw.disk.RenameFile(minioMetaBucket, cw.tmpData, bucket, path.Join(object, cacheDataFile)); err != nil {
+		errorIf(err, "Unable to cache %s/%s.", bucket, object)
+		return
+	}
+	if err = cw.disk.RenameFile(minioMetaBucket, cw.tmpMeta, bucket, path.Join(object, xlMetaJSONFile)); err != nil {
+		errorIf(err, "Unable to cache %s/%s.", bucket, object)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// Tests reads and writes through the disk cache of the gateway.
+func TestGatewayCacheObjects(t *testing.T) {
+	backend, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(fsDir)
+	cacheDirs, err := getRandomDisks(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(cacheDirs)
+
+	obj, err := newCacheObjects(backend, cacheDirs, []string{"bucket/excluded*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := obj.(cacheObjects)
+	if err = obj.MakeBucket("bucket"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Objects written are cached along with their ETag.
+	data := bytes.Repeat([]byte("a"), 1024)
+	objInfo, err := obj.PutObject("bucket", "dir/object", int64(len(data)), bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xlMeta, err := readXLMeta(cache.getDisk("bucket", "dir/object"), "bucket", "dir/object")
+	if err != nil {
+		t.Fatalf("Object was not cached: %s", err)
+	}
+	if xlMeta.Meta["md5Sum"] != objInfo.MD5Sum || xlMeta.Stat.Size != objInfo.Size {
+		t.Fatalf("Expected cached ETag %s and size %d, got %s and %d",
+			objInfo.MD5Sum, objInfo.Size, xlMeta.Meta["md5Sum"], xlMeta.Stat.Size)
+	}
+
+	// Up to date copies are served from the cache.
+	cachedData := bytes.Repeat([]byte("b"), len(data))
+	dataFile := filepath.Join(cacheDirs[0], "bucket", "dir", "object", cacheDataFile)
+	if err = ioutil.WriteFile(dataFile, cachedData, 0644); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = obj.GetObject("bucket", "dir/object", 0, int64(len(data)), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), cachedData) {
+		t.Fatal("Expected the object to be read from the cache")
+	}
+	buf.Reset()
+	if err = obj.GetObject("bucket", "dir/object", 10, 20, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), cachedData[10:30]) {
+		t.Fatal("Expected the range to be read from the cache")
+	}
+
+	// Objects changed in the backend are read from it and cached again.
+	newData := bytes.Repeat([]byte("c"), 2048)
+	newInfo, err := backend.PutObject("bucket", "dir/object", int64(len(newData)), bytes.NewReader(newData), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err = obj.GetObject("bucket", "dir/object", 0, int64(len(newData)), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), newData) {
+		t.Fatal("Expected the changed object to be read from the backend")
+	}
+	xlMeta, err = readXLMeta(cache.getDisk("bucket", "dir/object"), "bucket", "dir/object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if xlMeta.Meta["md5Sum"] != newInfo.MD5Sum {
+		t.Fatalf("Expected cached ETag %s, got %s", newInfo.MD5Sum, xlMeta.Meta["md5Sum"])
+	}
+
+	// Excluded objects are not cached.
+	_, err = obj.PutObject("bucket", "excluded-object", int64(len(data)), bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = readXLMeta(cache.getDisk("bucket", "excluded-object"), "bucket", "excluded-object"); err == nil {
+		t.Fatal("Excluded object should not be cached")
+	}
+
+	// Deleted objects are removed from the cache.
+	if err = obj.DeleteObject("bucket", "dir/object"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = readXLMeta(cache.getDisk("bucket", "dir/object"), "bucket", "dir/object"); err == nil {
+		t.Fatal("Deleted object should be removed from the cache")
+	}
+	if err = obj.GetObject("bucket", "dir/object", 0, 0, &buf); err == nil {
+		t.Fatal("Expected an error reading a deleted object")
+	}
+}
@@ -0,0 +1,511 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	minio "github.com/minio/minio-go"
+)
+
+// Objects of unknown size, like the ones uploaded from the browser,
+// are sent to the remote service in parts of this size.
+const gatewayPartSize = 4 * minPartSize
+
+// s3Objects - implements the object layer by proxying every call to
+// a remote S3 service with the minio-go client. The server's own
+// configuration stored in the minio meta bucket, like bucket policies,
+// is kept in a local FS object layer instead.
+type s3Objects struct {
+	client minio.Core
+	meta   ObjectLayer
+}
+
+// newS3Objects - returns an object layer proxying to the S3 service
+// at the endpoint, for ex. https://s3.amazonaws.com, metaDir is where
+// the local configuration is kept.
+func newS3Objects(endpoint, accessKey, secretKey, region, metaDir string) (ObjectLayer, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("Invalid S3 endpoint %s, should be of the form http(s)://host[:port]", endpoint)
+	}
+	if region == "" {
+		region = defaultRegion
+	}
+	client, err := minio.NewWithRegion(u.Host, accessKey, secretKey, u.Scheme == "https", region)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := newFSObjects(metaDir)
+	if err != nil {
+		return nil, err
+	}
+	return s3Objects{
+		client: minio.Core{Client: client},
+		meta:   meta,
+	}, nil
+}
+
+// s3ToObjectErr - converts errors of the remote S3 service to object
+// layer errors, params are the bucket, the object and the upload id.
+func s3ToObjectErr(err error, params ...string) error {
+	errResp, ok := err.(minio.ErrorResponse)
+	if !ok {
+		return err
+	}
+	bucket, object, uploadID := "", "", ""
+	if len(params) >= 1 {
+		bucket = params[0]
+	}
+	if len(params) >= 2 {
+		object = params[1]
+	}
+	if len(params) >= 3 {
+		uploadID = params[2]
+	}
+	switch errResp.Code {
+	case "NoSuchBucket":
+		return BucketNotFound{Bucket: bucket}
+	case "NoSuchKey":
+		return ObjectNotFound{Bucket: bucket, Object: object}
+	case "BucketAlreadyOwnedByYou", "BucketAlreadyExists":
+		return BucketExists{Bucket: bucket}
+	case "BucketNotEmpty":
+		return BucketNotEmpty{Bucket: bucket}
+	case "InvalidBucketName":
+		return BucketNameInvalid{Bucket: bucket}
+	case "XMinioInvalidObjectName", "KeyTooLong":
+		return ObjectNameInvalid{Bucket: bucket, Object: object}
+	case "XMinioObjectExistsAsDirectory":
+		return ObjectExistsAsDirectory{Bucket: bucket, Object: object}
+	case "NoSuchUpload":
+		return InvalidUploadID{UploadID: uploadID}
+	case "InvalidPart", "InvalidPartOrder":
+		return InvalidPart{}
+	case "EntityTooSmall":
+		return PartTooSmall{}
+	case "BadDigest", "InvalidDigest":
+		return BadDigest{}
+	case "IncompleteBody":
+		return IncompleteBody{}
+	case "InvalidRange":
+		return InvalidRange{}
+	case "XMinioStorageFull":
+		return StorageFull{}
+	case "AccessDenied":
+		return errAccessDenied
+	case "NotImplemented":
+		return NotImplemented{}
+	}
+	return err
+}
+
+// s3Metadata - returns the object metadata, as extracted by
+// extractMetadataFromHeader, to be saved by the remote service, and
+// the base64 encoded md5sum of the content if it is known, so that
+// the remote service verifies it.
+func s3Metadata(metadata map[string]string) (meta map[string]string, md5Base64 string) {
+	meta = make(map[string]string)
+	for k, v := range metadata {
+		switch {
+		case k == "md5Sum":
+			md5Base64 = hexToBase64MD5(v)
+		case isInternalMetaKey(k):
+		default:
+			meta[k] = v
+		}
+	}
+	return meta, md5Base64
+}
+
+// hexToBase64MD5 - returns the base64 encoding of a hex encoded md5sum,
+// or "" if it is not set.
+func hexToBase64MD5(md5Hex string) string {
+	md5Bytes, err := hex.DecodeString(md5Hex)
+	if err != nil || md5Hex == "" {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(md5Bytes)
+}
+
+// s3PutObjectOptions - returns the options of a multipart upload
+// saving the metadata returned by s3Metadata.
+func s3PutObjectOptions(meta map[string]string) (opts minio.PutObjectOptions) {
+	opts.UserMetadata = make(map[string]string)
+	for k, v := range meta {
+		switch {
+		case strings.EqualFold(k, "content-type"):
+			opts.ContentType = v
+		case strings.EqualFold(k, "content-encoding"):
+			opts.ContentEncoding = v
+		case strings.EqualFold(k, "content-disposition"):
+			opts.ContentDisposition = v
+		case strings.EqualFold(k, "cache-control"):
+			opts.CacheControl = v
+		default:
+			opts.UserMetadata[k] = v
+		}
+	}
+	return opts
+}
+
+// s3ObjectInfo - converts the info of a remote object.
+func s3ObjectInfo(bucket string, info minio.ObjectInfo) ObjectInfo {
+	objInfo := ObjectInfo{
+		Bucket:      bucket,
+		Name:        info.Key,
+		ModTime:     info.LastModified.UTC(),
+		Size:        info.Size,
+		MD5Sum:      strings.Trim(info.ETag, "\""),
+		ContentType: info.ContentType,
+	}
+	if info.Metadata != nil {
+		objInfo.ContentEncoding = info.Metadata.Get("Content-Encoding")
+		objInfo.UserDefined = extractMetadataFromHeader(info.Metadata)
+	}
+	return objInfo
+}
+
+// Shutdown - shuts down the local object layer.
+func (s s3Objects) Shutdown() error {
+	return s.meta.Shutdown()
+}
+
+// HealDiskMetadata - not applicable to a remote service.
+func (s s3Objects) HealDiskMetadata() error {
+	return traceError(NotImplemented{})
+}
+
+// StorageInfo - the usage of the remote service is not known.
+func (s s3Objects) StorageInfo() StorageInfo {
+	return StorageInfo{}
+}
+
+// MakeBucket - creates a bucket on the remote service.
+func (s s3Objects) MakeBucket(bucket string) error {
+	if !IsValidBucketName(bucket) {
+		return traceError(BucketNameInvalid{Bucket: bucket})
+	}
+	if err := s.client.MakeBucket(bucket, ""); err != nil {
+		return traceError(s3ToObjectErr(err, bucket))
+	}
+	return nil
+}
+
+// GetBucketInfo - returns the info of a remote bucket.
+func (s s3Objects) GetBucketInfo(bucket string) (BucketInfo, error) {
+	if bucket == minioMetaBucket {
+		return s.meta.GetBucketInfo(bucket)
+	}
+	if !IsValidBucketName(bucket) {
+		return BucketInfo{}, traceError(BucketNameInvalid{Bucket: bucket})
+	}
+	// Creation dates are only returned when listing all the buckets,
+	// which is not served by every remote service, the creation date
+	// is left unset.
+	exists, err := s.client.BucketExists(bucket)
+	if err != nil {
+		return BucketInfo{}, traceError(s3ToObjectErr(err, bucket))
+	}
+	if !exists {
+		return BucketInfo{}, traceError(BucketNotFound{Bucket: bucket})
+	}
+	return BucketInfo{Name: bucket}, nil
+}
+
+// ListBuckets - lists the remote buckets.
+func (s s3Objects) ListBuckets() ([]BucketInfo, error) {
+	buckets, err := s.client.ListBuckets()
+	if err != nil {
+		return nil, traceError(s3ToObjectErr(err))
+	}
+	bucketInfos := make([]BucketInfo, len(buckets))
+	for i, bucket := range buckets {
+		bucketInfos[i] = BucketInfo{
+			Name:    bucket.Name,
+			Created: bucket.CreationDate,
+		}
+	}
+	return bucketInfos, nil
+}
+
+// DeleteBucket - deletes an empty remote bucket.
+func (s s3Objects) DeleteBucket(bucket string) error {
+	if !IsValidBucketName(bucket) {
+		return traceError(BucketNameInvalid{Bucket: bucket})
+	}
+	if err := s.client.RemoveBucket(bucket); err != nil {
+		return traceError(s3ToObjectErr(err, bucket))
+	}
+	return nil
+}
+
+// ListObjects - lists the objects of a remote bucket.
+func (s s3Objects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	if bucket == minioMetaBucket {
+		return s.meta.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+	}
+	resp, err := s.client.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil {
+		return ListObjectsInfo{}, traceError(s3ToObjectErr(err, bucket))
+	}
+	result := ListObjectsInfo{
+		IsTruncated: resp.IsTruncated,
+		NextMarker:  resp.NextMarker,
+	}
+	for _, object := range resp.Contents {
+		result.Objects = append(result.Objects, s3ObjectInfo(bucket, object))
+	}
+	for _, commonPrefix := range resp.CommonPrefixes {
+		result.Prefixes = append(result.Prefixes, commonPrefix.Prefix)
+	}
+	// The next marker is only sent along with a delimiter.
+	if result.IsTruncated && result.NextMarker == "" && len(result.Objects) > 0 {
+		result.NextMarker = result.Objects[len(result.Objects)-1].Name
+	}
+	return result, nil
+}
+
+// ListObjectsHeal - not applicable to a remote service.
+func (s s3Objects) ListObjectsHeal(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	return ListObjectsInfo{}, traceError(NotImplemented{})
+}
+
+// GetObject - reads length bytes of a remote object from startOffset on.
+func (s s3Objects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer) error {
+	if bucket == minioMetaBucket {
+		return s.meta.GetObject(bucket, object, startOffset, length, writer)
+	}
+	if length == 0 {
+		// Nothing to read, an empty range cannot be requested.
+		_, err := s.GetObjectInfo(bucket, object)
+		return err
+	}
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetRange(startOffset, startOffset+length-1); err != nil {
+		return traceError(InvalidRange{})
+	}
+	reader, _, err := s.client.GetObject(bucket, object, opts)
+	if err != nil {
+		return traceError(s3ToObjectErr(err, bucket, object))
+	}
+	defer reader.Close()
+	n, err := io.Copy(writer, reader)
+	if err != nil {
+		return traceError(err)
+	}
+	if n != length {
+		return traceError(io.ErrUnexpectedEOF)
+	}
+	return nil
+}
+
+// GetObjectInfo - returns the info of a remote object.
+func (s s3Objects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
+	if bucket == minioMetaBucket {
+		return s.meta.GetObjectInfo(bucket, object)
+	}
+	info, err := s.client.StatObject(bucket, object, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, traceError(s3ToObjectErr(err, bucket, object))
+	}
+	return s3ObjectInfo(bucket, info), nil
+}
+
+// PutObject - uploads an object to the remote service.
+func (s s3Objects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string) (ObjectInfo, error) {
+	if bucket == minioMetaBucket {
+		return s.meta.PutObject(bucket, object, size, data, metadata)
+	}
+	if size < 0 {
+		return s.putObjectStream(bucket, object, data, metadata)
+	}
+	meta, md5Base64 := s3Metadata(metadata)
+	if _, err := s.client.PutObject(bucket, object, data, size, md5Base64, "", meta, nil); err != nil {
+		return ObjectInfo{}, traceError(s3ToObjectErr(err, bucket, object))
+	}
+	return s.GetObjectInfo(bucket, object)
+}
+
+// putObjectStream - uploads an object of unknown size, the remote
+// service needs the length of every request. Objects larger than a
+// part are uploaded with a multipart upload. Data is buffered a part
+// at a time, the md5sum of every request is sent for the remote
+// service to verify it.
+func (s s3Objects) putObjectStream(bucket, object string, data io.Reader, metadata map[string]string) (ObjectInfo, error) {
+	buf := make([]byte, gatewayPartSize)
+	n, err := io.ReadFull(data, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		metadata["md5Sum"] = hex.EncodeToString(sumMD5(buf[:n]))
+		return s.PutObject(bucket, object, int64(n), bytes.NewReader(buf[:n]), metadata)
+	}
+	if err != nil {
+		return ObjectInfo{}, traceError(err)
+	}
+
+	uploadID, err := s.NewMultipartUpload(bucket, object, metadata)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	var parts []completePart
+	for partID := 1; n > 0; partID++ {
+		md5Hex := hex.EncodeToString(sumMD5(buf[:n]))
+		md5Sum, pErr := s.PutObjectPart(bucket, object, uploadID, partID, int64(n), bytes.NewReader(buf[:n]), md5Hex)
+		if pErr != nil {
+			s.AbortMultipartUpload(bucket, object, uploadID)
+			return ObjectInfo{}, pErr
+		}
+		parts = append(parts, completePart{PartNumber: partID, ETag: md5Sum})
+		n, err = io.ReadFull(data, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			s.AbortMultipartUpload(bucket, object, uploadID)
+			return ObjectInfo{}, traceError(err)
+		}
+	}
+	if _, err = s.CompleteMultipartUpload(bucket, object, uploadID, parts); err != nil {
+		return ObjectInfo{}, err
+	}
+	return s.GetObjectInfo(bucket, object)
+}
+
+// DeleteObject - deletes a remote object.
+func (s s3Objects) DeleteObject(bucket, object string) error {
+	if bucket == minioMetaBucket {
+		return s.meta.DeleteObject(bucket, object)
+	}
+	if err := s.client.RemoveObject(bucket, object); err != nil {
+		return traceError(s3ToObjectErr(err, bucket, object))
+	}
+	return nil
+}
+
+// HealObject - not applicable to a remote service.
+func (s s3Objects) HealObject(bucket, object string) error {
+	return traceError(NotImplemented{})
+}
+
+// ListMultipartUploads - lists the multipart uploads in progress on a
+// remote bucket.
+func (s s3Objects) ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (ListMultipartsInfo, error) {
+	resp, err := s.client.ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter, maxUploads)
+	if err != nil {
+		return ListMultipartsInfo{}, traceError(s3ToObjectErr(err, bucket))
+	}
+	result := ListMultipartsInfo{
+		KeyMarker:          resp.KeyMarker,
+		UploadIDMarker:     resp.UploadIDMarker,
+		NextKeyMarker:      resp.NextKeyMarker,
+		NextUploadIDMarker: resp.NextUploadIDMarker,
+		MaxUploads:         int(resp.MaxUploads),
+		IsTruncated:        resp.IsTruncated,
+		Prefix:             resp.Prefix,
+		Delimiter:          resp.Delimiter,
+	}
+	for _, upload := range resp.Uploads {
+		result.Uploads = append(result.Uploads, uploadMetadata{
+			Object:    upload.Key,
+			UploadID:  upload.UploadID,
+			Initiated: upload.Initiated.UTC(),
+		})
+	}
+	for _, commonPrefix := range resp.CommonPrefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix.Prefix)
+	}
+	return result, nil
+}
+
+// NewMultipartUpload - initiates a multipart upload on the remote service.
+func (s s3Objects) NewMultipartUpload(bucket, object string, metadata map[string]string) (string, error) {
+	meta, _ := s3Metadata(metadata)
+	uploadID, err := s.client.NewMultipartUpload(bucket, object, s3PutObjectOptions(meta))
+	if err != nil {
+		return "", traceError(s3ToObjectErr(err, bucket, object))
+	}
+	return uploadID, nil
+}
+
+// PutObjectPart - uploads a part of a remote multipart upload.
+func (s s3Objects) PutObjectPart(bucket, object, uploadID string, partID int, size int64, data io.Reader, md5Hex string) (string, error) {
+	part, err := s.client.PutObjectPart(bucket, object, uploadID, partID, data, size, hexToBase64MD5(md5Hex), "", nil)
+	if err != nil {
+		return "", traceError(s3ToObjectErr(err, bucket, object, uploadID))
+	}
+	return strings.Trim(part.ETag, "\""), nil
+}
+
+// ListObjectParts - lists the parts of a remote multipart upload.
+func (s s3Objects) ListObjectParts(bucket, object, uploadID string, partNumberMarker int, maxParts int) (ListPartsInfo, error) {
+	resp, err := s.client.ListObjectParts(bucket, object, uploadID, partNumberMarker, maxParts)
+	if err != nil {
+		return ListPartsInfo{}, traceError(s3ToObjectErr(err, bucket, object, uploadID))
+	}
+	result := ListPartsInfo{
+		Bucket:               bucket,
+		Object:               object,
+		UploadID:             uploadID,
+		StorageClass:         resp.StorageClass,
+		PartNumberMarker:     resp.PartNumberMarker,
+		NextPartNumberMarker: resp.NextPartNumberMarker,
+		MaxParts:             resp.MaxParts,
+		IsTruncated:          resp.IsTruncated,
+	}
+	for _, part := range resp.ObjectParts {
+		result.Parts = append(result.Parts, partInfo{
+			PartNumber:   part.PartNumber,
+			LastModified: part.LastModified.UTC(),
+			ETag:         strings.Trim(part.ETag, "\""),
+			Size:         part.Size,
+		})
+	}
+	return result, nil
+}
+
+// AbortMultipartUpload - aborts a remote multipart upload.
+func (s s3Objects) AbortMultipartUpload(bucket, object, uploadID string) error {
+	if err := s.client.AbortMultipartUpload(bucket, object, uploadID); err != nil {
+		return traceError(s3ToObjectErr(err, bucket, object, uploadID))
+	}
+	return nil
+}
+
+// CompleteMultipartUpload - completes a remote multipart upload.
+func (s s3Objects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (string, error) {
+	parts := make([]minio.CompletePart, len(uploadedParts))
+	for i, part := range uploadedParts {
+		parts[i] = minio.CompletePart{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+		}
+	}
+	md5Sum, err := s.client.CompleteMultipartUpload(bucket, object, uploadID, parts)
+	if err != nil {
+		return "", traceError(s3ToObjectErr(err, bucket, object, uploadID))
+	}
+	return strings.Trim(md5Sum, "\""), nil
+}
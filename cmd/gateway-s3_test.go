@@ -0,0 +1,238 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+)
+
+// Tests the S3 gateway object layer against a minio server.
+func TestGatewayS3Objects(t *testing.T) {
+	remote := StartTestServer(t, "FS")
+	defer remote.Stop()
+	metaDirs, err := getRandomDisks(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(metaDirs)
+
+	obj, err := newS3Objects(remote.Server.URL, remote.AccessKey, remote.SecretKey, "us-east-1", metaDirs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = obj.MakeBucket("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	if err = obj.MakeBucket("bucket"); !isSameType(errorCause(err), BucketExists{}) {
+		t.Fatalf("Expected BucketExists, got %v", err)
+	}
+	if _, err = obj.GetBucketInfo("missing-bucket"); !isSameType(errorCause(err), BucketNotFound{}) {
+		t.Fatalf("Expected BucketNotFound, got %v", err)
+	}
+	// Buckets are looked up with HEAD, the test server serves the
+	// browser on GET "/" in place of listing the buckets.
+	bucketInfo, err := obj.GetBucketInfo("bucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bucketInfo.Name != "bucket" {
+		t.Fatalf("Unexpected bucket %v", bucketInfo)
+	}
+
+	// Objects and their metadata go through.
+	data := []byte("hello, world")
+	metadata := map[string]string{
+		"content-type":   "text/plain",
+		"X-Amz-Meta-Foo": "bar",
+	}
+	objInfo, err := obj.PutObject("bucket", "dir/object", int64(len(data)), bytes.NewReader(data), metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objInfo.Size != int64(len(data)) || objInfo.ContentType != "text/plain" || objInfo.UserDefined["X-Amz-Meta-Foo"] != "bar" {
+		t.Fatalf("Unexpected object info %v", objInfo)
+	}
+	var buf bytes.Buffer
+	if err = obj.GetObject("bucket", "dir/object", 7, 5, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "world" {
+		t.Fatalf("Expected \"world\", got %q", buf.String())
+	}
+	if _, err = obj.GetObjectInfo("bucket", "missing"); !isSameType(errorCause(err), ObjectNotFound{}) {
+		t.Fatalf("Expected ObjectNotFound, got %v", err)
+	}
+
+	result, err := obj.ListObjects("bucket", "", "", "/", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Prefixes) != 1 || result.Prefixes[0] != "dir/" {
+		t.Fatalf("Unexpected prefixes %v", result.Prefixes)
+	}
+	result, err = obj.ListObjects("bucket", "dir/", "", "", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Objects) != 1 || result.Objects[0].MD5Sum != objInfo.MD5Sum {
+		t.Fatalf("Unexpected objects %v", result.Objects)
+	}
+
+	// Multipart uploads.
+	uploadID, err := obj.NewMultipartUpload("bucket", "multipart", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md5Sum, err := obj.PutObjectPart("bucket", "multipart", uploadID, 1, int64(len(data)), bytes.NewReader(data), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts, err := obj.ListObjectParts("bucket", "multipart", uploadID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts.Parts) != 1 || parts.Parts[0].ETag != md5Sum {
+		t.Fatalf("Unexpected parts %v", parts.Parts)
+	}
+	uploads, err := obj.ListMultipartUploads("bucket", "", "", "", "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads.Uploads) != 1 || uploads.Uploads[0].UploadID != uploadID {
+		t.Fatalf("Unexpected uploads %v", uploads.Uploads)
+	}
+	if _, err = obj.CompleteMultipartUpload("bucket", "multipart", uploadID, []completePart{{1, md5Sum}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = obj.AbortMultipartUpload("bucket", "multipart", uploadID); !isSameType(errorCause(err), InvalidUploadID{}) {
+		t.Fatalf("Expected InvalidUploadID, got %v", err)
+	}
+
+	// The configuration of the gateway is kept locally.
+	if _, err = obj.PutObject(minioMetaBucket, "config", int64(len(data)), bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = obj.GetObjectInfo(minioMetaBucket, "config"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = remote.Obj.GetObjectInfo(minioMetaBucket, "config"); err == nil {
+		t.Fatal("Gateway configuration should not be sent to the remote service")
+	}
+
+	if err = obj.DeleteBucket("bucket"); !isSameType(errorCause(err), BucketNotEmpty{}) {
+		t.Fatalf("Expected BucketNotEmpty, got %v", err)
+	}
+	for _, object := range []string{"dir/object", "multipart"} {
+		if err = obj.DeleteObject("bucket", object); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = obj.DeleteBucket("bucket"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Tests uploads of unknown size from the browser through the gateway.
+func TestGatewayS3WebUpload(t *testing.T) {
+	remote := StartTestServer(t, "FS")
+	defer remote.Stop()
+	metaDirs, err := getRandomDisks(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(metaDirs)
+
+	// Uploads reaching the remote service are recorded, FS objects
+	// uploaded without metadata have no ETag to tell them apart.
+	var mu sync.Mutex
+	parts := make(map[string]int)
+	withoutMD5 := make(map[string]bool)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			object := path.Base(r.URL.Path)
+			mu.Lock()
+			if r.URL.Query().Get("partNumber") != "" {
+				parts[object]++
+			}
+			if r.Header.Get("Content-Md5") == "" {
+				withoutMD5[object] = true
+			}
+			mu.Unlock()
+		}
+		remote.Server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+
+	obj, err := newS3Objects(proxy.URL, remote.AccessKey, remote.SecretKey, "us-east-1", metaDirs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = obj.MakeBucket("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	webRouter := initTestWebRPCEndPoint(obj)
+	authorization, err := getWebRPCToken(webRouter, remote.AccessKey, remote.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		object string
+		size   int
+		parts  int
+	}{
+		{"small", 1024, 0},
+		{"empty", 0, 0},
+		{"large", gatewayPartSize + 1, 2},
+	}
+	for i, testCase := range testCases {
+		content := bytes.Repeat([]byte("a"), testCase.size)
+		req, err := http.NewRequest("PUT", "/minio/upload/bucket/"+testCase.object, bytes.NewReader(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+authorization)
+		rec := httptest.NewRecorder()
+		webRouter.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Test %d: expected status 200, got %d", i+1, rec.Code)
+		}
+
+		objInfo, err := remote.Obj.GetObjectInfo("bucket", testCase.object)
+		if err != nil {
+			t.Fatalf("Test %d: %s", i+1, err)
+		}
+		if objInfo.Size != int64(testCase.size) {
+			t.Fatalf("Test %d: expected size %d, got %d", i+1, testCase.size, objInfo.Size)
+		}
+		mu.Lock()
+		partsSent, md5Sent := parts[testCase.object], !withoutMD5[testCase.object]
+		mu.Unlock()
+		if partsSent != testCase.parts {
+			t.Fatalf("Test %d: expected %d parts uploaded, got %d", i+1, testCase.parts, partsSent)
+		}
+		if !md5Sent {
+			t.Fatalf("Test %d: expected the md5sum of every upload to be sent", i+1)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var gencertFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "host",
+		Value: "localhost,127.0.0.1",
+		Usage: "Comma separated list of hostnames and IPs the certificate is valid for.",
+	},
+	cli.DurationFlag{
+		Name:  "duration",
+		Value: 365 * 24 * time.Hour,
+		Usage: "Duration for which the certificate is valid.",
+	},
+	cli.BoolFlag{
+		Name:  "force",
+		Usage: "Overwrite existing certificate and private key.",
+	},
+}
+
+var gencertCmd = cli.Command{
+	Name:   "gencert",
+	Usage:  "Generate a self-signed certificate for development.",
+	Action: mainGencert,
+	Flags:  append(gencertFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+   minio {{.Name}} - {{.Usage}}
+
+USAGE:
+   minio {{.Name}} [FLAGS]
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+   1. Generate a certificate for localhost.
+      $ minio {{.Name}}
+
+   2. Generate a certificate for a server reachable at minio.example.com and 192.168.1.11.
+      $ minio {{.Name}} --host minio.example.com,192.168.1.11
+`,
+}
+
+// errCertExists - certificate or private key already present in the certs directory.
+var errCertExists = errors.New("Certificate or private key already exists, use --force to overwrite.")
+
+// generateSelfSignedCert - writes a new self-signed certificate and its
+// private key valid for all the hosts to certFile and keyFile.
+func generateSelfSignedCert(hosts []string, validFor time.Duration, certFile, keyFile string) error {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		return err
+	}
+
+	notBefore := time.Now().UTC()
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Organization: []string{"Minio Self-Signed Certificate"},
+		},
+		NotBefore: notBefore,
+		NotAfter:  notBefore.Add(validFor),
+
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		// Self-signed certificates are their own CA, clients
+		// may trust them by adding them to their CAs.
+		IsCA: true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	if len(template.DNSNames) > 0 {
+		template.Subject.CommonName = template.DNSNames[0]
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	if err != nil {
+		return err
+	}
+
+	certOut, err := os.OpenFile(certFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer certOut.Close()
+	if err = pem.Encode(certOut, &pem.Block{Type: "CERTIFICATE", Bytes: derBytes}); err != nil {
+		return err
+	}
+
+	keyOut, err := os.OpenFile(keyFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer keyOut.Close()
+	return pem.Encode(keyOut, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)})
+}
+
+// main entry point for gencert command.
+func mainGencert(ctx *cli.Context) {
+	if len(ctx.Args()) != 0 {
+		cli.ShowCommandHelpAndExit(ctx, "gencert", 1)
+	}
+
+	var hosts []string
+	for _, host := range strings.Split(ctx.String("host"), ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		fatalIf(errInvalidArgument, "At least one host is required.")
+	}
+
+	err := createCertsPath()
+	fatalIf(err, "Unable to create \"certs\" directory.")
+
+	certFile, keyFile := mustGetCertFile(), mustGetKeyFile()
+	if !ctx.Bool("force") && (isCertFileExists() || isKeyFileExists()) {
+		fatalIf(errCertExists, "Unable to generate certificate in %s.", filepath.Dir(certFile))
+	}
+
+	err = generateSelfSignedCert(hosts, ctx.Duration("duration"), certFile, keyFile)
+	fatalIf(err, "Unable to generate certificate.")
+
+	console.Println("Certificate: " + certFile)
+	console.Println("Private key: " + keyFile)
+}
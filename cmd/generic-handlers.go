@@ -295,7 +295,6 @@ func ignoreNotImplementedObjectResources(req *http.Request) bool {
 
 // List of not implemented bucket queries
 var notimplementedBucketResourceNames = map[string]bool{
-	"acl":            true,
 	"cors":           true,
 	"lifecycle":      true,
 	"logging":        true,
@@ -310,6 +309,5 @@ var notimplementedBucketResourceNames = map[string]bool{
 // List of not implemented object queries
 var notimplementedObjectResourceNames = map[string]bool{
 	"torrent": true,
-	"acl":     true,
 	"policy":  true,
 }
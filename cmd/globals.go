@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"crypto/x509"
 	"time"
 
 	"os"
@@ -32,9 +33,10 @@ const (
 
 // minio configuration related constants.
 const (
-	globalMinioConfigVersion = "7"
+	globalMinioConfigVersion = "8"
 	globalMinioConfigDir     = ".minio"
 	globalMinioCertsDir      = "certs"
+	globalMinioCertsCADir    = "CAs"
 	globalMinioCertFile      = "public.crt"
 	globalMinioKeyFile       = "private.key"
 	globalMinioConfigFile    = "config.json"
@@ -57,6 +59,32 @@ var (
 	globalMaxCacheSize = uint64(maxCacheSize)
 	// Cache expiry.
 	globalCacheExpiry = objcache.DefaultExpiry
+	// Directory cached blocks evicted from memory are moved to,
+	// blocks are only cached in memory if empty.
+	globalCacheDir = ""
+	// Maximum size of the cache directory.
+	globalMaxCacheDiskSize = uint64(maxCacheSize)
+	// Time at which this server was started.
+	globalBootTime = time.Now().UTC()
+	// Set to true if the server is configured with TLS, peers
+	// are then connected to over TLS as well.
+	globalIsSSL = false
+	// Root CAs used to verify peers, includes the system CAs
+	// and all the CAs found in the certs CA directory.
+	globalRootCAs *x509.CertPool
+	// Set to true if new objects with the extensions or the content
+	// types below are compressed.
+	globalIsCompressionEnabled = false
+	globalCompressExtensions   = []string{".txt", ".log", ".csv", ".json"}
+	globalCompressMimeTypes    = []string{"text/*", "application/json", "application/xml"}
+	// Bit-rot algorithm of new objects in XL mode.
+	globalBitRotAlgo = defaultBitRotAlgo
+	// Set to true if erasure coded files are written with direct I/O,
+	// bypassing the page cache.
+	globalIsDirectIO = false
+	// Directory the object index is saved in, buckets are only
+	// indexed if set.
+	globalIndexDir = ""
 	// Add new variable global values here.
 )
 
@@ -99,6 +99,21 @@ func extractMetadataFromHeader(header http.Header) map[string]string {
 	return metadata
 }
 
+// setBitRotAlgoMetadata - saves the bit-rot algorithm selected in the
+// request header in the metadata of a new object. Returns false for
+// unsupported algorithms.
+func setBitRotAlgoMetadata(r *http.Request, metadata map[string]string) bool {
+	algo := r.Header.Get(bitRotAlgoHeader)
+	if algo == "" {
+		return true
+	}
+	if !isValidBitRotAlgo(algo) {
+		return false
+	}
+	metadata[bitRotAlgoMetaKey] = algo
+	return true
+}
+
 // Extract form fields and file data from a HTTP POST Policy
 func extractPostPolicyFormValues(reader *multipart.Reader) (filePart io.Reader, fileName string, formValues map[string]string, err error) {
 	/// HTML Form values
@@ -125,3 +125,33 @@ func TestExtractMetadataHeaders(t *testing.T) {
 		}
 	}
 }
+
+// Tests the bit-rot algorithm selected in the request header.
+func TestSetBitRotAlgoMetadata(t *testing.T) {
+	testCases := []struct {
+		algo     string
+		metadata map[string]string
+		ok       bool
+	}{
+		// Not selected.
+		{"", map[string]string{}, true},
+		// Supported algorithms.
+		{blake2bAlgo, map[string]string{bitRotAlgoMetaKey: blake2bAlgo}, true},
+		{sha256Algo, map[string]string{bitRotAlgoMetaKey: sha256Algo}, true},
+		// Unsupported algorithm.
+		{"md5", map[string]string{}, false},
+	}
+	for i, testCase := range testCases {
+		r := &http.Request{Header: http.Header{}}
+		if testCase.algo != "" {
+			r.Header.Set(bitRotAlgoHeader, testCase.algo)
+		}
+		metadata := make(map[string]string)
+		if ok := setBitRotAlgoMetadata(r, metadata); ok != testCase.ok {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.ok, ok)
+		}
+		if !reflect.DeepEqual(metadata, testCase.metadata) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.metadata, metadata)
+		}
+	}
+}
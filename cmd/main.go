@@ -120,6 +120,8 @@ func registerApp() *cli.App {
 	registerCommand(versionCmd)
 	registerCommand(updateCmd)
 	registerCommand(controlCmd)
+	registerCommand(gatewayCmd)
+	registerCommand(gencertCmd)
 
 	// Set up app.
 	app := cli.NewApp()
@@ -177,6 +179,10 @@ func Main() {
 		err := initConfig()
 		fatalIf(err, "Unable to initialize minio config.")
 
+		// Load the CAs trusted for TLS connections to the servers.
+		globalRootCAs, err = loadRootCAs(mustGetCertsCAPath())
+		fatalIf(err, "Unable to load CA certificates.")
+
 		// Enable all loggers by now.
 		enableLoggers()
 
@@ -0,0 +1,158 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// Wait before retrying to take a file lock which failed with an
+	// error, doubled on every retry up to fileLockMaxRetryWait.
+	fileLockRetryWait    = 10 * time.Millisecond
+	fileLockMaxRetryWait = time.Second
+)
+
+// fileRWLocker - read-write lock which is also held as an advisory lock
+// on a file, so that servers sharing the lock directory exclude each
+// other. Goroutines of this server are serialized by the in-process
+// mutex first, all the readers of this server share one file lock.
+type fileRWLocker struct {
+	mu       sync.RWMutex
+	lockPath string
+
+	// Guards readers and file when read locked.
+	fileMu  sync.Mutex
+	readers int
+	file    *os.File
+}
+
+// newFileRWLocker - returns a lock on the file under lockDir named
+// after the hash of the locked resource.
+func newFileRWLocker(lockDir, volume, path string) *fileRWLocker {
+	sum := sha256.Sum256([]byte(pathJoin(volume, path)))
+	return &fileRWLocker{
+		lockPath: filepath.Join(lockDir, hex.EncodeToString(sum[:])),
+	}
+}
+
+// Lock - locks for writes.
+func (l *fileRWLocker) Lock() {
+	l.mu.Lock()
+	l.file = l.acquireFileRetry(true)
+}
+
+// Unlock - unlocks a lock held for writes.
+func (l *fileRWLocker) Unlock() {
+	l.releaseFile(l.file)
+	l.file = nil
+	l.mu.Unlock()
+}
+
+// RLock - locks for reads.
+func (l *fileRWLocker) RLock() {
+	l.mu.RLock()
+	l.fileMu.Lock()
+	if l.readers == 0 {
+		l.file = l.acquireFileRetry(false)
+	}
+	l.readers++
+	l.fileMu.Unlock()
+}
+
+// RUnlock - unlocks a lock held for reads.
+func (l *fileRWLocker) RUnlock() {
+	l.fileMu.Lock()
+	l.readers--
+	if l.readers == 0 {
+		l.releaseFile(l.file)
+		l.file = nil
+	}
+	l.fileMu.Unlock()
+	l.mu.RUnlock()
+}
+
+// acquireFileRetry - acquires the lock file, retrying on errors until
+// it succeeds so that the lock is never held by this server only.
+func (l *fileRWLocker) acquireFileRetry(exclusive bool) *os.File {
+	wait := fileLockRetryWait
+	for {
+		file, err := l.acquireFile(exclusive)
+		if err == nil {
+			return file
+		}
+		// Log only the first error and the ones at the longest wait.
+		if wait == fileLockRetryWait || wait == fileLockMaxRetryWait {
+			errorIf(err, "Unable to lock %s, retrying.", l.lockPath)
+		}
+		time.Sleep(wait)
+		if wait *= 2; wait > fileLockMaxRetryWait {
+			wait = fileLockMaxRetryWait
+		}
+	}
+}
+
+// acquireFile - opens and locks the lock file. Lock files are removed by
+// their last holder, the lock is taken again if the file locked is no
+// longer the one in place.
+func (l *fileRWLocker) acquireFile(exclusive bool) (*os.File, error) {
+	for {
+		file, err := os.OpenFile(l.lockPath, os.O_RDWR|os.O_CREATE, 0666)
+		if err != nil {
+			return nil, err
+		}
+		if err = lockFile(file, exclusive); err != nil {
+			file.Close()
+			return nil, err
+		}
+		lockedFi, err := file.Stat()
+		if err != nil {
+			unlockFile(file)
+			file.Close()
+			return nil, err
+		}
+		fi, err := os.Stat(l.lockPath)
+		if err == nil && os.SameFile(lockedFi, fi) {
+			return file, nil
+		}
+		unlockFile(file)
+		file.Close()
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+}
+
+// releaseFile - unlocks and closes the lock file, the file is removed
+// if no other server holds it.
+func (l *fileRWLocker) releaseFile(file *os.File) {
+	if file == nil {
+		return
+	}
+	errorIf(unlockFile(file), "Unable to unlock %s.", l.lockPath)
+	if tryLockFile(file) == nil {
+		// Servers waiting on the removed file lock the new one instead.
+		os.Remove(l.lockPath)
+		unlockFile(file)
+	}
+	file.Close()
+}
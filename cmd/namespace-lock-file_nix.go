@@ -0,0 +1,53 @@
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile - locks the file, blocks until the lock is acquired.
+func lockFile(file *os.File, exclusive bool) error {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	return flock(file, how)
+}
+
+// tryLockFile - locks the file exclusively, fails if it is locked.
+func tryLockFile(file *os.File) error {
+	return flock(file, syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+// unlockFile - unlocks the file.
+func unlockFile(file *os.File) error {
+	return flock(file, syscall.LOCK_UN)
+}
+
+func flock(file *os.File, how int) error {
+	for {
+		err := syscall.Flock(int(file.Fd()), how)
+		// Retry if interrupted by a signal.
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
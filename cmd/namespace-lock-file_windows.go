@@ -0,0 +1,70 @@
+// +build windows
+
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modkernel32      = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = modkernel32.NewProc("LockFileEx")
+	procUnlockFileEx = modkernel32.NewProc("UnlockFileEx")
+)
+
+const (
+	lockfileFailImmediately = 0x1
+	lockfileExclusiveLock   = 0x2
+)
+
+// lockFile - locks the file, blocks until the lock is acquired.
+func lockFile(file *os.File, exclusive bool) error {
+	var flags uint32
+	if exclusive {
+		flags = lockfileExclusiveLock
+	}
+	return lockFileEx(file, flags)
+}
+
+// tryLockFile - locks the file exclusively, fails if it is locked.
+func tryLockFile(file *os.File) error {
+	return lockFileEx(file, lockfileExclusiveLock|lockfileFailImmediately)
+}
+
+// unlockFile - unlocks the file.
+func unlockFile(file *os.File) error {
+	overlapped := new(syscall.Overlapped)
+	r1, _, err := procUnlockFileEx.Call(file.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(overlapped)))
+	if r1 == 0 {
+		return err
+	}
+	return nil
+}
+
+// Locks the first byte of the file, which is enough for advisory locks.
+func lockFileEx(file *os.File, flags uint32) error {
+	overlapped := new(syscall.Overlapped)
+	r1, _, err := procLockFileEx.Call(file.Fd(), uintptr(flags), 0, 1, 0, uintptr(unsafe.Pointer(overlapped)))
+	if r1 == 0 {
+		return err
+	}
+	return nil
+}
@@ -49,6 +49,7 @@ func initDsyncNodes(disks []string, port int) error {
 				// Construct a new rpc path for the disk.
 				path:        pathutil.Join(lockRPCPath, disk[idx+1:]),
 				loginMethod: "Dsync.LoginHandler",
+				secureConn:  globalIsSSL,
 			}))
 
 			if isLocalStorage(disk) && myNode == -1 {
@@ -72,6 +73,18 @@ func initNSLock(isDist bool) {
 	}
 }
 
+// initFileNSLock - initialize name space lock map with locks which are
+// also held on files under lockDir, to exclude other servers sharing
+// the directory.
+func initFileNSLock(lockDir string) error {
+	if err := mkdirAll(lockDir, 0777); err != nil {
+		return err
+	}
+	initNSLock(false)
+	nsMutex.lockDir = lockDir
+	return nil
+}
+
 func (n *nsLockMap) initLockInfoForVolumePath(param nsParam) {
 	n.debugLockMap[param] = newDebugLockInfoPerVolumePath()
 }
@@ -106,7 +119,10 @@ type nsLockMap struct {
 
 	// Indicates whether the locking service is part
 	// of a distributed setup or not.
-	isDist       bool
+	isDist bool
+	// Directory holding the lock files when locks are shared
+	// through a file system, empty otherwise.
+	lockDir      string
 	lockMap      map[nsParam]*nsLock
 	lockMapMutex sync.Mutex
 }
@@ -124,6 +140,9 @@ func (n *nsLockMap) lock(volume, path string, lockOrigin, opsID string, readLock
 				if n.isDist {
 					return dsync.NewDRWMutex(pathutil.Join(volume, path))
 				}
+				if n.lockDir != "" {
+					return newFileRWLocker(n.lockDir, volume, path)
+				}
 				return &sync.RWMutex{}
 			}(),
 			ref: 0,
@@ -17,6 +17,9 @@
 package cmd
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"testing"
@@ -390,3 +393,93 @@ func TestLockStats(t *testing.T) {
 	verifyGlobalLockStats(expectedLockStats, t, 8)
 
 }
+
+// Tests that file locks of different lockers exclude each other, as
+// held by servers sharing the lock directory.
+func TestFileRWLocker(t *testing.T) {
+	lockDir, err := ioutil.TempDir("", "minio-locks-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(lockDir)
+
+	// Each locker stands for another server.
+	reader1 := newFileRWLocker(lockDir, "bucket", "object")
+	reader2 := newFileRWLocker(lockDir, "bucket", "object")
+	writer := newFileRWLocker(lockDir, "bucket", "object")
+
+	// Readers share the lock.
+	reader1.RLock()
+	reader1.RLock()
+	reader2.RLock()
+
+	locked := make(chan struct{})
+	go func() {
+		writer.Lock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		t.Fatal("Write lock acquired while read locked")
+	case <-time.After(100 * time.Millisecond):
+	}
+	reader1.RUnlock()
+	reader1.RUnlock()
+	reader2.RUnlock()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the write lock")
+	}
+
+	// Other resources are not locked.
+	other := newFileRWLocker(lockDir, "bucket", "other")
+	other.Lock()
+	other.Unlock()
+
+	// Lock files are removed once unlocked.
+	writer.Unlock()
+	if _, err = os.Stat(writer.lockPath); !os.IsNotExist(err) {
+		t.Fatalf("Expected lock file to be removed, got %v", err)
+	}
+}
+
+// Tests that file locks are not acquired while the lock file cannot be
+// locked, and are once it can.
+func TestFileRWLockerRetry(t *testing.T) {
+	lockDir, err := ioutil.TempDir("", "minio-locks-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(lockDir)
+
+	// Lock directory missing, lock files cannot be opened.
+	missingDir := filepath.Join(lockDir, "missing")
+	locker := newFileRWLocker(missingDir, "bucket", "object")
+	if _, err = locker.acquireFile(true); err == nil {
+		t.Fatal("Expected an error for a missing lock directory")
+	}
+
+	locked := make(chan struct{})
+	go func() {
+		locker.Lock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		t.Fatal("Lock acquired without the lock file")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if err = os.Mkdir(missingDir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the lock")
+	}
+	if locker.file == nil {
+		t.Fatal("Expected the lock file to be held")
+	}
+	locker.Unlock()
+}
@@ -138,3 +138,10 @@ func (d *naughtyDisk) ReadAll(volume string, path string) (buf []byte, err error
 	}
 	return d.disk.ReadAll(volume, path)
 }
+
+func (d *naughtyDisk) ReadAllBatch(volume string, paths []string) (bufs [][]byte, errs []error, err error) {
+	if err := d.calcError(); err != nil {
+		return nil, nil, err
+	}
+	return d.disk.ReadAllBatch(volume, paths)
+}
@@ -17,7 +17,12 @@
 package cmd
 
 import (
+	"bufio"
+	"crypto/tls"
 	"errors"
+	"io"
+	"net"
+	"net/http"
 	"net/rpc"
 	"sync"
 )
@@ -28,15 +33,50 @@ type RPCClient struct {
 	rpcPrivate *rpc.Client
 	node       string
 	rpcPath    string
+	secureConn bool
 }
 
 // newClient constructs a RPCClient object with node and rpcPath initialized.
 // It _doesn't_ connect to the remote endpoint. See Call method to see when the
-// connect happens.
-func newClient(node, rpcPath string) *RPCClient {
+// connect happens. If secureConn is set the connection is made over TLS.
+func newClient(node, rpcPath string, secureConn bool) *RPCClient {
 	return &RPCClient{
-		node:    node,
-		rpcPath: rpcPath,
+		node:       node,
+		rpcPath:    rpcPath,
+		secureConn: secureConn,
+	}
+}
+
+// dialRPCOverTLS - same as rpc.DialHTTPPath but over a TLS connection,
+// server certificates are verified against globalRootCAs.
+func dialRPCOverTLS(node, rpcPath string) (*rpc.Client, error) {
+	host, _, err := net.SplitHostPort(node)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := tls.Dial("tcp", node, &tls.Config{
+		ServerName: host,
+		RootCAs:    globalRootCAs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	io.WriteString(conn, "CONNECT "+rpcPath+" HTTP/1.0\n\n")
+
+	// Require successful HTTP response before switching to RPC protocol.
+	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: "CONNECT"})
+	if err == nil && resp.Status == "200 Connected to Go RPC" {
+		return rpc.NewClient(conn), nil
+	}
+	if err == nil {
+		err = errors.New("unexpected HTTP response: " + resp.Status)
+	}
+	conn.Close()
+	return nil, &net.OpError{
+		Op:   "dial-http",
+		Net:  "tcp " + node,
+		Addr: nil,
+		Err:  err,
 	}
 }
 
@@ -63,13 +103,19 @@ func (rpcClient *RPCClient) dialRPCClient() (*rpc.Client, error) {
 	if rpcClient.rpcPrivate != nil {
 		return rpcClient.rpcPrivate, nil
 	}
-	rpc, err := rpc.DialHTTPPath("tcp", rpcClient.node, rpcClient.rpcPath)
+	var client *rpc.Client
+	var err error
+	if rpcClient.secureConn {
+		client, err = dialRPCOverTLS(rpcClient.node, rpcClient.rpcPath)
+	} else {
+		client, err = rpc.DialHTTPPath("tcp", rpcClient.node, rpcClient.rpcPath)
+	}
 	if err != nil {
 		return nil, err
-	} else if rpc == nil {
+	} else if client == nil {
 		return nil, errors.New("No valid RPC Client created after dial")
 	}
-	rpcClient.rpcPrivate = rpc
+	rpcClient.rpcPrivate = client
 	return rpcClient.rpcPrivate, nil
 }
 
@@ -0,0 +1,83 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http/httptest"
+	"net/rpc"
+	"testing"
+)
+
+// testArith - RPC service used to test RPC connections.
+type testArith struct{}
+
+// Add - adds the two arguments.
+func (testArith) Add(args [2]int, reply *int) error {
+	*reply = args[0] + args[1]
+	return nil
+}
+
+// Tests RPC calls over TLS verified against custom root CAs.
+func TestRPCClientOverTLS(t *testing.T) {
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName("Arith", testArith{}); err != nil {
+		t.Fatal(err)
+	}
+
+	certsPath, err := ioutil.TempDir("", "minio-rpc-certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(certsPath)
+
+	generateTestCertIn(t, certsPath, "localhost", "127.0.0.1")
+	certs, err := loadX509KeyPairs(certsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(rpcServer)
+	server.TLS = &tls.Config{Certificates: certs}
+	server.StartTLS()
+	defer server.Close()
+	addr := server.Listener.Addr().String()
+
+	// Server certificate is not trusted.
+	client := newClient(addr, rpc.DefaultRPCPath, true)
+	var sum int
+	if err = client.Call("Arith.Add", [2]int{1, 2}, &sum); err == nil {
+		t.Fatal("Expected the call to fail for an untrusted certificate")
+	}
+	client.Close()
+
+	savedRootCAs := globalRootCAs
+	defer func() { globalRootCAs = savedRootCAs }()
+	if globalRootCAs, err = loadRootCAs(certsPath); err != nil {
+		t.Fatal(err)
+	}
+
+	client = newClient(addr, rpc.DefaultRPCPath, true)
+	defer client.Close()
+	if err = client.Call("Arith.Add", [2]int{1, 2}, &sum); err != nil {
+		t.Fatalf("Unable to call over TLS: %s", err)
+	}
+	if sum != 3 {
+		t.Fatalf("Expected 3, got %d", sum)
+	}
+}
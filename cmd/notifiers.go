@@ -18,8 +18,10 @@ package cmd
 
 import (
 	"errors"
+	"regexp"
 	"strings"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/mf-00/minio/pkg/wildcard"
 )
 
@@ -34,6 +36,16 @@ const (
 	queueTypeElastic = "elasticsearch"
 	// Static string indicating queue type 'redis'.
 	queueTypeRedis = "redis"
+	// Static string indicating queue type 'webhook'.
+	queueTypeWebhook = "webhook"
+	// Static string indicating queue type 'kafka'.
+	queueTypeKafka = "kafka"
+	// Static string indicating queue type 'nats'.
+	queueTypeNATS = "nats"
+	// Static string indicating queue type 'postgresql'.
+	queueTypePostgreSQL = "postgresql"
+	// Static string indicating queue type 'mysql'.
+	queueTypeMySQL = "mysql"
 )
 
 // Topic type.
@@ -47,68 +59,64 @@ const (
 
 var errNotifyNotEnabled = errors.New("requested notifier not enabled")
 
+var errInvalidSQLTable = errors.New("Table name of the notifier must be letters, digits and underscores, optionally prefixed by a schema")
+
+// Table names of SQL notifiers are part of the statements and cannot be
+// passed as parameters, only plain identifiers are accepted.
+var sqlTableRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Returns true if the table name is a plain, optionally schema
+// qualified, SQL identifier.
+func isValidSQLTable(table string) bool {
+	return sqlTableRegexp.MatchString(table)
+}
+
 // Notifier represents collection of supported notification queues.
 type notifier struct {
 	AMQP          map[string]amqpNotify          `json:"amqp"`
 	ElasticSearch map[string]elasticSearchNotify `json:"elasticsearch"`
 	Redis         map[string]redisNotify         `json:"redis"`
+	Webhook       map[string]webhookNotify       `json:"webhook"`
+	Kafka         map[string]kafkaNotify         `json:"kafka"`
+	NATS          map[string]natsNotify          `json:"nats"`
+	PostgreSQL    map[string]postgreSQLNotify    `json:"postgresql"`
+	MySQL         map[string]mySQLNotify         `json:"mysql"`
 	// Add new notification queues.
 }
 
-// Returns true if queueArn is for an AMQP queue.
-func isAMQPQueue(sqsArn arnSQS) bool {
-	if sqsArn.Type != queueTypeAMQP {
-		return false
-	}
-	amqpL := serverConfig.GetAMQPNotifyByID(sqsArn.AccountID)
-	if !amqpL.Enable {
-		return false
-	}
-	// Connect to amqp server to validate.
-	amqpC, err := dialAMQP(amqpL)
-	if err != nil {
-		errorIf(err, "Unable to connect to amqp service. %#v", amqpL)
-		return false
-	}
-	defer amqpC.Close()
-	return true
+// notifyTarget - implemented by every type of notification queue target.
+// Each target type is registered in notifyTargets under the queue type
+// used in its ARN 'arn:minio:sqs:<region>:<accountID>:<type>'.
+type notifyTarget interface {
+	// Returns account IDs of all the enabled targets of this type.
+	enabledIDs() []string
+	// Returns true if the target for the account ID is enabled,
+	// properly configured and reachable.
+	isValid(accountID string) bool
+	// Returns a new logger sending events to the target for the account ID.
+	newNotify(accountID string) (*logrus.Logger, error)
 }
 
-// Returns true if queueArn is for an Redis queue.
-func isRedisQueue(sqsArn arnSQS) bool {
-	if sqsArn.Type != queueTypeRedis {
-		return false
-	}
-	rNotify := serverConfig.GetRedisNotifyByID(sqsArn.AccountID)
-	if !rNotify.Enable {
-		return false
-	}
-	// Connect to redis server to validate.
-	rPool, err := dialRedis(rNotify)
-	if err != nil {
-		errorIf(err, "Unable to connect to redis service. %#v", rNotify)
-		return false
-	}
-	defer rPool.Close()
-	return true
+// notifyTargets - all the supported notification queue targets by type,
+// add new notification queues here.
+var notifyTargets = map[string]notifyTarget{
+	queueTypeAMQP:       amqpTarget{},
+	queueTypeElastic:    elasticTarget{},
+	queueTypeRedis:      redisTarget{},
+	queueTypeWebhook:    webhookTarget{},
+	queueTypeKafka:      kafkaTarget{},
+	queueTypeNATS:       natsTarget{},
+	queueTypePostgreSQL: postgreSQLTarget{},
+	queueTypeMySQL:      mySQLTarget{},
 }
 
-// Returns true if queueArn is for an ElasticSearch queue.
-func isElasticQueue(sqsArn arnSQS) bool {
-	if sqsArn.Type != queueTypeElastic {
-		return false
-	}
-	esNotify := serverConfig.GetElasticSearchNotifyByID(sqsArn.AccountID)
-	if !esNotify.Enable {
-		return false
-	}
-	elasticC, err := dialElastic(esNotify)
-	if err != nil {
-		errorIf(err, "Unable to connect to elasticsearch service %#v", esNotify)
+// Returns true if queueArn is for a known and valid queue target.
+func isValidQueueTarget(sqsArn arnSQS) bool {
+	target, ok := notifyTargets[sqsArn.Type]
+	if !ok {
 		return false
 	}
-	defer elasticC.Stop()
-	return true
+	return target.isValid(sqsArn.AccountID)
 }
 
 // Match function matches wild cards in 'pattern' for events.
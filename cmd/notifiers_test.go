@@ -16,7 +16,10 @@
 
 package cmd
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 // Tests for event filter rules.
 func TestFilterMatch(t *testing.T) {
@@ -148,6 +151,37 @@ func TestEventMatch(t *testing.T) {
 			},
 			match: true,
 		},
+		// Valid object accessed events wild card match.
+		{
+			eventName: ObjectAccessedGet,
+			events: []string{
+				"s3:ObjectAccessed:*",
+			},
+			match: true,
+		},
+		{
+			eventName: ObjectAccessedHead,
+			events: []string{
+				"s3:ObjectAccessed:*",
+			},
+			match: true,
+		},
+		// Object accessed events do not match object created events.
+		{
+			eventName: ObjectAccessedGet,
+			events: []string{
+				"s3:ObjectCreated:*",
+			},
+			match: false,
+		},
+		// Valid delete marker event wild card match.
+		{
+			eventName: ObjectRemovedDeleteMarkerCreated,
+			events: []string{
+				"s3:ObjectRemoved:*",
+			},
+			match: true,
+		},
 		// Invalid events fails to match with empty events.
 		{
 			eventName: ObjectRemovedDelete,
@@ -212,3 +246,44 @@ func TestEventMatch(t *testing.T) {
 		}
 	}
 }
+
+// Tests table names of SQL notifiers.
+func TestIsValidSQLTable(t *testing.T) {
+	testCases := []struct {
+		table string
+		valid bool
+	}{
+		{"minio_events", true},
+		{"public.minio_events", true},
+		{"_events2", true},
+		{"", false},
+		{"2events", false},
+		{"a.b.c", false},
+		{"events; DROP TABLE users", false},
+		{"events (key", false},
+		{"`events`", false},
+	}
+	for i, testCase := range testCases {
+		if valid := isValidSQLTable(testCase.table); valid != testCase.valid {
+			t.Errorf("Test %d: Expected \"%t\" for %q, got \"%t\"", i+1, testCase.valid, testCase.table, valid)
+		}
+	}
+}
+
+// Tests that logged SQL notifier configurations hold no credentials.
+func TestSQLNotifyStringRedacted(t *testing.T) {
+	testCases := []string{
+		postgreSQLNotify{Host: "db", Port: "5432", User: "minio", Password: "secret", Database: "events", Table: "t"}.String(),
+		postgreSQLNotify{ConnectionString: "host=db user=minio password=secret", Table: "t"}.String(),
+		mySQLNotify{Host: "db", Port: "3306", User: "minio", Password: "secret", Database: "events", Table: "t"}.String(),
+		mySQLNotify{DsnString: "minio:secret@tcp(db:3306)/events", Table: "t"}.String(),
+	}
+	for i, str := range testCases {
+		if strings.Contains(str, "secret") || strings.Contains(str, "minio") {
+			t.Errorf("Test %d: Expected no credentials, got %s", i+1, str)
+		}
+	}
+	if str := testCases[3]; str != "db:3306/events, table t" {
+		t.Errorf("Expected \"db:3306/events, table t\", got %s", str)
+	}
+}
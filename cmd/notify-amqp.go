@@ -58,6 +58,39 @@ func dialAMQP(amqpL amqpNotify) (amqpConn, error) {
 	return amqpConn{Connection: conn, params: amqpL}, nil
 }
 
+// amqpTarget - notifyTarget implementation for AMQP queues.
+type amqpTarget struct{}
+
+// Returns account IDs of all the enabled AMQP targets.
+func (amqpTarget) enabledIDs() (ids []string) {
+	for accountID, amqpN := range serverConfig.GetAMQP() {
+		if amqpN.Enable {
+			ids = append(ids, accountID)
+		}
+	}
+	return ids
+}
+
+// Returns true if AMQP target is enabled and reachable.
+func (amqpTarget) isValid(accountID string) bool {
+	amqpL := serverConfig.GetAMQPNotifyByID(accountID)
+	if !amqpL.Enable || amqpL.URL == "" {
+		return false
+	}
+	// Connect to amqp server to validate.
+	amqpC, err := dialAMQP(amqpL)
+	if err != nil {
+		errorIf(err, "Unable to connect to amqp service. %#v", amqpL)
+		return false
+	}
+	defer amqpC.Close()
+	return true
+}
+
+func (amqpTarget) newNotify(accountID string) (*logrus.Logger, error) {
+	return newAMQPNotify(accountID)
+}
+
 func newAMQPNotify(accountID string) (*logrus.Logger, error) {
 	amqpL := serverConfig.GetAMQPNotifyByID(accountID)
 
@@ -54,6 +54,38 @@ func dialElastic(esNotify elasticSearchNotify) (*elastic.Client, error) {
 	return client, nil
 }
 
+// elasticTarget - notifyTarget implementation for ElasticSearch queues.
+type elasticTarget struct{}
+
+// Returns account IDs of all the enabled ElasticSearch targets.
+func (elasticTarget) enabledIDs() (ids []string) {
+	for accountID, esNotify := range serverConfig.GetElasticSearch() {
+		if esNotify.Enable {
+			ids = append(ids, accountID)
+		}
+	}
+	return ids
+}
+
+// Returns true if ElasticSearch target is enabled and reachable.
+func (elasticTarget) isValid(accountID string) bool {
+	esNotify := serverConfig.GetElasticSearchNotifyByID(accountID)
+	if !esNotify.Enable || esNotify.URL == "" {
+		return false
+	}
+	elasticC, err := dialElastic(esNotify)
+	if err != nil {
+		errorIf(err, "Unable to connect to elasticsearch service %#v", esNotify)
+		return false
+	}
+	defer elasticC.Stop()
+	return true
+}
+
+func (elasticTarget) newNotify(accountID string) (*logrus.Logger, error) {
+	return newElasticNotify(accountID)
+}
+
 func newElasticNotify(accountID string) (*logrus.Logger, error) {
 	esNotify := serverConfig.GetElasticSearchNotifyByID(accountID)
 
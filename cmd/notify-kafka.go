@@ -0,0 +1,190 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaNotify - represents logrus compatible Kafka hook.
+// All fields represent Kafka configuration details.
+type kafkaNotify struct {
+	Enable  bool     `json:"enable"`
+	Brokers []string `json:"brokers"`
+	Topic   string   `json:"topic"`
+}
+
+// Timeout for connecting to Kafka brokers.
+const kafkaTimeout = 10 * time.Second
+
+var (
+	errKafkaNoBrokers = errors.New("No kafka brokers configured")
+	errKafkaNoTopic   = errors.New("No kafka topic configured")
+)
+
+// kafkaConn - produces events to a Kafka topic. Messages are keyed by
+// the object, so that all events for an object land in the same
+// partition.
+type kafkaConn struct {
+	params kafkaNotify
+	writer *kafka.Writer
+}
+
+// dialKafka - validates that the topic is available on the configured
+// brokers and returns a kafkaConn producing to it, returns error if
+// kafka logger is not enabled.
+func dialKafka(kNotify kafkaNotify) (kafkaConn, error) {
+	if !kNotify.Enable {
+		return kafkaConn{}, errNotifyNotEnabled
+	}
+	if len(kNotify.Brokers) == 0 {
+		return kafkaConn{}, errKafkaNoBrokers
+	}
+	if kNotify.Topic == "" {
+		return kafkaConn{}, errKafkaNoTopic
+	}
+	dialer := &kafka.Dialer{
+		ClientID: "minio",
+		Timeout:  kafkaTimeout,
+	}
+	if err := lookupKafkaTopic(dialer, kNotify); err != nil {
+		return kafkaConn{}, err
+	}
+	writer := kafka.NewWriter(kafka.WriterConfig{
+		Brokers:  kNotify.Brokers,
+		Topic:    kNotify.Topic,
+		Dialer:   dialer,
+		Balancer: &kafka.Hash{},
+		// Every event is sent on its own and acknowledged by the
+		// partition leader before Fire returns.
+		BatchSize:    1,
+		RequiredAcks: 1,
+	})
+	return kafkaConn{params: kNotify, writer: writer}, nil
+}
+
+// lookupKafkaTopic - returns nil if the first configured broker which
+// responds has partitions of the topic.
+func lookupKafkaTopic(dialer *kafka.Dialer, kNotify kafkaNotify) error {
+	var lastErr error
+	for _, broker := range kNotify.Brokers {
+		ctx, cancel := context.WithTimeout(context.Background(), kafkaTimeout)
+		partitions, err := dialer.LookupPartitions(ctx, "tcp", broker, kNotify.Topic)
+		cancel()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if len(partitions) == 0 {
+			return fmt.Errorf("No partitions available for kafka topic %s", kNotify.Topic)
+		}
+		return nil
+	}
+	return lastErr
+}
+
+// Close - closes the connections to the brokers.
+func (k kafkaConn) Close() error {
+	return k.writer.Close()
+}
+
+// kafkaTarget - notifyTarget implementation for Kafka topics.
+type kafkaTarget struct{}
+
+// Returns account IDs of all the enabled Kafka targets.
+func (kafkaTarget) enabledIDs() (ids []string) {
+	for accountID, kNotify := range serverConfig.GetKafka() {
+		if kNotify.Enable {
+			ids = append(ids, accountID)
+		}
+	}
+	return ids
+}
+
+// Returns true if Kafka target is enabled and reachable.
+func (kafkaTarget) isValid(accountID string) bool {
+	kNotify := serverConfig.GetKafkaNotifyByID(accountID)
+	if !kNotify.Enable || len(kNotify.Brokers) == 0 || kNotify.Topic == "" {
+		return false
+	}
+	// Connect to kafka brokers to validate.
+	kafkaC, err := dialKafka(kNotify)
+	if err != nil {
+		errorIf(err, "Unable to connect to kafka service. %#v", kNotify)
+		return false
+	}
+	defer kafkaC.Close()
+	return true
+}
+
+func (kafkaTarget) newNotify(accountID string) (*logrus.Logger, error) {
+	return newKafkaNotify(accountID)
+}
+
+func newKafkaNotify(accountID string) (*logrus.Logger, error) {
+	kNotify := serverConfig.GetKafkaNotifyByID(accountID)
+
+	// Connect to kafka brokers.
+	kafkaC, err := dialKafka(kNotify)
+	if err != nil {
+		return nil, err
+	}
+
+	kafkaLog := logrus.New()
+
+	// Disable writing to console.
+	kafkaLog.Out = ioutil.Discard
+
+	// Add a kafka hook.
+	kafkaLog.Hooks.Add(kafkaC)
+
+	// Set default JSON formatter.
+	kafkaLog.Formatter = new(logrus.JSONFormatter)
+
+	// Successfully enabled Kafka.
+	return kafkaLog, nil
+}
+
+// Fire is called when an event should be sent to the message broker.
+func (k kafkaConn) Fire(entry *logrus.Entry) error {
+	keyStr, ok := entry.Data["Key"].(string)
+	if !ok {
+		return nil
+	}
+	body, err := entry.String()
+	if err != nil {
+		return err
+	}
+	return k.writer.WriteMessages(context.Background(), kafka.Message{
+		Key:   []byte(keyStr),
+		Value: []byte(body),
+	})
+}
+
+// Levels is available logging levels.
+func (k kafkaConn) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.InfoLevel,
+	}
+}
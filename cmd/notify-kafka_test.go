@@ -0,0 +1,75 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net"
+	"testing"
+)
+
+// Tests Kafka target validation of its config and brokers.
+func TestKafkaNotify(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	// Broker closing connections without responding.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Unable to listen:", err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, aerr := l.Accept()
+			if aerr != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	testCases := []struct {
+		kNotify     kafkaNotify
+		expectedErr error
+	}{
+		{kafkaNotify{Brokers: []string{l.Addr().String()}, Topic: "minio"}, errNotifyNotEnabled},
+		{kafkaNotify{Enable: true, Topic: "minio"}, errKafkaNoBrokers},
+		{kafkaNotify{Enable: true, Brokers: []string{l.Addr().String()}}, errKafkaNoTopic},
+		{kafkaNotify{Enable: true, Brokers: []string{l.Addr().String()}, Topic: "minio"}, nil},
+	}
+	for i, testCase := range testCases {
+		_, err = dialKafka(testCase.kNotify)
+		if err == nil {
+			t.Fatalf("Test %d: Expected an error", i+1)
+		}
+		if testCase.expectedErr != nil && err != testCase.expectedErr {
+			t.Fatalf("Test %d: Expected %v, got %v", i+1, testCase.expectedErr, err)
+		}
+	}
+
+	serverConfig.SetKafkaNotifyByID("1", kafkaNotify{Enable: true, Brokers: []string{l.Addr().String()}, Topic: "minio"})
+	if (kafkaTarget{}).isValid("1") {
+		t.Fatal("Kafka target without reachable brokers should not be valid")
+	}
+	if _, err = newKafkaNotify("1"); err == nil {
+		t.Fatal("Expected an error for unreachable brokers")
+	}
+}
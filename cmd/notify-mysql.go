@@ -0,0 +1,224 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/go-sql-driver/mysql"
+)
+
+// mySQLNotify - represents logrus compatible MySQL hook, every object is
+// a row in the table keyed by its bucket and object name and holding the
+// latest event as JSON.
+type mySQLNotify struct {
+	Enable bool `json:"enable"`
+
+	// DSN string overrides all the other connection
+	// parameters below when set.
+	DsnString string `json:"dsnString"`
+	Table     string `json:"table"`
+
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	Database string `json:"database"`
+}
+
+const (
+	// Creates the notification table if it does not exist, key length is
+	// limited so that the primary key fits in an InnoDB index.
+	mysqlCreateTable = "CREATE TABLE IF NOT EXISTS %s (" +
+		"key_name VARCHAR(768) CHARACTER SET utf8 COLLATE utf8_bin NOT NULL, " +
+		"value JSON, " +
+		"PRIMARY KEY (key_name));"
+	// Inserts or replaces the event for a key.
+	mysqlUpsertRow = "INSERT INTO %s (key_name, value) VALUES (?, ?) " +
+		"ON DUPLICATE KEY UPDATE value=VALUES(value);"
+	// Deletes the row for a key.
+	mysqlDeleteRow = "DELETE FROM %s WHERE key_name = ?;"
+)
+
+type mySQLConn struct {
+	*sql.DB
+	params     mySQLNotify
+	upsertStmt *sql.Stmt
+	deleteStmt *sql.Stmt
+}
+
+// Returns the DSN for MySQL, built from the individual parameters when
+// no DSN is configured.
+func (msqlN mySQLNotify) dsn() string {
+	if msqlN.DsnString != "" {
+		return msqlN.DsnString
+	}
+	config := mysql.Config{
+		User:   msqlN.User,
+		Passwd: msqlN.Password,
+		Net:    "tcp",
+		Addr:   net.JoinHostPort(msqlN.Host, msqlN.Port),
+		DBName: msqlN.Database,
+	}
+	return config.FormatDSN()
+}
+
+// Returns address and database of the connection for logging, the user
+// and password are never logged.
+func (msqlN mySQLNotify) String() string {
+	addr, database := net.JoinHostPort(msqlN.Host, msqlN.Port), msqlN.Database
+	if msqlN.DsnString != "" {
+		config, err := mysql.ParseDSN(msqlN.DsnString)
+		if err != nil {
+			return fmt.Sprintf("invalid DSN, table %s", msqlN.Table)
+		}
+		addr, database = config.Addr, config.DBName
+	}
+	return fmt.Sprintf("%s/%s, table %s", addr, database, msqlN.Table)
+}
+
+// dialMySQL - connects to the database, creates the notification table
+// if needed and prepares the statements used for notifications. Returns
+// error if MySQL logger is not enabled.
+func dialMySQL(msqlN mySQLNotify) (mySQLConn, error) {
+	if !msqlN.Enable {
+		return mySQLConn{}, errNotifyNotEnabled
+	}
+	if !isValidSQLTable(msqlN.Table) {
+		return mySQLConn{}, errInvalidSQLTable
+	}
+	db, err := sql.Open("mysql", msqlN.dsn())
+	if err != nil {
+		return mySQLConn{}, err
+	}
+	// sql.Open does not connect, verify that the database is reachable.
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return mySQLConn{}, err
+	}
+	if _, err = db.Exec(fmt.Sprintf(mysqlCreateTable, msqlN.Table)); err != nil {
+		db.Close()
+		return mySQLConn{}, err
+	}
+	upsertStmt, err := db.Prepare(fmt.Sprintf(mysqlUpsertRow, msqlN.Table))
+	if err != nil {
+		db.Close()
+		return mySQLConn{}, err
+	}
+	deleteStmt, err := db.Prepare(fmt.Sprintf(mysqlDeleteRow, msqlN.Table))
+	if err != nil {
+		db.Close()
+		return mySQLConn{}, err
+	}
+	return mySQLConn{db, msqlN, upsertStmt, deleteStmt}, nil
+}
+
+// mySQLTarget - notifyTarget implementation for MySQL tables.
+type mySQLTarget struct{}
+
+// Returns account IDs of all the enabled MySQL targets.
+func (mySQLTarget) enabledIDs() (ids []string) {
+	for accountID, msqlN := range serverConfig.GetMySQL() {
+		if msqlN.Enable {
+			ids = append(ids, accountID)
+		}
+	}
+	return ids
+}
+
+// Returns true if MySQL target is enabled and reachable.
+func (mySQLTarget) isValid(accountID string) bool {
+	msqlN := serverConfig.GetMySQLNotifyByID(accountID)
+	if !msqlN.Enable || msqlN.Table == "" {
+		return false
+	}
+	// Connect to mysql server to validate.
+	msqlC, err := dialMySQL(msqlN)
+	if err != nil {
+		errorIf(err, "Unable to connect to mysql service %s.", msqlN)
+		return false
+	}
+	defer msqlC.Close()
+	return true
+}
+
+func (mySQLTarget) newNotify(accountID string) (*logrus.Logger, error) {
+	return newMySQLNotify(accountID)
+}
+
+func newMySQLNotify(accountID string) (*logrus.Logger, error) {
+	msqlN := serverConfig.GetMySQLNotifyByID(accountID)
+
+	// Connect to mysql server.
+	msqlC, err := dialMySQL(msqlN)
+	if err != nil {
+		return nil, err
+	}
+
+	mysqlLog := logrus.New()
+
+	// Disable writing to console.
+	mysqlLog.Out = ioutil.Discard
+
+	// Add a mysql hook.
+	mysqlLog.Hooks.Add(msqlC)
+
+	// Set default JSON formatter.
+	mysqlLog.Formatter = new(logrus.JSONFormatter)
+
+	// Successfully enabled MySQL.
+	return mysqlLog, nil
+}
+
+// Fire is called when an event should be sent to the database.
+func (msqlC mySQLConn) Fire(entry *logrus.Entry) error {
+	// Fetch event type upon reflecting on its original type.
+	entryStr, ok := entry.Data["EventType"].(string)
+	if !ok {
+		return nil
+	}
+	keyStr, ok := entry.Data["Key"].(string)
+	if !ok {
+		return nil
+	}
+
+	// Match the event if its a delete request, delete the row.
+	if eventMatch(entryStr, []string{"s3:ObjectRemoved:*"}) {
+		_, err := msqlC.deleteStmt.Exec(keyStr)
+		return err
+	} // else insert or update the row with the new event.
+	value, err := json.Marshal(map[string]interface{}{
+		"Records": entry.Data["Records"],
+	})
+	if err != nil {
+		return err
+	}
+	_, err = msqlC.upsertStmt.Exec(keyStr, value)
+	return err
+}
+
+// Levels is available logging levels.
+func (msqlC mySQLConn) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.InfoLevel,
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/nats-io/nats.go"
+)
+
+// natsNotify - represents logrus compatible NATS hook.
+// All fields represent NATS configuration details.
+type natsNotify struct {
+	Enable   bool   `json:"enable"`
+	Address  string `json:"address"`
+	Subject  string `json:"subject"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+	Secure   bool   `json:"secure"`
+}
+
+// Timeout for connecting to the NATS server and for publishes to be
+// acknowledged.
+const natsTimeout = 10 * time.Second
+
+type natsConn struct {
+	params natsNotify
+	*nats.Conn
+}
+
+// dialNATS - dials and returns a natsConn instance, for sending
+// notifications. Returns error if NATS logger is not enabled.
+func dialNATS(natsN natsNotify) (natsConn, error) {
+	if !natsN.Enable {
+		return natsConn{}, errNotifyNotEnabled
+	}
+	opts := []nats.Option{
+		nats.Name("minio"),
+		nats.Timeout(natsTimeout),
+	}
+	if natsN.Username != "" {
+		opts = append(opts, nats.UserInfo(natsN.Username, natsN.Password))
+	}
+	if natsN.Token != "" {
+		opts = append(opts, nats.Token(natsN.Token))
+	}
+	if natsN.Secure {
+		opts = append(opts, nats.Secure(&tls.Config{RootCAs: globalRootCAs}))
+	}
+	conn, err := nats.Connect(natsN.Address, opts...)
+	if err != nil {
+		return natsConn{}, err
+	}
+	return natsConn{Conn: conn, params: natsN}, nil
+}
+
+// natsTarget - notifyTarget implementation for NATS subjects.
+type natsTarget struct{}
+
+// Returns account IDs of all the enabled NATS targets.
+func (natsTarget) enabledIDs() (ids []string) {
+	for accountID, natsN := range serverConfig.GetNATS() {
+		if natsN.Enable {
+			ids = append(ids, accountID)
+		}
+	}
+	return ids
+}
+
+// Returns true if NATS target is enabled and reachable.
+func (natsTarget) isValid(accountID string) bool {
+	natsN := serverConfig.GetNATSNotifyByID(accountID)
+	if !natsN.Enable || natsN.Address == "" || natsN.Subject == "" {
+		return false
+	}
+	// Connect to nats server to validate.
+	natsC, err := dialNATS(natsN)
+	if err != nil {
+		errorIf(err, "Unable to connect to nats service %s, subject %s.", natsN.Address, natsN.Subject)
+		return false
+	}
+	defer natsC.Close()
+	return true
+}
+
+func (natsTarget) newNotify(accountID string) (*logrus.Logger, error) {
+	return newNATSNotify(accountID)
+}
+
+func newNATSNotify(accountID string) (*logrus.Logger, error) {
+	natsN := serverConfig.GetNATSNotifyByID(accountID)
+
+	// Connect to nats server.
+	natsC, err := dialNATS(natsN)
+	if err != nil {
+		return nil, err
+	}
+
+	natsLog := logrus.New()
+
+	// Disable writing to console.
+	natsLog.Out = ioutil.Discard
+
+	// Add a nats hook.
+	natsLog.Hooks.Add(natsC)
+
+	// Set default JSON formatter.
+	natsLog.Formatter = new(logrus.JSONFormatter)
+
+	// Successfully enabled NATS.
+	return natsLog, nil
+}
+
+// Fire is called when an event should be sent to the message broker.
+func (n natsConn) Fire(entry *logrus.Entry) error {
+	body, err := entry.String()
+	if err != nil {
+		return err
+	}
+	if err = n.Publish(n.params.Subject, []byte(body)); err != nil {
+		return err
+	}
+	// Wait for the server, so that errors it reports are returned.
+	return n.FlushTimeout(natsTimeout)
+}
+
+// Levels is available logging levels.
+func (n natsConn) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.InfoLevel,
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Starts a NATS server speaking just enough of the protocol to accept
+// connections and publishes, published payloads are sent on pubCh.
+func startTestNATSServer(t *testing.T, pubCh chan<- string) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Unable to listen:", err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestNATSConn(conn, pubCh)
+		}
+	}()
+	return l
+}
+
+func serveTestNATSConn(conn net.Conn, pubCh chan<- string) {
+	defer conn.Close()
+	if _, err := conn.Write([]byte("INFO {\"server_id\":\"test\",\"max_payload\":1048576}\r\n")); err != nil {
+		return
+	}
+	br := bufio.NewReader(conn)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "CONNECT":
+			if strings.Contains(line, "\"user\":\"bad\"") {
+				conn.Write([]byte("-ERR 'Authorization Violation'\r\n"))
+				return
+			}
+		case "PING":
+			conn.Write([]byte("PONG\r\n"))
+		case "PUB":
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			if _, err = io.ReadFull(br, payload); err != nil {
+				return
+			}
+			pubCh <- fields[1] + " " + string(payload[:size])
+		}
+	}
+}
+
+// Tests NATS target publishing events.
+func TestNATSNotify(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	pubCh := make(chan string, 1)
+	l := startTestNATSServer(t, pubCh)
+	defer l.Close()
+
+	// Server rejecting credentials.
+	if _, err = dialNATS(natsNotify{Enable: true, Address: l.Addr().String(), Subject: "minio", Username: "bad"}); err == nil {
+		t.Fatal("Expected an error for rejected credentials")
+	}
+
+	serverConfig.SetNATSNotifyByID("1", natsNotify{Enable: true, Address: l.Addr().String(), Subject: "minio"})
+	if !(natsTarget{}).isValid("1") {
+		t.Fatal("Enabled NATS target should be valid")
+	}
+
+	natsLog, err := newNATSNotify("1")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	natsLog.WithFields(logrus.Fields{
+		"Key":       "bucket/object",
+		"EventType": "s3:ObjectCreated:Put",
+	}).Info()
+
+	select {
+	case msg := <-pubCh:
+		if !strings.HasPrefix(msg, "minio ") || !strings.Contains(msg, "bucket/object") {
+			t.Fatalf("Unexpected message %s", msg)
+		}
+	default:
+		t.Fatal("NATS server did not receive the event")
+	}
+
+	// Unreachable server.
+	l.Close()
+	if _, err = dialNATS(natsNotify{Enable: true, Address: l.Addr().String(), Subject: "minio"}); err == nil {
+		t.Fatal("Expected an error for unreachable server")
+	}
+}
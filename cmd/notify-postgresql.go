@@ -0,0 +1,233 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+
+	// Register postgres driver.
+	_ "github.com/lib/pq"
+)
+
+// postgreSQLNotify - represents logrus compatible PostgreSQL hook, every
+// object is a row in the table keyed by its bucket and object name and
+// holding the latest event as JSON.
+type postgreSQLNotify struct {
+	Enable bool `json:"enable"`
+
+	// Connection string overrides all the other connection
+	// parameters below when set.
+	ConnectionString string `json:"connectionString"`
+	Table            string `json:"table"`
+
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	Database string `json:"database"`
+}
+
+const (
+	// Creates the notification table if it does not exist.
+	pgCreateTable = `CREATE TABLE IF NOT EXISTS %s (
+		key VARCHAR PRIMARY KEY,
+		value JSONB
+	);`
+	// Inserts or replaces the event for a key.
+	pgUpsertRow = `INSERT INTO %s (key, value) VALUES ($1, $2)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value;`
+	// Deletes the row for a key.
+	pgDeleteRow = `DELETE FROM %s WHERE key = $1;`
+)
+
+type pgConn struct {
+	*sql.DB
+	params     postgreSQLNotify
+	upsertStmt *sql.Stmt
+	deleteStmt *sql.Stmt
+}
+
+// Returns the connection string for PostgreSQL, built from the individual
+// parameters when no connection string is configured.
+func (pgN postgreSQLNotify) connString() string {
+	if pgN.ConnectionString != "" {
+		return pgN.ConnectionString
+	}
+	var params []string
+	for _, kv := range [][2]string{
+		{"host", pgN.Host},
+		{"port", pgN.Port},
+		{"user", pgN.User},
+		{"password", pgN.Password},
+		{"dbname", pgN.Database},
+	} {
+		if kv[1] != "" {
+			params = append(params, kv[0]+"="+quotePGConnValue(kv[1]))
+		}
+	}
+	return strings.Join(params, " ")
+}
+
+// Returns host, port and database of the connection for logging, the
+// connection string is never logged as it may hold the password.
+func (pgN postgreSQLNotify) String() string {
+	if pgN.ConnectionString != "" {
+		return fmt.Sprintf("connection string, table %s", pgN.Table)
+	}
+	return fmt.Sprintf("%s/%s, table %s", net.JoinHostPort(pgN.Host, pgN.Port), pgN.Database, pgN.Table)
+}
+
+// Quotes a value in a PostgreSQL key/value connection string.
+func quotePGConnValue(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, `'`, `\'`, -1)
+	return "'" + value + "'"
+}
+
+// dialPostgreSQL - connects to the database, creates the notification
+// table if needed and prepares the statements used for notifications.
+// Returns error if PostgreSQL logger is not enabled.
+func dialPostgreSQL(pgN postgreSQLNotify) (pgConn, error) {
+	if !pgN.Enable {
+		return pgConn{}, errNotifyNotEnabled
+	}
+	if !isValidSQLTable(pgN.Table) {
+		return pgConn{}, errInvalidSQLTable
+	}
+	db, err := sql.Open("postgres", pgN.connString())
+	if err != nil {
+		return pgConn{}, err
+	}
+	// sql.Open does not connect, verify that the database is reachable.
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return pgConn{}, err
+	}
+	if _, err = db.Exec(fmt.Sprintf(pgCreateTable, pgN.Table)); err != nil {
+		db.Close()
+		return pgConn{}, err
+	}
+	upsertStmt, err := db.Prepare(fmt.Sprintf(pgUpsertRow, pgN.Table))
+	if err != nil {
+		db.Close()
+		return pgConn{}, err
+	}
+	deleteStmt, err := db.Prepare(fmt.Sprintf(pgDeleteRow, pgN.Table))
+	if err != nil {
+		db.Close()
+		return pgConn{}, err
+	}
+	return pgConn{db, pgN, upsertStmt, deleteStmt}, nil
+}
+
+// postgreSQLTarget - notifyTarget implementation for PostgreSQL tables.
+type postgreSQLTarget struct{}
+
+// Returns account IDs of all the enabled PostgreSQL targets.
+func (postgreSQLTarget) enabledIDs() (ids []string) {
+	for accountID, pgN := range serverConfig.GetPostgreSQL() {
+		if pgN.Enable {
+			ids = append(ids, accountID)
+		}
+	}
+	return ids
+}
+
+// Returns true if PostgreSQL target is enabled and reachable.
+func (postgreSQLTarget) isValid(accountID string) bool {
+	pgN := serverConfig.GetPostgreSQLNotifyByID(accountID)
+	if !pgN.Enable || pgN.Table == "" {
+		return false
+	}
+	// Connect to postgresql server to validate.
+	pgC, err := dialPostgreSQL(pgN)
+	if err != nil {
+		errorIf(err, "Unable to connect to postgresql service %s.", pgN)
+		return false
+	}
+	defer pgC.Close()
+	return true
+}
+
+func (postgreSQLTarget) newNotify(accountID string) (*logrus.Logger, error) {
+	return newPostgreSQLNotify(accountID)
+}
+
+func newPostgreSQLNotify(accountID string) (*logrus.Logger, error) {
+	pgN := serverConfig.GetPostgreSQLNotifyByID(accountID)
+
+	// Connect to postgresql server.
+	pgC, err := dialPostgreSQL(pgN)
+	if err != nil {
+		return nil, err
+	}
+
+	pgLog := logrus.New()
+
+	// Disable writing to console.
+	pgLog.Out = ioutil.Discard
+
+	// Add a postgresql hook.
+	pgLog.Hooks.Add(pgC)
+
+	// Set default JSON formatter.
+	pgLog.Formatter = new(logrus.JSONFormatter)
+
+	// Successfully enabled PostgreSQL.
+	return pgLog, nil
+}
+
+// Fire is called when an event should be sent to the database.
+func (pgC pgConn) Fire(entry *logrus.Entry) error {
+	// Fetch event type upon reflecting on its original type.
+	entryStr, ok := entry.Data["EventType"].(string)
+	if !ok {
+		return nil
+	}
+	keyStr, ok := entry.Data["Key"].(string)
+	if !ok {
+		return nil
+	}
+
+	// Match the event if its a delete request, delete the row.
+	if eventMatch(entryStr, []string{"s3:ObjectRemoved:*"}) {
+		_, err := pgC.deleteStmt.Exec(keyStr)
+		return err
+	} // else insert or update the row with the new event.
+	value, err := json.Marshal(map[string]interface{}{
+		"Records": entry.Data["Records"],
+	})
+	if err != nil {
+		return err
+	}
+	_, err = pgC.upsertStmt.Exec(keyStr, value)
+	return err
+}
+
+// Levels is available logging levels.
+func (pgC pgConn) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.InfoLevel,
+	}
+}
@@ -0,0 +1,302 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Directory under the config directory holding the event queues of all
+// the notification targets, one sub-directory per target ARN.
+const notifyQueueDir = "notify-queue"
+
+// Backoff between failed delivery attempts, doubled after every failure.
+var (
+	notifyRetryMinBackoff = time.Second
+	notifyRetryMaxBackoff = 5 * time.Minute
+)
+
+// queuedEvent - event persisted in a notification queue.
+type queuedEvent struct {
+	EventType string              `json:"eventType"`
+	Key       string              `json:"key"`
+	Records   []NotificationEvent `json:"records"`
+}
+
+// notifyQueue - durable queue of events for a notification target. Every
+// event is persisted in its own file named by its sequence number and
+// delivered in order by a worker, failed deliveries are retried with
+// exponential backoff. Events are removed only after a successful
+// delivery, so events still queued are replayed after a restart and are
+// delivered at least once. The worker connects to the target when it
+// first delivers an event, unreachable targets are retried like failed
+// deliveries.
+type notifyQueue struct {
+	queueARN  string
+	dir       string
+	newTarget func() (*logrus.Logger, error)
+	target    *logrus.Logger // Connected target, only used by the worker.
+
+	mu       *sync.Mutex
+	next     uint64 // Sequence number of the next event to deliver.
+	last     uint64 // Sequence number of the last queued event.
+	failures int64  // Total number of failed delivery attempts.
+	lastErr  string // Error of the last failed delivery attempt.
+
+	wakeupCh  chan struct{}
+	doneCh    chan struct{}
+	stoppedCh chan struct{}
+}
+
+// NotifyQueueStatus - delivery status of the event queue of a
+// notification target.
+type NotifyQueueStatus struct {
+	QueueARN  string
+	Pending   uint64
+	Failures  int64
+	LastError string
+}
+
+// Returns the file name of the event with the sequence number.
+func queuedEventName(seq uint64) string {
+	return fmt.Sprintf("%020d.json", seq)
+}
+
+// newNotifyQueue - opens the queue of the target under queueDir, events
+// left over from a previous run are picked up for delivery. newTarget
+// connects to the target, it is only called by the delivery worker.
+func newNotifyQueue(queueARN, queueDir string, newTarget func() (*logrus.Logger, error)) (*notifyQueue, error) {
+	dir := filepath.Join(queueDir, url.QueryEscape(queueARN))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &notifyQueue{
+		queueARN:  queueARN,
+		dir:       dir,
+		newTarget: newTarget,
+		mu:        &sync.Mutex{},
+		wakeupCh:  make(chan struct{}, 1),
+		doneCh:    make(chan struct{}),
+		stoppedCh: make(chan struct{}),
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".json") {
+			// Remove partially written events.
+			os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		seq, perr := strconv.ParseUint(strings.TrimSuffix(name, ".json"), 10, 64)
+		if perr != nil {
+			continue
+		}
+		if q.next == 0 || seq < q.next {
+			q.next = seq
+		}
+		if seq > q.last {
+			q.last = seq
+		}
+	}
+	if q.next == 0 {
+		// Empty queue.
+		q.next = q.last + 1
+	}
+	return q, nil
+}
+
+// enqueue - persists the event and wakes up the delivery worker.
+func (q *notifyQueue) enqueue(event queuedEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	seq := q.last + 1
+	name := filepath.Join(q.dir, queuedEventName(seq))
+	// Write to a temporary file first so that the worker never sees
+	// a partially written event.
+	if err = writeSyncedFile(name+".tmp", data); err == nil {
+		err = os.Rename(name+".tmp", name)
+	}
+	if err != nil {
+		q.mu.Unlock()
+		os.Remove(name + ".tmp")
+		return err
+	}
+	q.last = seq
+	q.mu.Unlock()
+
+	select {
+	case q.wakeupCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// writeSyncedFile - writes data to the file and flushes it to disk, so
+// that queued events survive a crash of the host.
+func writeSyncedFile(name string, data []byte) error {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(data); err == nil {
+		err = file.Sync()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// getTarget - returns the target, connecting to it first if needed.
+func (q *notifyQueue) getTarget() (*logrus.Logger, error) {
+	if q.target == nil {
+		target, err := q.newTarget()
+		if err != nil {
+			return nil, err
+		}
+		q.target = target
+	}
+	return q.target, nil
+}
+
+// status - returns the delivery status of the queue.
+func (q *notifyQueue) status() NotifyQueueStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return NotifyQueueStatus{
+		QueueARN:  q.queueARN,
+		Pending:   q.last + 1 - q.next,
+		Failures:  q.failures,
+		LastError: q.lastErr,
+	}
+}
+
+// deliverNext - delivers the oldest queued event to the target and
+// removes it from the queue, returns false if the queue is empty.
+func (q *notifyQueue) deliverNext() (bool, error) {
+	q.mu.Lock()
+	seq, last := q.next, q.last
+	q.mu.Unlock()
+	if seq > last {
+		return false, nil
+	}
+
+	name := filepath.Join(q.dir, queuedEventName(seq))
+	data, err := ioutil.ReadFile(name)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if err == nil {
+		var event queuedEvent
+		if err = json.Unmarshal(data, &event); err != nil {
+			// Event can never be delivered, drop it.
+			errorIf(err, "Dropping corrupted event %s.", name)
+		} else {
+			target, terr := q.getTarget()
+			if terr != nil {
+				return false, terr
+			}
+			entry := target.WithFields(logrus.Fields{
+				"Key":       event.Key,
+				"EventType": event.EventType,
+				"Records":   event.Records,
+			})
+			entry.Time = time.Now().UTC()
+			entry.Level = logrus.InfoLevel
+			// Fire the hooks directly, logging swallows their errors.
+			if err = target.Hooks.Fire(logrus.InfoLevel, entry); err != nil {
+				return false, err
+			}
+		}
+		if err = os.Remove(name); err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+
+	q.mu.Lock()
+	q.next = seq + 1
+	q.mu.Unlock()
+	return true, nil
+}
+
+// run - delivers queued events until the queue is closed.
+func (q *notifyQueue) run() {
+	defer close(q.stoppedCh)
+
+	backoff := notifyRetryMinBackoff
+	for {
+		delivered, err := q.deliverNext()
+		if err != nil {
+			q.mu.Lock()
+			q.failures++
+			q.lastErr = err.Error()
+			q.mu.Unlock()
+			errorIf(err, "Unable to deliver event to %s, retrying in %s.", q.queueARN, backoff)
+
+			select {
+			case <-time.After(backoff):
+			case <-q.doneCh:
+				return
+			}
+			if backoff *= 2; backoff > notifyRetryMaxBackoff {
+				backoff = notifyRetryMaxBackoff
+			}
+			continue
+		}
+		backoff = notifyRetryMinBackoff
+		if delivered {
+			select {
+			case <-q.doneCh:
+				return
+			default:
+			}
+			continue
+		}
+
+		// Queue is empty, wait for new events.
+		select {
+		case <-q.wakeupCh:
+		case <-q.doneCh:
+			return
+		}
+	}
+}
+
+// close - stops the delivery worker, queued events stay on disk.
+func (q *notifyQueue) close() {
+	close(q.doneCh)
+	<-q.stoppedCh
+}
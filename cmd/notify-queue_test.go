@@ -0,0 +1,211 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// testQueueHook - records delivered event keys, failing the first
+// failCount deliveries.
+type testQueueHook struct {
+	mu        *sync.Mutex
+	failCount int
+	keys      []string
+	keyCh     chan string
+}
+
+func (h *testQueueHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.failCount > 0 {
+		h.failCount--
+		return errors.New("target unavailable")
+	}
+	key, _ := entry.Data["Key"].(string)
+	h.keys = append(h.keys, key)
+	h.keyCh <- key
+	return nil
+}
+
+func (h *testQueueHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.InfoLevel}
+}
+
+// Returns a function connecting to a target recording events in the
+// returned hook, the first dialFailCount connections fail.
+func newTestQueueTarget(dialFailCount, failCount int) (func() (*logrus.Logger, error), *testQueueHook) {
+	hook := &testQueueHook{mu: &sync.Mutex{}, failCount: failCount, keyCh: make(chan string, 10)}
+	targetLog := logrus.New()
+	targetLog.Out = ioutil.Discard
+	targetLog.Hooks.Add(hook)
+	newTarget := func() (*logrus.Logger, error) {
+		hook.mu.Lock()
+		defer hook.mu.Unlock()
+		if dialFailCount > 0 {
+			dialFailCount--
+			return nil, errors.New("target unreachable")
+		}
+		return targetLog, nil
+	}
+	return newTarget, hook
+}
+
+// Waits for the hook to receive the keys in order.
+func waitQueueKeys(t *testing.T, hook *testQueueHook, keys ...string) {
+	for _, expected := range keys {
+		select {
+		case key := <-hook.keyCh:
+			if key != expected {
+				t.Fatalf("Expected event %s, got %s", expected, key)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for event %s", expected)
+		}
+	}
+}
+
+// Tests that events are delivered in order and failed deliveries retried.
+func TestNotifyQueueRetry(t *testing.T) {
+	queueDir, err := ioutil.TempDir("", "minio-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(queueDir)
+
+	savedBackoff := notifyRetryMinBackoff
+	notifyRetryMinBackoff = 10 * time.Millisecond
+	defer func() { notifyRetryMinBackoff = savedBackoff }()
+
+	newTarget, hook := newTestQueueTarget(0, 2)
+	queue, err := newNotifyQueue("arn:minio:sqs:us-east-1:1:amqp", queueDir, newTarget)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	for _, key := range []string{"bucket/a", "bucket/b", "bucket/c"} {
+		if err = queue.enqueue(queuedEvent{Key: key, EventType: "s3:ObjectCreated:Put"}); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+	}
+	if status := queue.status(); status.Pending != 3 {
+		t.Fatalf("Expected 3 pending events, got %d", status.Pending)
+	}
+
+	go queue.run()
+	waitQueueKeys(t, hook, "bucket/a", "bucket/b", "bucket/c")
+	queue.close()
+
+	status := queue.status()
+	if status.Pending != 0 {
+		t.Fatalf("Expected no pending events, got %d", status.Pending)
+	}
+	if status.Failures != 2 || status.LastError != "target unavailable" {
+		t.Fatalf("Unexpected queue status %#v", status)
+	}
+}
+
+// Tests that unreachable targets are connected to by the worker, events
+// are queued meanwhile.
+func TestNotifyQueueUnreachableTarget(t *testing.T) {
+	queueDir, err := ioutil.TempDir("", "minio-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(queueDir)
+
+	savedBackoff := notifyRetryMinBackoff
+	notifyRetryMinBackoff = 10 * time.Millisecond
+	defer func() { notifyRetryMinBackoff = savedBackoff }()
+
+	newTarget, hook := newTestQueueTarget(2, 0)
+	queue, err := newNotifyQueue("arn:minio:sqs:us-east-1:1:kafka", queueDir, newTarget)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	go queue.run()
+	for _, key := range []string{"bucket/a", "bucket/b"} {
+		if err = queue.enqueue(queuedEvent{Key: key, EventType: "s3:ObjectCreated:Put"}); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+	}
+	waitQueueKeys(t, hook, "bucket/a", "bucket/b")
+	queue.close()
+
+	status := queue.status()
+	if status.Pending != 0 {
+		t.Fatalf("Expected no pending events, got %d", status.Pending)
+	}
+	if status.Failures != 2 || status.LastError != "target unreachable" {
+		t.Fatalf("Unexpected queue status %#v", status)
+	}
+}
+
+// Tests that queued events are delivered after the queue is reopened.
+func TestNotifyQueueReplay(t *testing.T) {
+	queueDir, err := ioutil.TempDir("", "minio-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(queueDir)
+
+	queueARN := "arn:minio:sqs:us-east-1:1:redis"
+	newTarget, hook := newTestQueueTarget(0, 0)
+	queue, err := newNotifyQueue(queueARN, queueDir, newTarget)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	for _, key := range []string{"bucket/a", "bucket/b"} {
+		if err = queue.enqueue(queuedEvent{Key: key, EventType: "s3:ObjectCreated:Put"}); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+	}
+	// Leave a partially written event behind, it must be ignored.
+	if err = ioutil.WriteFile(filepath.Join(queue.dir, queuedEventName(3)+".tmp"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen the queue as after a restart.
+	queue, err = newNotifyQueue(queueARN, queueDir, newTarget)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if status := queue.status(); status.Pending != 2 {
+		t.Fatalf("Expected 2 pending events, got %d", status.Pending)
+	}
+	if err = queue.enqueue(queuedEvent{Key: "bucket/c", EventType: "s3:ObjectRemoved:Delete"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	go queue.run()
+	waitQueueKeys(t, hook, "bucket/a", "bucket/b", "bucket/c")
+	queue.close()
+
+	entries, err := ioutil.ReadDir(queue.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("Expected empty queue directory, found %d entries", len(entries))
+	}
+}
@@ -81,6 +81,39 @@ func dialRedis(rNotify redisNotify) (*redis.Pool, error) {
 	return rPool, nil
 }
 
+// redisTarget - notifyTarget implementation for Redis queues.
+type redisTarget struct{}
+
+// Returns account IDs of all the enabled Redis targets.
+func (redisTarget) enabledIDs() (ids []string) {
+	for accountID, rNotify := range serverConfig.GetRedis() {
+		if rNotify.Enable {
+			ids = append(ids, accountID)
+		}
+	}
+	return ids
+}
+
+// Returns true if Redis target is enabled and reachable.
+func (redisTarget) isValid(accountID string) bool {
+	rNotify := serverConfig.GetRedisNotifyByID(accountID)
+	if !rNotify.Enable || rNotify.Addr == "" {
+		return false
+	}
+	// Connect to redis server to validate.
+	rPool, err := dialRedis(rNotify)
+	if err != nil {
+		errorIf(err, "Unable to connect to redis service. %#v", rNotify)
+		return false
+	}
+	defer rPool.Close()
+	return true
+}
+
+func (redisTarget) newNotify(accountID string) (*logrus.Logger, error) {
+	return newRedisNotify(accountID)
+}
+
 func newRedisNotify(accountID string) (*logrus.Logger, error) {
 	rNotify := serverConfig.GetRedisNotifyByID(accountID)
 
@@ -0,0 +1,178 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// webhookNotify - represents logrus compatible webhook hook, events
+// are posted as JSON to the configured endpoint.
+type webhookNotify struct {
+	Enable   bool   `json:"enable"`
+	Endpoint string `json:"endpoint"`
+}
+
+type httpConn struct {
+	*http.Client
+	Endpoint string
+}
+
+// Timeout for connecting to and posting events to a webhook endpoint.
+const webhookTimeout = 10 * time.Second
+
+// dialWebhook - validates the webhook endpoint and verifies that it is
+// reachable, returns an httpConn for sending notifications. Returns
+// error if webhook is not enabled.
+func dialWebhook(whNotify webhookNotify) (httpConn, error) {
+	if !whNotify.Enable {
+		return httpConn{}, errNotifyNotEnabled
+	}
+	u, err := url.Parse(whNotify.Endpoint)
+	if err != nil {
+		return httpConn{}, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return httpConn{}, fmt.Errorf("Unsupported webhook endpoint scheme %s", u.Scheme)
+	}
+	host := u.Host
+	if _, _, err = net.SplitHostPort(host); err != nil {
+		if u.Scheme == "https" {
+			host = net.JoinHostPort(host, "443")
+		} else {
+			host = net.JoinHostPort(host, "80")
+		}
+	}
+	// Only verify that the endpoint is reachable, webhook endpoints
+	// need not implement any other method than POST.
+	conn, err := net.DialTimeout("tcp", host, webhookTimeout)
+	if err != nil {
+		return httpConn{}, err
+	}
+	conn.Close()
+
+	return httpConn{
+		Client: &http.Client{
+			Timeout: webhookTimeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{RootCAs: globalRootCAs},
+			},
+		},
+		Endpoint: whNotify.Endpoint,
+	}, nil
+}
+
+// webhookTarget - notifyTarget implementation for webhook endpoints.
+type webhookTarget struct{}
+
+// Returns account IDs of all the enabled webhook targets.
+func (webhookTarget) enabledIDs() (ids []string) {
+	for accountID, whNotify := range serverConfig.GetWebhook() {
+		if whNotify.Enable {
+			ids = append(ids, accountID)
+		}
+	}
+	return ids
+}
+
+// Returns true if webhook target is enabled and reachable.
+func (webhookTarget) isValid(accountID string) bool {
+	whNotify := serverConfig.GetWebhookNotifyByID(accountID)
+	if !whNotify.Enable || whNotify.Endpoint == "" {
+		return false
+	}
+	if _, err := dialWebhook(whNotify); err != nil {
+		errorIf(err, "Unable to connect to webhook endpoint %#v", whNotify)
+		return false
+	}
+	return true
+}
+
+func (webhookTarget) newNotify(accountID string) (*logrus.Logger, error) {
+	return newWebhookNotify(accountID)
+}
+
+func newWebhookNotify(accountID string) (*logrus.Logger, error) {
+	whNotify := serverConfig.GetWebhookNotifyByID(accountID)
+
+	// Validate the webhook endpoint.
+	conn, err := dialWebhook(whNotify)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookLog := logrus.New()
+
+	// Disable writing to console.
+	webhookLog.Out = ioutil.Discard
+
+	// Set default JSON formatter.
+	webhookLog.Formatter = new(logrus.JSONFormatter)
+
+	webhookLog.Hooks.Add(conn)
+
+	// Success, webhook enabled.
+	return webhookLog, nil
+}
+
+// Fire is called when an event should be sent to the webhook endpoint.
+func (n httpConn) Fire(entry *logrus.Entry) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"EventType": entry.Data["EventType"],
+		"Key":       entry.Data["Key"],
+		"Records":   entry.Data["Records"],
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", n.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Any 2xx response is treated as the event being accepted.
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Unable to send event to webhook endpoint %s, %s", n.Endpoint, resp.Status)
+	}
+	return nil
+}
+
+// Levels are Required for logrus hook implementation.
+func (httpConn) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.InfoLevel,
+	}
+}
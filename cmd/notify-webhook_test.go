@@ -0,0 +1,111 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Tests webhook target posting events to an endpoint.
+func TestWebhookNotify(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	eventCh := make(chan map[string]interface{}, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, rerr := ioutil.ReadAll(r.Body)
+		if rerr != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		event := make(map[string]interface{})
+		if rerr = json.Unmarshal(body, &event); rerr != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		eventCh <- event
+	}))
+	defer ts.Close()
+
+	// Disabled target is not valid.
+	serverConfig.SetWebhookNotifyByID("1", webhookNotify{Endpoint: ts.URL})
+	if (webhookTarget{}).isValid("1") {
+		t.Fatal("Disabled webhook target should not be valid")
+	}
+	if _, err = newWebhookNotify("1"); err != errNotifyNotEnabled {
+		t.Fatalf("Expected %s, got %s", errNotifyNotEnabled, err)
+	}
+
+	serverConfig.SetWebhookNotifyByID("1", webhookNotify{Enable: true, Endpoint: ts.URL})
+	if !(webhookTarget{}).isValid("1") {
+		t.Fatal("Enabled webhook target should be valid")
+	}
+	if ids := (webhookTarget{}).enabledIDs(); len(ids) != 1 || ids[0] != "1" {
+		t.Fatalf("Unexpected enabled webhook targets %v", ids)
+	}
+
+	webhookLog, err := newWebhookNotify("1")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	webhookLog.WithFields(logrus.Fields{
+		"Key":       "bucket/object",
+		"EventType": "s3:ObjectCreated:Put",
+		"Records":   []string{"record"},
+	}).Info()
+
+	select {
+	case event := <-eventCh:
+		if event["Key"] != "bucket/object" || event["EventType"] != "s3:ObjectCreated:Put" {
+			t.Fatalf("Unexpected event %v", event)
+		}
+	default:
+		t.Fatal("Webhook endpoint did not receive the event")
+	}
+
+	// Endpoint rejecting events must return an error.
+	conn, err := dialWebhook(webhookNotify{Enable: true, Endpoint: ts.URL + "/fail"})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err = conn.Fire(&logrus.Entry{Data: logrus.Fields{"Key": "bucket/object"}}); err == nil {
+		t.Fatal("Expected an error from failing webhook endpoint")
+	}
+
+	// Unsupported endpoint scheme.
+	if _, err = dialWebhook(webhookNotify{Enable: true, Endpoint: "ftp://localhost"}); err == nil {
+		t.Fatal("Expected an error for unsupported endpoint scheme")
+	}
+}
@@ -455,6 +455,21 @@ func BenchmarkPutObject5MbXL(b *testing.B) {
 	benchmarkPutObject(b, "XL", 5*1024*1024)
 }
 
+// BenchmarkPutObject5MbXLBlake2b - Benchmark XL.PutObject() for object size of 5MB with blake2b checksums.
+func BenchmarkPutObject5MbXLBlake2b(b *testing.B) {
+	benchmarkPutObjectBitRot(b, blake2bAlgo, 5*1024*1024)
+}
+
+// BenchmarkPutObject5MbXLSha256 - Benchmark XL.PutObject() for object size of 5MB with sha256 checksums.
+func BenchmarkPutObject5MbXLSha256(b *testing.B) {
+	benchmarkPutObjectBitRot(b, sha256Algo, 5*1024*1024)
+}
+
+// BenchmarkPutObject5MbXLHighwayHash - Benchmark XL.PutObject() for object size of 5MB with highwayhash checksums.
+func BenchmarkPutObject5MbXLHighwayHash(b *testing.B) {
+	benchmarkPutObjectBitRot(b, highwayHashAlgo, 5*1024*1024)
+}
+
 // BenchmarkPutObject10MbFS - Benchmark FS.PutObject() for object size of 10MB.
 func BenchmarkPutObject10MbFS(b *testing.B) {
 	benchmarkPutObject(b, "FS", 10*1024*1024)
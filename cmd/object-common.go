@@ -20,6 +20,7 @@ import (
 	"net"
 	"strings"
 	"sync"
+	"time"
 )
 
 const (
@@ -31,6 +32,10 @@ const (
 
 	// Buckets meta prefix.
 	bucketMetaPrefix = "buckets"
+
+	// Temporary files older than this are left behind by servers
+	// gone down while sharing the disk, newer ones may be in use.
+	fsSharedTmpExpiry = 24 * time.Hour
 )
 
 // isErrIgnored should we ignore this error?, takes a list of errors which can be ignored.
@@ -54,6 +59,36 @@ func fsHouseKeeping(storageDisk StorageAPI) error {
 	return nil
 }
 
+// House keeping code needed for FS on a disk shared with other servers.
+func fsSharedHouseKeeping(storageDisk StorageAPI) error {
+	entries, err := storageDisk.ListDir(minioMetaBucket, tmpMetaPrefix)
+	if err != nil {
+		if err == errFileNotFound {
+			return nil
+		}
+		return toObjectErr(traceError(err), minioMetaBucket, tmpMetaPrefix)
+	}
+	// Cleanup only expired temp files, all of them are at the top level.
+	for _, entry := range entries {
+		if strings.HasSuffix(entry, slashSeparator) {
+			continue
+		}
+		entryPath := pathJoin(tmpMetaPrefix, entry)
+		fi, err := storageDisk.StatFile(minioMetaBucket, entryPath)
+		if err != nil {
+			// Removed by another server meanwhile.
+			continue
+		}
+		if time.Since(fi.ModTime) < fsSharedTmpExpiry {
+			continue
+		}
+		if err = storageDisk.DeleteFile(minioMetaBucket, entryPath); err != nil && err != errFileNotFound {
+			return toObjectErr(traceError(err), minioMetaBucket, entryPath)
+		}
+	}
+	return nil
+}
+
 // Check if a network path is local to this node.
 func isLocalStorage(networkPath string) bool {
 	if idx := strings.LastIndex(networkPath, ":"); idx != -1 {
@@ -67,39 +102,44 @@ func isLocalStorage(networkPath string) bool {
 		if netAddr == "" {
 			return true
 		}
-		// Resolve host to address to check if the IP is loopback.
-		// If address resolution fails, assume it's a non-local host.
-		addrs, err := net.LookupHost(netAddr)
-		if err != nil {
-			errorIf(err, "Failed to lookup host")
-			return false
+		return isLocalHost(netAddr)
+	}
+	return true
+}
+
+// Check if a host name or address is of this node.
+func isLocalHost(host string) bool {
+	// Resolve host to address to check if the IP is loopback.
+	// If address resolution fails, assume it's a non-local host.
+	addrs, err := net.LookupHost(host)
+	if err != nil {
+		errorIf(err, "Failed to lookup host")
+		return false
+	}
+	for _, addr := range addrs {
+		if ip := net.ParseIP(addr); ip.IsLoopback() {
+			return true
 		}
-		for _, addr := range addrs {
-			if ip := net.ParseIP(addr); ip.IsLoopback() {
+	}
+	iaddrs, err := net.InterfaceAddrs()
+	if err != nil {
+		errorIf(err, "Unable to list interface addresses")
+		return false
+	}
+	for _, addr := range addrs {
+		for _, iaddr := range iaddrs {
+			ip, _, err := net.ParseCIDR(iaddr.String())
+			if err != nil {
+				errorIf(err, "Unable to parse CIDR")
+				return false
+			}
+			if ip.String() == addr {
 				return true
 			}
-		}
-		iaddrs, err := net.InterfaceAddrs()
-		if err != nil {
-			errorIf(err, "Unable to list interface addresses")
-			return false
-		}
-		for _, addr := range addrs {
-			for _, iaddr := range iaddrs {
-				ip, _, err := net.ParseCIDR(iaddr.String())
-				if err != nil {
-					errorIf(err, "Unable to parse CIDR")
-					return false
-				}
-				if ip.String() == addr {
-					return true
-				}
 
-			}
 		}
-		return false
 	}
-	return true
+	return false
 }
 
 // Depending on the disk type network or local, initialize storage API.
@@ -0,0 +1,421 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mf-00/minio/pkg/mimedb"
+	"github.com/mf-00/minio/pkg/wildcard"
+)
+
+const (
+	// Set on compressed objects and on their multipart uploads,
+	// holds the compression format.
+	compressionMetaKey = "X-Minio-Internal-Compression"
+	// Size of a compressed object before compression.
+	actualSizeMetaKey = "X-Minio-Internal-Actual-Size"
+	// Offsets of frames in the compressed data of an object, see
+	// compressIndex.
+	compressIndexMetaKey = "X-Minio-Internal-Compress-Index"
+
+	// Compressed data is a sequence of frames, each frame holds one
+	// block of the object deflated on its own. Frames start with a
+	// header of the frame type, the size of the frame data and the
+	// size of the block, sizes are big endian uint32.
+	compressionFormatV1     = "deflate-frames/v1"
+	compressBlockSizeV1     = 1024 * 1024 // 1MiB.
+	compressFrameHeaderSize = 9
+	// Frames indexed are at least 16MiB of the object apart.
+	compressIndexInterval = 16 * compressBlockSizeV1
+
+	// Frame types, blocks which do not shrink are stored as is.
+	compressFrameStored  = 0
+	compressFrameDeflate = 1
+)
+
+// errCompressedDataCorrupted - compressed data cannot be decompressed.
+var errCompressedDataCorrupted = errors.New("Compressed object data is corrupted.")
+
+// isCompressible - returns true if the new object is to be compressed,
+// based on its extension and content type.
+func isCompressible(bucket, object string, metadata map[string]string) bool {
+	if !globalIsCompressionEnabled || bucket == minioMetaBucket {
+		return false
+	}
+	// Objects already compressed by the client do not shrink further.
+	if metadata["content-encoding"] != "" {
+		return false
+	}
+	objectExt := strings.ToLower(path.Ext(object))
+	for _, ext := range globalCompressExtensions {
+		if objectExt == ext {
+			return true
+		}
+	}
+	contentType := metadata["content-type"]
+	if contentType == "" {
+		if content, ok := mimedb.DB[strings.TrimPrefix(objectExt, ".")]; ok {
+			contentType = content.ContentType
+		}
+	}
+	// Ignore parameters like the charset.
+	if idx := strings.Index(contentType, ";"); idx != -1 {
+		contentType = contentType[:idx]
+	}
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "" {
+		return false
+	}
+	for _, pattern := range globalCompressMimeTypes {
+		if wildcard.Match(pattern, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// setCompressionMetadata - marks the metadata of a new object or
+// multipart upload as compressed if the object is compressible, returns
+// true if it is. Compression metadata copied from another object is
+// removed.
+func setCompressionMetadata(bucket, object string, metadata map[string]string) bool {
+	delete(metadata, compressionMetaKey)
+	delete(metadata, actualSizeMetaKey)
+	delete(metadata, compressIndexMetaKey)
+	if !isCompressible(bucket, object, metadata) {
+		return false
+	}
+	metadata[compressionMetaKey] = compressionFormatV1
+	return true
+}
+
+// isCompressed - returns true if the object, or the multipart upload,
+// with the metadata is compressed.
+func isCompressed(metadata map[string]string) bool {
+	_, ok := metadata[compressionMetaKey]
+	return ok
+}
+
+// getActualSize - returns the size of a compressed object before
+// compression.
+func getActualSize(metadata map[string]string) (int64, error) {
+	size, err := strconv.ParseInt(metadata[actualSizeMetaKey], 10, 64)
+	if err != nil {
+		return 0, errCompressedDataCorrupted
+	}
+	return size, nil
+}
+
+// compressOffset - offset of a frame in the compressed data and offset
+// of its block in the object.
+type compressOffset struct {
+	actual     int64
+	compressed int64
+}
+
+// compressIndex - offsets of frames in the compressed data, at least
+// compressIndexInterval bytes of the object apart. Ranges are read
+// starting at the closest frame indexed before them instead of the
+// start of the compressed data.
+type compressIndex []compressOffset
+
+// add - adds the offsets of a frame unless the frame is too close to
+// the last one indexed.
+func (index *compressIndex) add(actual, compressed int64) {
+	var last compressOffset
+	if len(*index) > 0 {
+		last = (*index)[len(*index)-1]
+	}
+	if actual-last.actual < compressIndexInterval {
+		return
+	}
+	*index = append(*index, compressOffset{actual, compressed})
+}
+
+// seek - returns the offsets of the closest frame indexed at or before
+// offset of the object.
+func (index compressIndex) seek(offset int64) (actual, compressed int64) {
+	i := sort.Search(len(index), func(i int) bool {
+		return index[i].actual > offset
+	})
+	if i == 0 {
+		return 0, 0
+	}
+	return index[i-1].actual, index[i-1].compressed
+}
+
+// String - formats the index as comma separated "actual:compressed"
+// offsets.
+func (index compressIndex) String() string {
+	offsets := make([]string, len(index))
+	for i, offset := range index {
+		offsets[i] = strconv.FormatInt(offset.actual, 10) + ":" + strconv.FormatInt(offset.compressed, 10)
+	}
+	return strings.Join(offsets, ",")
+}
+
+// parseCompressIndex - parses an index formatted by String.
+func parseCompressIndex(s string) (compressIndex, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var index compressIndex
+	var last compressOffset
+	for _, field := range strings.Split(s, ",") {
+		offsets := strings.SplitN(field, ":", 2)
+		if len(offsets) != 2 {
+			return nil, errCompressedDataCorrupted
+		}
+		actual, err := strconv.ParseInt(offsets[0], 10, 64)
+		if err != nil {
+			return nil, errCompressedDataCorrupted
+		}
+		compressed, err := strconv.ParseInt(offsets[1], 10, 64)
+		if err != nil {
+			return nil, errCompressedDataCorrupted
+		}
+		// Offsets only grow.
+		if actual <= last.actual || compressed <= last.compressed {
+			return nil, errCompressedDataCorrupted
+		}
+		last = compressOffset{actual, compressed}
+		index = append(index, last)
+	}
+	return index, nil
+}
+
+// getCompressIndex - returns the index of a compressed object, objects
+// without one are read from the start.
+func getCompressIndex(metadata map[string]string) (compressIndex, error) {
+	return parseCompressIndex(metadata[compressIndexMetaKey])
+}
+
+// setCompressIndex - saves the index of a compressed object in its
+// metadata.
+func setCompressIndex(metadata map[string]string, index compressIndex) {
+	if len(index) == 0 {
+		delete(metadata, compressIndexMetaKey)
+		return
+	}
+	metadata[compressIndexMetaKey] = index.String()
+}
+
+// partsCompressIndex - returns the index of a compressed object made of
+// the parts, from the parts' own indexes. Parts are frame aligned.
+func partsCompressIndex(parts []objectPartInfo) (compressIndex, error) {
+	var index compressIndex
+	var actual, compressed int64
+	for _, part := range parts {
+		index.add(actual, compressed)
+		partIndex, err := parseCompressIndex(part.CompressIndex)
+		if err != nil {
+			return nil, err
+		}
+		for _, offset := range partIndex {
+			index.add(actual+offset.actual, compressed+offset.compressed)
+		}
+		actual += part.actualSize()
+		compressed += part.Size
+	}
+	return index, nil
+}
+
+// actualSize - returns the size of the part before compression.
+func (part objectPartInfo) actualSize() int64 {
+	if part.ActualSize > 0 {
+		return part.ActualSize
+	}
+	return part.Size
+}
+
+// compressReader - reads the data of the underlying reader as frames of
+// compressed blocks.
+type compressReader struct {
+	reader io.Reader
+	// Bytes read from the underlying reader.
+	actualSize int64
+	// Bytes of the frames read so far, and their index.
+	compressedSize int64
+	index          compressIndex
+
+	block  []byte
+	frame  bytes.Buffer
+	writer *flate.Writer
+	err    error
+}
+
+func newCompressReader(reader io.Reader) *compressReader {
+	// Never fails for a valid compression level.
+	writer, _ := flate.NewWriter(nil, flate.BestSpeed)
+	return &compressReader{
+		reader: reader,
+		block:  make([]byte, compressBlockSizeV1),
+		writer: writer,
+	}
+}
+
+func (c *compressReader) Read(p []byte) (int, error) {
+	for c.frame.Len() == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+		c.err = c.nextFrame()
+	}
+	return c.frame.Read(p)
+}
+
+// nextFrame - compresses the next block into frame.
+func (c *compressReader) nextFrame() error {
+	n, err := io.ReadFull(c.reader, c.block)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	if n == 0 {
+		return err
+	}
+	c.index.add(c.actualSize, c.compressedSize)
+	c.actualSize += int64(n)
+
+	c.frame.Reset()
+	c.frame.Write(make([]byte, compressFrameHeaderSize))
+	c.writer.Reset(&c.frame)
+	if _, wErr := c.writer.Write(c.block[:n]); wErr != nil {
+		return wErr
+	}
+	if wErr := c.writer.Close(); wErr != nil {
+		return wErr
+	}
+	frameType := byte(compressFrameDeflate)
+	if c.frame.Len()-compressFrameHeaderSize >= n {
+		frameType = compressFrameStored
+		c.frame.Truncate(compressFrameHeaderSize)
+		c.frame.Write(c.block[:n])
+	}
+	header := c.frame.Bytes()[:compressFrameHeaderSize]
+	header[0] = frameType
+	binary.BigEndian.PutUint32(header[1:5], uint32(c.frame.Len()-compressFrameHeaderSize))
+	binary.BigEndian.PutUint32(header[5:9], uint32(n))
+	c.compressedSize += int64(c.frame.Len())
+	return err
+}
+
+// decompressRange - writes length bytes at offset of a compressed
+// object. Its compressed data is written by readCompressed from
+// compressedOffset, the closest frame indexed before offset, frames
+// between it and offset are skipped without being decompressed.
+func decompressRange(writer io.Writer, offset, length int64, index compressIndex, readCompressed func(compressedOffset int64, compressedWriter io.Writer) error) error {
+	if length == 0 {
+		return nil
+	}
+	actualOffset, compressedOffset := index.seek(offset)
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(readCompressed(compressedOffset, pipeWriter))
+	}()
+	err := decompressFrames(pipeReader, writer, offset-actualOffset, length)
+	// Stop reading if done before the end of the object.
+	pipeReader.Close()
+	return err
+}
+
+// decompressFrames - writes length bytes at offset of the data
+// compressed in the frames read from reader.
+func decompressFrames(reader io.Reader, writer io.Writer, offset, length int64) error {
+	header := make([]byte, compressFrameHeaderSize)
+	frameData := make([]byte, compressBlockSizeV1)
+	block := make([]byte, compressBlockSizeV1)
+	var flateReader io.ReadCloser
+
+	endOffset := offset + length
+	// Offset of the block of the current frame in the object.
+	var blockOffset int64
+	for blockOffset < endOffset {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return traceError(errCompressedDataCorrupted)
+			}
+			return err
+		}
+		frameType := header[0]
+		frameSize := int64(binary.BigEndian.Uint32(header[1:5]))
+		blockSize := int64(binary.BigEndian.Uint32(header[5:9]))
+		if frameSize > compressBlockSizeV1 || blockSize > compressBlockSizeV1 {
+			return traceError(errCompressedDataCorrupted)
+		}
+
+		// Skip frames before the range.
+		if blockOffset+blockSize <= offset {
+			if _, err := io.CopyN(ioutil.Discard, reader, frameSize); err != nil {
+				if err == io.EOF {
+					return traceError(errCompressedDataCorrupted)
+				}
+				return err
+			}
+			blockOffset += blockSize
+			continue
+		}
+
+		if _, err := io.ReadFull(reader, frameData[:frameSize]); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return traceError(errCompressedDataCorrupted)
+			}
+			return err
+		}
+		switch frameType {
+		case compressFrameStored:
+			if frameSize != blockSize {
+				return traceError(errCompressedDataCorrupted)
+			}
+			copy(block, frameData[:frameSize])
+		case compressFrameDeflate:
+			if flateReader == nil {
+				flateReader = flate.NewReader(bytes.NewReader(frameData[:frameSize]))
+			} else if err := flateReader.(flate.Resetter).Reset(bytes.NewReader(frameData[:frameSize]), nil); err != nil {
+				return traceError(err)
+			}
+			if _, err := io.ReadFull(flateReader, block[:blockSize]); err != nil {
+				return traceError(errCompressedDataCorrupted)
+			}
+		default:
+			return traceError(errCompressedDataCorrupted)
+		}
+
+		// Write the requested part of the block.
+		blockStart, blockEnd := int64(0), blockSize
+		if offset > blockOffset {
+			blockStart = offset - blockOffset
+		}
+		if endOffset-blockOffset < blockEnd {
+			blockEnd = endOffset - blockOffset
+		}
+		if _, err := writer.Write(block[blockStart:blockEnd]); err != nil {
+			return traceError(err)
+		}
+		blockOffset += blockSize
+	}
+	return nil
+}
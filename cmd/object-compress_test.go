@@ -0,0 +1,286 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests the objects picked for compression.
+func TestIsCompressible(t *testing.T) {
+	defer func(enabled bool) { globalIsCompressionEnabled = enabled }(globalIsCompressionEnabled)
+
+	testCases := []struct {
+		enabled      bool
+		bucket       string
+		object       string
+		metadata     map[string]string
+		compressible bool
+	}{
+		// Compression turned off.
+		{false, "bucket", "object.txt", nil, false},
+		// Matched by extension.
+		{true, "bucket", "object.txt", nil, true},
+		{true, "bucket", "dir/object.LOG", nil, true},
+		// Matched by content type.
+		{true, "bucket", "object", map[string]string{"content-type": "text/plain; charset=utf-8"}, true},
+		{true, "bucket", "object.xml", nil, true},
+		// Neither extension nor content type match.
+		{true, "bucket", "object.mp4", nil, false},
+		{true, "bucket", "object", map[string]string{"content-type": "application/octet-stream"}, false},
+		// Already compressed by the client.
+		{true, "bucket", "object.txt", map[string]string{"content-encoding": "gzip"}, false},
+		// Internal objects are never compressed.
+		{true, minioMetaBucket, "object.json", nil, false},
+	}
+	for i, testCase := range testCases {
+		globalIsCompressionEnabled = testCase.enabled
+		metadata := testCase.metadata
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		if compressible := isCompressible(testCase.bucket, testCase.object, metadata); compressible != testCase.compressible {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.compressible, compressible)
+		}
+	}
+}
+
+// Tests compressing data into frames and decompressing ranges of it.
+func TestCompressFrames(t *testing.T) {
+	// Compressible text followed by random bytes, which are stored as is.
+	text := []byte(strings.Repeat("minio compresses text. ", 100000))
+	random := make([]byte, 3*compressBlockSizeV1/2)
+	rand.New(rand.NewSource(1)).Read(random)
+	data := append(text, random...)
+
+	reader := newCompressReader(bytes.NewReader(data))
+	compressed, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reader.actualSize != int64(len(data)) {
+		t.Fatalf("Expected actual size %d, got %d", len(data), reader.actualSize)
+	}
+	if len(compressed) >= len(data) {
+		t.Fatalf("Expected data of %d bytes to shrink, got %d bytes", len(data), len(compressed))
+	}
+
+	dataLen := int64(len(data))
+	testCases := []struct {
+		offset, length int64
+	}{
+		{0, dataLen},
+		{0, 1},
+		{1, 10},
+		{compressBlockSizeV1 - 5, 10},
+		{compressBlockSizeV1, compressBlockSizeV1},
+		{int64(len(text)) - 100, 200},
+		{dataLen - 1, 1},
+		{dataLen, 0},
+	}
+	for i, testCase := range testCases {
+		buffer := new(bytes.Buffer)
+		if err = decompressFrames(bytes.NewReader(compressed), buffer, testCase.offset, testCase.length); err != nil {
+			t.Fatalf("Test %d: %s", i+1, err)
+		}
+		if !bytes.Equal(buffer.Bytes(), data[testCase.offset:testCase.offset+testCase.length]) {
+			t.Errorf("Test %d: decompressed data does not match", i+1)
+		}
+	}
+
+	// Truncated data fails.
+	err = decompressFrames(bytes.NewReader(compressed[:len(compressed)-1]), ioutil.Discard, 0, dataLen)
+	if errorCause(err) != errCompressedDataCorrupted {
+		t.Fatalf("Expected %s, got %v", errCompressedDataCorrupted, err)
+	}
+}
+
+// Tests indexing frames of compressed data and reading ranges from the
+// closest frame indexed.
+func TestCompressIndex(t *testing.T) {
+	data := []byte(strings.Repeat("minio indexes compressed text. ", 1400000))
+	dataLen := int64(len(data))
+
+	reader := newCompressReader(bytes.NewReader(data))
+	compressed, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Frames are indexed every 16MiB of the 41MiB of text.
+	if len(reader.index) != 2 || reader.index[0].actual != compressIndexInterval || reader.index[1].actual != 2*compressIndexInterval {
+		t.Fatalf("Unexpected index %v", reader.index)
+	}
+	index, err := parseCompressIndex(reader.index.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(index, reader.index) {
+		t.Fatalf("Expected index %v, got %v", reader.index, index)
+	}
+
+	testCases := []struct {
+		offset, length   int64
+		compressedOffset int64
+	}{
+		{0, dataLen, 0},
+		{compressIndexInterval - 1, 2, 0},
+		{compressIndexInterval, 10, index[0].compressed},
+		{dataLen - 10, 10, index[1].compressed},
+	}
+	for i, testCase := range testCases {
+		buffer := new(bytes.Buffer)
+		err = decompressRange(buffer, testCase.offset, testCase.length, index, func(compressedOffset int64, compressedWriter io.Writer) error {
+			if compressedOffset != testCase.compressedOffset {
+				t.Errorf("Test %d: expected to read from %d, got %d", i+1, testCase.compressedOffset, compressedOffset)
+			}
+			_, wErr := compressedWriter.Write(compressed[compressedOffset:])
+			return wErr
+		})
+		if err != nil {
+			t.Fatalf("Test %d: %s", i+1, err)
+		}
+		if !bytes.Equal(buffer.Bytes(), data[testCase.offset:testCase.offset+testCase.length]) {
+			t.Errorf("Test %d: decompressed data does not match", i+1)
+		}
+	}
+
+	// Indexes which are not well formed are rejected.
+	for _, s := range []string{"1", "a:1", "1:b", "2:2,1:3", "1:1,,"} {
+		if _, err = parseCompressIndex(s); err != errCompressedDataCorrupted {
+			t.Errorf("Index %q: expected %s, got %v", s, errCompressedDataCorrupted, err)
+		}
+	}
+
+	// Parts are indexed at their start and by their own index.
+	parts := []objectPartInfo{
+		{Size: 100, ActualSize: compressIndexInterval + 10},
+		{Size: 200, ActualSize: 3 * compressIndexInterval, CompressIndex: reader.index.String()},
+	}
+	index, err = partsCompressIndex(parts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := compressIndex{
+		{compressIndexInterval + 10, 100},
+		{2*compressIndexInterval + 10, 100 + reader.index[0].compressed},
+		{3*compressIndexInterval + 10, 100 + reader.index[1].compressed},
+	}
+	if !reflect.DeepEqual(index, expected) {
+		t.Fatalf("Expected index %v, got %v", expected, index)
+	}
+}
+
+// Wrapper for calling compressed object tests for both XL multiple disks and single node setup.
+func TestCompressedObject(t *testing.T) {
+	defer func(enabled bool) { globalIsCompressionEnabled = enabled }(globalIsCompressionEnabled)
+	globalIsCompressionEnabled = true
+	ExecObjectLayerTest(t, testCompressedObject)
+}
+
+// Tests compressible objects are read back as uploaded, whole and in ranges.
+func testCompressedObject(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	data := []byte(strings.Repeat("a line of a compressible log file\n", 1000000))
+	md5Sum := md5.Sum(data)
+
+	objInfo, err := obj.PutObject(bucket, "object.log", int64(len(data)), bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if objInfo.Size != int64(len(data)) {
+		t.Fatalf("%s: expected size %d, got %d", instanceType, len(data), objInfo.Size)
+	}
+	if objInfo.MD5Sum != hex.EncodeToString(md5Sum[:]) {
+		t.Fatalf("%s: expected md5 of the uncompressed data, got %s", instanceType, objInfo.MD5Sum)
+	}
+	if !isCompressed(objInfo.UserDefined) {
+		t.Fatalf("%s: expected object to be compressed", instanceType)
+	}
+
+	// Multipart uploads are compressed part by part.
+	uploadID, err := obj.NewMultipartUpload(bucket, "multipart.log", nil)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	var parts []completePart
+	for i, partData := range [][]byte{data[:5*1024*1024], data[5*1024*1024:]} {
+		partMD5 := md5.Sum(partData)
+		etag, pErr := obj.PutObjectPart(bucket, "multipart.log", uploadID, i+1, int64(len(partData)), bytes.NewReader(partData), hex.EncodeToString(partMD5[:]))
+		if pErr != nil {
+			t.Fatalf("%s: %s", instanceType, pErr)
+		}
+		parts = append(parts, completePart{PartNumber: i + 1, ETag: etag})
+	}
+	listInfo, err := obj.ListObjectParts(bucket, "multipart.log", uploadID, 0, 10)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if len(listInfo.Parts) != 2 || listInfo.Parts[0].Size != 5*1024*1024 {
+		t.Fatalf("%s: expected parts listed with their uncompressed size, got %v", instanceType, listInfo.Parts)
+	}
+	if _, err = obj.CompleteMultipartUpload(bucket, "multipart.log", uploadID, parts); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+
+	for _, object := range []string{"object.log", "multipart.log"} {
+		objInfo, err = obj.GetObjectInfo(bucket, object)
+		if err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+		if objInfo.Size != int64(len(data)) {
+			t.Fatalf("%s: %s: expected size %d, got %d", instanceType, object, len(data), objInfo.Size)
+		}
+		// Ranges are read from the closest frame indexed.
+		if objInfo.UserDefined[compressIndexMetaKey] == "" {
+			t.Fatalf("%s: %s: expected the object to be indexed", instanceType, object)
+		}
+		testCases := []struct {
+			offset, length int64
+		}{
+			{0, int64(len(data))},
+			{10, 100},
+			{5*1024*1024 - 10, 20},
+			{compressIndexInterval + 5*1024*1024 - 10, 20},
+			{int64(len(data)) - 1, 1},
+		}
+		for i, testCase := range testCases {
+			buffer := new(bytes.Buffer)
+			if err = obj.GetObject(bucket, object, testCase.offset, testCase.length, buffer); err != nil {
+				t.Fatalf("%s: %s: test %d: %s", instanceType, object, i+1, err)
+			}
+			if !bytes.Equal(buffer.Bytes(), data[testCase.offset:testCase.offset+testCase.length]) {
+				t.Errorf("%s: %s: test %d: data does not match", instanceType, object, i+1)
+			}
+		}
+		// Ranges past the uncompressed size are invalid.
+		err = obj.GetObject(bucket, object, int64(len(data)), 1, ioutil.Discard)
+		if _, ok := errorCause(err).(InvalidRange); !ok {
+			t.Errorf("%s: %s: expected InvalidRange, got %v", instanceType, object, err)
+		}
+	}
+}
@@ -136,6 +136,13 @@ func (e ObjectExistsAsDirectory) Error() string {
 	return "Object exists on : " + e.Bucket + " as directory " + e.Object
 }
 
+// ObjectLocked object is under retention or legal hold.
+type ObjectLocked GenericError
+
+func (e ObjectLocked) Error() string {
+	return "Object is WORM protected and cannot be overwritten or deleted: " + e.Bucket + "#" + e.Object
+}
+
 // BucketExists bucket exists.
 type BucketExists GenericError
 
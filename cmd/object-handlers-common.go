@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -210,3 +211,26 @@ func canonicalizeETag(etag string) string {
 func isETagEqual(left, right string) bool {
 	return canonicalizeETag(left) == canonicalizeETag(right)
 }
+
+// updateObjectMetadata - metadata of an object cannot be updated in
+// place, rewrites the object with the new metadata.
+func updateObjectMetadata(objAPI ObjectLayer, objInfo ObjectInfo, metadata map[string]string) error {
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		gErr := objAPI.GetObject(objInfo.Bucket, objInfo.Name, 0, objInfo.Size, pipeWriter)
+		if gErr != nil {
+			errorIf(gErr, "Unable to read an object.")
+			pipeWriter.CloseWithError(gErr)
+			return
+		}
+		pipeWriter.Close() // Close.
+	}()
+
+	if _, err := objAPI.PutObject(objInfo.Bucket, objInfo.Name, objInfo.Size, pipeReader, metadata); err != nil {
+		// Close the this end of the pipe upon error in PutObject.
+		pipeReader.CloseWithError(err)
+		return err
+	}
+	pipeReader.Close()
+	return nil
+}
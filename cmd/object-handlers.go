@@ -67,8 +67,10 @@ func errAllowableObjectNotFound(bucket string, r *http.Request) APIErrorCode {
 		//we care about the bucket as a whole, not a particular resource
 		url := *r.URL
 		url.Path = "/" + bucket
+		req := *r
+		req.URL = &url
 
-		if s3Error := enforceBucketPolicy(bucket, "s3:ListBucket", &url); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:ListBucket", &req); s3Error != ErrNone {
 			return ErrAccessDenied
 		}
 	}
@@ -106,7 +108,7 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:GetObject", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:GetObject", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -194,6 +196,18 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		// call wrter.Write(nil) to set appropriate headers.
 		writer.Write(nil)
 	}
+
+	if globalEventNotifier.IsBucketNotificationSet(bucket) {
+		// Notify object accessed via a GET request.
+		eventNotify(eventData{
+			Type:    ObjectAccessedGet,
+			Bucket:  bucket,
+			ObjInfo: objInfo,
+			ReqParams: map[string]string{
+				"sourceIPAddress": r.RemoteAddr,
+			},
+		})
+	}
 }
 
 // HeadObjectHandler - HEAD Object
@@ -218,7 +232,7 @@ func (api objectAPIHandlers) HeadObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:GetObject", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:GetObject", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -251,6 +265,18 @@ func (api objectAPIHandlers) HeadObjectHandler(w http.ResponseWriter, r *http.Re
 
 	// Successful response.
 	w.WriteHeader(http.StatusOK)
+
+	if globalEventNotifier.IsBucketNotificationSet(bucket) {
+		// Notify object accessed via a HEAD request.
+		eventNotify(eventData{
+			Type:    ObjectAccessedHead,
+			Bucket:  bucket,
+			ObjInfo: objInfo,
+			ReqParams: map[string]string{
+				"sourceIPAddress": r.RemoteAddr,
+			},
+		})
+	}
 }
 
 // CopyObjectHandler - Copy Object
@@ -275,7 +301,7 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:PutObject", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:PutObject", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -353,7 +379,32 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 	}()
 
 	// Save other metadata if available.
-	metadata := objInfo.UserDefined
+	metadata := make(map[string]string)
+	for k, v := range objInfo.UserDefined {
+		metadata[k] = v
+	}
+
+	// ACL of the source object is not copied, the copy gets the
+	// canned ACL of the request.
+	acl, ok := getCannedACL(r)
+	if !ok {
+		writeErrorResponse(w, r, ErrInvalidCannedACL, r.URL.Path)
+		return
+	}
+	setObjectACLMetadata(metadata, acl)
+
+	// Save the bit-rot algorithm selected for the object.
+	if !setBitRotAlgoMetadata(r, metadata) {
+		writeErrorResponse(w, r, ErrInvalidBitRotAlgorithm, r.URL.Path)
+		return
+	}
+
+	// Retention and legal hold of the source object are not copied
+	// either, the copy is locked as requested or by bucket default.
+	if s3Error := applyObjectLock(objectAPI, bucket, object, r, metadata); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
 
 	// Do not set `md5sum` as CopyObject will not keep the
 	// same md5sum as the source.
@@ -445,6 +496,27 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 	// Make sure we hex encode md5sum here.
 	metadata["md5Sum"] = hex.EncodeToString(md5Bytes)
 
+	// Save the canned ACL of the object.
+	acl, ok := getCannedACL(r)
+	if !ok {
+		writeErrorResponse(w, r, ErrInvalidCannedACL, r.URL.Path)
+		return
+	}
+	setObjectACLMetadata(metadata, acl)
+
+	// Save the bit-rot algorithm selected for the object.
+	if !setBitRotAlgoMetadata(r, metadata) {
+		writeErrorResponse(w, r, ErrInvalidBitRotAlgorithm, r.URL.Path)
+		return
+	}
+
+	// Save retention and legal hold of the object, an existing
+	// locked object cannot be overwritten.
+	if s3Error := applyObjectLock(objectAPI, bucket, object, r, metadata); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
 	var objInfo ObjectInfo
 	switch rAuthType {
 	default:
@@ -453,7 +525,7 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:PutObject", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:PutObject", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -517,7 +589,7 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/mpuAndPermissions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:PutObject", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:PutObject", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -532,6 +604,27 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 	// Extract metadata that needs to be saved.
 	metadata := extractMetadataFromHeader(r.Header)
 
+	// Save the canned ACL of the object.
+	acl, ok := getCannedACL(r)
+	if !ok {
+		writeErrorResponse(w, r, ErrInvalidCannedACL, r.URL.Path)
+		return
+	}
+	setObjectACLMetadata(metadata, acl)
+
+	// Save the bit-rot algorithm selected for the object.
+	if !setBitRotAlgoMetadata(r, metadata) {
+		writeErrorResponse(w, r, ErrInvalidBitRotAlgorithm, r.URL.Path)
+		return
+	}
+
+	// Save retention and legal hold of the object, they take effect
+	// once the upload is completed.
+	if s3Error := applyObjectLock(objectAPI, bucket, object, r, metadata); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
 	uploadID, err := objectAPI.NewMultipartUpload(bucket, object, metadata)
 	if err != nil {
 		errorIf(err, "Unable to initiate new multipart upload id.")
@@ -615,7 +708,7 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/mpuAndPermissions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:PutObject", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:PutObject", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -666,7 +759,7 @@ func (api objectAPIHandlers) AbortMultipartUploadHandler(w http.ResponseWriter,
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/mpuAndPermissions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:AbortMultipartUpload", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:AbortMultipartUpload", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -706,7 +799,7 @@ func (api objectAPIHandlers) ListObjectPartsHandler(w http.ResponseWriter, r *ht
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/mpuAndPermissions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:ListMultipartUploadParts", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:ListMultipartUploadParts", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -764,7 +857,7 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/mpuAndPermissions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:PutObject", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:PutObject", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -803,6 +896,13 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 		completeParts = append(completeParts, part)
 	}
 
+	// Verify the existing object, if any, can be overwritten.
+	if err = enforceObjectLock(objectAPI, bucket, object, r); err != nil {
+		errorIf(err, "Unable to complete multipart upload.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
 	md5Sum, err = objectAPI.CompleteMultipartUpload(bucket, object, uploadID, completeParts)
 
 	if err != nil {
@@ -875,7 +975,7 @@ func (api objectAPIHandlers) DeleteObjectHandler(w http.ResponseWriter, r *http.
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:DeleteObject", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:DeleteObject", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
@@ -886,6 +986,11 @@ func (api objectAPIHandlers) DeleteObjectHandler(w http.ResponseWriter, r *http.
 			return
 		}
 	}
+	// Objects under retention or legal hold cannot be deleted.
+	if err := enforceObjectLock(objectAPI, bucket, object, r); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
 	/// http://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectDELETE.html
 	/// Ignore delete object errors, since we are suppposed to reply
 	/// only 204.
@@ -0,0 +1,797 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mf-00/minio/pkg/wildcard"
+)
+
+// Directory of the index files inside minioMetaBucket, each indexed
+// bucket has its own file.
+const indexMetaPrefix = "index"
+
+// errIndexNotEnabled - the bucket has no index, searches are not served.
+var errIndexNotEnabled = errors.New("Bucket is not indexed, set MINIO_INDEX_DIR and rebuild the index of the bucket with `minio control index rebuild`.")
+
+// errIndexRebuilding - the index of the bucket is already being rebuilt.
+var errIndexRebuilding = errors.New("Index of the bucket is already being rebuilt.")
+
+// indexEntry - metadata of an object kept in the index.
+type indexEntry struct {
+	Name        string    `json:"name"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"modTime"`
+	MD5Sum      string    `json:"md5Sum,omitempty"`
+	ContentType string    `json:"contentType,omitempty"`
+}
+
+// Returns the index entry of the object.
+func newIndexEntry(objInfo ObjectInfo) indexEntry {
+	return indexEntry{
+		Name:        objInfo.Name,
+		Size:        objInfo.Size,
+		ModTime:     objInfo.ModTime,
+		MD5Sum:      objInfo.MD5Sum,
+		ContentType: objInfo.ContentType,
+	}
+}
+
+// indexRecord - a line of an index file, indexes are saved as a
+// journal of the puts and deletes of their objects. An "intent" record
+// is flushed to disk before each write of an object to the backend, the
+// objects of intents without a following put or delete are looked up
+// in the backend when the index is loaded.
+type indexRecord struct {
+	Op string `json:"op"` // "intent", "put" or "delete".
+	indexEntry
+}
+
+// sortedNames - sorted set of names, split in chunks so that inserts
+// and deletes only move the names of one chunk.
+type sortedNames struct {
+	chunks [][]string // Never empty.
+}
+
+// Chunks are split in two once they hold twice as many names.
+const sortedNamesChunkSize = 512
+
+// namePos - position of a name in sortedNames.
+type namePos struct {
+	chunk, i int
+}
+
+// search - returns the position of the first name for which f is true,
+// f must be false for all the names before it as with sort.Search.
+func (s *sortedNames) search(f func(string) bool) namePos {
+	c := sort.Search(len(s.chunks), func(c int) bool {
+		return f(s.chunks[c][len(s.chunks[c])-1])
+	})
+	if c == len(s.chunks) {
+		return namePos{chunk: c}
+	}
+	chunk := s.chunks[c]
+	return namePos{c, sort.Search(len(chunk), func(i int) bool { return f(chunk[i]) })}
+}
+
+// valid - returns false for the position past the last name.
+func (s *sortedNames) valid(p namePos) bool {
+	return p.chunk < len(s.chunks)
+}
+
+// at - returns the name at a valid position.
+func (s *sortedNames) at(p namePos) string {
+	return s.chunks[p.chunk][p.i]
+}
+
+// next - returns the position of the name after the one at p.
+func (s *sortedNames) next(p namePos) namePos {
+	if p.i++; p.i == len(s.chunks[p.chunk]) {
+		return namePos{chunk: p.chunk + 1}
+	}
+	return p
+}
+
+// find - returns the position of the first name not before name, and
+// true if it is name.
+func (s *sortedNames) find(name string) (namePos, bool) {
+	p := s.search(func(n string) bool { return n >= name })
+	return p, s.valid(p) && s.at(p) == name
+}
+
+// insert - adds the name, if not already in the set.
+func (s *sortedNames) insert(name string) {
+	p, ok := s.find(name)
+	if ok {
+		return
+	}
+	if len(s.chunks) == 0 {
+		s.chunks = [][]string{{name}}
+		return
+	}
+	if !s.valid(p) {
+		// Last name of the set.
+		p = namePos{len(s.chunks) - 1, len(s.chunks[len(s.chunks)-1])}
+	}
+	chunk := append(s.chunks[p.chunk], "")
+	copy(chunk[p.i+1:], chunk[p.i:])
+	chunk[p.i] = name
+	s.chunks[p.chunk] = chunk
+	if len(chunk) < 2*sortedNamesChunkSize {
+		return
+	}
+	// Split the chunk, the second half is copied so that appends to
+	// the first one don't overwrite it.
+	second := append([]string(nil), chunk[sortedNamesChunkSize:]...)
+	s.chunks[p.chunk] = chunk[:sortedNamesChunkSize]
+	s.chunks = append(s.chunks, nil)
+	copy(s.chunks[p.chunk+2:], s.chunks[p.chunk+1:])
+	s.chunks[p.chunk+1] = second
+}
+
+// remove - removes the name, if in the set.
+func (s *sortedNames) remove(name string) {
+	p, ok := s.find(name)
+	if !ok {
+		return
+	}
+	chunk := append(s.chunks[p.chunk][:p.i], s.chunks[p.chunk][p.i+1:]...)
+	if len(chunk) == 0 {
+		s.chunks = append(s.chunks[:p.chunk], s.chunks[p.chunk+1:]...)
+		return
+	}
+	s.chunks[p.chunk] = chunk
+}
+
+// all - returns all the names, sorted.
+func (s *sortedNames) all() []string {
+	var names []string
+	for _, chunk := range s.chunks {
+		names = append(names, chunk...)
+	}
+	return names
+}
+
+// bucketIndex - index of the objects of a bucket, by name.
+type bucketIndex struct {
+	mu      sync.RWMutex
+	entries map[string]indexEntry
+	names   sortedNames // Names of entries.
+
+	// Number of writes of each object sent to the backend whose put or
+	// delete is not recorded yet, their intents are kept when the
+	// index file is replaced.
+	inflight map[string]int
+
+	// Set once a rebuilt index replaces this one.
+	closed bool
+
+	// Records of the writes done while the index is rebuilt, they are
+	// applied once all the objects are listed.
+	pending []indexRecord
+}
+
+func newBucketIndex() *bucketIndex {
+	return &bucketIndex{
+		entries:  make(map[string]indexEntry),
+		inflight: make(map[string]int),
+	}
+}
+
+// apply - applies a record to the index. Puts of older versions of an
+// object, such as the ones racing with a rebuild, are ignored.
+func (b *bucketIndex) apply(rec indexRecord) {
+	current, ok := b.entries[rec.Name]
+	switch rec.Op {
+	case "put":
+		if ok && rec.ModTime.Before(current.ModTime) {
+			return
+		}
+		b.entries[rec.Name] = rec.indexEntry
+		if !ok {
+			b.names.insert(rec.Name)
+		}
+	case "delete":
+		if ok {
+			delete(b.entries, rec.Name)
+			b.names.remove(rec.Name)
+		}
+	}
+}
+
+// load - applies the records of an index file, a partially written
+// last record is ignored. Returns the number of intents of each object
+// without a following put or delete.
+func (b *bucketIndex) load(buf []byte) (records int, intents map[string]int) {
+	intents = make(map[string]int)
+	for _, line := range bytes.Split(buf, []byte("\n")) {
+		var rec indexRecord
+		if len(line) == 0 || json.Unmarshal(line, &rec) != nil {
+			continue
+		}
+		records++
+		if rec.Op == "intent" {
+			intents[rec.Name]++
+			continue
+		}
+		if intents[rec.Name] > 0 {
+			if intents[rec.Name]--; intents[rec.Name] == 0 {
+				delete(intents, rec.Name)
+			}
+		}
+		b.apply(rec)
+	}
+	return records, intents
+}
+
+// marshal - returns the index file of the current entries.
+func (b *bucketIndex) marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	for _, chunk := range b.names.chunks {
+		for _, name := range chunk {
+			line, err := json.Marshal(indexRecord{Op: "put", indexEntry: b.entries[name]})
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(line)
+			buf.WriteByte('\n')
+		}
+	}
+	for name, count := range b.inflight {
+		line, err := json.Marshal(indexRecord{Op: "intent", indexEntry: indexEntry{Name: name}})
+		if err != nil {
+			return nil, err
+		}
+		for ; count > 0; count-- {
+			buf.Write(line)
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// list - lists the entries after marker with the prefix, names
+// sharing a common prefix up to the delimiter are rolled up into it as
+// tree walks do. Only entries accepted by match are listed.
+func (b *bucketIndex) list(prefix, marker, delimiter string, maxKeys int, match func(indexEntry) bool) ListObjectsInfo {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var result ListObjectsInfo
+	p := b.names.search(func(n string) bool { return n >= prefix })
+	if marker > prefix {
+		p = b.names.search(func(n string) bool { return n > marker })
+	}
+	for b.names.valid(p) && strings.HasPrefix(b.names.at(p), prefix) {
+		name := b.names.at(p)
+		if delimiter != "" {
+			if j := strings.Index(name[len(prefix):], delimiter); j != -1 {
+				commonPrefix := name[:len(prefix)+j+len(delimiter)]
+				// Skip all the names with the common prefix.
+				p = b.names.search(func(n string) bool {
+					return !strings.HasPrefix(n, commonPrefix) && n > commonPrefix
+				})
+				// The common prefix was listed before the marker.
+				if strings.HasPrefix(marker, commonPrefix) {
+					continue
+				}
+				if len(result.Objects)+len(result.Prefixes) == maxKeys {
+					result.IsTruncated = true
+					break
+				}
+				result.Prefixes = append(result.Prefixes, commonPrefix)
+				result.NextMarker = commonPrefix
+				continue
+			}
+		}
+		p = b.names.next(p)
+		entry := b.entries[name]
+		if match != nil && !match(entry) {
+			continue
+		}
+		if len(result.Objects)+len(result.Prefixes) == maxKeys {
+			result.IsTruncated = true
+			break
+		}
+		result.Objects = append(result.Objects, ObjectInfo{
+			Name:        entry.Name,
+			ModTime:     entry.ModTime,
+			Size:        entry.Size,
+			MD5Sum:      entry.MD5Sum,
+			ContentType: entry.ContentType,
+		})
+		result.NextMarker = entry.Name
+	}
+	return result
+}
+
+// objectQuery - conditions on the objects returned by a search, zero
+// values match all objects.
+type objectQuery struct {
+	Prefix         string
+	Suffix         string
+	ContentType    string // Wildcards allowed, as in "image/*".
+	MinSize        int64
+	MaxSize        int64 // Negative for no limit.
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+}
+
+// match - returns true if the entry matches all the conditions.
+func (q objectQuery) match(entry indexEntry) bool {
+	if !strings.HasSuffix(entry.Name, q.Suffix) {
+		return false
+	}
+	if q.ContentType != "" && !wildcard.Match(q.ContentType, entry.ContentType) {
+		return false
+	}
+	if entry.Size < q.MinSize || (q.MaxSize >= 0 && entry.Size > q.MaxSize) {
+		return false
+	}
+	if !q.ModifiedAfter.IsZero() && !entry.ModTime.After(q.ModifiedAfter) {
+		return false
+	}
+	if !q.ModifiedBefore.IsZero() && !entry.ModTime.Before(q.ModifiedBefore) {
+		return false
+	}
+	return true
+}
+
+// objectSearcher - implemented by object layers which can search the
+// objects of a bucket by their metadata.
+type objectSearcher interface {
+	SearchObjects(bucket string, query objectQuery, marker string, maxKeys int) (ListObjectsInfo, error)
+}
+
+// indexObjects - an object layer keeping an index of the objects of the
+// backend in a local directory. Listings of indexed buckets are served
+// from their index, which also serves searches by suffix and metadata.
+// Writes going through this layer keep the indexes in sync, so the
+// backend must not be written to by other servers. Buckets created
+// before the index was enabled are indexed by rebuilding their index.
+type indexObjects struct {
+	// All the calls not involving the index are passed through.
+	ObjectLayer
+
+	*indexStore
+}
+
+// indexStore - indexes of the buckets and the disk they are saved on.
+type indexStore struct {
+	disk    *posix
+	backend ObjectLayer // Looked up for the writes interrupted by a crash.
+
+	mu       sync.Mutex
+	buckets  map[string]*bucketIndex // Loaded indexes, nil if not indexed.
+	builders map[string]*bucketIndex // Indexes being rebuilt.
+}
+
+// newIndexObjects - returns an object layer indexing the objects of the
+// backend, indexes are saved in the directory.
+func newIndexObjects(backend ObjectLayer, dir string) (ObjectLayer, error) {
+	storage, err := newPosix(dir)
+	if err != nil {
+		return nil, err
+	}
+	disk := storage.(*posix)
+	// Left over temporary files of a previous run are removed.
+	if err = disk.MakeVol(minioMetaBucket); err != nil && err != errVolumeExists {
+		return nil, err
+	}
+	if err = cleanupDir(disk, minioMetaBucket, tmpMetaPrefix); err != nil {
+		return nil, errorCause(err)
+	}
+	return indexObjects{
+		ObjectLayer: backend,
+		indexStore: &indexStore{
+			disk:     disk,
+			backend:  backend,
+			buckets:  make(map[string]*bucketIndex),
+			builders: make(map[string]*bucketIndex),
+		},
+	}, nil
+}
+
+// indexFile - returns the path of the index file of the bucket.
+func indexFile(bucket string) string {
+	return path.Join(indexMetaPrefix, bucket+".json")
+}
+
+// getIndex - returns the index of the bucket, loading it if needed.
+// Returns nil if the bucket is not indexed.
+func (s *indexStore) getIndex(bucket string) *bucketIndex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.getIndexLocked(bucket)
+}
+
+func (s *indexStore) getIndexLocked(bucket string) *bucketIndex {
+	if b, ok := s.buckets[bucket]; ok {
+		return b
+	}
+	buf, err := s.disk.ReadAll(minioMetaBucket, indexFile(bucket))
+	if err != nil {
+		if err != errFileNotFound {
+			errorIf(err, "Unable to read the index of %s.", bucket)
+		}
+		// Not indexed, until the index is rebuilt.
+		s.buckets[bucket] = nil
+		return nil
+	}
+	b := newBucketIndex()
+	records, intents := b.load(buf)
+	if len(intents) > 0 {
+		// Writes interrupted by a crash, the backend has their outcome.
+		if err = s.resolve(bucket, b, intents); err != nil {
+			errorIf(err, "Unable to resolve the interrupted writes of the index of %s.", bucket)
+			s.buckets[bucket] = nil
+			return nil
+		}
+	}
+	if len(intents) > 0 || records > 2*len(b.entries)+maxObjectList {
+		// Intents are resolved or most records are overwritten,
+		// compact the index file.
+		if err = s.save(bucket, b); err != nil {
+			errorIf(err, "Unable to compact the index of %s.", bucket)
+		}
+	}
+	s.buckets[bucket] = b
+	return b
+}
+
+// resolve - updates the index with the objects of the intents, as they
+// are in the backend.
+func (s *indexStore) resolve(bucket string, b *bucketIndex, intents map[string]int) error {
+	for object := range intents {
+		objInfo, err := s.backend.GetObjectInfo(bucket, object)
+		if err != nil {
+			if _, ok := errorCause(err).(ObjectNotFound); ok {
+				b.apply(indexRecord{Op: "delete", indexEntry: indexEntry{Name: object}})
+				continue
+			}
+			return err
+		}
+		b.apply(indexRecord{Op: "put", indexEntry: newIndexEntry(objInfo)})
+	}
+	return nil
+}
+
+// save - replaces the index file of the bucket by the entries of b.
+func (s *indexStore) save(bucket string, b *bucketIndex) error {
+	buf, err := b.marshal()
+	if err != nil {
+		return err
+	}
+	tmpPath := path.Join(tmpMetaPrefix, getUUID())
+	if err = s.disk.appendFileSync(minioMetaBucket, tmpPath, buf); err != nil {
+		return err
+	}
+	return s.disk.RenameFile(minioMetaBucket, tmpPath, minioMetaBucket, indexFile(bucket))
+}
+
+// drop - removes the index of the bucket, which is no longer indexed.
+func (s *indexStore) drop(bucket string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropLocked(bucket)
+}
+
+func (s *indexStore) dropLocked(bucket string) {
+	if b := s.buckets[bucket]; b != nil {
+		b.mu.Lock()
+		b.closed = true
+		b.mu.Unlock()
+	}
+	s.buckets[bucket] = nil
+	if err := s.disk.DeleteFile(minioMetaBucket, indexFile(bucket)); err != nil && err != errFileNotFound {
+		errorIf(err, "Unable to remove the index of %s.", bucket)
+	}
+}
+
+// recordIntent - records, before the object is written to the backend,
+// that its put or delete may not be recorded if the server crashes. An
+// index which can't be written is dropped rather than left out of sync.
+func (s *indexStore) recordIntent(bucket, object string) {
+	for {
+		b := s.getIndex(bucket)
+		if b == nil {
+			return
+		}
+		b.mu.Lock()
+		if b.closed {
+			// Replaced by a rebuilt index, record in the new one.
+			b.mu.Unlock()
+			continue
+		}
+		line, err := json.Marshal(indexRecord{Op: "intent", indexEntry: indexEntry{Name: object}})
+		if err == nil {
+			err = s.disk.appendFileSync(minioMetaBucket, indexFile(bucket), append(line, '\n'))
+		}
+		if err == nil {
+			b.inflight[object]++
+		}
+		b.mu.Unlock()
+		if err != nil {
+			errorIf(err, "Unable to update the index of %s, the index is dropped.", bucket)
+			s.drop(bucket)
+		}
+		return
+	}
+}
+
+// record - records a put or a delete of an object in the index of its
+// bucket, once written to the backend. rec is nil if the write failed.
+// An index which can't be written is dropped rather than left out of
+// sync.
+func (s *indexStore) record(bucket, object string, rec *indexRecord) {
+	s.mu.Lock()
+	if builder := s.builders[bucket]; builder != nil && rec != nil {
+		builder.pending = append(builder.pending, *rec)
+	}
+	s.mu.Unlock()
+
+	for {
+		b := s.getIndex(bucket)
+		if b == nil {
+			return
+		}
+		b.mu.Lock()
+		if b.closed {
+			// Replaced by a rebuilt index, record in the new one.
+			b.mu.Unlock()
+			continue
+		}
+		if b.inflight[object]--; b.inflight[object] <= 0 {
+			delete(b.inflight, object)
+		}
+		var err error
+		if rec != nil {
+			b.apply(*rec)
+			var line []byte
+			line, err = json.Marshal(rec)
+			if err == nil {
+				err = s.disk.AppendFile(minioMetaBucket, indexFile(bucket), append(line, '\n'))
+			}
+		}
+		b.mu.Unlock()
+		if err != nil {
+			errorIf(err, "Unable to update the index of %s, the index is dropped.", bucket)
+			s.drop(bucket)
+		}
+		return
+	}
+}
+
+// recordPut - records the object as put.
+func (s *indexStore) recordPut(bucket string, objInfo ObjectInfo) {
+	s.record(bucket, objInfo.Name, &indexRecord{Op: "put", indexEntry: newIndexEntry(objInfo)})
+}
+
+// MakeBucket - new buckets are indexed from the start.
+func (l indexObjects) MakeBucket(bucket string) error {
+	if err := l.ObjectLayer.MakeBucket(bucket); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := newBucketIndex()
+	if err := l.save(bucket, b); err != nil {
+		errorIf(err, "Unable to create the index of %s.", bucket)
+		return nil
+	}
+	l.buckets[bucket] = b
+	return nil
+}
+
+// DeleteBucket - deletes the bucket and its index.
+func (l indexObjects) DeleteBucket(bucket string) error {
+	if err := l.ObjectLayer.DeleteBucket(bucket); err != nil {
+		return err
+	}
+	l.drop(bucket)
+	return nil
+}
+
+// PutObject - puts the object and adds it to the index.
+func (l indexObjects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string) (ObjectInfo, error) {
+	l.recordIntent(bucket, object)
+	objInfo, err := l.ObjectLayer.PutObject(bucket, object, size, data, metadata)
+	if err != nil {
+		l.record(bucket, object, nil)
+		return ObjectInfo{}, err
+	}
+	l.recordPut(bucket, objInfo)
+	return objInfo, nil
+}
+
+// DeleteObject - deletes the object and removes it from the index.
+func (l indexObjects) DeleteObject(bucket, object string) error {
+	l.recordIntent(bucket, object)
+	if err := l.ObjectLayer.DeleteObject(bucket, object); err != nil {
+		l.record(bucket, object, nil)
+		return err
+	}
+	l.record(bucket, object, &indexRecord{Op: "delete", indexEntry: indexEntry{Name: object}})
+	return nil
+}
+
+// CompleteMultipartUpload - completes the upload and adds the object
+// to the index.
+func (l indexObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (string, error) {
+	l.recordIntent(bucket, object)
+	md5Sum, err := l.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+	if err != nil {
+		l.record(bucket, object, nil)
+		return "", err
+	}
+	objInfo, err := l.ObjectLayer.GetObjectInfo(bucket, object)
+	if err != nil {
+		errorIf(err, "Unable to index %s/%s, the index is dropped.", bucket, object)
+		l.drop(bucket)
+		return md5Sum, nil
+	}
+	l.recordPut(bucket, objInfo)
+	return md5Sum, nil
+}
+
+// ListObjects - lists the objects from the index of the bucket, or from
+// the backend if the bucket is not indexed.
+func (l indexObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	var b *bucketIndex
+	if bucket != minioMetaBucket {
+		b = l.getIndex(bucket)
+	}
+	if b == nil {
+		return l.ObjectLayer.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+	}
+	if err := l.checkListArgs(bucket, prefix, marker, delimiter); err != nil {
+		return ListObjectsInfo{}, err
+	}
+	// Over flowing count - reset to maxObjectList.
+	if maxKeys < 0 || maxKeys > maxObjectList {
+		maxKeys = maxObjectList
+	}
+	// For delimiter and prefix as '/' we do not list anything at all.
+	if maxKeys == 0 || (delimiter == slashSeparator && prefix == slashSeparator) {
+		return ListObjectsInfo{}, nil
+	}
+	return b.list(prefix, marker, delimiter, maxKeys, nil), nil
+}
+
+// SearchObjects - lists the objects of the bucket after marker which
+// match the query, from the index of the bucket.
+func (l indexObjects) SearchObjects(bucket string, query objectQuery, marker string, maxKeys int) (ListObjectsInfo, error) {
+	if err := l.checkListArgs(bucket, query.Prefix, marker, ""); err != nil {
+		return ListObjectsInfo{}, err
+	}
+	b := l.getIndex(bucket)
+	if b == nil {
+		return ListObjectsInfo{}, traceError(errIndexNotEnabled)
+	}
+	// Over flowing count - reset to maxObjectList.
+	if maxKeys < 0 || maxKeys > maxObjectList {
+		maxKeys = maxObjectList
+	}
+	if maxKeys == 0 {
+		return ListObjectsInfo{}, nil
+	}
+	return b.list(query.Prefix, marker, "", maxKeys, query.match), nil
+}
+
+// checkListArgs - validates the arguments of a listing, as the object
+// layers do.
+func (l indexObjects) checkListArgs(bucket, prefix, marker, delimiter string) error {
+	if err := isBucketExist(bucket, l.ObjectLayer); err != nil {
+		return traceError(err)
+	}
+	if !IsValidObjectPrefix(prefix) {
+		return traceError(ObjectNameInvalid{Bucket: bucket, Object: prefix})
+	}
+	// Verify if delimiter is anything other than '/', which we do not support.
+	if delimiter != "" && delimiter != slashSeparator {
+		return traceError(UnsupportedDelimiter{
+			Delimiter: delimiter,
+		})
+	}
+	// Verify if marker has prefix.
+	if marker != "" && !strings.HasPrefix(marker, prefix) {
+		return traceError(InvalidMarkerPrefixCombination{
+			Marker: marker,
+			Prefix: prefix,
+		})
+	}
+	return nil
+}
+
+// RebuildIndex - regenerates the index of the bucket from the metadata
+// of all its objects. Writes done meanwhile are applied to the rebuilt
+// index before it replaces the current one.
+func (l indexObjects) RebuildIndex(bucket string) error {
+	if err := isBucketExist(bucket, l.ObjectLayer); err != nil {
+		return traceError(err)
+	}
+
+	b := newBucketIndex()
+	l.mu.Lock()
+	if l.builders[bucket] != nil {
+		l.mu.Unlock()
+		return traceError(errIndexRebuilding)
+	}
+	l.builders[bucket] = b
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		delete(l.builders, bucket)
+		l.mu.Unlock()
+	}()
+
+	// Listings don't carry all the metadata, each object is stat'ed.
+	marker := ""
+	for {
+		result, err := l.ObjectLayer.ListObjects(bucket, "", marker, "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, object := range result.Objects {
+			objInfo, err := l.ObjectLayer.GetObjectInfo(bucket, object.Name)
+			if err != nil {
+				// Deleted since listed.
+				if _, ok := errorCause(err).(ObjectNotFound); ok {
+					continue
+				}
+				return err
+			}
+			b.apply(indexRecord{Op: "put", indexEntry: newIndexEntry(objInfo)})
+		}
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextMarker
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// Writes recorded from now on go to the rebuilt index.
+	if old := l.buckets[bucket]; old != nil {
+		old.mu.Lock()
+		old.closed = true
+		// Writes in flight are recorded in the rebuilt index.
+		for object, count := range old.inflight {
+			b.inflight[object] += count
+		}
+		old.mu.Unlock()
+	}
+	for _, rec := range b.pending {
+		b.apply(rec)
+	}
+	b.pending = nil
+	if err := l.save(bucket, b); err != nil {
+		l.dropLocked(bucket)
+		return traceError(err)
+	}
+	l.buckets[bucket] = b
+	return nil
+}
@@ -0,0 +1,304 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// Tests listings of bucketIndex against the tree walk of the same names.
+func TestBucketIndexList(t *testing.T) {
+	names := []string{"a", "a-b", "a/b", "a/c/d", "a/c/e", "b/x", "ba", "c"}
+	b := newBucketIndex()
+	// Out of order puts, a delete and a stale put.
+	for i := len(names) - 1; i >= 0; i-- {
+		b.apply(indexRecord{Op: "put", indexEntry: indexEntry{Name: names[i], Size: int64(i), ModTime: time.Unix(100, 0)}})
+	}
+	b.apply(indexRecord{Op: "put", indexEntry: indexEntry{Name: "deleted"}})
+	b.apply(indexRecord{Op: "delete", indexEntry: indexEntry{Name: "deleted"}})
+	b.apply(indexRecord{Op: "put", indexEntry: indexEntry{Name: "c", Size: 100, ModTime: time.Unix(50, 0)}})
+	if !reflect.DeepEqual(b.names.all(), names) {
+		t.Fatalf("Expected names %v, got %v", names, b.names.all())
+	}
+	if b.entries["c"].Size != int64(len(names)-1) {
+		t.Fatalf("Stale put was applied")
+	}
+
+	testCases := []struct {
+		prefix, marker, delimiter string
+		maxKeys                   int
+		objects, prefixes         []string
+		nextMarker                string
+		isTruncated               bool
+	}{
+		{"", "", "", 1000, names, nil, "c", false},
+		{"", "", "/", 1000, []string{"a", "a-b", "ba", "c"}, []string{"a/", "b/"}, "c", false},
+		{"", "", "/", 3, []string{"a", "a-b"}, []string{"a/"}, "a/", true},
+		{"", "a/", "/", 3, []string{"ba", "c"}, []string{"b/"}, "c", false},
+		{"a/", "", "/", 1000, []string{"a/b"}, []string{"a/c/"}, "a/c/", false},
+		{"a/c", "", "", 1000, []string{"a/c/d", "a/c/e"}, nil, "a/c/e", false},
+		{"a/c/", "a/c/d", "", 1000, []string{"a/c/e"}, nil, "a/c/e", false},
+		{"b", "", "", 1, []string{"b/x"}, nil, "b/x", true},
+		{"z", "", "", 1000, nil, nil, "", false},
+	}
+	for i, testCase := range testCases {
+		result := b.list(testCase.prefix, testCase.marker, testCase.delimiter, testCase.maxKeys, nil)
+		var objects []string
+		for _, objInfo := range result.Objects {
+			objects = append(objects, objInfo.Name)
+		}
+		if !reflect.DeepEqual(objects, testCase.objects) {
+			t.Errorf("Test %d: expected objects %v, got %v", i+1, testCase.objects, objects)
+		}
+		if !reflect.DeepEqual(result.Prefixes, testCase.prefixes) {
+			t.Errorf("Test %d: expected prefixes %v, got %v", i+1, testCase.prefixes, result.Prefixes)
+		}
+		if result.NextMarker != testCase.nextMarker || result.IsTruncated != testCase.isTruncated {
+			t.Errorf("Test %d: expected next marker %q and truncated %v, got %q and %v", i+1,
+				testCase.nextMarker, testCase.isTruncated, result.NextMarker, result.IsTruncated)
+		}
+	}
+
+	// Index files are loaded back to the same entries.
+	buf, err := b.marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := newBucketIndex()
+	// A partially written last record is ignored, intents followed by
+	// a put or delete are resolved.
+	buf = append(buf, `{"op":"intent","name":"a"}`+"\n"+`{"op":"intent","name":"b/x"}`+"\n"+
+		`{"op":"intent","name":"b/x"}`+"\n"+`{"op":"put","name":"b/x"}`+"\n"+`{"op":"put","na`...)
+	records, intents := loaded.load(buf)
+	if records != len(names)+4 {
+		t.Fatalf("Expected %d records, got %d", len(names)+4, records)
+	}
+	if !reflect.DeepEqual(intents, map[string]int{"a": 1, "b/x": 1}) {
+		t.Fatalf("Expected unresolved intents of a and b/x, got %v", intents)
+	}
+	if !reflect.DeepEqual(loaded.names.all(), names) {
+		t.Fatalf("Expected names %v, got %v", names, loaded.names.all())
+	}
+	for _, name := range names {
+		if !loaded.entries[name].ModTime.Equal(b.entries[name].ModTime) || loaded.entries[name].Size != b.entries[name].Size {
+			t.Fatalf("Entry %s was not loaded back", name)
+		}
+	}
+}
+
+// Tests matching of entries by search queries.
+func TestObjectQueryMatch(t *testing.T) {
+	entry := indexEntry{Name: "photos/beach.jpg", Size: 1024, ModTime: time.Date(2017, 1, 2, 0, 0, 0, 0, time.UTC), ContentType: "image/jpeg"}
+	testCases := []struct {
+		query objectQuery
+		match bool
+	}{
+		{objectQuery{MaxSize: -1}, true},
+		{objectQuery{Suffix: ".jpg", MaxSize: -1}, true},
+		{objectQuery{Suffix: ".png", MaxSize: -1}, false},
+		{objectQuery{ContentType: "image/*", MaxSize: -1}, true},
+		{objectQuery{ContentType: "text/*", MaxSize: -1}, false},
+		{objectQuery{MinSize: 1024, MaxSize: 1024}, true},
+		{objectQuery{MinSize: 1025, MaxSize: -1}, false},
+		{objectQuery{MaxSize: 1023}, false},
+		{objectQuery{ModifiedAfter: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), MaxSize: -1}, true},
+		{objectQuery{ModifiedAfter: entry.ModTime, MaxSize: -1}, false},
+		{objectQuery{ModifiedBefore: time.Date(2017, 1, 3, 0, 0, 0, 0, time.UTC), MaxSize: -1}, true},
+		{objectQuery{ModifiedBefore: entry.ModTime, MaxSize: -1}, false},
+	}
+	for i, testCase := range testCases {
+		if match := testCase.query.match(entry); match != testCase.match {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.match, match)
+		}
+	}
+}
+
+// Tests that sortedNames stays sorted through inserts and removes
+// spanning many chunks.
+func TestSortedNames(t *testing.T) {
+	var s sortedNames
+	expected := make(map[string]bool)
+	for i := 0; i < 10*sortedNamesChunkSize; i++ {
+		name := fmt.Sprintf("%08d", (i*7919)%(10*sortedNamesChunkSize))
+		s.insert(name)
+		expected[name] = true
+		if i%3 == 0 {
+			removed := fmt.Sprintf("%08d", (i*104729)%(10*sortedNamesChunkSize))
+			s.remove(removed)
+			delete(expected, removed)
+		}
+	}
+	var names []string
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(s.all(), names) {
+		t.Fatalf("Expected %d sorted names, got %d", len(names), len(s.all()))
+	}
+	for _, chunk := range s.chunks {
+		if len(chunk) == 0 || len(chunk) >= 2*sortedNamesChunkSize {
+			t.Fatalf("Unexpected chunk of %d names", len(chunk))
+		}
+	}
+	for _, name := range names {
+		if p, ok := s.find(name); !ok || s.at(p) != name {
+			t.Fatalf("Expected to find %s", name)
+		}
+	}
+	if _, ok := s.find("missing"); ok {
+		t.Fatal("Expected not to find missing")
+	}
+}
+
+// Tests writes, listings, searches and rebuilds through the index.
+func TestIndexObjects(t *testing.T) {
+	backend, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(fsDir)
+	indexDirs, err := getRandomDisks(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(indexDirs)
+
+	obj, err := newIndexObjects(backend, indexDirs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = obj.MakeBucket("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	objects := map[string]string{
+		"docs/a.txt":   "text/plain",
+		"docs/b.json":  "application/json",
+		"photos/c.jpg": "image/jpeg",
+		"d.txt":        "text/plain",
+	}
+	for object, contentType := range objects {
+		data := bytes.Repeat([]byte("a"), len(object))
+		if _, err = obj.PutObject("bucket", object, int64(len(data)), bytes.NewReader(data), map[string]string{"content-type": contentType}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = obj.DeleteObject("bucket", "d.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Listings of the index and of the backend are the same.
+	checkListings := func(obj ObjectLayer) {
+		for _, delimiter := range []string{"", "/"} {
+			expected, err := backend.ListObjects("bucket", "", "", delimiter, 1000)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := obj.ListObjects("bucket", "", "", delimiter, 1000)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Objects) != len(expected.Objects) || !reflect.DeepEqual(result.Prefixes, expected.Prefixes) {
+				t.Fatalf("Expected %v, got %v", expected, result)
+			}
+			for i := range expected.Objects {
+				if result.Objects[i].Name != expected.Objects[i].Name || result.Objects[i].Size != expected.Objects[i].Size {
+					t.Fatalf("Expected %v, got %v", expected.Objects[i], result.Objects[i])
+				}
+			}
+		}
+	}
+	checkListings(obj)
+
+	query := objectQuery{ContentType: "text/*", MaxSize: -1}
+	result, err := obj.(objectSearcher).SearchObjects("bucket", query, "", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Objects) != 1 || result.Objects[0].Name != "docs/a.txt" {
+		t.Fatalf("Expected docs/a.txt, got %v", result.Objects)
+	}
+
+	// Indexes are loaded back from the index directory.
+	obj, err = newIndexObjects(backend, indexDirs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkListings(obj)
+
+	// Writes interrupted by a crash are looked up in the backend.
+	if _, err = backend.PutObject("bucket", "crashed.txt", 1, bytes.NewReader([]byte("a")), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = backend.DeleteObject("bucket", "docs/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	intents := []byte(`{"op":"intent","name":"crashed.txt"}` + "\n" + `{"op":"intent","name":"docs/a.txt"}` + "\n")
+	if err = obj.(indexObjects).disk.AppendFile(minioMetaBucket, indexFile("bucket"), intents); err != nil {
+		t.Fatal(err)
+	}
+	obj, err = newIndexObjects(backend, indexDirs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkListings(obj)
+	// Resolved intents are compacted out of the index file.
+	buf, err := obj.(indexObjects).disk.ReadAll(minioMetaBucket, indexFile("bucket"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf, []byte(`"intent"`)) {
+		t.Fatalf("Expected no intents left in the index, got %s", buf)
+	}
+
+	// Buckets created in the backend are indexed once rebuilt.
+	if err = backend.MakeBucket("unindexed"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = backend.PutObject("unindexed", "e.txt", 1, bytes.NewReader([]byte("a")), map[string]string{"content-type": "text/plain"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = obj.(objectSearcher).SearchObjects("unindexed", query, "", 1000); errorCause(err) != errIndexNotEnabled {
+		t.Fatalf("Expected %s, got %s", errIndexNotEnabled, err)
+	}
+	if err = obj.(indexObjects).RebuildIndex("unindexed"); err != nil {
+		t.Fatal(err)
+	}
+	result, err = obj.(objectSearcher).SearchObjects("unindexed", query, "", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Objects) != 1 || result.Objects[0].Name != "e.txt" {
+		t.Fatalf("Expected e.txt, got %v", result.Objects)
+	}
+
+	// Indexes of deleted buckets are removed.
+	if err = obj.DeleteObject("unindexed", "e.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err = obj.DeleteBucket("unindexed"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = obj.(indexObjects).disk.StatFile(minioMetaBucket, indexFile("unindexed")); err != errFileNotFound {
+		t.Fatalf("Expected %s, got %s", errFileNotFound, err)
+	}
+}
@@ -0,0 +1,346 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	mux "github.com/gorilla/mux"
+)
+
+// maximum supported object lock configuration, retention and legal hold size.
+const maxObjectLockConfigSize = 1024 * 1024 // 1MiB.
+
+// readObjectLockXML - reads and unmarshals XML request body.
+func readObjectLockXML(r *http.Request, v interface{}) APIErrorCode {
+	if r.ContentLength == 0 {
+		return ErrMissingRequestBodyError
+	}
+	xmlBytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxObjectLockConfigSize))
+	if err != nil {
+		errorIf(err, "Unable to read from client.")
+		return toAPIErrorCode(err)
+	}
+	if err = xml.Unmarshal(xmlBytes, v); err != nil {
+		return ErrMalformedXML
+	}
+	return ErrNone
+}
+
+// GetBucketObjectLockConfigHandler - GET Bucket object-lock
+// -----------------
+// This operation uses the object-lock subresource to return the
+// object lock configuration of a bucket.
+func (api objectAPIHandlers) GetBucketObjectLockConfigHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if s3Error := checkAuthWithPolicy(r, bucket, "s3:GetBucketObjectLockConfiguration"); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+	if err := isBucketExist(bucket, objAPI); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	config, err := readObjectLockConfig(bucket, objAPI)
+	if err != nil {
+		if err == errObjectLockNotEnabled {
+			writeErrorResponse(w, r, ErrObjectLockConfigurationNotFound, r.URL.Path)
+			return
+		}
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	encodedSuccessResponse := encodeResponse(config)
+	setCommonHeaders(w)
+	writeSuccessResponse(w, encodedSuccessResponse)
+}
+
+// PutBucketObjectLockConfigHandler - PUT Bucket object-lock
+// -----------------
+// This operation uses the object-lock subresource to enable object
+// lock on a bucket and to set its default retention.
+func (api objectAPIHandlers) PutBucketObjectLockConfigHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if s3Error := checkAuthWithPolicy(r, bucket, "s3:PutBucketObjectLockConfiguration"); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+	if err := isBucketExist(bucket, objAPI); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	config := &ObjectLockConfiguration{}
+	if s3Error := readObjectLockXML(r, config); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+	if s3Error := validateObjectLockConfig(config); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if err := writeObjectLockConfig(bucket, config, objAPI); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Success.
+	writeSuccessResponse(w, nil)
+}
+
+// getLockedObjectInfo - returns the object info of an object in a
+// bucket with object lock enabled.
+func getLockedObjectInfo(objAPI ObjectLayer, bucket, object string) (ObjectInfo, APIErrorCode) {
+	if _, err := readObjectLockConfig(bucket, objAPI); err != nil {
+		if err == errObjectLockNotEnabled {
+			return ObjectInfo{}, ErrObjectLockNotEnabled
+		}
+		return ObjectInfo{}, toAPIErrorCode(err)
+	}
+	objInfo, err := objAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		errorIf(err, "Unable to fetch object info.")
+		return ObjectInfo{}, toAPIErrorCode(err)
+	}
+	return objInfo, ErrNone
+}
+
+// GetObjectRetentionHandler - GET Object retention
+// -----------------
+// This operation uses the retention subresource to return the
+// retention of an object.
+func (api objectAPIHandlers) GetObjectRetentionHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	if s3Error := checkAuthWithPolicy(r, bucket, "s3:GetObjectRetention"); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	objInfo, s3Error := getLockedObjectInfo(objAPI, bucket, object)
+	if s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	l := getObjectLock(objInfo.UserDefined)
+	if l.Mode == "" {
+		writeErrorResponse(w, r, ErrNoSuchObjectLockConfiguration, r.URL.Path)
+		return
+	}
+	retention := ObjectRetention{
+		Mode:            l.Mode,
+		RetainUntilDate: l.RetainUntil.UTC().Format(time.RFC3339),
+	}
+	encodedSuccessResponse := encodeResponse(retention)
+	setCommonHeaders(w)
+	writeSuccessResponse(w, encodedSuccessResponse)
+}
+
+// PutObjectRetentionHandler - PUT Object retention
+// -----------------
+// This operation uses the retention subresource to set the retention
+// of an object. Compliance retention can only be extended, governance
+// retention can be shortened or removed when it is bypassed.
+func (api objectAPIHandlers) PutObjectRetentionHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	if s3Error := checkAuthWithPolicy(r, bucket, "s3:PutObjectRetention"); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	retention := ObjectRetention{}
+	if s3Error := readObjectLockXML(r, &retention); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+	if !isValidObjectLockMode(retention.Mode) {
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+	retainUntil, err := time.Parse(time.RFC3339, retention.RetainUntilDate)
+	if err != nil {
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+	now := time.Now().UTC()
+	if !retainUntil.After(now) {
+		writeErrorResponse(w, r, ErrPastObjectLockRetainDate, r.URL.Path)
+		return
+	}
+
+	objInfo, s3Error := getLockedObjectInfo(objAPI, bucket, object)
+	if s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	l := getObjectLock(objInfo.UserDefined)
+	if l.isRetained(now) {
+		// Changing the mode or shortening the retention weakens it.
+		weakened := l.Mode != retention.Mode || retainUntil.Before(l.RetainUntil)
+		if weakened && (l.Mode == objectLockCompliance || !isGovernanceBypassed(r)) {
+			writeErrorResponse(w, r, ErrObjectLocked, r.URL.Path)
+			return
+		}
+	}
+	l.Mode, l.RetainUntil = retention.Mode, retainUntil
+
+	metadata := make(map[string]string)
+	for k, v := range objInfo.UserDefined {
+		metadata[k] = v
+	}
+	setObjectLockMetadata(metadata, l)
+	if err = updateObjectMetadata(objAPI, objInfo, metadata); err != nil {
+		errorIf(err, "Unable to update object retention.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Success.
+	writeSuccessResponse(w, nil)
+}
+
+// GetObjectLegalHoldHandler - GET Object legal-hold
+// -----------------
+// This operation uses the legal-hold subresource to return the legal
+// hold status of an object.
+func (api objectAPIHandlers) GetObjectLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	if s3Error := checkAuthWithPolicy(r, bucket, "s3:GetObjectLegalHold"); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	objInfo, s3Error := getLockedObjectInfo(objAPI, bucket, object)
+	if s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	legalHold := ObjectLegalHold{Status: legalHoldOff}
+	if getObjectLock(objInfo.UserDefined).LegalHold {
+		legalHold.Status = legalHoldOn
+	}
+	encodedSuccessResponse := encodeResponse(legalHold)
+	setCommonHeaders(w)
+	writeSuccessResponse(w, encodedSuccessResponse)
+}
+
+// PutObjectLegalHoldHandler - PUT Object legal-hold
+// -----------------
+// This operation uses the legal-hold subresource to place or remove a
+// legal hold on an object. Objects under legal hold cannot be deleted
+// or overwritten irrespective of their retention.
+func (api objectAPIHandlers) PutObjectLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	if s3Error := checkAuthWithPolicy(r, bucket, "s3:PutObjectLegalHold"); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	legalHold := ObjectLegalHold{}
+	if s3Error := readObjectLockXML(r, &legalHold); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+	if legalHold.Status != legalHoldOn && legalHold.Status != legalHoldOff {
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+
+	objInfo, s3Error := getLockedObjectInfo(objAPI, bucket, object)
+	if s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	l := getObjectLock(objInfo.UserDefined)
+	l.LegalHold = legalHold.Status == legalHoldOn
+
+	metadata := make(map[string]string)
+	for k, v := range objInfo.UserDefined {
+		metadata[k] = v
+	}
+	setObjectLockMetadata(metadata, l)
+	if err := updateObjectMetadata(objAPI, objInfo, metadata); err != nil {
+		errorIf(err, "Unable to update object legal hold.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Success.
+	writeSuccessResponse(w, nil)
+}
@@ -0,0 +1,161 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Wrapper for calling object lock handler tests for both XL multiple disks and single node setup.
+func TestObjectLockHandlers(t *testing.T) {
+	ExecObjectLayerTest(t, testObjectLockHandlers)
+}
+
+// testObjectLockHandlers - Tests validate object lock configuration,
+// retention and legal hold, and that locked objects are neither
+// deleted nor overwritten.
+func testObjectLockHandlers(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucketName := getRandomBucketName()
+	if err := obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+	if _, err := obj.PutObject(bucketName, "unlocked", int64(len("hello")), bytes.NewReader([]byte("hello")), nil); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+
+	// Register all the API end points.
+	apiRouter := initTestAPIEndPoints(obj, []string{"All"})
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+
+	future := time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+	past := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	lockConfig := `<ObjectLockConfiguration><ObjectLockEnabled>Enabled</ObjectLockEnabled><Rule><DefaultRetention><Mode>GOVERNANCE</Mode><Days>1</Days></DefaultRetention></Rule></ObjectLockConfiguration>`
+	retention := func(mode, date string) []byte {
+		return []byte(fmt.Sprintf("<Retention><Mode>%s</Mode><RetainUntilDate>%s</RetainUntilDate></Retention>", mode, date))
+	}
+	legalHold := func(status string) []byte {
+		return []byte(fmt.Sprintf("<LegalHold><Status>%s</Status></LegalHold>", status))
+	}
+	bypass := map[string]string{"X-Amz-Bypass-Governance-Retention": "true"}
+
+	testCases := []struct {
+		method  string
+		url     string
+		headers map[string]string
+		body    []byte
+		// expected Response.
+		expectedRespStatus int
+	}{
+		// Test cases 1-2.
+		// Object lock is not enabled on the bucket.
+		{"GET", getObjectLockConfigURL("", bucketName), nil, nil, http.StatusNotFound},
+		{"PUT", getObjectRetentionURL("", bucketName, "unlocked"), nil, retention(objectLockGovernance, future), http.StatusBadRequest},
+		// Test cases 3-5.
+		// Enable object lock with a default governance retention.
+		{"PUT", getObjectLockConfigURL("", bucketName), nil, []byte(`<ObjectLockConfiguration><ObjectLockEnabled>Disabled</ObjectLockEnabled></ObjectLockConfiguration>`), http.StatusBadRequest},
+		{"PUT", getObjectLockConfigURL("", bucketName), nil, []byte(lockConfig), http.StatusOK},
+		{"GET", getObjectLockConfigURL("", bucketName), nil, nil, http.StatusOK},
+		// Test cases 6-7.
+		// Objects uploaded before enabling object lock are not retained.
+		{"GET", getObjectRetentionURL("", bucketName, "unlocked"), nil, nil, http.StatusNotFound},
+		{"DELETE", getDeleteObjectURL("", bucketName, "unlocked"), nil, nil, http.StatusNoContent},
+		// Test cases 8-11.
+		// New objects get the default retention, they can only be
+		// deleted or overwritten when governance is bypassed.
+		{"PUT", getPutObjectURL("", bucketName, "governance"), nil, []byte("hello"), http.StatusOK},
+		{"GET", getObjectRetentionURL("", bucketName, "governance"), nil, nil, http.StatusOK},
+		{"PUT", getPutObjectURL("", bucketName, "governance"), nil, []byte("hello"), http.StatusForbidden},
+		{"DELETE", getDeleteObjectURL("", bucketName, "governance"), nil, nil, http.StatusForbidden},
+		// Test cases 12-15.
+		// Legal hold cannot be bypassed.
+		{"PUT", getObjectLegalHoldURL("", bucketName, "governance"), nil, legalHold(legalHoldOn), http.StatusOK},
+		{"DELETE", getDeleteObjectURL("", bucketName, "governance"), bypass, nil, http.StatusForbidden},
+		{"PUT", getObjectLegalHoldURL("", bucketName, "governance"), nil, legalHold(legalHoldOff), http.StatusOK},
+		{"DELETE", getDeleteObjectURL("", bucketName, "governance"), bypass, nil, http.StatusNoContent},
+		// Test cases 16-19.
+		// Compliance retention cannot be bypassed nor shortened.
+		{"PUT", getPutObjectURL("", bucketName, "compliance"), map[string]string{objectLockModeKey: objectLockCompliance, objectLockRetainUntilKey: future}, []byte("hello"), http.StatusOK},
+		{"PUT", getObjectRetentionURL("", bucketName, "compliance"), bypass, retention(objectLockGovernance, future), http.StatusForbidden},
+		{"DELETE", getDeleteObjectURL("", bucketName, "compliance"), bypass, nil, http.StatusForbidden},
+		{"PUT", getPutObjectURL("", bucketName, "compliance"), bypass, []byte("hello"), http.StatusForbidden},
+		// Test cases 20-21.
+		// Invalid retention headers.
+		{"PUT", getPutObjectURL("", bucketName, "past"), map[string]string{objectLockModeKey: objectLockCompliance, objectLockRetainUntilKey: past}, []byte("hello"), http.StatusBadRequest},
+		{"PUT", getPutObjectURL("", bucketName, "invalid"), map[string]string{objectLockModeKey: objectLockCompliance}, []byte("hello"), http.StatusBadRequest},
+		// Test case 22.
+		// Object lock configuration cannot be read anonymously.
+		{"GET", getObjectLockConfigURL("", bucketName), nil, nil, http.StatusForbidden},
+	}
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		var req *http.Request
+		if i == len(testCases)-1 {
+			req, err = newTestRequest(testCase.method, testCase.url, int64(len(testCase.body)), bytes.NewReader(testCase.body))
+		} else {
+			req, err = newTestSignedRequest(testCase.method, testCase.url, int64(len(testCase.body)), bytes.NewReader(testCase.body),
+				credentials.AccessKeyID, credentials.SecretAccessKey)
+		}
+		if err != nil {
+			t.Fatalf("Test %d: %s: Failed to create HTTP request: <ERROR> %v", i+1, instanceType, err)
+		}
+		for k, v := range testCase.headers {
+			// Header is not signed, not part of the signature v4 canonical headers for tests.
+			req.Header.Set(k, v)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedRespStatus {
+			t.Errorf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, testCase.expectedRespStatus, rec.Code)
+		}
+	}
+
+	// Completing a multipart upload cannot overwrite a locked object.
+	uploadID, err := obj.NewMultipartUpload(bucketName, "compliance", nil)
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	md5Sum, err := obj.PutObjectPart(bucketName, "compliance", uploadID, 1, int64(len("hello")), bytes.NewReader([]byte("hello")), "")
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	completeBytes, err := xml.Marshal(&completeMultipartUpload{Parts: []completePart{{PartNumber: 1, ETag: md5Sum}}})
+	if err != nil {
+		t.Fatalf("%s: Error XML encoding of parts: <ERROR> %v", instanceType, err)
+	}
+	req, err := newTestSignedRequest("POST", getCompleteMultipartUploadURL("", bucketName, "compliance", uploadID),
+		int64(len(completeBytes)), bytes.NewReader(completeBytes), credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+	}
+	rec := httptest.NewRecorder()
+	apiRouter.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("%s: Expected the response status to be `%d`, but instead found `%d`", instanceType, http.StatusForbidden, rec.Code)
+	}
+}
@@ -0,0 +1,314 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"path"
+	"time"
+)
+
+const (
+	// Object lock configuration of a bucket is saved as
+	// 'buckets/<bucket>/object-lock.xml' in the meta bucket.
+	bucketObjectLockConfig = "object-lock.xml"
+
+	// Retention and legal hold of an object are saved in its
+	// metadata, sent back as headers same as AWS S3.
+	objectLockModeKey        = "X-Amz-Object-Lock-Mode"
+	objectLockRetainUntilKey = "X-Amz-Object-Lock-Retain-Until-Date"
+	objectLockLegalHoldKey   = "X-Amz-Object-Lock-Legal-Hold"
+
+	// Retention modes.
+	objectLockGovernance = "GOVERNANCE"
+	objectLockCompliance = "COMPLIANCE"
+
+	// Legal hold status.
+	legalHoldOn  = "ON"
+	legalHoldOff = "OFF"
+)
+
+// errObjectLockNotEnabled - object lock is not enabled on the bucket.
+var errObjectLockNotEnabled = errors.New("Object lock is not enabled on the bucket")
+
+// DefaultRetention - retention applied to new objects which do not
+// set their own, for either Days or Years.
+type DefaultRetention struct {
+	Mode  string
+	Days  int `xml:"Days,omitempty"`
+	Years int `xml:"Years,omitempty"`
+}
+
+// ObjectLockConfiguration - bucket object lock configuration, the
+// default retention rule is optional.
+type ObjectLockConfiguration struct {
+	XMLName           xml.Name `xml:"ObjectLockConfiguration"`
+	ObjectLockEnabled string
+	Rule              *struct {
+		DefaultRetention DefaultRetention
+	} `xml:"Rule,omitempty"`
+}
+
+// ObjectRetention - retention of an object.
+type ObjectRetention struct {
+	XMLName         xml.Name `xml:"Retention"`
+	Mode            string
+	RetainUntilDate string
+}
+
+// ObjectLegalHold - legal hold of an object.
+type ObjectLegalHold struct {
+	XMLName xml.Name `xml:"LegalHold"`
+	Status  string
+}
+
+// isValidObjectLockMode - is the retention mode supported.
+func isValidObjectLockMode(mode string) bool {
+	return mode == objectLockGovernance || mode == objectLockCompliance
+}
+
+// validateObjectLockConfig - validates object lock configuration, object
+// lock cannot be disabled once enabled.
+func validateObjectLockConfig(config *ObjectLockConfiguration) APIErrorCode {
+	if config.ObjectLockEnabled != "Enabled" {
+		return ErrMalformedXML
+	}
+	if config.Rule == nil {
+		return ErrNone
+	}
+	retention := config.Rule.DefaultRetention
+	if !isValidObjectLockMode(retention.Mode) {
+		return ErrMalformedXML
+	}
+	// Only one of Days and Years should be set.
+	if (retention.Days > 0) == (retention.Years > 0) || retention.Days < 0 || retention.Years < 0 {
+		return ErrMalformedXML
+	}
+	return ErrNone
+}
+
+// readObjectLockConfig - reads object lock configuration of the bucket,
+// returns errObjectLockNotEnabled if object lock is not enabled.
+func readObjectLockConfig(bucket string, objAPI ObjectLayer) (*ObjectLockConfiguration, error) {
+	configPath := path.Join(bucketConfigPrefix, bucket, bucketObjectLockConfig)
+	objInfo, err := objAPI.GetObjectInfo(minioMetaBucket, configPath)
+	err = errorCause(err)
+	if err != nil {
+		if _, ok := err.(ObjectNotFound); ok {
+			return nil, errObjectLockNotEnabled
+		}
+		errorIf(err, "Unable to load object lock configuration for bucket %s", bucket)
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	err = objAPI.GetObject(minioMetaBucket, configPath, 0, objInfo.Size, &buffer)
+	err = errorCause(err)
+	if err != nil {
+		if _, ok := err.(ObjectNotFound); ok {
+			return nil, errObjectLockNotEnabled
+		}
+		errorIf(err, "Unable to load object lock configuration for bucket %s", bucket)
+		return nil, err
+	}
+
+	config := &ObjectLockConfiguration{}
+	if err = xml.Unmarshal(buffer.Bytes(), config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// writeObjectLockConfig - saves object lock configuration of the bucket.
+func writeObjectLockConfig(bucket string, config *ObjectLockConfiguration, objAPI ObjectLayer) error {
+	configBytes, err := xml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	configPath := path.Join(bucketConfigPrefix, bucket, bucketObjectLockConfig)
+	if _, err = objAPI.PutObject(minioMetaBucket, configPath, int64(len(configBytes)), bytes.NewReader(configBytes), nil); err != nil {
+		errorIf(err, "Unable to write object lock configuration for bucket %s", bucket)
+		return errorCause(err)
+	}
+	return nil
+}
+
+// removeObjectLockConfig - removes object lock configuration of the bucket.
+func removeObjectLockConfig(bucket string, objAPI ObjectLayer) error {
+	configPath := path.Join(bucketConfigPrefix, bucket, bucketObjectLockConfig)
+	return objAPI.DeleteObject(minioMetaBucket, configPath)
+}
+
+// objectLock - retention and legal hold of an object.
+type objectLock struct {
+	Mode        string
+	RetainUntil time.Time
+	LegalHold   bool
+}
+
+// isRetained - is the object under retention at the given time.
+func (l objectLock) isRetained(now time.Time) bool {
+	return l.Mode != "" && now.Before(l.RetainUntil)
+}
+
+// getObjectLock - reads retention and legal hold from object metadata.
+func getObjectLock(metadata map[string]string) objectLock {
+	l := objectLock{
+		Mode:      metadata[objectLockModeKey],
+		LegalHold: metadata[objectLockLegalHoldKey] == legalHoldOn,
+	}
+	if l.Mode != "" {
+		retainUntil, err := time.Parse(time.RFC3339, metadata[objectLockRetainUntilKey])
+		if err != nil {
+			// Corrupted retention date, err on the side of
+			// keeping the object.
+			retainUntil = time.Unix(1<<62, 0)
+		}
+		l.RetainUntil = retainUntil
+	}
+	return l
+}
+
+// setObjectLockMetadata - saves retention and legal hold in object
+// metadata, unset values are removed.
+func setObjectLockMetadata(metadata map[string]string, l objectLock) {
+	delete(metadata, objectLockModeKey)
+	delete(metadata, objectLockRetainUntilKey)
+	delete(metadata, objectLockLegalHoldKey)
+	if l.Mode != "" {
+		metadata[objectLockModeKey] = l.Mode
+		metadata[objectLockRetainUntilKey] = l.RetainUntil.UTC().Format(time.RFC3339)
+	}
+	if l.LegalHold {
+		metadata[objectLockLegalHoldKey] = legalHoldOn
+	}
+}
+
+// parseObjectLockHeaders - reads retention and legal hold of a new
+// object from request headers, the default retention of the bucket
+// applies when no retention is set. config is nil when object lock is
+// not enabled on the bucket.
+func parseObjectLockHeaders(header http.Header, config *ObjectLockConfiguration, now time.Time) (objectLock, APIErrorCode) {
+	mode := header.Get(objectLockModeKey)
+	retainUntilDate := header.Get(objectLockRetainUntilKey)
+	legalHold := header.Get(objectLockLegalHoldKey)
+
+	l := objectLock{}
+	if mode == "" && retainUntilDate == "" && legalHold == "" {
+		if config != nil && config.Rule != nil {
+			retention := config.Rule.DefaultRetention
+			l.Mode = retention.Mode
+			l.RetainUntil = now.AddDate(retention.Years, 0, retention.Days)
+		}
+		return l, ErrNone
+	}
+	if config == nil {
+		return l, ErrObjectLockNotEnabled
+	}
+
+	// Mode and retain until date are set together.
+	if (mode == "") != (retainUntilDate == "") {
+		return l, ErrObjectLockInvalidHeaders
+	}
+	if mode != "" {
+		if !isValidObjectLockMode(mode) {
+			return l, ErrObjectLockInvalidHeaders
+		}
+		retainUntil, err := time.Parse(time.RFC3339, retainUntilDate)
+		if err != nil {
+			return l, ErrObjectLockInvalidHeaders
+		}
+		if !retainUntil.After(now) {
+			return l, ErrPastObjectLockRetainDate
+		}
+		l.Mode, l.RetainUntil = mode, retainUntil
+	}
+	switch legalHold {
+	case "", legalHoldOff:
+	case legalHoldOn:
+		l.LegalHold = true
+	default:
+		return l, ErrObjectLockInvalidHeaders
+	}
+	return l, ErrNone
+}
+
+// isGovernanceBypassed - governance retention may be bypassed by
+// authenticated requests with 'x-amz-bypass-governance-retention'.
+func isGovernanceBypassed(r *http.Request) bool {
+	return getRequestAuthType(r) != authTypeAnonymous &&
+		r.Header.Get("X-Amz-Bypass-Governance-Retention") == "true"
+}
+
+// checkObjectLock - returns ObjectLocked if the object cannot be
+// deleted or overwritten at the given time.
+func checkObjectLock(bucket, object string, l objectLock, bypassGovernance bool, now time.Time) error {
+	if l.LegalHold {
+		return ObjectLocked{Bucket: bucket, Object: object}
+	}
+	if !l.isRetained(now) {
+		return nil
+	}
+	if l.Mode == objectLockGovernance && bypassGovernance {
+		return nil
+	}
+	return ObjectLocked{Bucket: bucket, Object: object}
+}
+
+// enforceObjectLock - verifies if the existing object, if any, can be
+// deleted or overwritten by the request.
+func enforceObjectLock(objAPI ObjectLayer, bucket, object string, r *http.Request) error {
+	// Objects are only ever locked in buckets with object lock enabled.
+	if _, err := readObjectLockConfig(bucket, objAPI); err != nil {
+		if err == errObjectLockNotEnabled {
+			return nil
+		}
+		return err
+	}
+	objInfo, err := objAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		switch errorCause(err).(type) {
+		case ObjectNotFound, ObjectNameInvalid:
+			// Nothing to protect.
+			return nil
+		}
+		return err
+	}
+	return checkObjectLock(bucket, object, getObjectLock(objInfo.UserDefined), isGovernanceBypassed(r), time.Now().UTC())
+}
+
+// applyObjectLock - sets retention and legal hold of the new object in
+// its metadata and verifies the existing object can be overwritten.
+func applyObjectLock(objAPI ObjectLayer, bucket, object string, r *http.Request, metadata map[string]string) APIErrorCode {
+	config, err := readObjectLockConfig(bucket, objAPI)
+	if err != nil && err != errObjectLockNotEnabled {
+		return toAPIErrorCode(err)
+	}
+	l, s3Error := parseObjectLockHeaders(r.Header, config, time.Now().UTC())
+	if s3Error != ErrNone {
+		return s3Error
+	}
+	if config != nil {
+		if err = enforceObjectLock(objAPI, bucket, object, r); err != nil {
+			return toAPIErrorCode(err)
+		}
+	}
+	setObjectLockMetadata(metadata, l)
+	return ErrNone
+}
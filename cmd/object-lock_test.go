@@ -0,0 +1,142 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Tests validate bucket object lock configurations.
+func TestValidateObjectLockConfig(t *testing.T) {
+	withRule := func(mode string, days, years int) *ObjectLockConfiguration {
+		config := &ObjectLockConfiguration{ObjectLockEnabled: "Enabled"}
+		config.Rule = &struct {
+			DefaultRetention DefaultRetention
+		}{DefaultRetention{Mode: mode, Days: days, Years: years}}
+		return config
+	}
+	testCases := []struct {
+		config      *ObjectLockConfiguration
+		expectedErr APIErrorCode
+	}{
+		{&ObjectLockConfiguration{ObjectLockEnabled: "Enabled"}, ErrNone},
+		{&ObjectLockConfiguration{ObjectLockEnabled: "Disabled"}, ErrMalformedXML},
+		{withRule(objectLockGovernance, 1, 0), ErrNone},
+		{withRule(objectLockCompliance, 0, 1), ErrNone},
+		// Only one of days and years.
+		{withRule(objectLockGovernance, 1, 1), ErrMalformedXML},
+		{withRule(objectLockGovernance, 0, 0), ErrMalformedXML},
+		{withRule(objectLockGovernance, -1, 0), ErrMalformedXML},
+		// Unknown mode.
+		{withRule("WORM", 1, 0), ErrMalformedXML},
+	}
+	for i, testCase := range testCases {
+		if s3Error := validateObjectLockConfig(testCase.config); s3Error != testCase.expectedErr {
+			t.Errorf("Test %d: Expected %d, but found %d", i+1, testCase.expectedErr, s3Error)
+		}
+	}
+}
+
+// Tests validate retention and legal hold parsed from request headers.
+func TestParseObjectLockHeaders(t *testing.T) {
+	now := time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC)
+	future := now.Add(time.Hour).Format(time.RFC3339)
+	past := now.Add(-time.Hour).Format(time.RFC3339)
+
+	config := &ObjectLockConfiguration{ObjectLockEnabled: "Enabled"}
+	defaultConfig := &ObjectLockConfiguration{ObjectLockEnabled: "Enabled"}
+	defaultConfig.Rule = &struct {
+		DefaultRetention DefaultRetention
+	}{DefaultRetention{Mode: objectLockCompliance, Days: 2}}
+
+	testCases := []struct {
+		headers     map[string]string
+		config      *ObjectLockConfiguration
+		expected    objectLock
+		expectedErr APIErrorCode
+	}{
+		// Test cases 1-3.
+		// No headers, default retention applies if set.
+		{nil, nil, objectLock{}, ErrNone},
+		{nil, config, objectLock{}, ErrNone},
+		{nil, defaultConfig, objectLock{Mode: objectLockCompliance, RetainUntil: now.AddDate(0, 0, 2)}, ErrNone},
+		// Test case 4.
+		// Headers need object lock enabled on the bucket.
+		{map[string]string{objectLockLegalHoldKey: legalHoldOn}, nil, objectLock{}, ErrObjectLockNotEnabled},
+		// Test cases 5-6.
+		// Retention from headers overrides the default.
+		{map[string]string{objectLockModeKey: objectLockGovernance, objectLockRetainUntilKey: future}, defaultConfig,
+			objectLock{Mode: objectLockGovernance, RetainUntil: now.Add(time.Hour)}, ErrNone},
+		{map[string]string{objectLockLegalHoldKey: legalHoldOn}, config, objectLock{LegalHold: true}, ErrNone},
+		// Test cases 7-10.
+		// Invalid headers.
+		{map[string]string{objectLockModeKey: objectLockGovernance}, config, objectLock{}, ErrObjectLockInvalidHeaders},
+		{map[string]string{objectLockModeKey: "WORM", objectLockRetainUntilKey: future}, config, objectLock{}, ErrObjectLockInvalidHeaders},
+		{map[string]string{objectLockLegalHoldKey: "MAYBE"}, config, objectLock{}, ErrObjectLockInvalidHeaders},
+		{map[string]string{objectLockModeKey: objectLockGovernance, objectLockRetainUntilKey: past}, config, objectLock{}, ErrPastObjectLockRetainDate},
+	}
+	for i, testCase := range testCases {
+		header := http.Header{}
+		for k, v := range testCase.headers {
+			header.Set(k, v)
+		}
+		l, s3Error := parseObjectLockHeaders(header, testCase.config, now)
+		if s3Error != testCase.expectedErr {
+			t.Errorf("Test %d: Expected %d, but found %d", i+1, testCase.expectedErr, s3Error)
+			continue
+		}
+		if s3Error == ErrNone && (l.Mode != testCase.expected.Mode || !l.RetainUntil.Equal(testCase.expected.RetainUntil) ||
+			l.LegalHold != testCase.expected.LegalHold) {
+			t.Errorf("Test %d: Expected %#v, but found %#v", i+1, testCase.expected, l)
+		}
+	}
+}
+
+// Tests validate when locked objects can be deleted or overwritten.
+func TestCheckObjectLock(t *testing.T) {
+	now := time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		lock             objectLock
+		bypassGovernance bool
+		locked           bool
+	}{
+		{objectLock{}, false, false},
+		{objectLock{Mode: objectLockGovernance, RetainUntil: now.Add(time.Hour)}, false, true},
+		{objectLock{Mode: objectLockGovernance, RetainUntil: now.Add(time.Hour)}, true, false},
+		{objectLock{Mode: objectLockCompliance, RetainUntil: now.Add(time.Hour)}, true, true},
+		// Retention expired.
+		{objectLock{Mode: objectLockCompliance, RetainUntil: now.Add(-time.Hour)}, false, false},
+		// Legal hold cannot be bypassed.
+		{objectLock{LegalHold: true}, true, true},
+	}
+	for i, testCase := range testCases {
+		err := checkObjectLock("bucket", "object", testCase.lock, testCase.bypassGovernance, now)
+		if _, locked := err.(ObjectLocked); locked != testCase.locked {
+			t.Errorf("Test %d: Expected locked to be %t, but found %v", i+1, testCase.locked, err)
+		}
+	}
+
+	// Retention and legal hold round trip through object metadata.
+	l := objectLock{Mode: objectLockGovernance, RetainUntil: now.Add(time.Hour), LegalHold: true}
+	metadata := map[string]string{objectLockLegalHoldKey: legalHoldOff}
+	setObjectLockMetadata(metadata, l)
+	if got := getObjectLock(metadata); got.Mode != l.Mode || !got.RetainUntil.Equal(l.RetainUntil) || !got.LegalHold {
+		t.Errorf("Expected %#v, but found %#v", l, got)
+	}
+}
@@ -35,6 +35,8 @@ const (
 	mpartMetaPrefix = "multipart"
 	// Tmp meta prefix.
 	tmpMetaPrefix = "tmp"
+	// Lock files meta prefix, used by servers sharing a disk.
+	locksMetaPrefix = "locks"
 )
 
 // validBucket regexp.
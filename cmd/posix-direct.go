@@ -0,0 +1,136 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/mf-00/minio/pkg/bpool"
+)
+
+const (
+	// Alignment of the buffers, offsets and sizes of direct writes.
+	directIOAlignSize = 4096
+	// Size of the buffers direct writes are staged in.
+	directIOBufSize = 4 * 1024 * 1024 // 4MiB.
+)
+
+// errDirectIONotSupported - direct I/O is not supported on this platform.
+var errDirectIONotSupported = errors.New("Direct I/O is not supported.")
+
+// directIOBufPool - pool of the staging buffers of direct writes, with
+// room to align them.
+var directIOBufPool = bpool.NewBytePool(directIOBufSize+directIOAlignSize, 64)
+
+// noWaitCh - closed, files are not kept waiting for a staging buffer
+// while other files are written.
+var noWaitCh = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// directFileWriter - writer of a file opened with direct I/O. Writes are
+// staged in an aligned buffer and written to the file whenever it is
+// full, the unaligned end of the file is written on Close once direct
+// I/O is turned off.
+type directFileWriter struct {
+	disk *posix
+	file *os.File
+
+	poolBuf []byte // as returned by the pool.
+	buf     []byte // aligned part of poolBuf.
+	n       int    // bytes staged in buf.
+}
+
+// newDirectFileWriter - returns a writer of the file staged in a buffer
+// of the pool, fails with bpool.ErrGetAborted if none is free.
+func newDirectFileWriter(disk *posix, file *os.File) (*directFileWriter, error) {
+	poolBuf, err := directIOBufPool.Get(noWaitCh)
+	if err != nil {
+		return nil, err
+	}
+	return &directFileWriter{
+		disk:    disk,
+		file:    file,
+		poolBuf: poolBuf,
+		buf:     alignedBuf(poolBuf, directIOBufSize),
+	}, nil
+}
+
+// alignedBuf - returns size bytes of buf starting at an address aligned
+// for direct I/O, buf must have directIOAlignSize bytes to spare.
+func alignedBuf(buf []byte, size int) []byte {
+	offset := 0
+	if rem := int(uintptr(unsafe.Pointer(&buf[0])) & (directIOAlignSize - 1)); rem != 0 {
+		offset = directIOAlignSize - rem
+	}
+	return buf[offset : offset+size]
+}
+
+func (w *directFileWriter) Write(p []byte) (int, error) {
+	if w.disk.ioErrCount > maxAllowedIOError {
+		return 0, errFaultyDisk
+	}
+	written := 0
+	for len(p) > 0 {
+		m := copy(w.buf[w.n:], p)
+		w.n += m
+		p = p[m:]
+		written += m
+		if w.n == len(w.buf) {
+			if err := w.flush(w.n); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// flush - writes the first n staged bytes to the file.
+func (w *directFileWriter) flush(n int) error {
+	if _, err := w.file.Write(w.buf[:n]); err != nil {
+		if isSysErrIO(err) {
+			atomic.AddInt32(&w.disk.ioErrCount, 1)
+		}
+		return err
+	}
+	w.n = copy(w.buf, w.buf[n:w.n])
+	return nil
+}
+
+// Close - writes the staged data and closes the file.
+func (w *directFileWriter) Close() (err error) {
+	defer directIOBufPool.Put(w.poolBuf)
+
+	// Aligned data is still written directly.
+	if aligned := w.n - w.n%directIOAlignSize; aligned > 0 {
+		err = w.flush(aligned)
+	}
+	if err == nil && w.n > 0 {
+		if err = disableDirectIO(w.file); err == nil {
+			err = w.flush(w.n)
+		}
+	}
+	if cErr := w.file.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
@@ -0,0 +1,43 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// openFileDirect - opens the file with O_DIRECT, its writes bypass the
+// page cache.
+func openFileDirect(path string, flag int, perm os.FileMode) (*os.File, error) {
+	return os.OpenFile(path, flag|syscall.O_DIRECT, perm)
+}
+
+// disableDirectIO - turns off O_DIRECT on the file, so that data not
+// aligned for direct I/O can be written.
+func disableDirectIO(file *os.File) error {
+	fd := file.Fd()
+	flag, _, errno := syscall.Syscall(syscall.SYS_FCNTL, fd, syscall.F_GETFL, 0)
+	if errno != 0 {
+		return os.NewSyscallError("fcntl", errno)
+	}
+	_, _, errno = syscall.Syscall(syscall.SYS_FCNTL, fd, syscall.F_SETFL, flag&^syscall.O_DIRECT)
+	if errno != 0 {
+		return os.NewSyscallError("fcntl", errno)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+)
+
+// Tests alignedBuf() returns aligned slices of the requested size.
+func TestAlignedBuf(t *testing.T) {
+	buf := make([]byte, directIOAlignSize*3)
+	for offset := 0; offset <= directIOAlignSize; offset++ {
+		aligned := alignedBuf(buf[offset:], directIOAlignSize)
+		if len(aligned) != directIOAlignSize {
+			t.Fatalf("Offset %d: expected %d bytes, got %d", offset, directIOAlignSize, len(aligned))
+		}
+		if addr := uintptr(unsafe.Pointer(&aligned[0])); addr%directIOAlignSize != 0 {
+			t.Fatalf("Offset %d: address %x is not aligned", offset, addr)
+		}
+	}
+}
+
+// Tests directFileWriter stages and writes all the data, files are not
+// opened with direct I/O so that the test runs on any file system.
+func TestDirectFileWriter(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "minio-")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory, %s", err)
+	}
+	defer removeAll(dir)
+
+	disk := &posix{diskPath: dir}
+	testCases := [][]int{
+		{},
+		{1},
+		{directIOAlignSize},
+		{directIOBufSize},
+		{directIOBufSize + 1},
+		{100, directIOBufSize, directIOAlignSize, 3},
+		{directIOBufSize - 1, 2, 2*directIOBufSize + directIOAlignSize},
+	}
+	for i, sizes := range testCases {
+		filePath := filepath.Join(dir, "file")
+		file, err := os.OpenFile(filePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+		if err != nil {
+			t.Fatalf("Test %d: Unable to create file, %s", i+1, err)
+		}
+		var expected []byte
+		w, err := newDirectFileWriter(disk, file)
+		if err != nil {
+			t.Fatalf("Test %d: Unable to get a staging buffer, %s", i+1, err)
+		}
+		for j, size := range sizes {
+			data := bytes.Repeat([]byte{byte(j + 1)}, size)
+			if n, err := w.Write(data); err != nil || n != size {
+				t.Fatalf("Test %d: Write returned %d, %v", i+1, n, err)
+			}
+			expected = append(expected, data...)
+		}
+		if err = w.Close(); err != nil {
+			t.Fatalf("Test %d: Unable to close file, %s", i+1, err)
+		}
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("Test %d: Unable to read file, %s", i+1, err)
+		}
+		if !bytes.Equal(data, expected) {
+			t.Errorf("Test %d: File content mismatch, got %d bytes, expected %d", i+1, len(data), len(expected))
+		}
+	}
+}
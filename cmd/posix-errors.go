@@ -76,3 +76,11 @@ func isSysErrHandleInvalid(err error) bool {
 	}
 	return false
 }
+
+// Check if the given error corresponds to EIO (I/O error)
+func isSysErrIO(err error) bool {
+	if pathErr, ok := err.(*os.PathError); ok {
+		err = pathErr.Err
+	}
+	return err == syscall.EIO
+}
@@ -17,7 +17,6 @@
 package cmd
 
 import (
-	"bytes"
 	"errors"
 	"io"
 	"io/ioutil"
@@ -416,8 +415,59 @@ func (s *posix) ReadAll(volume, path string) (buf []byte, err error) {
 		return nil, err
 	}
 
+	return readAllFile(pathJoin(volumeDir, path))
+}
+
+// ReadAllBatch - reads entire contents of all the files at paths, such
+// as the `xl.json` of the objects of a listing, with a single call. bufs
+// and errs hold the contents and the error of each file, err is set
+// only if the disk or the volume is not available.
+// This API is meant to be used on files which have small memory footprint, do
+// not use this on large files as it would cause server to crash.
+func (s *posix) ReadAllBatch(volume string, paths []string) (bufs [][]byte, errs []error, err error) {
+	defer func() {
+		if err == syscall.EIO {
+			atomic.AddInt32(&s.ioErrCount, 1)
+		}
+	}()
+
+	if s.ioErrCount > maxAllowedIOError {
+		return nil, nil, errFaultyDisk
+	}
+
+	// Check disk availability.
+	if _, err = getDiskInfo(s.diskPath); err != nil {
+		return nil, nil, err
+	}
+
+	volumeDir, err := s.getVolDir(volume)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Stat a volume entry.
+	_, err = os.Stat(preparePath(volumeDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, errVolumeNotFound
+		}
+		return nil, nil, err
+	}
+
+	bufs = make([][]byte, len(paths))
+	errs = make([]error, len(paths))
+	for i, path := range paths {
+		bufs[i], errs[i] = readAllFile(pathJoin(volumeDir, path))
+		if isSysErrIO(errs[i]) {
+			atomic.AddInt32(&s.ioErrCount, 1)
+		}
+	}
+	return bufs, errs, nil
+}
+
+// readAllFile - reads entire contents of the file at filePath, shared
+// by ReadAll and ReadAllBatch.
+func readAllFile(filePath string) (buf []byte, err error) {
 	// Validate file path length, before reading.
-	filePath := pathJoin(volumeDir, path)
 	if err = checkPathLength(filePath); err != nil {
 		return nil, err
 	}
@@ -534,36 +584,111 @@ func (s *posix) AppendFile(volume, path string, buf []byte) (err error) {
 		}
 	}()
 
+	w, _, err := s.openAppendFile(volume, path, false)
+	if err != nil {
+		return err
+	}
+
+	// Close upon return.
+	defer w.Close()
+
+	// Write the buffer as is, without staging it.
+	_, err = w.Write(buf)
+	return err
+}
+
+// appendFileSync - same as AppendFile, except that the file is flushed
+// to disk before returning.
+func (s *posix) appendFileSync(volume, path string, buf []byte) (err error) {
+	defer func() {
+		if err == syscall.EIO {
+			atomic.AddInt32(&s.ioErrCount, 1)
+		}
+	}()
+
+	w, _, err := s.openAppendFile(volume, path, false)
+	if err != nil {
+		return err
+	}
+
+	// Close upon return.
+	defer w.Close()
+
+	if _, err = w.Write(buf); err != nil {
+		return err
+	}
+	return w.Sync()
+}
+
+// CreateFile - opens a file at path for appends, creating it if it
+// doesn't exist. The file is kept open until the returned writer is
+// closed, so that erasure coded files are written block by block
+// without opening them for each block. With direct I/O enabled the
+// file is written bypassing the page cache.
+func (s *posix) CreateFile(volume, path string) (w io.WriteCloser, err error) {
+	defer func() {
+		if err == syscall.EIO {
+			atomic.AddInt32(&s.ioErrCount, 1)
+		}
+	}()
+
+	file, directIO, err := s.openAppendFile(volume, path, globalIsDirectIO)
+	if err != nil {
+		return nil, err
+	}
+	if directIO {
+		dw, dErr := newDirectFileWriter(s, file)
+		if dErr == nil {
+			return dw, nil
+		}
+		// All the staging buffers are in use, the file is written
+		// through the page cache instead.
+		if err = disableDirectIO(file); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return &posixFileWriter{s, file}, nil
+}
+
+// openAppendFile - validates the disk, the volume and the path, then
+// opens the file at path for appends. Direct I/O is used if asked for
+// and supported for the file, returns true if it is.
+func (s *posix) openAppendFile(volume, path string, directIO bool) (*os.File, bool, error) {
 	if s.ioErrCount > maxAllowedIOError {
-		return errFaultyDisk
+		return nil, false, errFaultyDisk
 	}
 
 	// Validate if disk is free.
-	if err = checkDiskFree(s.diskPath, s.minFreeDisk); err != nil {
-		return err
+	if err := checkDiskFree(s.diskPath, s.minFreeDisk); err != nil {
+		return nil, false, err
 	}
 
 	volumeDir, err := s.getVolDir(volume)
 	if err != nil {
-		return err
+		return nil, false, err
 	}
 	// Stat a volume entry.
 	_, err = os.Stat(preparePath(volumeDir))
 	if err != nil {
 		if os.IsNotExist(err) {
-			return errVolumeNotFound
+			return nil, false, errVolumeNotFound
 		}
-		return err
+		return nil, false, err
 	}
 	filePath := pathJoin(volumeDir, path)
 	if err = checkPathLength(filePath); err != nil {
-		return err
+		return nil, false, err
 	}
 	// Verify if the file already exists and is not of regular type.
 	var st os.FileInfo
 	if st, err = os.Stat(preparePath(filePath)); err == nil {
 		if !st.Mode().IsRegular() {
-			return errIsNotRegular
+			return nil, false, errIsNotRegular
+		}
+		// Direct writes start at aligned offsets only.
+		if st.Size()%directIOAlignSize != 0 {
+			directIO = false
 		}
 	}
 	// Create top level directories if they don't exist.
@@ -571,31 +696,54 @@ func (s *posix) AppendFile(volume, path string, buf []byte) (err error) {
 	if err = mkdirAll(filepath.Dir(filePath), 0777); err != nil {
 		// File path cannot be verified since one of the parents is a file.
 		if isSysErrNotDir(err) {
-			return errFileAccessDenied
+			return nil, false, errFileAccessDenied
 		} else if isSysErrPathNotFound(err) {
 			// Add specific case for windows.
-			return errFileAccessDenied
+			return nil, false, errFileAccessDenied
 		}
-		return err
+		return nil, false, err
 	}
 
 	// Creates the named file with mode 0666 (before umask), or starts appending
 	// to an existig file.
-	w, err := os.OpenFile(preparePath(filePath), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	flag := os.O_CREATE | os.O_APPEND | os.O_WRONLY
+	if directIO {
+		// Not all file systems support direct I/O, fall back to
+		// regular writes for those.
+		if w, dErr := openFileDirect(preparePath(filePath), flag, 0666); dErr == nil {
+			return w, true, nil
+		}
+	}
+	w, err := os.OpenFile(preparePath(filePath), flag, 0666)
 	if err != nil {
 		// File path cannot be verified since one of the parents is a file.
 		if isSysErrNotDir(err) {
-			return errFileAccessDenied
+			return nil, false, errFileAccessDenied
 		}
-		return err
+		return nil, false, err
 	}
+	return w, false, nil
+}
 
-	// Close upon return.
-	defer w.Close()
+// posixFileWriter - writer of a file opened by CreateFile.
+type posixFileWriter struct {
+	disk *posix
+	file *os.File
+}
 
-	// Return io.Copy
-	_, err = io.Copy(w, bytes.NewReader(buf))
-	return err
+func (w *posixFileWriter) Write(buf []byte) (n int, err error) {
+	if w.disk.ioErrCount > maxAllowedIOError {
+		return 0, errFaultyDisk
+	}
+	n, err = w.file.Write(buf)
+	if isSysErrIO(err) {
+		atomic.AddInt32(&w.disk.ioErrCount, 1)
+	}
+	return n, err
+}
+
+func (w *posixFileWriter) Close() error {
+	return w.file.Close()
 }
 
 // StatFile - get file info.
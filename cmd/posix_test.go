@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
@@ -167,6 +168,57 @@ func TestReadAll(t *testing.T) {
 	}
 }
 
+// Test posix.ReadAllBatch()
+func TestReadAllBatch(t *testing.T) {
+	// create posix test setup
+	posixStorage, path, err := newPosixTestSetup()
+	if err != nil {
+		t.Fatalf("Unable to create posix test setup, %s", err)
+	}
+	defer removeAll(path)
+
+	if err = posixStorage.MakeVol("exists"); err != nil {
+		t.Fatalf("Unable to create a volume \"exists\", %s", err)
+	}
+	if err = posixStorage.AppendFile("exists", "as-directory/as-file", []byte("Hello, World")); err != nil {
+		t.Fatalf("Unable to create a file \"as-directory/as-file\", %s", err)
+	}
+	if err = posixStorage.AppendFile("exists", "as-file", []byte("Hello")); err != nil {
+		t.Fatalf("Unable to create a file \"as-file\", %s", err)
+	}
+
+	paths := []string{"as-directory/as-file", "as-file-not-found", "as-directory", "as-file"}
+	expectedBufs := []string{"Hello, World", "", "", "Hello"}
+	expectedErrs := []error{nil, errFileNotFound, errFileNotFound, nil}
+	bufs, errs, err := posixStorage.ReadAllBatch("exists", paths)
+	if err != nil {
+		t.Fatalf("Unable to read files, %s", err)
+	}
+	if len(bufs) != len(paths) || len(errs) != len(paths) {
+		t.Fatalf("Expected %d results, got %d buffers and %d errors", len(paths), len(bufs), len(errs))
+	}
+	for i := range paths {
+		if errs[i] != expectedErrs[i] {
+			t.Errorf("Test %d: Expected err \"%s\", got err \"%s\"", i+1, expectedErrs[i], errs[i])
+		}
+		if string(bufs[i]) != expectedBufs[i] {
+			t.Errorf("Test %d: Expected the data read to be \"%s\", but instead got \"%s\"", i+1, expectedBufs[i], string(bufs[i]))
+		}
+	}
+
+	// Errors of the volume are returned for the whole batch.
+	if _, _, err = posixStorage.ReadAllBatch("i-dont-exist", paths); err != errVolumeNotFound {
+		t.Errorf("Expected err \"%s\", got err \"%s\"", errVolumeNotFound, err)
+	}
+	if _, _, err = posixStorage.ReadAllBatch("ab", paths); err != errInvalidArgument {
+		t.Errorf("Expected err \"%s\", got err \"%s\"", errInvalidArgument, err)
+	}
+	posixStorage.(*posix).ioErrCount = int32(6)
+	if _, _, err = posixStorage.ReadAllBatch("exists", paths); err != errFaultyDisk {
+		t.Errorf("Expected err \"%s\", got err \"%s\"", errFaultyDisk, err)
+	}
+}
+
 // TestNewPosix all the cases handled in posix storage layer initialization.
 func TestNewPosix(t *testing.T) {
 	// Temporary dir name.
@@ -1124,6 +1176,63 @@ func TestAppendFile(t *testing.T) {
 	}
 }
 
+// Test posix.CreateFile() with and without direct I/O.
+func TestCreateFile(t *testing.T) {
+	// create posix test setup
+	posixStorage, path, err := newPosixTestSetup()
+	if err != nil {
+		t.Fatalf("Unable to create posix test setup, %s", err)
+	}
+	defer removeAll(path)
+
+	if err = posixStorage.MakeVol("success-vol"); err != nil {
+		t.Fatalf("Unable to create volume, %s", err)
+	}
+	if err = os.Mkdir(slashpath.Join(path, "success-vol", "object-as-dir"), 0777); err != nil {
+		t.Fatalf("Unable to create directory, %s", err)
+	}
+
+	defer func(directIO bool) { globalIsDirectIO = directIO }(globalIsDirectIO)
+
+	// Blocks of both aligned and unaligned sizes.
+	data := bytes.Repeat([]byte("0123456789abcdef"), 3*directIOBufSize/16)
+	blocks := [][]byte{data[:directIOAlignSize], data[directIOAlignSize : directIOAlignSize+100], data[directIOAlignSize+100:]}
+	for i, directIO := range []bool{false, true} {
+		globalIsDirectIO = directIO
+		fileName := fmt.Sprintf("path/to/object%d", i)
+		w, err := posixStorage.(*posix).CreateFile("success-vol", fileName)
+		if err != nil {
+			t.Fatalf("Test %d: Unable to create file, %s", i+1, err)
+		}
+		for _, block := range blocks {
+			if _, err = w.Write(block); err != nil {
+				t.Fatalf("Test %d: Unable to write file, %s", i+1, err)
+			}
+		}
+		if err = w.Close(); err != nil {
+			t.Fatalf("Test %d: Unable to close file, %s", i+1, err)
+		}
+		// Appends to the file, which is no longer aligned.
+		if err = posixStorage.AppendFile("success-vol", fileName, data[:10]); err != nil {
+			t.Fatalf("Test %d: Unable to append file, %s", i+1, err)
+		}
+		buf, err := posixStorage.ReadAll("success-vol", fileName)
+		if err != nil {
+			t.Fatalf("Test %d: Unable to read file, %s", i+1, err)
+		}
+		if !bytes.Equal(buf, append(append([]byte{}, data...), data[:10]...)) {
+			t.Errorf("Test %d: File content mismatch", i+1)
+		}
+	}
+
+	if _, err = posixStorage.(*posix).CreateFile("success-vol", "object-as-dir"); err != errIsNotRegular {
+		t.Errorf("expected: %s, got: %s", errIsNotRegular, err)
+	}
+	if _, err = posixStorage.(*posix).CreateFile("bn", "object"); err != errInvalidArgument {
+		t.Errorf("expected: %s, got: %s", errInvalidArgument, err)
+	}
+}
+
 // Test posix.RenameFile()
 func TestRenameFile(t *testing.T) {
 	// create posix test setup
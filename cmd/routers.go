@@ -44,18 +44,27 @@ func newObjectLayer(disks, ignoredDisks []string) (ObjectLayer, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err = initObjectLayer(objAPI); err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return objAPI, nil
+}
 
+// initObjectLayer - sets up the server's use of a new object layer.
+func initObjectLayer(objAPI ObjectLayer) error {
 	// Migrate bucket policy from configDir to .minio.sys/buckets/
-	err = migrateBucketPolicyConfig(objAPI)
+	err := migrateBucketPolicyConfig(objAPI)
 	if err != nil {
 		errorIf(err, "Unable to migrate bucket policy from config directory")
-		return nil, err
+		return err
 	}
 
 	err = cleanupOldBucketPolicyConfigs()
 	if err != nil {
 		errorIf(err, "Unable to clean up bucket policy from config directory.")
-		return nil, err
+		return err
 	}
 
 	// Register the callback that should be called when the process shuts down.
@@ -73,8 +82,7 @@ func newObjectLayer(disks, ignoredDisks []string) (ObjectLayer, error) {
 	err = initEventNotifier(objAPI)
 	fatalIf(err, "Unable to initialize event notification.")
 
-	// Success.
-	return objAPI, nil
+	return nil
 }
 
 // configureServer handler returns final handler for the http server.
@@ -95,7 +103,8 @@ func configureServerHandler(srvCmdConfig serverCmdConfig) http.Handler {
 
 	// Initialize Controller.
 	controllerHandlers := &controllerAPIHandlers{
-		ObjectAPI: newObjectLayerFn,
+		ObjectAPI:        newObjectLayerFn,
+		ShutdownSignalCh: globalShutdownSignalCh,
 	}
 
 	// Initialize router.
@@ -110,6 +119,9 @@ func configureServerHandler(srvCmdConfig serverCmdConfig) http.Handler {
 	// Register controller rpc router.
 	registerControllerRPCRouter(mux, controllerHandlers)
 
+	// Register S3 peer rpc router.
+	registerS3PeerRPCRouter(mux, &s3PeerAPIHandlers{})
+
 	// set environmental variable MINIO_BROWSER=off to disable minio web browser.
 	// By default minio web browser is enabled.
 	if !strings.EqualFold(os.Getenv("MINIO_BROWSER"), "off") {
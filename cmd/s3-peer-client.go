@@ -0,0 +1,252 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"net"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// Interval at which listeners are registered again on all the
+	// peers, re-registration also covers restarted peers.
+	listenerRefreshInterval = 30 * time.Second
+
+	// Registrations of listeners not refreshed within this duration
+	// are considered gone along with their server.
+	listenerRegistrationExpiry = 3 * listenerRefreshInterval
+
+	// Events waiting to be forwarded to a peer, events are dropped
+	// once a peer is that far behind.
+	peerEventQueueSize = 10000
+)
+
+// Backoff between failed forwards of an event to a peer, doubled after
+// every failure.
+var (
+	peerForwardMinBackoff = time.Second
+	peerForwardMaxBackoff = listenerRefreshInterval
+)
+
+// s3PeerClients - cache of authenticated S3 peer RPC clients by peer
+// address, a client is used by one call at a time.
+type s3PeerClients struct {
+	mu      *sync.Mutex
+	clients map[string]*s3PeerClient
+}
+
+type s3PeerClient struct {
+	mu *sync.Mutex
+	*AuthRPCClient
+}
+
+var globalS3PeerClients = &s3PeerClients{
+	mu:      &sync.Mutex{},
+	clients: make(map[string]*s3PeerClient),
+}
+
+// Returns the cached client for the peer, creating one if needed.
+func (s *s3PeerClients) get(peer string) *s3PeerClient {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client, ok := s.clients[peer]
+	if !ok {
+		cred := serverConfig.GetCredential()
+		client = &s3PeerClient{
+			mu: &sync.Mutex{},
+			AuthRPCClient: newAuthClient(&authConfig{
+				accessKey:   cred.AccessKeyID,
+				secretKey:   cred.SecretAccessKey,
+				address:     peer,
+				path:        path.Join(reservedBucket, s3PeerPath),
+				loginMethod: "S3Peer.LoginHandler",
+				secureConn:  globalIsSSL,
+			}),
+		}
+		s.clients[peer] = client
+	}
+	return client
+}
+
+// Closes and forgets the client for the peer, a new client with a
+// fresh login is created on next use.
+func (s *s3PeerClients) remove(peer string, client *s3PeerClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.clients[peer] == client {
+		delete(s.clients, peer)
+	}
+	client.Close()
+}
+
+// call - calls serviceMethod on the peer.
+func (s *s3PeerClients) call(peer string, serviceMethod string, args interface {
+	SetToken(token string)
+	SetTimestamp(tstamp time.Time)
+}, reply interface{}) error {
+	client := s.get(peer)
+	client.mu.Lock()
+	err := client.Call(serviceMethod, args, reply)
+	client.mu.Unlock()
+	if err != nil {
+		s.remove(peer, client)
+	}
+	return err
+}
+
+// getLocalPeerAddr - returns the address of this server as known to its
+// peers, taken from the first local disk with a network address.
+func getLocalPeerAddr(srvCmdConfig serverCmdConfig) string {
+	port := strconv.Itoa(getPort(srvCmdConfig.serverAddr))
+	for _, disk := range srvCmdConfig.disks {
+		if !isLocalStorage(disk) {
+			continue
+		}
+		host, _, err := splitNetPath(disk)
+		if err != nil || host == "" {
+			continue
+		}
+		return net.JoinHostPort(host, port)
+	}
+	return ""
+}
+
+// registerListenerOnPeers - registers a listener for snsARN on all the
+// peers, so that events on other servers are forwarded to this server.
+// Registrations are refreshed until doneCh is closed.
+func registerListenerOnPeers(snsARN string, doneCh <-chan struct{}) {
+	peers := getPeerAddrs(srvConfig)
+	localAddr := getLocalPeerAddr(srvConfig)
+	if len(peers) == 0 || localAddr == "" {
+		return
+	}
+
+	ticker := time.NewTicker(listenerRefreshInterval)
+	defer ticker.Stop()
+	for {
+		for _, peer := range peers {
+			args := &ListenerArgs{TopicARN: snsARN, Addr: localAddr}
+			err := globalS3PeerClients.call(peer, "S3Peer.AddListenerHandler", args, &GenericReply{})
+			errorIf(err, "Unable to register listener for %s on %s.", snsARN, peer)
+		}
+		select {
+		case <-ticker.C:
+		case <-doneCh:
+			return
+		}
+	}
+}
+
+// peerEvent - events for the listeners of snsARN on a peer.
+type peerEvent struct {
+	snsARN string
+	events []NotificationEvent
+}
+
+// peerEventForwarders - queues of the events forwarded to each peer, a
+// worker per peer forwards them in order.
+type peerEventForwarders struct {
+	mu     *sync.Mutex
+	queues map[string]chan peerEvent
+}
+
+var globalPeerEventForwarders = &peerEventForwarders{
+	mu:     &sync.Mutex{},
+	queues: make(map[string]chan peerEvent),
+}
+
+// Returns the queue of the peer, starting its worker if needed.
+func (f *peerEventForwarders) get(peer string) chan<- peerEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	queue, ok := f.queues[peer]
+	if !ok {
+		queue = make(chan peerEvent, peerEventQueueSize)
+		f.queues[peer] = queue
+		go forwardPeerEvents(peer, queue)
+	}
+	return queue
+}
+
+// forwardEventToPeer - queues events to be sent to the listeners of
+// snsARN on the peer, events are sent in the order they are queued.
+func forwardEventToPeer(peer string, snsARN string, events []NotificationEvent) {
+	select {
+	case globalPeerEventForwarders.get(peer) <- peerEvent{snsARN, events}:
+	default:
+		errorIf(errors.New("event queue is full"), "Dropping event for %s to %s.", snsARN, peer)
+	}
+}
+
+// forwardPeerEvents - sends the queued events to the peer one by one.
+// Failed sends are retried as long as the peer stays registered, which
+// it does until its registration expires.
+func forwardPeerEvents(peer string, queue <-chan peerEvent) {
+	for event := range queue {
+		backoff := peerForwardMinBackoff
+		for {
+			err := sendEventToPeer(peer, event.snsARN, event.events)
+			if err == nil || !isRemoteSNSTarget(event.snsARN, peer) {
+				break
+			}
+			errorIf(err, "Unable to forward event for %s to %s, retrying in %s.", event.snsARN, peer, backoff)
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > peerForwardMaxBackoff {
+				backoff = peerForwardMaxBackoff
+			}
+		}
+	}
+}
+
+// Returns true if the peer has listeners for snsARN.
+func isRemoteSNSTarget(snsARN string, peer string) bool {
+	if globalEventNotifier == nil {
+		return false
+	}
+	for _, remote := range globalEventNotifier.GetRemoteSNSTargets(snsARN) {
+		if remote == peer {
+			return true
+		}
+	}
+	return false
+}
+
+// sendEventToPeer - sends events to the listeners of snsARN on the peer,
+// the registration of the peer is removed if it no longer has any
+// listeners.
+func sendEventToPeer(peer string, snsARN string, events []NotificationEvent) error {
+	args := &EventArgs{TopicARN: snsARN, Events: events}
+	err := globalS3PeerClients.call(peer, "S3Peer.EventHandler", args, &GenericReply{})
+	if err != nil && err.Error() == errNoSuchListener.Error() {
+		globalEventNotifier.RemoveRemoteSNSTarget(snsARN, peer)
+	}
+	return err
+}
+
+// updateBucketNotificationOnPeers - asks all the peers to reload the
+// notification configuration of the bucket after it was changed here.
+func updateBucketNotificationOnPeers(bucket string) {
+	for _, peer := range getPeerAddrs(srvConfig) {
+		args := &BucketNotificationArgs{Bucket: bucket}
+		err := globalS3PeerClients.call(peer, "S3Peer.BucketNotificationHandler", args, &GenericReply{})
+		errorIf(err, "Unable to update bucket notification of %s on %s.", bucket, peer)
+	}
+}
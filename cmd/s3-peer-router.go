@@ -0,0 +1,41 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/rpc"
+
+	router "github.com/gorilla/mux"
+)
+
+// Routes path for S3 peer RPC, used by servers in a distributed setup
+// to forward bucket events to each other.
+const (
+	s3PeerPath = "/s3-peer"
+)
+
+// Register S3 peer RPC handlers.
+func registerS3PeerRPCRouter(mux *router.Router, s3PeerHandlers *s3PeerAPIHandlers) {
+	s3PeerRPCServer := rpc.NewServer()
+	s3PeerRPCServer.RegisterName("S3Peer", s3PeerHandlers)
+
+	s3PeerRouter := mux.NewRoute().PathPrefix(reservedBucket).Subrouter()
+	s3PeerRouter.Path(s3PeerPath).Handler(s3PeerRPCServer)
+}
+
+// Handler for S3 peer RPC.
+type s3PeerAPIHandlers struct{}
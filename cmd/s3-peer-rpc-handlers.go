@@ -0,0 +1,133 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import "errors"
+
+// errNoSuchListener - no listener for the sns ARN on this server.
+var errNoSuchListener = errors.New("No listener for the notification ARN.")
+
+// Login - login handler.
+func (s *s3PeerAPIHandlers) LoginHandler(args *RPCLoginArgs, reply *RPCLoginReply) error {
+	jwt, err := newJWT(defaultTokenExpiry)
+	if err != nil {
+		return err
+	}
+	if err = jwt.Authenticate(args.Username, args.Password); err != nil {
+		return err
+	}
+	token, err := jwt.GenerateToken(args.Username)
+	if err != nil {
+		return err
+	}
+	reply.Token = token
+	reply.ServerVersion = Version
+	return nil
+}
+
+// ListenerArgs - argument for AddListenerHandler RPC.
+type ListenerArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Notification ARN of the listener.
+	TopicARN string
+
+	// Address of the server with the listener.
+	Addr string
+}
+
+// AddListenerHandler - registers or refreshes a listener on the peer at
+// Addr, events for TopicARN are forwarded to it until the registration
+// expires or the peer stops accepting events.
+func (s *s3PeerAPIHandlers) AddListenerHandler(args *ListenerArgs, reply *GenericReply) error {
+	if !isRPCTokenValid(args.Token) {
+		return errInvalidToken
+	}
+	if globalEventNotifier == nil {
+		return errServerNotInitialized
+	}
+	if args.TopicARN == "" || args.Addr == "" {
+		return errInvalidArgument
+	}
+	globalEventNotifier.AddRemoteSNSTarget(args.TopicARN, args.Addr)
+	return nil
+}
+
+// EventArgs - argument for EventHandler RPC.
+type EventArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Notification ARN the events are sent for.
+	TopicARN string
+
+	// Events to be sent to the listeners.
+	Events []NotificationEvent
+}
+
+// EventHandler - sends events forwarded by a peer to the local listeners
+// of TopicARN, returns errNoSuchListener if there are none.
+func (s *s3PeerAPIHandlers) EventHandler(args *EventArgs, reply *GenericReply) error {
+	if !isRPCTokenValid(args.Token) {
+		return errInvalidToken
+	}
+	if globalEventNotifier == nil {
+		return errServerNotInitialized
+	}
+	listeners := globalEventNotifier.GetSNSTarget(args.TopicARN)
+	if len(listeners) == 0 {
+		return errNoSuchListener
+	}
+	for _, listener := range listeners {
+		listener <- args.Events
+	}
+	return nil
+}
+
+// BucketNotificationArgs - argument for BucketNotificationHandler RPC.
+type BucketNotificationArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Bucket with a changed notification configuration.
+	Bucket string
+}
+
+// BucketNotificationHandler - reloads the notification configuration of
+// the bucket from the object layer after a peer changed or removed it.
+func (s *s3PeerAPIHandlers) BucketNotificationHandler(args *BucketNotificationArgs, reply *GenericReply) error {
+	if !isRPCTokenValid(args.Token) {
+		return errInvalidToken
+	}
+	objAPI := newObjectLayerFn()
+	if globalEventNotifier == nil || objAPI == nil {
+		return errServerNotInitialized
+	}
+	if !IsValidBucketName(args.Bucket) {
+		return errInvalidArgument
+	}
+	nConfig, err := loadNotificationConfig(args.Bucket, objAPI)
+	if err == errNoSuchNotifications {
+		globalEventNotifier.RemoveBucketNotificationConfig(args.Bucket)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return globalEventNotifier.SetBucketNotificationConfig(args.Bucket, nConfig)
+}
@@ -0,0 +1,179 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	router "github.com/gorilla/mux"
+)
+
+// Tests listener registration and event forwarding between peers.
+func TestS3PeerEventForwarding(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	globalEventNotifier = &eventNotifier{
+		rwMutex:             &sync.RWMutex{},
+		notificationConfigs: make(map[string]*notificationConfig),
+		snsTargets:          make(map[string][]chan []NotificationEvent),
+		remoteSNSTargets:    make(map[string]map[string]time.Time),
+	}
+
+	mux := router.NewRouter()
+	registerS3PeerRPCRouter(mux, &s3PeerAPIHandlers{})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	peer := ts.Listener.Addr().String()
+
+	topicARN := "arn:minio:sns:us-east-1:1:listen"
+	events := []NotificationEvent{{EventName: ObjectCreatedPut.String()}}
+
+	// Requests without a valid token are rejected.
+	if err = (&s3PeerAPIHandlers{}).EventHandler(&EventArgs{TopicARN: topicARN}, &GenericReply{}); err != errInvalidToken {
+		t.Fatalf("Expected %s, got %s", errInvalidToken, err)
+	}
+
+	// Register a listener of the peer.
+	args := &ListenerArgs{TopicARN: topicARN, Addr: peer}
+	if err = globalS3PeerClients.call(peer, "S3Peer.AddListenerHandler", args, &GenericReply{}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if peers := globalEventNotifier.GetRemoteSNSTargets(topicARN); len(peers) != 1 || peers[0] != peer {
+		t.Fatalf("Unexpected remote listeners %v", peers)
+	}
+
+	// Peer without any local listeners drops the registration.
+	if err = sendEventToPeer(peer, topicARN, events); err == nil {
+		t.Fatal("Expected an error for a peer without listeners")
+	}
+	if peers := globalEventNotifier.GetRemoteSNSTargets(topicARN); len(peers) != 0 {
+		t.Fatalf("Expected registration to be removed, found %v", peers)
+	}
+
+	// Events are forwarded to local listeners of the peer, in order.
+	listenerCh := make(chan []NotificationEvent)
+	globalEventNotifier.SetSNSTarget(topicARN, listenerCh)
+	globalEventNotifier.AddRemoteSNSTarget(topicARN, peer)
+	eventNames := []string{ObjectCreatedPut.String(), ObjectCreatedCopy.String(), ObjectRemovedDelete.String()}
+	for _, eventName := range eventNames {
+		forwardEventToPeer(peer, topicARN, []NotificationEvent{{EventName: eventName}})
+	}
+	for _, eventName := range eventNames {
+		select {
+		case received := <-listenerCh:
+			if len(received) != 1 || received[0].EventName != eventName {
+				t.Fatalf("Expected forwarded event %s, got %v", eventName, received)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for forwarded event")
+		}
+	}
+	globalEventNotifier.RemoveSNSTarget(topicARN, listenerCh)
+
+	// Registrations which are not refreshed expire.
+	globalEventNotifier.AddRemoteSNSTarget(topicARN, peer)
+	globalEventNotifier.remoteSNSTargets[topicARN][peer] = time.Now().UTC().Add(-2 * listenerRegistrationExpiry)
+	if peers := globalEventNotifier.GetRemoteSNSTargets(topicARN); len(peers) != 0 {
+		t.Fatalf("Expected registration to expire, found %v", peers)
+	}
+
+	// Unreachable peer keeps its registration, sends are retried
+	// until it expires.
+	ts.Close()
+	// RPC connections are hijacked, close the cached one too.
+	globalS3PeerClients.remove(peer, globalS3PeerClients.get(peer))
+	globalEventNotifier.AddRemoteSNSTarget(topicARN, peer)
+	if err = sendEventToPeer(peer, topicARN, events); err == nil {
+		t.Fatal("Expected an error for an unreachable peer")
+	}
+	if peers := globalEventNotifier.GetRemoteSNSTargets(topicARN); len(peers) != 1 {
+		t.Fatalf("Expected registration to be kept, found %v", peers)
+	}
+	globalEventNotifier.RemoveRemoteSNSTarget(topicARN, peer)
+}
+
+// Tests reload of bucket notification configuration changed by a peer.
+func TestS3PeerBucketNotification(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	obj, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(fsDir)
+	objLayerMutex.Lock()
+	globalObjectAPI = obj
+	objLayerMutex.Unlock()
+
+	globalEventNotifier = &eventNotifier{
+		rwMutex:             &sync.RWMutex{},
+		notificationConfigs: make(map[string]*notificationConfig),
+		snsTargets:          make(map[string][]chan []NotificationEvent),
+		remoteSNSTargets:    make(map[string]map[string]time.Time),
+	}
+
+	mux := router.NewRouter()
+	registerS3PeerRPCRouter(mux, &s3PeerAPIHandlers{})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	peer := ts.Listener.Addr().String()
+
+	bucket := "bucket"
+	if err = obj.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	args := &BucketNotificationArgs{Bucket: bucket}
+
+	// Configuration set by another server is loaded.
+	config := []byte("<NotificationConfiguration></NotificationConfiguration>")
+	configPath := path.Join(bucketConfigPrefix, bucket, bucketNotificationConfig)
+	if _, err = obj.PutObject(minioMetaBucket, configPath, int64(len(config)), bytes.NewReader(config), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = globalS3PeerClients.call(peer, "S3Peer.BucketNotificationHandler", args, &GenericReply{}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !globalEventNotifier.IsBucketNotificationSet(bucket) {
+		t.Fatal("Expected bucket notification to be loaded")
+	}
+
+	// Configuration removed by another server is dropped.
+	if err = removeNotificationConfig(bucket, obj); err != nil {
+		t.Fatal(err)
+	}
+	if err = globalS3PeerClients.call(peer, "S3Peer.BucketNotificationHandler", args, &GenericReply{}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if globalEventNotifier.IsBucketNotificationSet(bucket) {
+		t.Fatal("Expected bucket notification to be removed")
+	}
+}
@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
@@ -64,6 +65,22 @@ ENVIRONMENT VARIABLES:
   CACHING:
      MINIO_CACHE_SIZE: Set total cache size in NN[GB|MB|KB]. Defaults to 8GB.
      MINIO_CACHE_EXPIRY: Set cache expiration duration in NN[h|m|s]. Defaults to 72 hours.
+     MINIO_CACHE_DIR: Set a directory, preferably on SSD, cached blocks evicted from memory are moved to.
+     MINIO_CACHE_DISK_SIZE: Set total size of the cache directory in NN[GB|MB|KB]. Defaults to 8GB.
+
+  COMPRESSION:
+     MINIO_COMPRESS: Set to "on" to compress new objects at rest, matched by extension or content type.
+     MINIO_COMPRESS_EXTENSIONS: Set comma separated list of extensions. Defaults to ".txt,.log,.csv,.json".
+     MINIO_COMPRESS_MIME_TYPES: Set comma separated list of content types, wildcards allowed. Defaults to "text/*,application/json,application/xml".
+
+  BITROT:
+     MINIO_BITROT_ALGORITHM: Set algorithm of bit-rot checksums of new objects, "blake2b", "sha256" or "highwayhash". Defaults to "blake2b".
+
+  DIRECT I/O:
+     MINIO_DIRECT_IO: Set to "on" to write erasure coded files with direct I/O, bypassing the page cache. Linux only.
+
+  INDEX:
+     MINIO_INDEX_DIR: Set a directory to keep an index of the objects of each bucket in, listings and searches are served from it. Not supported in distributed setups.
 
 EXAMPLES:
   1. Start minio server.
@@ -98,6 +115,7 @@ type serverCmdConfig struct {
 	serverAddr   string
 	disks        []string
 	ignoredDisks []string
+	peers        []string // Addresses of the other servers, if not found in disks.
 }
 
 // getListenIPs - gets all the ips to listen on.
@@ -171,6 +189,45 @@ func initServerConfig(c *cli.Context) {
 		fatalIf(err, "Unable to convert MINIO_CACHE_EXPIRY=%s environment variable into its time.Duration value.", cacheExpiryStr)
 	}
 
+	// Fetch cache directory from environment variable.
+	globalCacheDir = os.Getenv("MINIO_CACHE_DIR")
+
+	// Fetch max cache directory size from environment variable.
+	if maxCacheDiskSizeStr := os.Getenv("MINIO_CACHE_DISK_SIZE"); maxCacheDiskSizeStr != "" {
+		// We need to parse cache size to its integer value.
+		globalMaxCacheDiskSize, err = strconvBytes(maxCacheDiskSizeStr)
+		fatalIf(err, "Unable to convert MINIO_CACHE_DISK_SIZE=%s environment variable into its integer value.", maxCacheDiskSizeStr)
+	}
+
+	// Fetch compression settings from environment variables.
+	globalIsCompressionEnabled = strings.EqualFold(os.Getenv("MINIO_COMPRESS"), "on")
+	if extensions := os.Getenv("MINIO_COMPRESS_EXTENSIONS"); extensions != "" {
+		globalCompressExtensions = nil
+		for _, ext := range splitList(strings.ToLower(extensions)) {
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			globalCompressExtensions = append(globalCompressExtensions, ext)
+		}
+	}
+	if mimeTypes := os.Getenv("MINIO_COMPRESS_MIME_TYPES"); mimeTypes != "" {
+		globalCompressMimeTypes = splitList(strings.ToLower(mimeTypes))
+	}
+
+	// Fetch bit-rot algorithm from environment variable.
+	if algo := os.Getenv("MINIO_BITROT_ALGORITHM"); algo != "" {
+		if !isValidBitRotAlgo(algo) {
+			fatalIf(errBitRotAlgoInvalid, "Invalid MINIO_BITROT_ALGORITHM=%s environment variable.", algo)
+		}
+		globalBitRotAlgo = algo
+	}
+
+	// Enable direct I/O if requested.
+	globalIsDirectIO = strings.EqualFold(os.Getenv("MINIO_DIRECT_IO"), "on")
+
+	// Fetch object index directory from environment variable.
+	globalIndexDir = os.Getenv("MINIO_INDEX_DIR")
+
 	// Fetch access keys from environment variables if any and update the config.
 	accessKey := os.Getenv("MINIO_ACCESS_KEY")
 	secretKey := os.Getenv("MINIO_SECRET_KEY")
@@ -331,6 +388,9 @@ func formatDisks(disks, ignoredDisks []string) error {
 
 // serverMain handler called for 'minio server' command.
 func serverMain(c *cli.Context) {
+	// Record the time at which the server was started.
+	globalBootTime = time.Now().UTC()
+
 	// Check 'server' cli arguments.
 	checkServerSyntax(c)
 
@@ -338,7 +398,7 @@ func serverMain(c *cli.Context) {
 	initServerConfig(c)
 
 	// If https.
-	tls := isSSL()
+	globalIsSSL = isSSL()
 
 	// Server address.
 	serverAddress := c.String("address")
@@ -355,6 +415,10 @@ func serverMain(c *cli.Context) {
 	disks := c.Args()
 
 	isDist := isDistributedSetup(disks)
+	// The index is only kept in sync by the writes of this server.
+	if isDist && globalIndexDir != "" {
+		fatalIf(errInvalidArgument, "MINIO_INDEX_DIR is not supported in distributed setups.")
+	}
 	// Set nodes for dsync for distributed setup.
 	if isDist {
 		err = initDsyncNodes(disks, port)
@@ -380,12 +444,26 @@ func serverMain(c *cli.Context) {
 
 	apiServer := NewServerMux(serverAddress, handler)
 
+	// Serve certificates from the certs directory, they are
+	// reloaded whenever they change.
+	certsDoneCh := make(chan struct{})
+	if globalIsSSL {
+		certs, err := newCertManager(mustGetCertsPath())
+		fatalIf(err, "Unable to load certificates.")
+		apiServer.TLSConfig = &tls.Config{GetCertificate: certs.GetCertificate}
+		go certs.watch(certsReloadInterval, certsDoneCh)
+	}
+
 	// Fetch endpoints which we are going to serve from.
-	endPoints := finalizeEndpoints(tls, &apiServer.Server)
+	endPoints := finalizeEndpoints(globalIsSSL, &apiServer.Server)
 
-	// Register generic callbacks.
+	// Register generic callbacks, stop accepting new connections
+	// and wait for all in-flight requests to complete.
 	globalShutdownCBs.AddGenericCB(func() errCode {
-		// apiServer.Stop()
+		close(certsDoneCh)
+		if err := apiServer.Close(); err != nil {
+			errorIf(err, "Unable to close the server gracefully.")
+		}
 		return exitSuccess
 	})
 
@@ -398,11 +476,12 @@ func serverMain(c *cli.Context) {
 				wait <- struct{}{}
 			}()
 			if tls {
-				return apiServer.ListenAndServeTLS(mustGetCertFile(), mustGetKeyFile())
+				// Certificates are served by the TLS config.
+				return apiServer.ListenAndServeTLS("", "")
 			} // Fallback to http.
 			return apiServer.ListenAndServe()
 		}(), "Failed to start minio server.")
-	}(tls, wait)
+	}(globalIsSSL, wait)
 
 	// Wait for formatting of disks.
 	err = formatDisks(disks, ignoredDisks)
@@ -420,6 +499,12 @@ func serverMain(c *cli.Context) {
 		return
 	}
 
+	// Index objects if requested.
+	if globalIndexDir != "" {
+		newObject, err = newIndexObjects(newObject, globalIndexDir)
+		fatalIf(err, "Unable to initialize the object index in %s.", globalIndexDir)
+	}
+
 	// Prints the formatted startup message.
 	printStartupMessage(endPoints)
 
@@ -199,19 +199,26 @@ func NewServerMux(addr string, handler http.Handler) *ServerMux {
 
 // ListenAndServeTLS - similar to the http.Server version. However, it has the
 // ability to redirect http requests to the correct HTTPS url if the client
-// mistakenly initiates a http connection over the https port
+// mistakenly initiates a http connection over the https port. Like the
+// http.Server version certFile and keyFile may be empty if the TLSConfig
+// of the server already provides the certificates.
 func (m *ServerMux) ListenAndServeTLS(certFile, keyFile string) error {
-	listener, err := net.Listen("tcp", m.Server.Addr)
-	if err != nil {
-		return err
-	}
-
 	config := &tls.Config{} // Always instantiate.
+	if m.Server.TLSConfig != nil {
+		config = m.Server.TLSConfig.Clone()
+	}
 	if config.NextProtos == nil {
 		config.NextProtos = []string{"http/1.1", "h2"}
 	}
-	config.Certificates = make([]tls.Certificate, 1)
-	config.Certificates[0], err = tls.LoadX509KeyPair(certFile, keyFile)
+	if len(config.Certificates) == 0 && config.GetCertificate == nil {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	listener, err := net.Listen("tcp", m.Server.Addr)
 	if err != nil {
 		return err
 	}
@@ -222,26 +229,28 @@ func (m *ServerMux) ListenAndServeTLS(certFile, keyFile string) error {
 	m.listener = listenerMux
 	m.mu.Unlock()
 
-	err = http.Serve(listenerMux,
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// We reach here when ListenerMux.ConnMux is not wrapped with tls.Server
-			if r.TLS == nil {
-				u := url.URL{
-					Scheme:   "https",
-					Opaque:   r.URL.Opaque,
-					User:     r.URL.User,
-					Host:     r.Host,
-					Path:     r.URL.Path,
-					RawQuery: r.URL.RawQuery,
-					Fragment: r.URL.Fragment,
-				}
-				http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
-			} else {
-				// Execute registered handlers
-				m.Server.Handler.ServeHTTP(w, r)
+	// Serve through the embedded http.Server so that connections
+	// are tracked for a graceful shutdown.
+	handler := m.Server.Handler
+	m.Server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// We reach here when ListenerMux.ConnMux is not wrapped with tls.Server
+		if r.TLS == nil {
+			u := url.URL{
+				Scheme:   "https",
+				Opaque:   r.URL.Opaque,
+				User:     r.URL.User,
+				Host:     r.Host,
+				Path:     r.URL.Path,
+				RawQuery: r.URL.RawQuery,
+				Fragment: r.URL.Fragment,
 			}
-		}))
-	return err
+			http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+		} else {
+			// Execute registered handlers
+			handler.ServeHTTP(w, r)
+		}
+	})
+	return m.Server.Serve(listenerMux)
 }
 
 // ListenAndServe - Same as the http.Server version
@@ -0,0 +1,292 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Shared links of a bucket are saved as
+	// 'buckets/<bucket>/shared-links.json' in the meta bucket.
+	bucketSharedLinksJSON = "shared-links.json"
+
+	// Query parameter and POST form field identifying the shared
+	// link a presigned request was generated for.
+	sharedLinkIDKey = "X-Minio-Share-Id"
+
+	// Shared links expire in 7 days at most, same as AWS S3
+	// presigned URLs.
+	maxSharedLinkExpiry = 7 * 24 * time.Hour
+)
+
+// errInvalidSharedLinkExpiry - expiry of a shared link is out of range.
+var errInvalidSharedLinkExpiry = errors.New("Expiry must be between 1 second and 7 days")
+
+// sharedLink - a presigned link handed out by the web browser. POST
+// links allow uploads to any object under the prefix in Object.
+type sharedLink struct {
+	ID      string    `json:"id"`
+	Method  string    `json:"method"`
+	Object  string    `json:"object"`
+	Created time.Time `json:"created"`
+	Expiry  time.Time `json:"expiry"`
+}
+
+// Serializes updates of shared links, links are read, modified
+// and written back as a whole.
+var sharedLinksMutex = &sync.Mutex{}
+
+// getSharedLinkExpiry - returns the expiry in seconds as a duration,
+// links without an expiry are valid for 7 days.
+func getSharedLinkExpiry(expiry int64) (time.Duration, error) {
+	if expiry == 0 {
+		return maxSharedLinkExpiry, nil
+	}
+	if expiry < 0 || time.Duration(expiry)*time.Second > maxSharedLinkExpiry {
+		return 0, errInvalidSharedLinkExpiry
+	}
+	return time.Duration(expiry) * time.Second, nil
+}
+
+// readSharedLinks - reads all the shared links of the bucket, including
+// expired ones.
+func readSharedLinks(bucket string, objAPI ObjectLayer) ([]sharedLink, error) {
+	linksPath := pathJoin(bucketConfigPrefix, bucket, bucketSharedLinksJSON)
+	objInfo, err := objAPI.GetObjectInfo(minioMetaBucket, linksPath)
+	err = errorCause(err)
+	if err != nil {
+		if _, ok := err.(ObjectNotFound); ok {
+			return nil, nil
+		}
+		errorIf(err, "Unable to load shared links for the bucket %s.", bucket)
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	if err = objAPI.GetObject(minioMetaBucket, linksPath, 0, objInfo.Size, &buffer); err != nil {
+		errorIf(err, "Unable to load shared links for the bucket %s.", bucket)
+		return nil, errorCause(err)
+	}
+	var links []sharedLink
+	if err = json.Unmarshal(buffer.Bytes(), &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// writeSharedLinks - saves the shared links of the bucket, expired
+// links are dropped.
+func writeSharedLinks(bucket string, links []sharedLink, objAPI ObjectLayer) error {
+	now := time.Now().UTC()
+	activeLinks := []sharedLink{}
+	for _, link := range links {
+		if link.Expiry.After(now) {
+			activeLinks = append(activeLinks, link)
+		}
+	}
+	linksPath := pathJoin(bucketConfigPrefix, bucket, bucketSharedLinksJSON)
+	if len(activeLinks) == 0 {
+		return removeSharedLinks(bucket, objAPI)
+	}
+	linksBytes, err := json.Marshal(activeLinks)
+	if err != nil {
+		return err
+	}
+	if _, err = objAPI.PutObject(minioMetaBucket, linksPath, int64(len(linksBytes)), bytes.NewReader(linksBytes), nil); err != nil {
+		errorIf(err, "Unable to save shared links for the bucket %s.", bucket)
+		return errorCause(err)
+	}
+	return nil
+}
+
+// removeSharedLinks - removes all the shared links of the bucket, if any.
+func removeSharedLinks(bucket string, objAPI ObjectLayer) error {
+	linksPath := pathJoin(bucketConfigPrefix, bucket, bucketSharedLinksJSON)
+	if err := objAPI.DeleteObject(minioMetaBucket, linksPath); err != nil {
+		err = errorCause(err)
+		if _, ok := err.(ObjectNotFound); ok {
+			return nil
+		}
+		errorIf(err, "Unable to remove shared links for the bucket %s.", bucket)
+		return err
+	}
+	return nil
+}
+
+// addSharedLink - saves a new shared link for the bucket.
+func addSharedLink(bucket, method, object string, created time.Time, expiry time.Duration, objAPI ObjectLayer) (sharedLink, error) {
+	// Verify if bucket actually exists
+	if err := isBucketExist(bucket, objAPI); err != nil {
+		return sharedLink{}, err
+	}
+
+	sharedLinksMutex.Lock()
+	defer sharedLinksMutex.Unlock()
+
+	links, err := readSharedLinks(bucket, objAPI)
+	if err != nil {
+		return sharedLink{}, err
+	}
+	link := sharedLink{
+		ID:      getUUID(),
+		Method:  method,
+		Object:  object,
+		Created: created,
+		Expiry:  created.Add(expiry),
+	}
+	if err = writeSharedLinks(bucket, append(links, link), objAPI); err != nil {
+		return sharedLink{}, err
+	}
+	return link, nil
+}
+
+// revokeSharedLink - removes a shared link of the bucket, requests
+// presigned for it are denied from then on.
+func revokeSharedLink(bucket, id string, objAPI ObjectLayer) error {
+	sharedLinksMutex.Lock()
+	defer sharedLinksMutex.Unlock()
+
+	links, err := readSharedLinks(bucket, objAPI)
+	if err != nil {
+		return err
+	}
+	for i, link := range links {
+		if link.ID == id {
+			return writeSharedLinks(bucket, append(links[:i], links[i+1:]...), objAPI)
+		}
+	}
+	return fmt.Errorf("Shared link %s not found", id)
+}
+
+// isSharedLinkActive - verifies the shared link has neither expired
+// nor been revoked, and that it was generated for this request.
+func isSharedLinkActive(bucket, object, method, id string, objAPI ObjectLayer) bool {
+	links, err := readSharedLinks(bucket, objAPI)
+	if err != nil {
+		return false
+	}
+	now := time.Now().UTC()
+	for _, link := range links {
+		if link.ID != id {
+			continue
+		}
+		if !link.Expiry.After(now) || link.Method != method {
+			return false
+		}
+		if method == "POST" {
+			return strings.HasPrefix(object, link.Object)
+		}
+		return object == link.Object
+	}
+	return false
+}
+
+// checkSharedLinkRequest - presigned requests for shared links are
+// only valid until the link expires or is revoked.
+func checkSharedLinkRequest(r *http.Request, shareID string) APIErrorCode {
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return ErrServerNotInitialized
+	}
+	var bucket, object string
+	splits := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	bucket = splits[0]
+	if len(splits) == 2 {
+		object = splits[1]
+	}
+	if !isSharedLinkActive(bucket, object, r.Method, shareID, objAPI) {
+		return ErrAccessDenied
+	}
+	return ErrNone
+}
+
+// presignURL - returns a presigned URL for the method on the object,
+// 'host' is the only header signed.
+func presignURL(host, method, bucket, object, shareID string, date time.Time, expiry time.Duration) string {
+	cred := serverConfig.GetCredential()
+	region := serverConfig.GetRegion()
+
+	credential := fmt.Sprintf("%s/%s", cred.AccessKeyID, getScope(date, region))
+
+	query := make(url.Values)
+	query.Set("X-Amz-Algorithm", signV4Algorithm)
+	query.Set("X-Amz-Credential", credential)
+	query.Set("X-Amz-Date", date.Format(iso8601Format))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expiry/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+	query.Set(sharedLinkIDKey, shareID)
+	encodedQuery := query.Encode()
+
+	path := "/" + pathJoin(bucket, object)
+
+	// Headers are empty, since "host" is the only header required to be signed for Presigned URLs.
+	var extractedSignedHeaders http.Header
+
+	canonicalRequest := getCanonicalRequest(extractedSignedHeaders, unsignedPayload, encodedQuery, path, method, host)
+	stringToSign := getStringToSign(canonicalRequest, date, region)
+	signingKey := getSigningKey(cred.SecretAccessKey, date, region)
+	signature := getSignature(signingKey, stringToSign)
+
+	// Construct the final presigned URL.
+	return host + path + "?" + encodedQuery + "&" + "X-Amz-Signature=" + signature
+}
+
+// presignPostPolicy - returns the form fields of a POST policy upload
+// to any object under the prefix.
+func presignPostPolicy(bucket, prefix, shareID string, date time.Time, expiry time.Duration) map[string]string {
+	cred := serverConfig.GetCredential()
+	region := serverConfig.GetRegion()
+
+	credential := fmt.Sprintf("%s/%s", cred.AccessKeyID, getScope(date, region))
+	dateStr := date.Format(iso8601Format)
+
+	policy := map[string]interface{}{
+		"expiration": date.Add(expiry).Format(time.RFC3339Nano),
+		"conditions": []interface{}{
+			[]string{"eq", "$bucket", bucket},
+			[]string{"starts-with", "$key", prefix},
+			[]string{"eq", "$x-amz-date", dateStr},
+			[]string{"eq", "$x-amz-algorithm", signV4Algorithm},
+			[]string{"eq", "$x-amz-credential", credential},
+			[]string{"eq", "$" + strings.ToLower(sharedLinkIDKey), shareID},
+		},
+	}
+	// Marshalling a map of strings and slices of strings never fails.
+	policyBytes, _ := json.Marshal(policy)
+	encodedPolicy := base64.StdEncoding.EncodeToString(policyBytes)
+
+	signingKey := getSigningKey(cred.SecretAccessKey, date, region)
+	return map[string]string{
+		"bucket":           bucket,
+		"policy":           encodedPolicy,
+		"x-amz-algorithm":  signV4Algorithm,
+		"x-amz-credential": credential,
+		"x-amz-date":       dateStr,
+		"x-amz-signature":  getSignature(signingKey, encodedPolicy),
+		sharedLinkIDKey:    shareID,
+	}
+}
@@ -198,5 +198,10 @@ func checkPostPolicy(formValues map[string]string) APIErrorCode {
 			return ErrAccessDenied
 		}
 	}
+	if postPolicyForm.Conditions.Policies["$x-minio-share-id"].Operator == "eq" {
+		if formValues[sharedLinkIDKey] != postPolicyForm.Conditions.Policies["$x-minio-share-id"].Value {
+			return ErrAccessDenied
+		}
+	}
 	return ErrNone
 }
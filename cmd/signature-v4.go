@@ -316,6 +316,11 @@ func doesPresignedSignatureMatch(hashedPayload string, r *http.Request, validate
 	if req.URL.Query().Get("X-Amz-Signature") != newSignature {
 		return ErrSignatureDoesNotMatch
 	}
+
+	// Verify if the shared link, if any, is still active.
+	if shareID := req.URL.Query().Get(sharedLinkIDKey); shareID != "" {
+		return checkSharedLinkRequest(&req, shareID)
+	}
 	return ErrNone
 }
 
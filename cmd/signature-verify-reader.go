@@ -74,6 +74,8 @@ func (v *signVerifyReader) Verify() error {
 			sErr = errContentSHA256Mismatch
 		case ErrSignatureDoesNotMatch:
 			sErr = errSignatureMismatch
+		case ErrAccessDenied:
+			sErr = errAccessDenied
 		default:
 			sErr = fmt.Errorf("%v", getAPIError(s3Error))
 		}
@@ -39,4 +39,5 @@ type StorageAPI interface {
 
 	// Read all.
 	ReadAll(volume string, path string) (buf []byte, err error)
+	ReadAllBatch(volume string, paths []string) (bufs [][]byte, errs []error, err error)
 }
@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"errors"
 	"io"
 	"net"
 	"net/rpc"
@@ -110,6 +111,7 @@ func newRPCClient(networkPath string) (StorageAPI, error) {
 		address:     rpcAddr,
 		path:        rpcPath,
 		loginMethod: "Storage.LoginHandler",
+		secureConn:  globalIsSSL,
 	})
 	// Initialize network storage.
 	ndisk := &networkStorage{
@@ -210,6 +212,28 @@ func (n networkStorage) ReadAll(volume, path string) (buf []byte, err error) {
 	return buf, nil
 }
 
+// ReadAllBatch - reads entire contents of all the files at paths with
+// a single call, see ReadAll.
+func (n networkStorage) ReadAllBatch(volume string, paths []string) (bufs [][]byte, errs []error, err error) {
+	reply := ReadAllBatchReply{}
+	if err = n.rpcClient.Call("Storage.ReadAllBatchHandler", &ReadAllBatchArgs{
+		Vol:   volume,
+		Paths: paths,
+	}, &reply); err != nil {
+		return nil, nil, toStorageErr(err)
+	}
+	if len(reply.Bufs) != len(paths) || len(reply.Errs) != len(paths) {
+		return nil, nil, errUnexpected
+	}
+	errs = make([]error, len(paths))
+	for i, errStr := range reply.Errs {
+		if errStr != "" {
+			errs[i] = toStorageErr(errors.New(errStr))
+		}
+	}
+	return reply.Bufs, errs, nil
+}
+
 // ReadFile - reads a file.
 func (n networkStorage) ReadFile(volume string, path string, offset int64, buffer []byte) (m int64, err error) {
 	var result []byte
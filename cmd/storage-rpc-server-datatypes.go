@@ -43,6 +43,27 @@ type ReadAllArgs struct {
 	Path string
 }
 
+// ReadAllBatchArgs represents read all batch RPC arguments.
+type ReadAllBatchArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Name of the volume.
+	Vol string
+
+	// Name of the paths.
+	Paths []string
+}
+
+// ReadAllBatchReply represents read all batch RPC reply.
+type ReadAllBatchReply struct {
+	// Contents of the files.
+	Bufs [][]byte
+
+	// Errors of the files, empty for files read successfully.
+	Errs []string
+}
+
 // ReadFileArgs represents read file RPC arguments.
 type ReadFileArgs struct {
 	// Authentication token generated by Login.
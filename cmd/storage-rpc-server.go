@@ -152,6 +152,26 @@ func (s *storageServer) ReadAllHandler(args *ReadFileArgs, reply *[]byte) error
 	return nil
 }
 
+// ReadAllBatchHandler - read all batch handler is rpc wrapper to read
+// several files at once.
+func (s *storageServer) ReadAllBatchHandler(args *ReadAllBatchArgs, reply *ReadAllBatchReply) error {
+	if !isRPCTokenValid(args.Token) {
+		return errInvalidToken
+	}
+	bufs, errs, err := s.storage.ReadAllBatch(args.Vol, args.Paths)
+	if err != nil {
+		return err
+	}
+	reply.Bufs = bufs
+	reply.Errs = make([]string, len(errs))
+	for i, err := range errs {
+		if err != nil {
+			reply.Errs[i] = err.Error()
+		}
+	}
+	return nil
+}
+
 // ReadFileHandler - read file handler is rpc wrapper to read file.
 func (s *storageServer) ReadFileHandler(args *ReadFileArgs, reply *[]byte) (err error) {
 	defer func() {
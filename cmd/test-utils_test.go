@@ -190,7 +190,8 @@ func initTestControlRPCEndPoint(objectLayer ObjectLayer) http.Handler {
 	// Initialize Web.
 
 	controllerHandlers := &controllerAPIHandlers{
-		ObjectAPI: func() ObjectLayer { return objectLayer },
+		ObjectAPI:        func() ObjectLayer { return objectLayer },
+		ShutdownSignalCh: globalShutdownSignalCh,
 	}
 
 	// Initialize router.
@@ -990,6 +991,34 @@ func getDeletePolicyURL(endPoint, bucketName string) string {
 	return makeTestTargetURL(endPoint, bucketName, "", queryValue)
 }
 
+// return URL for the ACL of a bucket or an object.
+func getACLURL(endPoint, bucketName, objectName string) string {
+	queryValue := url.Values{}
+	queryValue.Set("acl", "")
+	return makeTestTargetURL(endPoint, bucketName, objectName, queryValue)
+}
+
+// return URL for the object lock configuration of a bucket.
+func getObjectLockConfigURL(endPoint, bucketName string) string {
+	queryValue := url.Values{}
+	queryValue.Set("object-lock", "")
+	return makeTestTargetURL(endPoint, bucketName, "", queryValue)
+}
+
+// return URL for the retention of an object.
+func getObjectRetentionURL(endPoint, bucketName, objectName string) string {
+	queryValue := url.Values{}
+	queryValue.Set("retention", "")
+	return makeTestTargetURL(endPoint, bucketName, objectName, queryValue)
+}
+
+// return URL for the legal hold of an object.
+func getObjectLegalHoldURL(endPoint, bucketName, objectName string) string {
+	queryValue := url.Values{}
+	queryValue.Set("legal-hold", "")
+	return makeTestTargetURL(endPoint, bucketName, objectName, queryValue)
+}
+
 // return URL for creating the bucket.
 func getMakeBucketURL(endPoint, bucketName string) string {
 	return makeTestTargetURL(endPoint, bucketName, "", url.Values{})
@@ -1343,6 +1372,10 @@ func initTestAPIEndPoints(objLayer ObjectLayer, apiFunctions []string) http.Hand
 		// Register HeadBucket handler.
 		case "HeadBucket":
 			bucket.Methods("HEAD").HandlerFunc(api.HeadBucketHandler)
+		// Register DeleteBucket handler, after the handlers of DELETE
+		// on bucket sub-resources.
+		case "DeleteBucket":
+			bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketHandler)
 			// Register New Multipart upload handler.
 		case "NewMultipart":
 			bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.NewMultipartUploadHandler).Queries("uploads", "")
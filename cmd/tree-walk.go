@@ -23,9 +23,10 @@ import (
 
 // Tree walk result carries results of tree walking.
 type treeWalkResult struct {
-	entry string
-	err   error
-	end   bool
+	entry   string
+	err     error
+	end     bool
+	objInfo *ObjectInfo // Set by walks which read metadata, nil otherwise.
 }
 
 // posix.ListDir returns entries with trailing "/" for directories. At the object layer
@@ -35,6 +35,10 @@ var errInvalidArgument = errors.New("Invalid arguments specified")
 // errSignatureMismatch means signature did not match.
 var errSignatureMismatch = errors.New("Signature does not match")
 
+// errAccessDenied means the signature matched but the request is not allowed,
+// for example when its shared link was revoked.
+var errAccessDenied = errors.New("Access denied")
+
 // used when token used for authentication by the MinioBrowser has expired
 var errInvalidToken = errors.New("Invalid token")
 
@@ -17,10 +17,21 @@
 package cmd
 
 import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"hash"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
@@ -28,6 +39,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/sha256-simd"
 )
 
 // command specific flags.
@@ -37,6 +49,18 @@ var (
 			Name:  "experimental, E",
 			Usage: "Check experimental update.",
 		},
+		cli.BoolFlag{
+			Name:  "apply",
+			Usage: "Download, verify and install the new release in place of the current binary.",
+		},
+		cli.StringFlag{
+			Name:  "restart",
+			Usage: "Restart the server at this \"URL\" after installing the new release, it must run on this host.",
+		},
+		cli.StringFlag{
+			Name:  "url",
+			Usage: "Use a custom update \"URL\", for example a local mirror of the releases.",
+		},
 	}
 )
 
@@ -61,6 +85,13 @@ EXAMPLES:
 
    2. Check for any new experimental release.
       $ minio {{.Name}} --experimental
+
+   3. Install the new official release and restart the server on this host.
+      Servers of a distributed deployment are updated on each of their hosts.
+      $ minio {{.Name}} --apply --restart http://localhost:9000/
+
+   4. Install the new release from a local mirror.
+      $ minio {{.Name}} --apply --url http://mirror.local/server/minio/release
 `,
 }
 
@@ -70,6 +101,10 @@ const (
 	minioUpdateExperimentalURL = "https://dl.minio.io/server/minio/experimental"
 )
 
+// Published file of the form "<sha1sum> <release-name>", read for the
+// release date by update checks and for the checksum by updates.
+const minioShasumFile = "minio.shasum"
+
 // updateMessage container to hold update messages.
 type updateMessage struct {
 	Status   string `json:"status"`
@@ -128,22 +163,30 @@ func parseReleaseData(data string) (time.Time, error) {
 	return parsedDate, nil
 }
 
-// verify updates for releases.
-func getReleaseUpdate(updateURL string) (updateMsg updateMessage, errMsg string, err error) {
-	// Construct a new update url.
-	newUpdateURLPrefix := updateURL + "/" + runtime.GOOS + "-" + runtime.GOARCH
-	newUpdateURL := newUpdateURLPrefix + "/minio.shasum"
+// Returns the update url prefix for the current platform.
+func getUpdateURLPrefix(updateURL string) string {
+	return strings.TrimSuffix(updateURL, "/") + "/" + runtime.GOOS + "-" + runtime.GOARCH
+}
 
-	// Get the downloadURL.
-	var downloadURL string
+// Returns the download url of the release binary for the current platform.
+func getDownloadURL(updateURL string) string {
 	switch runtime.GOOS {
 	case "windows":
 		// For windows.
-		downloadURL = newUpdateURLPrefix + "/minio.exe?update=yes"
+		return getUpdateURLPrefix(updateURL) + "/minio.exe?update=yes"
 	default:
 		// For all other operating systems.
-		downloadURL = newUpdateURLPrefix + "/minio?update=yes"
+		return getUpdateURLPrefix(updateURL) + "/minio?update=yes"
 	}
+}
+
+// verify updates for releases.
+func getReleaseUpdate(updateURL string) (updateMsg updateMessage, errMsg string, err error) {
+	// Construct a new update url.
+	newUpdateURL := getUpdateURLPrefix(updateURL) + "/" + minioShasumFile
+
+	// Get the downloadURL.
+	downloadURL := getDownloadURL(updateURL)
 
 	// Initialize update message.
 	updateMsg = updateMessage{
@@ -215,6 +258,137 @@ func getReleaseUpdate(updateURL string) (updateMsg updateMessage, errMsg string,
 	return updateMsg, "", nil
 }
 
+// Parses the hex encoded checksum from the published checksum file
+// which is of the form "<checksum> <release-name>". Releases publish
+// the sha1sum of the binary, mirrors may publish its sha256sum, the
+// hash matching the length of the checksum is returned with it.
+func parseShasum(data string) (sum []byte, newHash func() hash.Hash, err error) {
+	fields := strings.Fields(data)
+	if len(fields) < 1 {
+		return nil, nil, errors.New("Checksum data malformed")
+	}
+	sum, err = hex.DecodeString(fields[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	switch len(sum) {
+	case sha1.Size:
+		return sum, sha1.New, nil
+	case sha256.Size:
+		return sum, sha256.New, nil
+	}
+	return nil, nil, errors.New("Checksum data malformed, not a sha1sum or sha256sum")
+}
+
+// Fetches the content at reqURL, http statuses other than 200 are errors.
+func fetchUpdateURL(client *http.Client, reqURL string) (io.ReadCloser, error) {
+	resp, err := client.Get(reqURL)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("Unable to fetch %s, http status : %s", reqURL, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// Returns the absolute path of the currently running binary.
+func getBinaryPath() (string, error) {
+	binaryPath, err := exec.LookPath(os.Args[0])
+	if err != nil {
+		return "", err
+	}
+	if binaryPath, err = filepath.Abs(binaryPath); err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(binaryPath)
+}
+
+// applyUpdate - downloads the release binary for the current platform,
+// verifies it against the published checksum and atomically replaces
+// the binary at binaryPath with it.
+func applyUpdate(updateURL, binaryPath string) error {
+	// Downloads may take long, do not use a short timeout here.
+	client := &http.Client{}
+
+	sumReader, err := fetchUpdateURL(client, getUpdateURLPrefix(updateURL)+"/"+minioShasumFile)
+	if err != nil {
+		return err
+	}
+	sumData, err := ioutil.ReadAll(sumReader)
+	sumReader.Close()
+	if err != nil {
+		return err
+	}
+	expectedSum, newHash, err := parseShasum(string(sumData))
+	if err != nil {
+		return err
+	}
+
+	binaryInfo, err := os.Stat(binaryPath)
+	if err != nil {
+		return err
+	}
+
+	// Download into the same directory as the binary so that
+	// the final rename does not cross filesystem boundaries.
+	tmpFile, err := ioutil.TempFile(filepath.Dir(binaryPath), ".minio.update-")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	binReader, err := fetchUpdateURL(client, getDownloadURL(updateURL))
+	if err != nil {
+		tmpFile.Close()
+		return err
+	}
+	hashWriter := newHash()
+	_, err = io.Copy(io.MultiWriter(tmpFile, hashWriter), binReader)
+	binReader.Close()
+	if cerr := tmpFile.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	if sum := hashWriter.Sum(nil); !bytes.Equal(sum, expectedSum) {
+		return fmt.Errorf("Checksum mismatch, expected %s, got %s", hex.EncodeToString(expectedSum), hex.EncodeToString(sum))
+	}
+
+	if err = os.Chmod(tmpPath, binaryInfo.Mode()); err != nil {
+		return err
+	}
+
+	// Running executables cannot be replaced on windows, move
+	// it out of the way first.
+	return replaceBinary(tmpPath, binaryPath, runtime.GOOS == "windows")
+}
+
+// replaceBinary - renames the new binary at tmpPath to binaryPath. With
+// moveOld the current binary is first moved to binaryPath.old, and moved
+// back if the new binary cannot be renamed.
+func replaceBinary(tmpPath, binaryPath string, moveOld bool) error {
+	if !moveOld {
+		return os.Rename(tmpPath, binaryPath)
+	}
+	oldPath := binaryPath + ".old"
+	os.Remove(oldPath)
+	if err := os.Rename(binaryPath, oldPath); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, binaryPath); err != nil {
+		if rerr := os.Rename(oldPath, binaryPath); rerr != nil {
+			return fmt.Errorf("%s, unable to restore the binary from %s: %s", err, oldPath, rerr)
+		}
+		return err
+	}
+	return nil
+}
+
 // main entry point for update command.
 func mainUpdate(ctx *cli.Context) {
 	// Error out if 'update' command is issued for development based builds.
@@ -222,15 +396,46 @@ func mainUpdate(ctx *cli.Context) {
 		fatalIf(errors.New(""), "Update mechanism is not supported for ‘go get’ based binary builds. Please download official releases from https://minio.io/#minio")
 	}
 
-	// Check for update.
-	var updateMsg updateMessage
-	var errMsg string
-	var err error
+	// Choose the update url, a custom url takes precedence.
+	updateURL := minioUpdateStableURL
 	if ctx.Bool("experimental") {
-		updateMsg, errMsg, err = getReleaseUpdate(minioUpdateExperimentalURL)
-	} else {
-		updateMsg, errMsg, err = getReleaseUpdate(minioUpdateStableURL)
+		updateURL = minioUpdateExperimentalURL
+	}
+	if customURL := ctx.String("url"); customURL != "" {
+		updateURL = customURL
 	}
+
+	// Check for update.
+	updateMsg, errMsg, err := getReleaseUpdate(updateURL)
 	fatalIf(err, errMsg)
 	console.Println(updateMsg)
+
+	if !ctx.Bool("apply") || !updateMsg.Update {
+		return
+	}
+
+	binaryPath, err := getBinaryPath()
+	fatalIf(err, "Unable to find the path of the running binary.")
+
+	err = applyUpdate(updateURL, binaryPath)
+	fatalIf(err, "Unable to install the new release at %s.", binaryPath)
+	console.Println("Installed the new release at " + binaryPath + ".")
+
+	if restartURL := ctx.String("restart"); restartURL != "" {
+		parsedURL, err := url.Parse(restartURL)
+		fatalIf(err, "Unable to parse URL.")
+
+		// Only the server on this host runs the installed release,
+		// its peers are left running.
+		host := parsedURL.Host
+		if h, _, serr := net.SplitHostPort(host); serr == nil {
+			host = h
+		}
+		if !isLocalHost(host) {
+			fatalIf(errors.New("not a local server"), "Only the server on this host can be restarted with the new release, %s is not local.", parsedURL.Host)
+		}
+		_, err = callService(parsedURL.Host, parsedURL.Scheme == "https", serviceRestart, true)
+		fatalIf(err, "Restart of Minio server %s failed.", parsedURL.Host)
+		console.Println("Restarted Minio server " + parsedURL.Host + ".")
+	}
 }
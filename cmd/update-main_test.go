@@ -0,0 +1,225 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minio/sha256-simd"
+)
+
+// Tests parsing of the published checksum file.
+func TestParseShasum(t *testing.T) {
+	sha1Sum := sha1.Sum([]byte("minio"))
+	sha256Sum := sha256.Sum256([]byte("minio"))
+	sha1Hex := hex.EncodeToString(sha1Sum[:])
+	sha256Hex := hex.EncodeToString(sha256Sum[:])
+	testCases := []struct {
+		data       string
+		hexSum     string
+		shouldPass bool
+	}{
+		// Format of the published minio.shasum.
+		{sha1Hex + " minio.RELEASE.2016-10-07T01-16-39Z", sha1Hex, true},
+		{sha256Hex + " minio.RELEASE.2016-10-07T01-16-39Z", sha256Hex, true},
+		{sha256Hex, sha256Hex, true},
+		{"", "", false},
+		{"zz minio.RELEASE.2016-10-07T01-16-39Z", "", false},
+		{sha256Hex[:48] + " minio.RELEASE.2016-10-07T01-16-39Z", "", false},
+	}
+	for i, testCase := range testCases {
+		parsedSum, newHash, err := parseShasum(testCase.data)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Expected to pass, but failed with: %s", i+1, err)
+			continue
+		}
+		if !testCase.shouldPass {
+			if err == nil {
+				t.Errorf("Test %d: Expected to fail, but passed", i+1)
+			}
+			continue
+		}
+		if hex.EncodeToString(parsedSum) != testCase.hexSum {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.hexSum, hex.EncodeToString(parsedSum))
+		}
+		h := newHash()
+		h.Write([]byte("minio"))
+		if hex.EncodeToString(h.Sum(nil)) != testCase.hexSum {
+			t.Errorf("Test %d: Expected the hash of the checksum %s", i+1, testCase.hexSum)
+		}
+	}
+}
+
+// Tests downloading, verifying and replacing the binary from a local mirror.
+func TestApplyUpdate(t *testing.T) {
+	newBinary := []byte("new minio binary")
+	sum := sha1.Sum(newBinary)
+	// Same format as the published minio.shasum.
+	publishedSum := hex.EncodeToString(sum[:])
+
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/"+minioShasumFile):
+			w.Write([]byte(publishedSum + " minio.RELEASE.2016-10-07T01-16-39Z\n"))
+		case strings.HasSuffix(r.URL.Path, "/minio"), strings.HasSuffix(r.URL.Path, "/minio.exe"):
+			w.Write(newBinary)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mirror.Close()
+
+	dir, err := ioutil.TempDir("", "minio-update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(dir)
+
+	binaryPath := filepath.Join(dir, "minio")
+	if err = ioutil.WriteFile(binaryPath, []byte("old minio binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Binary is replaced when the checksum matches.
+	if err = applyUpdate(mirror.URL, binaryPath); err != nil {
+		t.Fatalf("Unable to apply update: %s", err)
+	}
+	data, err := ioutil.ReadFile(binaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(newBinary) {
+		t.Fatalf("Expected the binary to be replaced, got %q", string(data))
+	}
+	fi, err := os.Stat(binaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm()&0100 == 0 {
+		t.Fatalf("Expected the binary to stay executable, got %s", fi.Mode())
+	}
+
+	// Mirrors may publish the sha256sum instead.
+	sha256Sum := sha256.Sum256(newBinary)
+	publishedSum = hex.EncodeToString(sha256Sum[:])
+	if err = ioutil.WriteFile(binaryPath, []byte("old minio binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = applyUpdate(mirror.URL, binaryPath); err != nil {
+		t.Fatalf("Unable to apply update: %s", err)
+	}
+	if data, err = ioutil.ReadFile(binaryPath); err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(newBinary) {
+		t.Fatalf("Expected the binary to be replaced, got %q", string(data))
+	}
+
+	// Binary is left untouched when the checksum does not match.
+	publishedSum = strings.Repeat("0", sha256.Size*2)
+	if err = ioutil.WriteFile(binaryPath, []byte("old minio binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = applyUpdate(mirror.URL, binaryPath); err == nil {
+		t.Fatal("Expected checksum mismatch, but update succeeded")
+	}
+	if data, err = ioutil.ReadFile(binaryPath); err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "old minio binary" {
+		t.Fatalf("Expected the binary to be left untouched, got %q", string(data))
+	}
+
+	// No temporary files are left behind.
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected only the binary in %s, found %d entries", dir, len(entries))
+	}
+}
+
+// Tests that the binary moved out of the way is restored when the new
+// binary cannot be renamed in its place.
+func TestReplaceBinary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minio-update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(dir)
+
+	binaryPath := filepath.Join(dir, "minio.exe")
+	tmpPath := filepath.Join(dir, ".minio.update-test")
+	if err = ioutil.WriteFile(binaryPath, []byte("old minio binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// New binary missing, the old one is put back.
+	if err = replaceBinary(tmpPath, binaryPath, true); err == nil {
+		t.Fatal("Expected an error for a missing new binary")
+	}
+	data, err := ioutil.ReadFile(binaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "old minio binary" {
+		t.Fatalf("Expected the binary to be restored, got %q", string(data))
+	}
+
+	// New binary in place, the old one is kept aside.
+	if err = ioutil.WriteFile(tmpPath, []byte("new minio binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = replaceBinary(tmpPath, binaryPath, true); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = ioutil.ReadFile(binaryPath); err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new minio binary" {
+		t.Fatalf("Expected the binary to be replaced, got %q", string(data))
+	}
+	if data, err = ioutil.ReadFile(binaryPath + ".old"); err != nil || string(data) != "old minio binary" {
+		t.Fatalf("Expected the old binary to be kept aside, got %q, %v", string(data), err)
+	}
+}
+
+// Tests that only servers on this host are restarted after an update.
+func TestIsLocalHost(t *testing.T) {
+	testCases := []struct {
+		host  string
+		local bool
+	}{
+		{"localhost", true},
+		{"127.0.0.1", true},
+		{"192.0.2.1", false},
+	}
+	for i, testCase := range testCases {
+		if local := isLocalHost(testCase.host); local != testCase.local {
+			t.Errorf("Test %d: Expected %v for %s, got %v", i+1, testCase.local, testCase.host, local)
+		}
+	}
+}
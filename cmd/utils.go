@@ -25,7 +25,6 @@ import (
 	"net"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -310,14 +309,9 @@ func startMonitorShutdownSignal(onExitFn onExitFunc) error {
 				// any concurrent process could be
 				// started again
 				if signal == shutdownRestart {
-					path := os.Args[0]
-					cmdArgs := os.Args[1:]
-					cmd := exec.Command(path, cmdArgs...)
-					cmd.Stdout = os.Stdout
-					cmd.Stderr = os.Stderr
-
-					err := cmd.Start()
-					if err != nil {
+					// On success the process image is replaced
+					// and this call does not return.
+					if err := restartProcess(); err != nil {
 						errorIf(errors.New("Unable to reboot."), err.Error())
 					}
 
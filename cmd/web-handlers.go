@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"archive/zip"
 	"bytes"
 	"encoding/json"
 	"errors"
@@ -26,6 +27,7 @@ import (
 	"os"
 	"path"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -273,12 +275,133 @@ func (web *webAPIHandlers) RemoveObject(r *http.Request, args *RemoveObjectArgs,
 	if objectAPI == nil {
 		return &json2.Error{Message: "Server not initialized, please try again."}
 	}
+	// Objects under retention or legal hold cannot be deleted.
+	if err := enforceObjectLock(objectAPI, args.BucketName, args.ObjectName, r); err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
 	if err := objectAPI.DeleteObject(args.BucketName, args.ObjectName); err != nil {
 		return &json2.Error{Message: err.Error()}
 	}
 	return nil
 }
 
+// RemovePrefixArgs - args to remove all the objects under a prefix.
+type RemovePrefixArgs struct {
+	BucketName string `json:"bucketName"`
+	Prefix     string `json:"prefix"`
+}
+
+// WebOperationRep - reply for operations running in the background.
+type WebOperationRep struct {
+	OperationID string `json:"operationId"`
+	UIVersion   string `json:"uiVersion"`
+}
+
+// RemovePrefix - removes all the objects under a prefix in the
+// background, progress is fetched with GetOperationProgress.
+func (web *webAPIHandlers) RemovePrefix(r *http.Request, args *RemovePrefixArgs, reply *WebOperationRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized, please try again."}
+	}
+	if !strings.HasSuffix(args.Prefix, slashSeparator) {
+		return &json2.Error{Message: "Prefix must end with " + slashSeparator}
+	}
+	if err := isBucketExist(args.BucketName, objectAPI); err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	reply.OperationID = globalWebOperations.start(func(op *webOperation) error {
+		return walkObjects(objectAPI, args.BucketName, args.Prefix, func(object string) error {
+			err := removeWebObject(objectAPI, args.BucketName, object, r)
+			errorIf(err, "Unable to remove %s/%s.", args.BucketName, object)
+			op.update(err)
+			return nil
+		})
+	})
+	reply.UIVersion = miniobrowser.UIVersion
+	return nil
+}
+
+// MoveObjectsArgs - args to move an object, or all the objects under
+// a prefix ending with "/", to a new name.
+type MoveObjectsArgs struct {
+	BucketName  string `json:"bucketName"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+// MoveObjects - renames an object or a prefix in the background by
+// copying each object on the server and removing the source, progress
+// is fetched with GetOperationProgress.
+func (web *webAPIHandlers) MoveObjects(r *http.Request, args *MoveObjectsArgs, reply *WebOperationRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized, please try again."}
+	}
+	isPrefix := strings.HasSuffix(args.Source, slashSeparator)
+	if args.Source == "" || args.Destination == "" {
+		return &json2.Error{Message: "Source and destination are required"}
+	}
+	if isPrefix != strings.HasSuffix(args.Destination, slashSeparator) {
+		return &json2.Error{Message: "Source and destination must both be objects or both be prefixes"}
+	}
+	if args.Source == args.Destination {
+		return &json2.Error{Message: "Source and destination are the same"}
+	}
+	if isPrefix && strings.HasPrefix(args.Destination, args.Source) {
+		return &json2.Error{Message: "Destination cannot be inside the source prefix"}
+	}
+	if err := isBucketExist(args.BucketName, objectAPI); err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	reply.OperationID = globalWebOperations.start(func(op *webOperation) error {
+		return walkObjects(objectAPI, args.BucketName, args.Source, func(object string) error {
+			dstObject := args.Destination + strings.TrimPrefix(object, args.Source)
+			err := copyWebObject(objectAPI, args.BucketName, object, dstObject, r)
+			if err == nil {
+				err = removeWebObject(objectAPI, args.BucketName, object, r)
+			}
+			errorIf(err, "Unable to move %s/%s to %s.", args.BucketName, object, dstObject)
+			op.update(err)
+			return nil
+		})
+	})
+	reply.UIVersion = miniobrowser.UIVersion
+	return nil
+}
+
+// GetOperationProgressArgs - args to fetch the progress of an operation.
+type GetOperationProgressArgs struct {
+	OperationID string `json:"operationId"`
+}
+
+// GetOperationProgressRep - progress of an operation.
+type GetOperationProgressRep struct {
+	Progress  webOperationProgress `json:"progress"`
+	UIVersion string               `json:"uiVersion"`
+}
+
+// GetOperationProgress - returns the progress of a RemovePrefix or
+// MoveObjects operation.
+func (web *webAPIHandlers) GetOperationProgress(r *http.Request, args *GetOperationProgressArgs, reply *GetOperationProgressRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	progress, ok := globalWebOperations.get(args.OperationID)
+	if !ok {
+		return &json2.Error{Message: "Operation " + args.OperationID + " not found"}
+	}
+	reply.Progress = progress
+	reply.UIVersion = miniobrowser.UIVersion
+	return nil
+}
+
 // LoginArgs - login arguments.
 type LoginArgs struct {
 	Username string `json:"username" form:"username"`
@@ -417,6 +540,15 @@ func (web *webAPIHandlers) Upload(w http.ResponseWriter, r *http.Request) {
 		writeWebErrorResponse(w, errors.New("Server not initialized, please try again."))
 		return
 	}
+
+	// Save default retention of the bucket, an existing locked
+	// object cannot be overwritten.
+	if s3Error := applyObjectLock(objectAPI, bucket, object, r, metadata); s3Error != ErrNone {
+		apiErr := getAPIError(s3Error)
+		w.WriteHeader(apiErr.HTTPStatusCode)
+		w.Write([]byte(apiErr.Description))
+		return
+	}
 	if _, err := objectAPI.PutObject(bucket, object, -1, r.Body, metadata); err != nil {
 		writeWebErrorResponse(w, err)
 		return
@@ -442,6 +574,251 @@ func (web *webAPIHandlers) Upload(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// NewMultipartUploadArgs - new multipart upload args.
+type NewMultipartUploadArgs struct {
+	BucketName  string `json:"bucketName"`
+	ObjectName  string `json:"objectName"`
+	ContentType string `json:"contentType"`
+}
+
+// NewMultipartUploadRep - new multipart upload reply.
+type NewMultipartUploadRep struct {
+	UploadID  string `json:"uploadId"`
+	UIVersion string `json:"uiVersion"`
+}
+
+// NewMultipartUpload - starts a multipart upload, parts are uploaded
+// with UploadPart. Metadata, canned ACL and object lock headers of
+// the request are saved same as the S3 multipart handlers.
+func (web *webAPIHandlers) NewMultipartUpload(r *http.Request, args *NewMultipartUploadArgs, reply *NewMultipartUploadRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized, please try again."}
+	}
+
+	// Extract metadata that needs to be saved, content type of the
+	// request is that of the JSON RPC.
+	metadata := extractMetadataFromHeader(r.Header)
+	delete(metadata, "content-type")
+	if args.ContentType != "" {
+		metadata["content-type"] = args.ContentType
+	}
+
+	// Save the canned ACL of the object.
+	acl, ok := getCannedACL(r)
+	if !ok {
+		return &json2.Error{Message: getAPIError(ErrInvalidCannedACL).Description}
+	}
+	setObjectACLMetadata(metadata, acl)
+
+	// Save retention and legal hold of the object, they take effect
+	// once the upload is completed.
+	if s3Error := applyObjectLock(objectAPI, args.BucketName, args.ObjectName, r, metadata); s3Error != ErrNone {
+		return &json2.Error{Message: getAPIError(s3Error).Description}
+	}
+
+	uploadID, err := objectAPI.NewMultipartUpload(args.BucketName, args.ObjectName, metadata)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	reply.UploadID = uploadID
+	reply.UIVersion = miniobrowser.UIVersion
+	return nil
+}
+
+// UploadPart - uploads a part of a multipart upload started with
+// NewMultipartUpload, an interrupted part is uploaded again.
+func (web *webAPIHandlers) UploadPart(w http.ResponseWriter, r *http.Request) {
+	if !isJWTReqAuthenticated(r) {
+		writeWebErrorResponse(w, errInvalidToken)
+		return
+	}
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+	uploadID := vars["uploadId"]
+
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		writeWebErrorResponse(w, errors.New("Server not initialized, please try again."))
+		return
+	}
+
+	// Size of the part is needed up front.
+	size := r.ContentLength
+	if size == -1 {
+		writeWebErrorResponse(w, errors.New(getAPIError(ErrMissingContentLength).Description))
+		return
+	}
+	if isMaxObjectSize(size) {
+		writeWebErrorResponse(w, errors.New(getAPIError(ErrEntityTooLarge).Description))
+		return
+	}
+
+	partID, err := strconv.Atoi(vars["partNumber"])
+	if err != nil || isMaxPartID(partID) {
+		writeWebErrorResponse(w, errors.New(getAPIError(ErrInvalidPart).Description))
+		return
+	}
+
+	partMD5, err := objectAPI.PutObjectPart(bucket, object, uploadID, partID, size, r.Body, "")
+	if err != nil {
+		writeWebErrorResponse(w, errorCause(err))
+		return
+	}
+	w.Header().Set("ETag", "\""+partMD5+"\"")
+}
+
+// ListObjectPartsArgs - list object parts args.
+type ListObjectPartsArgs struct {
+	BucketName       string `json:"bucketName"`
+	ObjectName       string `json:"objectName"`
+	UploadID         string `json:"uploadId"`
+	PartNumberMarker int    `json:"partNumberMarker"`
+}
+
+// WebPartInfo - an uploaded part.
+type WebPartInfo struct {
+	PartNumber   int       `json:"partNumber"`
+	ETag         string    `json:"etag"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// ListObjectPartsRep - list object parts reply.
+type ListObjectPartsRep struct {
+	Parts                []WebPartInfo `json:"parts"`
+	NextPartNumberMarker int           `json:"nextPartNumberMarker"`
+	IsTruncated          bool          `json:"isTruncated"`
+	UIVersion            string        `json:"uiVersion"`
+}
+
+// ListObjectParts - lists the parts uploaded so far, an interrupted
+// upload is resumed from the parts which are missing.
+func (web *webAPIHandlers) ListObjectParts(r *http.Request, args *ListObjectPartsArgs, reply *ListObjectPartsRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized, please try again."}
+	}
+	listPartsInfo, err := objectAPI.ListObjectParts(args.BucketName, args.ObjectName, args.UploadID, args.PartNumberMarker, maxPartsList)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	for _, part := range listPartsInfo.Parts {
+		reply.Parts = append(reply.Parts, WebPartInfo{
+			PartNumber:   part.PartNumber,
+			ETag:         part.ETag,
+			Size:         part.Size,
+			LastModified: part.LastModified,
+		})
+	}
+	reply.NextPartNumberMarker = listPartsInfo.NextPartNumberMarker
+	reply.IsTruncated = listPartsInfo.IsTruncated
+	reply.UIVersion = miniobrowser.UIVersion
+	return nil
+}
+
+// CompleteMultipartUploadArgs - complete multipart upload args.
+type CompleteMultipartUploadArgs struct {
+	BucketName string        `json:"bucketName"`
+	ObjectName string        `json:"objectName"`
+	UploadID   string        `json:"uploadId"`
+	Parts      []WebPartInfo `json:"parts"`
+}
+
+// CompleteMultipartUploadRep - complete multipart upload reply.
+type CompleteMultipartUploadRep struct {
+	ETag      string `json:"etag"`
+	UIVersion string `json:"uiVersion"`
+}
+
+// CompleteMultipartUpload - completes a multipart upload from its
+// uploaded parts, in order of part number.
+func (web *webAPIHandlers) CompleteMultipartUpload(r *http.Request, args *CompleteMultipartUploadArgs, reply *CompleteMultipartUploadRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized, please try again."}
+	}
+	if len(args.Parts) == 0 {
+		return &json2.Error{Message: getAPIError(ErrMalformedXML).Description}
+	}
+	var completeParts []completePart
+	for _, part := range args.Parts {
+		completeParts = append(completeParts, completePart{
+			PartNumber: part.PartNumber,
+			ETag:       strings.Trim(part.ETag, "\""),
+		})
+	}
+	if !sort.IsSorted(completedParts(completeParts)) {
+		return &json2.Error{Message: getAPIError(ErrInvalidPartOrder).Description}
+	}
+
+	// Verify the existing object, if any, can be overwritten.
+	if err := enforceObjectLock(objectAPI, args.BucketName, args.ObjectName, r); err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+
+	md5Sum, err := objectAPI.CompleteMultipartUpload(args.BucketName, args.ObjectName, args.UploadID, completeParts)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	reply.ETag = md5Sum
+	reply.UIVersion = miniobrowser.UIVersion
+
+	if globalEventNotifier.IsBucketNotificationSet(args.BucketName) {
+		// Fetch object info for notifications.
+		objInfo, err := objectAPI.GetObjectInfo(args.BucketName, args.ObjectName)
+		if err != nil {
+			errorIf(err, "Unable to fetch object info for \"%s\"", path.Join(args.BucketName, args.ObjectName))
+			return nil
+		}
+
+		// Notify object created event.
+		eventNotify(eventData{
+			Type:    ObjectCreatedCompleteMultipartUpload,
+			Bucket:  args.BucketName,
+			ObjInfo: objInfo,
+			ReqParams: map[string]string{
+				"sourceIPAddress": r.RemoteAddr,
+			},
+		})
+	}
+	return nil
+}
+
+// AbortMultipartUploadArgs - abort multipart upload args.
+type AbortMultipartUploadArgs struct {
+	BucketName string `json:"bucketName"`
+	ObjectName string `json:"objectName"`
+	UploadID   string `json:"uploadId"`
+}
+
+// AbortMultipartUpload - aborts a multipart upload, removing the parts
+// uploaded so far.
+func (web *webAPIHandlers) AbortMultipartUpload(r *http.Request, args *AbortMultipartUploadArgs, reply *WebGenericRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized, please try again."}
+	}
+	if err := objectAPI.AbortMultipartUpload(args.BucketName, args.ObjectName, args.UploadID); err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	reply.UIVersion = miniobrowser.UIVersion
+	return nil
+}
+
 // Download - file download handler.
 func (web *webAPIHandlers) Download(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -449,10 +826,38 @@ func (web *webAPIHandlers) Download(w http.ResponseWriter, r *http.Request) {
 	object := vars["object"]
 	tokenStr := r.URL.Query().Get("token")
 
+	if !isJWTTokenValid(tokenStr) {
+		writeWebErrorResponse(w, errInvalidToken)
+		return
+	}
+	// Add content disposition.
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", path.Base(object)))
+
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		writeWebErrorResponse(w, errors.New("Server not initialized, please try again."))
+		return
+	}
+	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		writeWebErrorResponse(w, err)
+		return
+	}
+	offset := int64(0)
+	err = objectAPI.GetObject(bucket, object, offset, objInfo.Size, w)
+	if err != nil {
+		/// No need to print error, response writer already written to.
+		return
+	}
+}
+
+// isJWTTokenValid - validates a JWT passed in the query string, for
+// downloads started by the browser which cannot set headers.
+func isJWTTokenValid(tokenStr string) bool {
 	jwt, err := newJWT(defaultWebTokenExpiry) // Expiry set to 24Hrs.
 	if err != nil {
 		errorIf(err, "error in getting new JWT")
-		return
+		return false
 	}
 
 	token, e := jwtgo.Parse(tokenStr, func(token *jwtgo.Token) (interface{}, error) {
@@ -461,29 +866,101 @@ func (web *webAPIHandlers) Download(w http.ResponseWriter, r *http.Request) {
 		}
 		return []byte(jwt.SecretAccessKey), nil
 	})
-	if e != nil || !token.Valid {
+	return e == nil && token.Valid
+}
+
+// DownloadZipArgs - Argument for downloading a bunch of files as a zip file.
+// JSON will look like:
+// '{"bucketname":"testbucket","prefix":"john/pics/","objects":["hawaii/","maldives/","sanjose.jpg"]}'
+type DownloadZipArgs struct {
+	Objects    []string `json:"objects"`    // can be files or sub-directories
+	Prefix     string   `json:"prefix"`     // current directory in the browser-ui
+	BucketName string   `json:"bucketname"` // bucket name.
+}
+
+// DownloadZip - streams a zip archive of the selected objects and
+// sub-directories, assembled on the fly without temporary disk space.
+func (web *webAPIHandlers) DownloadZip(w http.ResponseWriter, r *http.Request) {
+	tokenStr := r.URL.Query().Get("token")
+	if !isJWTTokenValid(tokenStr) {
 		writeWebErrorResponse(w, errInvalidToken)
 		return
 	}
-	// Add content disposition.
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", path.Base(object)))
 
 	objectAPI := web.ObjectAPI()
 	if objectAPI == nil {
 		writeWebErrorResponse(w, errors.New("Server not initialized, please try again."))
 		return
 	}
-	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
-	if err != nil {
+
+	var args DownloadZipArgs
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
 		writeWebErrorResponse(w, err)
 		return
 	}
-	offset := int64(0)
-	err = objectAPI.GetObject(bucket, object, offset, objInfo.Size, w)
-	if err != nil {
-		/// No need to print error, response writer already written to.
+	if err := isBucketExist(args.BucketName, objectAPI); err != nil {
+		writeWebErrorResponse(w, errorCause(err))
 		return
 	}
+
+	archive := zip.NewWriter(w)
+	defer archive.Close()
+
+	// Adds an object to the archive, named relative to the prefix.
+	zipit := func(objectName string) error {
+		objInfo, err := objectAPI.GetObjectInfo(args.BucketName, objectName)
+		if err != nil {
+			return err
+		}
+		header := &zip.FileHeader{
+			Name:   strings.TrimPrefix(objectName, args.Prefix),
+			Method: zip.Deflate,
+		}
+		header.SetModTime(objInfo.ModTime)
+		writer, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		return objectAPI.GetObject(args.BucketName, objectName, 0, objInfo.Size, writer)
+	}
+
+	// Headers are written with the first object, errors after
+	// that can only abort the archive.
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.zip\"", path.Base(pathJoin(args.BucketName, args.Prefix))))
+
+	for _, object := range args.Objects {
+		objectName := pathJoin(args.Prefix, object)
+		if !strings.HasSuffix(object, slashSeparator) {
+			// If not a directory, compress the file and write it to response.
+			if err := zipit(objectName); err != nil {
+				errorIf(err, "Unable to add %s to the zip archive.", objectName)
+				return
+			}
+			continue
+		}
+
+		// For directories, list the contents recursively and write the objects as compressed
+		// data to the response writer.
+		marker := ""
+		for {
+			lo, err := objectAPI.ListObjects(args.BucketName, objectName, marker, "", 1000)
+			if err != nil {
+				errorIf(err, "Unable to list %s for the zip archive.", objectName)
+				return
+			}
+			marker = lo.NextMarker
+			for _, obj := range lo.Objects {
+				if err = zipit(obj.Name); err != nil {
+					errorIf(err, "Unable to add %s to the zip archive.", obj.Name)
+					return
+				}
+			}
+			if !lo.IsTruncated {
+				break
+			}
+		}
+	}
 }
 
 // writeWebErrorResponse - set HTTP status code and write error description to the body.
@@ -610,7 +1087,7 @@ func (web *webAPIHandlers) GetAllBucketPolicy(r *http.Request, args *GetAllBucke
 		return &json2.Error{Message: err.Error()}
 	}
 
-	policies := policy.GetPolicies(policyInfo.Statements, args.BucketName)
+	policies := policy.GetPolicies(policyInfo.Statements, args.BucketName, "")
 
 	reply.UIVersion = miniobrowser.UIVersion
 	reply.Policies = policies
@@ -682,12 +1159,18 @@ type PresignedGetArgs struct {
 
 	// Object name to be presigned.
 	ObjectName string `json:"object"`
+
+	// Expiry in seconds, defaults to and cannot exceed 7 days.
+	Expiry int64 `json:"expiry"`
 }
 
 // PresignedGetRep - presigned-get URL reply.
 type PresignedGetRep struct {
 	// Presigned URL of the object.
 	URL string `json:"url"`
+
+	// ID of the shared link, used to revoke it.
+	ID string `json:"id"`
 }
 
 // PresignedGET - returns presigned-Get url.
@@ -698,42 +1181,205 @@ func (web *webAPIHandlers) PresignedGet(r *http.Request, args *PresignedGetArgs,
 	if args.BucketName == "" || args.ObjectName == "" {
 		return &json2.Error{Message: "Required arguments: Host, Bucket, Object"}
 	}
-	reply.URL = presignedGet(args.HostName, args.BucketName, args.ObjectName)
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized, please try again."}
+	}
+	presignedURL, id, err := presignSharedLink(objectAPI, args.HostName, "GET", args.BucketName, args.ObjectName, args.Expiry)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	reply.URL, reply.ID = presignedURL, id
 	return nil
 }
 
-// Returns presigned url for GET method.
-func presignedGet(host, bucket, object string) string {
-	cred := serverConfig.GetCredential()
-	region := serverConfig.GetRegion()
+// PresignedPutArgs - presigned-put API args.
+type PresignedPutArgs struct {
+	// Host header required for signed headers.
+	HostName string `json:"host"`
+
+	// Bucket name of the object to be uploaded.
+	BucketName string `json:"bucket"`
 
-	accessKey := cred.AccessKeyID
-	secretKey := cred.SecretAccessKey
+	// Object name to be uploaded.
+	ObjectName string `json:"object"`
+
+	// Expiry in seconds, defaults to and cannot exceed 7 days.
+	Expiry int64 `json:"expiry"`
+}
+
+// PresignedPutRep - presigned-put URL reply.
+type PresignedPutRep struct {
+	// Presigned URL to upload the object.
+	URL string `json:"url"`
 
+	// ID of the shared link, used to revoke it.
+	ID string `json:"id"`
+}
+
+// PresignedPut - returns presigned-Put url, the object can be uploaded
+// without credentials until the link expires or is revoked.
+func (web *webAPIHandlers) PresignedPut(r *http.Request, args *PresignedPutArgs, reply *PresignedPutRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	if args.BucketName == "" || args.ObjectName == "" {
+		return &json2.Error{Message: "Required arguments: Host, Bucket, Object"}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized, please try again."}
+	}
+	presignedURL, id, err := presignSharedLink(objectAPI, args.HostName, "PUT", args.BucketName, args.ObjectName, args.Expiry)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	reply.URL, reply.ID = presignedURL, id
+	return nil
+}
+
+// PresignedPostPolicyArgs - presigned-post-policy API args.
+type PresignedPostPolicyArgs struct {
+	// Host header of the server.
+	HostName string `json:"host"`
+
+	// Bucket name to upload to.
+	BucketName string `json:"bucket"`
+
+	// Objects can only be uploaded under this prefix.
+	Prefix string `json:"prefix"`
+
+	// Expiry in seconds, defaults to and cannot exceed 7 days.
+	Expiry int64 `json:"expiry"`
+}
+
+// PresignedPostPolicyRep - presigned-post-policy reply.
+type PresignedPostPolicyRep struct {
+	// URL the form is posted to.
+	URL string `json:"url"`
+
+	// Form fields to be sent along with 'key' and 'file'.
+	FormData map[string]string `json:"formData"`
+
+	// ID of the shared link, used to revoke it.
+	ID string `json:"id"`
+}
+
+// PresignedPostPolicy - returns a signed POST policy form, objects can
+// be uploaded under the prefix without credentials until the link
+// expires or is revoked.
+func (web *webAPIHandlers) PresignedPostPolicy(r *http.Request, args *PresignedPostPolicyArgs, reply *PresignedPostPolicyRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	if args.BucketName == "" {
+		return &json2.Error{Message: "Required arguments: Host, Bucket"}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized, please try again."}
+	}
+	expiry, err := getSharedLinkExpiry(args.Expiry)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
 	date := time.Now().UTC()
-	dateStr := date.Format("20060102T150405Z")
-	credential := fmt.Sprintf("%s/%s", accessKey, getScope(date, region))
+	link, err := addSharedLink(args.BucketName, "POST", args.Prefix, date, expiry, objectAPI)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	reply.URL = args.HostName + "/" + args.BucketName
+	reply.FormData = presignPostPolicy(args.BucketName, args.Prefix, link.ID, date, expiry)
+	reply.ID = link.ID
+	return nil
+}
 
-	query := strings.Join([]string{
-		"X-Amz-Algorithm=" + signV4Algorithm,
-		"X-Amz-Credential=" + strings.Replace(credential, "/", "%2F", -1),
-		"X-Amz-Date=" + dateStr,
-		"X-Amz-Expires=" + "604800", // Default set to be expire in 7days.
-		"X-Amz-SignedHeaders=host",
-	}, "&")
+// presignSharedLink - saves a new shared link and returns its presigned URL.
+func presignSharedLink(objectAPI ObjectLayer, host, method, bucket, object string, expirySecs int64) (string, string, error) {
+	expiry, err := getSharedLinkExpiry(expirySecs)
+	if err != nil {
+		return "", "", err
+	}
+	date := time.Now().UTC()
+	link, err := addSharedLink(bucket, method, object, date, expiry, objectAPI)
+	if err != nil {
+		return "", "", err
+	}
+	return presignURL(host, method, bucket, object, link.ID, date, expiry), link.ID, nil
+}
 
-	path := "/" + path.Join(bucket, object)
+// ListSharedLinksArgs - list shared links args.
+type ListSharedLinksArgs struct {
+	BucketName string `json:"bucketName"`
+}
 
-	// Headers are empty, since "host" is the only header required to be signed for Presigned URLs.
-	var extractedSignedHeaders http.Header
+// SharedLinkInfo - an active shared link.
+type SharedLinkInfo struct {
+	ID         string    `json:"id"`
+	Method     string    `json:"method"`
+	ObjectName string    `json:"objectName"`
+	Created    time.Time `json:"created"`
+	Expiry     time.Time `json:"expiry"`
+}
 
-	canonicalRequest := getCanonicalRequest(extractedSignedHeaders, unsignedPayload, query, path, "GET", host)
-	stringToSign := getStringToSign(canonicalRequest, date, region)
-	signingKey := getSigningKey(secretKey, date, region)
-	signature := getSignature(signingKey, stringToSign)
+// ListSharedLinksRep - list shared links reply.
+type ListSharedLinksRep struct {
+	Links     []SharedLinkInfo `json:"links"`
+	UIVersion string           `json:"uiVersion"`
+}
 
-	// Construct the final presigned URL.
-	return host + path + "?" + query + "&" + "X-Amz-Signature=" + signature
+// ListSharedLinks - lists the shared links of a bucket which are
+// neither expired nor revoked.
+func (web *webAPIHandlers) ListSharedLinks(r *http.Request, args *ListSharedLinksArgs, reply *ListSharedLinksRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized, please try again."}
+	}
+	links, err := readSharedLinks(args.BucketName, objectAPI)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	now := time.Now().UTC()
+	for _, link := range links {
+		if !link.Expiry.After(now) {
+			continue
+		}
+		reply.Links = append(reply.Links, SharedLinkInfo{
+			ID:         link.ID,
+			Method:     link.Method,
+			ObjectName: link.Object,
+			Created:    link.Created,
+			Expiry:     link.Expiry,
+		})
+	}
+	reply.UIVersion = miniobrowser.UIVersion
+	return nil
+}
+
+// RevokeSharedLinkArgs - revoke shared link args.
+type RevokeSharedLinkArgs struct {
+	BucketName string `json:"bucketName"`
+	ID         string `json:"id"`
+}
+
+// RevokeSharedLink - revokes a shared link, its presigned URL or POST
+// policy cannot be used anymore.
+func (web *webAPIHandlers) RevokeSharedLink(r *http.Request, args *RevokeSharedLinkArgs, reply *WebGenericRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized, please try again."}
+	}
+	if err := revokeSharedLink(args.BucketName, args.ID, objectAPI); err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	reply.UIVersion = miniobrowser.UIVersion
+	return nil
 }
 
 func (web *webAPIHandlers) _defaultHandler(w http.ResponseWriter, r *http.Request) {
@@ -17,15 +17,19 @@
 package cmd
 
 import (
+	"archive/zip"
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	router "github.com/gorilla/mux"
 	"github.com/minio/minio-go/pkg/policy"
@@ -444,6 +448,140 @@ func testRemoveObjectWebHandler(obj ObjectLayer, instanceType string, t TestErrH
 	}
 }
 
+// Wrapper for calling RemovePrefix and MoveObjects Web Handlers
+func TestWebHandlerPrefixOperations(t *testing.T) {
+	ExecObjectLayerTest(t, testWebHandlerPrefixOperations)
+}
+
+// testWebHandlerPrefixOperations - Test prefixes are moved and removed
+// in the background and their progress is reported.
+func testWebHandlerPrefixOperations(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	// Register the API end points with XL/FS object layer.
+	apiRouter := initTestWebRPCEndPoint(obj)
+	// initialize the server and obtain the credentials and root.
+	// credentials are necessary to sign the HTTP request.
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+
+	authorization, err := getWebRPCToken(apiRouter, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatal("Cannot authenticate")
+	}
+
+	bucketName := getRandomBucketName()
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+	contents := map[string]string{
+		"pics/sanjose.jpg":        "sanjose",
+		"pics/hawaii/beach.jpg":   "beach",
+		"pics/hawaii/surfing.jpg": "surfing",
+		"docs/readme.txt":         "readme",
+	}
+	for objectName, content := range contents {
+		if _, err = obj.PutObject(bucketName, objectName, int64(len(content)), bytes.NewReader([]byte(content)), nil); err != nil {
+			t.Fatalf("Was not able to upload an object, %v", err)
+		}
+	}
+
+	webRPC := func(method string, args, reply interface{}) error {
+		rec := httptest.NewRecorder()
+		req, rErr := newTestWebRPCRequest(method, authorization, args)
+		if rErr != nil {
+			t.Fatalf("Failed to create HTTP request: <ERROR> %v", rErr)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected the response status to be 200, but instead found `%d`", rec.Code)
+		}
+		return getTestWebRPCResponse(rec, reply)
+	}
+	// Waits for the operation to finish and returns its progress.
+	waitOperation := func(operationID string) webOperationProgress {
+		for i := 0; i < 100; i++ {
+			progressRep := &GetOperationProgressRep{}
+			if pErr := webRPC("Web.GetOperationProgress", GetOperationProgressArgs{OperationID: operationID}, &progressRep); pErr != nil {
+				t.Fatalf("%s: Failed, %v", instanceType, pErr)
+			}
+			if progressRep.Progress.Done {
+				return progressRep.Progress
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatalf("%s: Operation %s did not finish", instanceType, operationID)
+		return webOperationProgress{}
+	}
+	checkObjects := func(expected []string) {
+		result, lErr := obj.ListObjects(bucketName, "", "", "", 1000)
+		if lErr != nil {
+			t.Fatalf("%s: Unexpected error: %v", instanceType, lErr)
+		}
+		var names []string
+		for _, objInfo := range result.Objects {
+			names = append(names, objInfo.Name)
+		}
+		if !reflect.DeepEqual(names, expected) {
+			t.Fatalf("%s: Expected objects %v, but found %v", instanceType, expected, names)
+		}
+	}
+
+	// Moving a prefix inside itself is rejected.
+	operationRep := &WebOperationRep{}
+	if err = webRPC("Web.MoveObjects", MoveObjectsArgs{BucketName: bucketName, Source: "pics/", Destination: "pics/old/"}, &operationRep); err == nil {
+		t.Fatalf("%s: Expected moving a prefix inside itself to fail", instanceType)
+	}
+
+	// Rename a prefix.
+	operationRep = &WebOperationRep{}
+	if err = webRPC("Web.MoveObjects", MoveObjectsArgs{BucketName: bucketName, Source: "pics/", Destination: "photos/"}, &operationRep); err != nil {
+		t.Fatalf("%s: Failed, %v", instanceType, err)
+	}
+	progress := waitOperation(operationRep.OperationID)
+	if progress.Processed != 3 || progress.Failed != 0 {
+		t.Fatalf("%s: Expected 3 objects moved, but found %#v", instanceType, progress)
+	}
+	checkObjects([]string{"docs/readme.txt", "photos/hawaii/beach.jpg", "photos/hawaii/surfing.jpg", "photos/sanjose.jpg"})
+
+	// Rename an object.
+	operationRep = &WebOperationRep{}
+	if err = webRPC("Web.MoveObjects", MoveObjectsArgs{BucketName: bucketName, Source: "docs/readme.txt", Destination: "docs/README.txt"}, &operationRep); err != nil {
+		t.Fatalf("%s: Failed, %v", instanceType, err)
+	}
+	waitOperation(operationRep.OperationID)
+	checkObjects([]string{"docs/README.txt", "photos/hawaii/beach.jpg", "photos/hawaii/surfing.jpg", "photos/sanjose.jpg"})
+
+	var buffer bytes.Buffer
+	if err = obj.GetObject(bucketName, "photos/hawaii/beach.jpg", 0, int64(len("beach")), &buffer); err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if buffer.String() != "beach" {
+		t.Fatalf("%s: Expected moved object content `beach`, but found `%s`", instanceType, buffer.String())
+	}
+
+	// Remove a prefix.
+	operationRep = &WebOperationRep{}
+	if err = webRPC("Web.RemovePrefix", RemovePrefixArgs{BucketName: bucketName, Prefix: "photos/"}, &operationRep); err != nil {
+		t.Fatalf("%s: Failed, %v", instanceType, err)
+	}
+	progress = waitOperation(operationRep.OperationID)
+	if progress.Processed != 3 || progress.Failed != 0 {
+		t.Fatalf("%s: Expected 3 objects removed, but found %#v", instanceType, progress)
+	}
+	checkObjects([]string{"docs/README.txt"})
+
+	// Unknown operations are not found.
+	progressRep := &GetOperationProgressRep{}
+	if err = webRPC("Web.GetOperationProgress", GetOperationProgressArgs{OperationID: "unknown"}, &progressRep); err == nil {
+		t.Fatalf("%s: Expected unknown operation to fail", instanceType)
+	}
+}
+
 // Wrapper for calling Generate Auth Handler
 func TestWebHandlerGenerateAuth(t *testing.T) {
 	ExecObjectLayerTest(t, testGenerateAuthWebHandler)
@@ -717,6 +855,216 @@ func testDownloadWebHandler(obj ObjectLayer, instanceType string, t TestErrHandl
 	}
 }
 
+// Wrapper for calling multipart upload Web Handlers
+func TestWebHandlerMultipartUpload(t *testing.T) {
+	ExecObjectLayerTest(t, testWebHandlerMultipartUpload)
+}
+
+// testWebHandlerMultipartUpload - Test an upload is resumed from the
+// parts listed after an interruption.
+func testWebHandlerMultipartUpload(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	// Register the API end points with XL/FS object layer.
+	apiRouter := initTestWebRPCEndPoint(obj)
+	// initialize the server and obtain the credentials and root.
+	// credentials are necessary to sign the HTTP request.
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+
+	authorization, err := getWebRPCToken(apiRouter, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatal("Cannot authenticate")
+	}
+
+	bucketName := getRandomBucketName()
+	objectName := "large.txt"
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+
+	webRPC := func(method string, args, reply interface{}) error {
+		rec := httptest.NewRecorder()
+		req, rErr := newTestWebRPCRequest(method, authorization, args)
+		if rErr != nil {
+			t.Fatalf("Failed to create HTTP request: <ERROR> %v", rErr)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected the response status to be 200, but instead found `%d`", rec.Code)
+		}
+		return getTestWebRPCResponse(rec, reply)
+	}
+	uploadPart := func(uploadID string, partNumber int, data []byte) int {
+		rec := httptest.NewRecorder()
+		req, rErr := http.NewRequest("PUT", "/minio/upload/"+bucketName+"/"+objectName+"?uploadId="+uploadID+"&partNumber="+strconv.Itoa(partNumber), bytes.NewReader(data))
+		if rErr != nil {
+			t.Fatalf("Cannot create upload request, %v", rErr)
+		}
+		req.Header.Set("Authorization", "Bearer "+authorization)
+		apiRouter.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	newRep := &NewMultipartUploadRep{}
+	if err = webRPC("Web.NewMultipartUpload", NewMultipartUploadArgs{BucketName: bucketName, ObjectName: objectName, ContentType: "text/plain"}, &newRep); err != nil {
+		t.Fatalf("%s: Failed, %v", instanceType, err)
+	}
+
+	// Parts other than the last one are at least 5MiB.
+	parts := [][]byte{bytes.Repeat([]byte("a"), 5*1024*1024), []byte("b")}
+	if code := uploadPart(newRep.UploadID, 1, parts[0]); code != http.StatusOK {
+		t.Fatalf("Expected the response status to be 200, but instead found `%d`", code)
+	}
+	// Part uploads need a valid token.
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("PUT", "/minio/upload/"+bucketName+"/"+objectName+"?uploadId="+newRep.UploadID+"&partNumber=2", bytes.NewReader(parts[1]))
+	if err != nil {
+		t.Fatalf("Cannot create upload request, %v", err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected the response status to be 403, but instead found `%d`", rec.Code)
+	}
+
+	// Resume from the parts uploaded so far.
+	listRep := &ListObjectPartsRep{}
+	if err = webRPC("Web.ListObjectParts", ListObjectPartsArgs{BucketName: bucketName, ObjectName: objectName, UploadID: newRep.UploadID}, &listRep); err != nil {
+		t.Fatalf("%s: Failed, %v", instanceType, err)
+	}
+	if len(listRep.Parts) != 1 || listRep.Parts[0].PartNumber != 1 || listRep.Parts[0].Size != int64(len(parts[0])) {
+		t.Fatalf("%s: Expected part 1 to be uploaded, but found %#v", instanceType, listRep.Parts)
+	}
+	if code := uploadPart(newRep.UploadID, 2, parts[1]); code != http.StatusOK {
+		t.Fatalf("Expected the response status to be 200, but instead found `%d`", code)
+	}
+	listRep = &ListObjectPartsRep{}
+	if err = webRPC("Web.ListObjectParts", ListObjectPartsArgs{BucketName: bucketName, ObjectName: objectName, UploadID: newRep.UploadID}, &listRep); err != nil {
+		t.Fatalf("%s: Failed, %v", instanceType, err)
+	}
+
+	completeRep := &CompleteMultipartUploadRep{}
+	if err = webRPC("Web.CompleteMultipartUpload", CompleteMultipartUploadArgs{BucketName: bucketName, ObjectName: objectName, UploadID: newRep.UploadID, Parts: listRep.Parts}, &completeRep); err != nil {
+		t.Fatalf("%s: Failed, %v", instanceType, err)
+	}
+
+	objInfo, err := obj.GetObjectInfo(bucketName, objectName)
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if objInfo.Size != int64(len(parts[0])+len(parts[1])) {
+		t.Errorf("%s: Expected object size %d, but found %d", instanceType, len(parts[0])+len(parts[1]), objInfo.Size)
+	}
+	if objInfo.ContentType != "text/plain" {
+		t.Errorf("%s: Expected content type `text/plain`, but found `%s`", instanceType, objInfo.ContentType)
+	}
+}
+
+// Wrapper for calling DownloadZip Web Handler
+func TestWebHandlerDownloadZip(t *testing.T) {
+	ExecObjectLayerTest(t, testWebHandlerDownloadZip)
+}
+
+// testWebHandlerDownloadZip - Test DownloadZip web handler streams the
+// selected objects and directories as a zip archive.
+func testWebHandlerDownloadZip(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	// Register the API end points with XL/FS object layer.
+	apiRouter := initTestWebRPCEndPoint(obj)
+	// initialize the server and obtain the credentials and root.
+	// credentials are necessary to sign the HTTP request.
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+
+	authorization, err := getWebRPCToken(apiRouter, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatal("Cannot authenticate")
+	}
+
+	bucketName := getRandomBucketName()
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+	contents := map[string]string{
+		"pics/sanjose.jpg":        "sanjose",
+		"pics/hawaii/beach.jpg":   "beach",
+		"pics/hawaii/surfing.jpg": "surfing",
+		"pics/maldives/sea.jpg":   "sea",
+	}
+	for objectName, content := range contents {
+		if _, err = obj.PutObject(bucketName, objectName, int64(len(content)), bytes.NewReader([]byte(content)), nil); err != nil {
+			t.Fatalf("Was not able to upload an object, %v", err)
+		}
+	}
+
+	args := DownloadZipArgs{
+		BucketName: bucketName,
+		Prefix:     "pics/",
+		Objects:    []string{"hawaii/", "sanjose.jpg"},
+	}
+	argsBytes, err := json.Marshal(args)
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+
+	// Requests without a valid token are denied.
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/minio/zip?token=invalid", bytes.NewReader(argsBytes))
+	if err != nil {
+		t.Fatalf("Cannot create zip request, %v", err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected the response status to be 403, but instead found `%d`", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("POST", "/minio/zip?token="+authorization, bytes.NewReader(argsBytes))
+	if err != nil {
+		t.Fatalf("Cannot create zip request, %v", err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected the response status to be 200, but instead found `%d`", rec.Code)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("%s: Unable to read the zip archive: %v", instanceType, err)
+	}
+	expected := map[string]string{
+		"hawaii/beach.jpg":   "beach",
+		"hawaii/surfing.jpg": "surfing",
+		"sanjose.jpg":        "sanjose",
+	}
+	if len(archive.File) != len(expected) {
+		t.Fatalf("%s: Expected %d files in the zip archive, but found %d", instanceType, len(expected), len(archive.File))
+	}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("%s: Unable to open %s: %v", instanceType, file.Name, err)
+		}
+		content, err := ioutil.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatalf("%s: Unable to read %s: %v", instanceType, file.Name, err)
+		}
+		if expected[file.Name] != string(content) {
+			t.Errorf("%s: Expected %s to contain `%s`, but found `%s`", instanceType, file.Name, expected[file.Name], string(content))
+		}
+	}
+}
+
 // Wrapper for calling PresignedGet handler
 func TestWebHandlerPresignedGetHandler(t *testing.T) {
 	ExecObjectLayerTest(t, testWebPresignedGetHandler)
@@ -803,6 +1151,136 @@ func testWebPresignedGetHandler(obj ObjectLayer, instanceType string, t TestErrH
 	}
 }
 
+// Wrapper for calling PresignedPut, PresignedPostPolicy, ListSharedLinks and RevokeSharedLink handlers
+func TestWebHandlerSharedLinksHandler(t *testing.T) {
+	ExecObjectLayerTest(t, testWebSharedLinksHandler)
+}
+
+// testWebSharedLinksHandler - Test presigned upload links are usable
+// without credentials until they are revoked.
+func testWebSharedLinksHandler(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	// Register the API end points with XL/FS object layer.
+	webRouter := initTestWebRPCEndPoint(obj)
+	// initialize the server and obtain the credentials and root.
+	// credentials are necessary to sign the HTTP request.
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+
+	authorization, err := getWebRPCToken(webRouter, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatal("Cannot authenticate")
+	}
+
+	bucketName := getRandomBucketName()
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+	apiRouter := initTestAPIEndPoints(obj, []string{"All"})
+
+	webRPC := func(method string, args, reply interface{}) error {
+		rec := httptest.NewRecorder()
+		req, rErr := newTestWebRPCRequest(method, authorization, args)
+		if rErr != nil {
+			t.Fatalf("Failed to create HTTP request: <ERROR> %v", rErr)
+		}
+		webRouter.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected the response status to be 200, but instead found `%d`", rec.Code)
+		}
+		return getTestWebRPCResponse(rec, reply)
+	}
+	presignedPut := func(url string) int {
+		rec := httptest.NewRecorder()
+		req, rErr := newTestRequest("PUT", url, int64(len("hello")), bytes.NewReader([]byte("hello")))
+		if rErr != nil {
+			t.Fatalf("Failed to create HTTP request: <ERROR> %v", rErr)
+		}
+		req.Header.Del("x-amz-content-sha256")
+		apiRouter.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	postPolicy := func(url string, formData map[string]string, key string) int {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		for k, v := range formData {
+			w.WriteField(k, v)
+		}
+		w.WriteField("key", key)
+		writer, rErr := w.CreateFormFile("file", "upload")
+		if rErr != nil {
+			t.Fatalf("Failed to create form: <ERROR> %v", rErr)
+		}
+		writer.Write([]byte("hello"))
+		w.Close()
+		req, rErr := http.NewRequest("POST", url, bytes.NewReader(buf.Bytes()))
+		if rErr != nil {
+			t.Fatalf("Failed to create HTTP request: <ERROR> %v", rErr)
+		}
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		rec := httptest.NewRecorder()
+		apiRouter.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Expiry cannot exceed 7 days.
+	putRep := &PresignedPutRep{}
+	if err = webRPC("Web.PresignedPut", PresignedPutArgs{BucketName: bucketName, ObjectName: "object", Expiry: 8 * 24 * 3600}, &putRep); err == nil {
+		t.Fatalf("%s: Expected expiry of 8 days to be rejected", instanceType)
+	}
+
+	if err = webRPC("Web.PresignedPut", PresignedPutArgs{BucketName: bucketName, ObjectName: "object", Expiry: 3600}, &putRep); err != nil {
+		t.Fatalf("%s: Failed, %v", instanceType, err)
+	}
+	if code := presignedPut(putRep.URL); code != http.StatusOK {
+		t.Fatalf("%s: Expected the response status to be 200, but instead found `%d`", instanceType, code)
+	}
+
+	postRep := &PresignedPostPolicyRep{}
+	if err = webRPC("Web.PresignedPostPolicy", PresignedPostPolicyArgs{BucketName: bucketName, Prefix: "dropbox/", Expiry: 3600}, &postRep); err != nil {
+		t.Fatalf("%s: Failed, %v", instanceType, err)
+	}
+	if code := postPolicy(postRep.URL, postRep.FormData, "dropbox/report"); code != http.StatusNoContent {
+		t.Fatalf("%s: Expected the response status to be 204, but instead found `%d`", instanceType, code)
+	}
+	if code := postPolicy(postRep.URL, postRep.FormData, "elsewhere/report"); code != http.StatusForbidden {
+		t.Fatalf("%s: Expected the response status to be 403, but instead found `%d`", instanceType, code)
+	}
+
+	listRep := &ListSharedLinksRep{}
+	if err = webRPC("Web.ListSharedLinks", ListSharedLinksArgs{BucketName: bucketName}, &listRep); err != nil {
+		t.Fatalf("%s: Failed, %v", instanceType, err)
+	}
+	if len(listRep.Links) != 2 || listRep.Links[0].ID != putRep.ID || listRep.Links[1].ID != postRep.ID {
+		t.Fatalf("%s: Expected the PUT and POST links, but found %#v", instanceType, listRep.Links)
+	}
+
+	// Revoked links are denied.
+	for _, id := range []string{putRep.ID, postRep.ID} {
+		if err = webRPC("Web.RevokeSharedLink", RevokeSharedLinkArgs{BucketName: bucketName, ID: id}, &WebGenericRep{}); err != nil {
+			t.Fatalf("%s: Failed, %v", instanceType, err)
+		}
+	}
+	if code := presignedPut(putRep.URL); code != http.StatusForbidden {
+		t.Fatalf("%s: Expected the response status to be 403, but instead found `%d`", instanceType, code)
+	}
+	if code := postPolicy(postRep.URL, postRep.FormData, "dropbox/report"); code != http.StatusForbidden {
+		t.Fatalf("%s: Expected the response status to be 403, but instead found `%d`", instanceType, code)
+	}
+	listRep = &ListSharedLinksRep{}
+	if err = webRPC("Web.ListSharedLinks", ListSharedLinksArgs{BucketName: bucketName}, &listRep); err != nil {
+		t.Fatalf("%s: Failed, %v", instanceType, err)
+	}
+	if len(listRep.Links) != 0 {
+		t.Fatalf("%s: Expected no shared links, but found %#v", instanceType, listRep.Links)
+	}
+}
+
 // Wrapper for calling GetBucketPolicy Handler
 func TestWebHandlerGetBucketPolicyHandler(t *testing.T) {
 	ExecObjectLayerTest(t, testWebGetBucketPolicyHandler)
@@ -0,0 +1,212 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Finished operations are kept around this long for their progress
+// to be fetched.
+const webOperationExpiry = time.Hour
+
+// webOperationProgress - progress of a web operation on a tree of objects.
+type webOperationProgress struct {
+	Processed int    `json:"processed"`
+	Failed    int    `json:"failed"`
+	Done      bool   `json:"done"`
+	Error     string `json:"error,omitempty"`
+}
+
+// webOperation - a running or finished web operation.
+type webOperation struct {
+	mutex    sync.Mutex
+	progress webOperationProgress
+	finished time.Time
+}
+
+// webOperations - web operations running in the background, by ID.
+type webOperations struct {
+	mutex      sync.Mutex
+	operations map[string]*webOperation
+}
+
+// Web operations of this server.
+var globalWebOperations = &webOperations{
+	operations: make(map[string]*webOperation),
+}
+
+// start - runs fn in the background, returns the ID of the operation
+// to fetch its progress with.
+func (ops *webOperations) start(fn func(op *webOperation) error) string {
+	ops.mutex.Lock()
+	defer ops.mutex.Unlock()
+
+	// Forget operations finished a while ago.
+	for id, op := range ops.operations {
+		op.mutex.Lock()
+		expired := op.progress.Done && time.Since(op.finished) > webOperationExpiry
+		op.mutex.Unlock()
+		if expired {
+			delete(ops.operations, id)
+		}
+	}
+
+	id := getUUID()
+	op := &webOperation{}
+	ops.operations[id] = op
+	go func() {
+		err := fn(op)
+		op.mutex.Lock()
+		defer op.mutex.Unlock()
+		if err != nil {
+			op.progress.Error = err.Error()
+		}
+		op.progress.Done = true
+		op.finished = time.Now().UTC()
+	}()
+	return id
+}
+
+// get - returns the progress of the operation.
+func (ops *webOperations) get(id string) (webOperationProgress, bool) {
+	ops.mutex.Lock()
+	op, ok := ops.operations[id]
+	ops.mutex.Unlock()
+	if !ok {
+		return webOperationProgress{}, false
+	}
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+	return op.progress, true
+}
+
+// update - records an object processed by the operation.
+func (op *webOperation) update(err error) {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+	op.progress.Processed++
+	if err != nil {
+		op.progress.Failed++
+	}
+}
+
+// walkObjects - calls fn for every object under the prefix, or only
+// for the object itself if it is not a prefix.
+func walkObjects(objAPI ObjectLayer, bucket, prefix string, fn func(object string) error) error {
+	if !strings.HasSuffix(prefix, slashSeparator) {
+		return fn(prefix)
+	}
+	marker := ""
+	for {
+		result, err := objAPI.ListObjects(bucket, prefix, marker, "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, objInfo := range result.Objects {
+			if err = fn(objInfo.Name); err != nil {
+				return err
+			}
+		}
+		if !result.IsTruncated {
+			return nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+// removeWebObject - removes an object which is not locked, and notifies
+// the removal.
+func removeWebObject(objAPI ObjectLayer, bucket, object string, r *http.Request) error {
+	// Objects under retention or legal hold cannot be deleted.
+	if err := enforceObjectLock(objAPI, bucket, object, r); err != nil {
+		return err
+	}
+	if err := objAPI.DeleteObject(bucket, object); err != nil {
+		return err
+	}
+	if globalEventNotifier.IsBucketNotificationSet(bucket) {
+		// Notify object deleted event.
+		eventNotify(eventData{
+			Type:   ObjectRemovedDelete,
+			Bucket: bucket,
+			ObjInfo: ObjectInfo{
+				Name: object,
+			},
+			ReqParams: map[string]string{
+				"sourceIPAddress": r.RemoteAddr,
+			},
+		})
+	}
+	return nil
+}
+
+// copyWebObject - copies an object on the server along with its
+// metadata, and notifies the copy.
+func copyWebObject(objAPI ObjectLayer, bucket, srcObject, dstObject string, r *http.Request) error {
+	// A locked destination cannot be overwritten.
+	if err := enforceObjectLock(objAPI, bucket, dstObject, r); err != nil {
+		return err
+	}
+	objInfo, err := objAPI.GetObjectInfo(bucket, srcObject)
+	if err != nil {
+		return err
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		gErr := objAPI.GetObject(bucket, srcObject, 0, objInfo.Size, pipeWriter)
+		if gErr != nil {
+			errorIf(gErr, "Unable to read an object.")
+			pipeWriter.CloseWithError(gErr)
+			return
+		}
+		pipeWriter.Close() // Close.
+	}()
+
+	// Save other metadata if available, the md5sum is not kept.
+	metadata := make(map[string]string)
+	for k, v := range objInfo.UserDefined {
+		metadata[k] = v
+	}
+	delete(metadata, "md5Sum")
+
+	objInfo, err = objAPI.PutObject(bucket, dstObject, objInfo.Size, pipeReader, metadata)
+	if err != nil {
+		// Close the this end of the pipe upon error in PutObject.
+		pipeReader.CloseWithError(err)
+		return err
+	}
+	pipeReader.Close()
+
+	if globalEventNotifier.IsBucketNotificationSet(bucket) {
+		// Notify object created event.
+		eventNotify(eventData{
+			Type:    ObjectCreatedCopy,
+			Bucket:  bucket,
+			ObjInfo: objInfo,
+			ReqParams: map[string]string{
+				"sourceIPAddress": r.RemoteAddr,
+			},
+		})
+	}
+	return nil
+}
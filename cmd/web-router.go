@@ -74,8 +74,10 @@ func registerWebRouter(mux *router.Router, web *webAPIHandlers) {
 
 	// RPC handler at URI - /minio/webrpc
 	webBrowserRouter.Methods("POST").Path("/webrpc").Handler(webRPC)
+	webBrowserRouter.Methods("PUT").Path("/upload/{bucket}/{object:.+}").Queries("uploadId", "{uploadId:.*}", "partNumber", "{partNumber:[0-9]+}").HandlerFunc(web.UploadPart)
 	webBrowserRouter.Methods("PUT").Path("/upload/{bucket}/{object:.+}").HandlerFunc(web.Upload)
 	webBrowserRouter.Methods("GET").Path("/download/{bucket}/{object:.+}").Queries("token", "{token:.*}").HandlerFunc(web.Download)
+	webBrowserRouter.Methods("POST").Path("/zip").Queries("token", "{token:.*}").HandlerFunc(web.DownloadZip)
 
 	// 2016.9.18 Mingfeng: Move authboss setup from api-router to here
 	myauthboss.SetupStorer()
@@ -16,7 +16,233 @@
 
 package cmd
 
-import "strings"
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Number of `xl.json` read with a single ReadAllBatch call while listing.
+const xlListBatchSize = 1000
+
+// xlMetaLister - lists objects reading their metadata in batches.
+// Each directory is listed from a single disk, which then reads the
+// `xl.json` of its entries with ReadAllBatch, a batch at a time, as the
+// walk reaches them. Metadata is not verified for quorum while
+// listing, this is done lazily when the object is read. Entries whose
+// metadata can't be read in a batch, including those without `xl.json`
+// on the listing disk, fall back to looking it up on all the disks, as
+// isObject and getObjectInfo do.
+type xlMetaLister struct {
+	xl    xlObjects
+	disks []StorageAPI
+
+	// Directories being walked whose entries are not read yet, only
+	// accessed by the tree walk routine.
+	dirs map[string]*xlListDir
+
+	// Metadata of the objects read ahead, by object name.
+	mu      sync.Mutex
+	objects map[string]xlListObject
+}
+
+// xlListDir - directory entries whose `xl.json` is yet to be read.
+type xlListDir struct {
+	disk    StorageAPI
+	entries []string // Sorted full paths, with trailing "/".
+}
+
+// xlListObject - metadata of an object read ahead.
+type xlListObject struct {
+	stat statInfo
+	meta map[string]string
+}
+
+func newXLMetaLister(xl xlObjects) *xlMetaLister {
+	return &xlMetaLister{
+		xl:      xl,
+		disks:   xl.getLoadBalancedDisks(),
+		dirs:    make(map[string]*xlListDir),
+		objects: make(map[string]xlListObject),
+	}
+}
+
+// listDir - lists the entries of prefixDir from a single disk, of type
+// listDirFunc.
+func (l *xlMetaLister) listDir(bucket, prefixDir, prefixEntry string) (entries []string, delayIsLeaf bool, err error) {
+	for _, disk := range l.disks {
+		if disk == nil {
+			continue
+		}
+		entries, err = disk.ListDir(bucket, prefixDir)
+		if err == nil {
+			// Listing needs to be sorted.
+			sort.Strings(entries)
+
+			// Filter entries that have the prefix prefixEntry.
+			entries = filterMatchingPrefix(entries, prefixEntry)
+
+			// Directories may be objects, their metadata is read
+			// from this disk.
+			dir := &xlListDir{disk: disk}
+			for _, entry := range entries {
+				if strings.HasSuffix(entry, slashSeparator) {
+					dir.entries = append(dir.entries, pathJoin(prefixDir, entry))
+				}
+			}
+			l.dirs[prefixDir] = dir
+
+			delayIsLeaf = delayIsLeafCheck(entries)
+			if delayIsLeaf {
+				return entries, delayIsLeaf, nil
+			}
+
+			// isLeaf() check has to happen here so that trailing "/" for objects can be removed.
+			for i, entry := range entries {
+				if l.isLeaf(bucket, pathJoin(prefixDir, entry)) {
+					entries[i] = strings.TrimSuffix(entry, slashSeparator)
+				}
+			}
+			// Sort again after removing trailing "/" for objects as the previous sort
+			// does not hold good anymore.
+			sort.Strings(entries)
+			return entries, delayIsLeaf, nil
+		}
+		// For any reason disk was deleted or goes offline, continue
+		// and list from other disks if possible.
+		if isErrIgnored(err, xlTreeWalkIgnoredErrs) {
+			continue
+		}
+		break
+	}
+	// Return error at the end.
+	return nil, false, traceError(err)
+}
+
+// isLeaf - returns true if entry is an object, of type isLeafFunc.
+func (l *xlMetaLister) isLeaf(bucket, entry string) bool {
+	if !strings.HasSuffix(entry, slashSeparator) {
+		return false
+	}
+	object := strings.TrimSuffix(entry, slashSeparator)
+	if _, ok := l.getObject(object, false); ok {
+		return true
+	}
+	l.readAhead(bucket, entry)
+	if _, ok := l.getObject(object, false); ok {
+		return true
+	}
+	return l.xl.isObject(bucket, object)
+}
+
+// getObject - returns the metadata of object read ahead, removes it
+// if asked to.
+func (l *xlMetaLister) getObject(object string, remove bool) (obj xlListObject, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	obj, ok = l.objects[object]
+	if ok && remove {
+		delete(l.objects, object)
+	}
+	return obj, ok
+}
+
+// readAhead - reads the `xl.json` of the next batch of entries of the
+// directory of entry, starting at entry.
+func (l *xlMetaLister) readAhead(bucket, entry string) {
+	object := strings.TrimSuffix(entry, slashSeparator)
+	prefixDir := object[:strings.LastIndex(object, slashSeparator)+1]
+	dir, ok := l.dirs[prefixDir]
+	if !ok {
+		return
+	}
+
+	// Entries before entry were either read or skipped by the walk.
+	dir.entries = dir.entries[sort.SearchStrings(dir.entries, entry):]
+	batch := dir.entries
+	if len(batch) > xlListBatchSize {
+		batch = batch[:xlListBatchSize]
+	}
+	dir.entries = dir.entries[len(batch):]
+	if len(dir.entries) == 0 {
+		delete(l.dirs, prefixDir)
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	paths := make([]string, len(batch))
+	for i, entry := range batch {
+		paths[i] = pathJoin(entry, xlMetaJSONFile)
+	}
+	bufs, errs, err := dir.disk.ReadAllBatch(bucket, paths)
+	if err != nil {
+		// Fall back to reading the metadata of each entry of the
+		// directory.
+		errorIf(err, "Unable to read metadata of %s/%s", bucket, prefixDir)
+		delete(l.dirs, prefixDir)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, entry := range batch {
+		object := strings.TrimSuffix(entry, slashSeparator)
+		// Entries without `xl.json` on this disk are prefixes, or
+		// objects whose write or healing missed this disk, they are
+		// looked up on all the disks.
+		if errs[i] != nil {
+			continue
+		}
+		xlStat, err := parseXLStat(bufs[i])
+		if err != nil {
+			continue
+		}
+		l.objects[object] = xlListObject{
+			stat: xlStat,
+			meta: parseXLMetaMap(bufs[i]),
+		}
+	}
+}
+
+// getObjectInfo - returns the info of object from the metadata read
+// ahead, or reads it if it wasn't.
+func (l *xlMetaLister) getObjectInfo(bucket, object string) (ObjectInfo, error) {
+	obj, ok := l.getObject(object, true)
+	if !ok {
+		return l.xl.getObjectInfo(bucket, object)
+	}
+	return newXLObjectInfo(bucket, object, obj.stat, obj.meta)
+}
+
+// startTreeWalk - starts a tree walk whose results carry the info of
+// their objects, which is looked up in the background of the listing.
+func (l *xlMetaLister) startTreeWalk(bucket, prefix, marker string, recursive bool, endWalkCh chan struct{}) chan treeWalkResult {
+	walkResultCh := startTreeWalk(bucket, prefix, marker, recursive, l.listDir, l.isLeaf, endWalkCh)
+	resultCh := make(chan treeWalkResult, maxObjectList)
+	go func() {
+		defer close(resultCh)
+		for walkResult := range walkResultCh {
+			if walkResult.err == nil && !strings.HasSuffix(walkResult.entry, slashSeparator) {
+				objInfo, err := l.getObjectInfo(bucket, walkResult.entry)
+				if err != nil {
+					// Ignore errFileNotFound
+					if errorCause(err) == errFileNotFound {
+						continue
+					}
+					walkResult.err = err
+				}
+				walkResult.objInfo = &objInfo
+			}
+			select {
+			case <-endWalkCh:
+				return
+			case resultCh <- walkResult:
+			}
+		}
+	}()
+	return resultCh
+}
 
 // listObjects - wrapper function implemented over file tree walk.
 func (xl xlObjects) listObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
@@ -30,9 +256,7 @@ func (xl xlObjects) listObjects(bucket, prefix, marker, delimiter string, maxKey
 	walkResultCh, endWalkCh := xl.listPool.Release(listParams{bucket, recursive, marker, prefix, heal})
 	if walkResultCh == nil {
 		endWalkCh = make(chan struct{})
-		isLeaf := xl.isObject
-		listDir := listDirFactory(isLeaf, xlTreeWalkIgnoredErrs, xl.getLoadBalancedDisks()...)
-		walkResultCh = startTreeWalk(bucket, prefix, marker, recursive, listDir, isLeaf, endWalkCh)
+		walkResultCh = newXLMetaLister(xl).startTreeWalk(bucket, prefix, marker, recursive, endWalkCh)
 	}
 
 	var objInfos []ObjectInfo
@@ -61,16 +285,8 @@ func (xl xlObjects) listObjects(bucket, prefix, marker, delimiter string, maxKey
 			objInfo.Name = entry
 			objInfo.IsDir = true
 		} else {
-			// Set the Mode to a "regular" file.
-			var err error
-			objInfo, err = xl.getObjectInfo(bucket, entry)
-			if err != nil {
-				// Ignore errFileNotFound
-				if errorCause(err) == errFileNotFound {
-					continue
-				}
-				return ListObjectsInfo{}, toObjectErr(err, bucket, prefix)
-			}
+			// Object info is looked up by the walk.
+			objInfo = *walkResult.objInfo
 		}
 		nextMarker = objInfo.Name
 		objInfos = append(objInfos, objInfo)
@@ -0,0 +1,112 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// Simulates a disk which can't read files in batches.
+type ReadAllBatchDown struct {
+	*posix
+}
+
+func (r ReadAllBatchDown) ReadAllBatch(volume string, paths []string) ([][]byte, []error, error) {
+	return nil, nil, errFaultyDisk
+}
+
+// Tests listing objects from the metadata read in batches, and from
+// the metadata of each object when it is missing on the listing disk
+// or batches can't be read.
+func TestXLListObjectsMeta(t *testing.T) {
+	obj, fsDirs, err := prepareXL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	xl := obj.(xlObjects)
+
+	bucket := "bucket"
+	if err = obj.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	// "a-b/" and "a/" sort differently without the trailing "/", so
+	// that isLeaf is not delayed for the top level.
+	objects := []string{"a-b", "a/b", "a/c/d", "dir/x", "z.txt"}
+	for i, object := range objects {
+		data := bytes.Repeat([]byte("a"), i+1)
+		metadata := map[string]string{"content-type": "text/plain"}
+		if _, err = obj.PutObject(bucket, object, int64(len(data)), bytes.NewReader(data), metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		prefix, delimiter string
+		objects           []string
+		prefixes          []string
+	}{
+		{"", "", []string{"a-b", "a/b", "a/c/d", "dir/x", "z.txt"}, nil},
+		{"", "/", []string{"a-b", "z.txt"}, []string{"a/", "dir/"}},
+		{"a/", "/", []string{"a/b"}, []string{"a/c/"}},
+		{"a/c", "", []string{"a/c/d"}, nil},
+	}
+	checkListing := func(name string) {
+		for i, testCase := range testCases {
+			result, err := obj.ListObjects(bucket, testCase.prefix, "", testCase.delimiter, 1000)
+			if err != nil {
+				t.Fatalf("%s, test %d: %s", name, i+1, err)
+			}
+			var names []string
+			for _, objInfo := range result.Objects {
+				names = append(names, objInfo.Name)
+				// Sizes tell objects apart.
+				for j, object := range objects {
+					if object == objInfo.Name && objInfo.Size != int64(j+1) {
+						t.Errorf("%s, test %d: %s has size %d, expected %d", name, i+1, object, objInfo.Size, j+1)
+					}
+				}
+			}
+			if !reflect.DeepEqual(names, testCase.objects) {
+				t.Errorf("%s, test %d: expected objects %v, got %v", name, i+1, testCase.objects, names)
+			}
+			if !reflect.DeepEqual(result.Prefixes, testCase.prefixes) {
+				t.Errorf("%s, test %d: expected prefixes %v, got %v", name, i+1, testCase.prefixes, result.Prefixes)
+			}
+		}
+	}
+	checkListing("Batch reads")
+
+	// Objects whose `xl.json` is missing on the listing disk, as
+	// after a write which failed on it, are looked up on the other
+	// disks. Only the last disk keeps the metadata of these objects.
+	for _, disk := range xl.storageDisks[:len(xl.storageDisks)-1] {
+		for _, object := range []string{"a-b", "a/c/d", "dir/x"} {
+			if err = disk.DeleteFile(bucket, pathJoin(object, xlMetaJSONFile)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	checkListing("Missing metadata")
+
+	for i := range xl.storageDisks {
+		xl.storageDisks[i] = ReadAllBatchDown{xl.storageDisks[i].(*posix)}
+	}
+	checkListing("No batch reads")
+}
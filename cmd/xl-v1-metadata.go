@@ -36,6 +36,10 @@ type objectPartInfo struct {
 	Name   string `json:"name"`
 	ETag   string `json:"etag"`
 	Size   int64  `json:"size"`
+	// Size before compression, set on parts of compressed objects.
+	ActualSize int64 `json:"actualSize,omitempty"`
+	// Index of the compressed data of the part, see compressIndex.
+	CompressIndex string `json:"compressIndex,omitempty"`
 }
 
 // byObjectPartNumber is a collection satisfying sort.Interface.
@@ -52,11 +56,23 @@ type checkSumInfo struct {
 	Hash      string `json:"hash"`
 }
 
-// Constant indicates current bit-rot algo used when creating objects.
+// Bit-rot algorithm of a new object can be selected with a request
+// header, the selection is saved in the metadata of multipart uploads
+// until they are completed.
 const (
-	bitRotAlgo = "blake2b"
+	bitRotAlgoHeader  = "X-Minio-Bitrot-Algorithm"
+	bitRotAlgoMetaKey = "X-Minio-Internal-Bitrot-Algorithm"
 )
 
+// getBitRotAlgo - returns the bit-rot algorithm selected in the metadata
+// of a new object or multipart upload, the server's one otherwise.
+func getBitRotAlgo(metadata map[string]string) string {
+	if algo := metadata[bitRotAlgoMetaKey]; algo != "" {
+		return algo
+	}
+	return globalBitRotAlgo
+}
+
 // erasureInfo - carries erasure coding related information, block
 // distribution and checksums.
 type erasureInfo struct {
@@ -23,6 +23,7 @@ import (
 	"io"
 	"io/ioutil"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -328,6 +329,10 @@ func (xl xlObjects) NewMultipartUpload(bucket, object string, meta map[string]st
 	if meta == nil {
 		meta = make(map[string]string)
 	}
+	// Parts are compressed if the object is compressible.
+	setCompressionMetadata(bucket, object, meta)
+	// All parts use the bit-rot algorithm selected when initiated.
+	meta[bitRotAlgoMetaKey] = getBitRotAlgo(meta)
 	return xl.newMultipartUpload(bucket, object, meta)
 }
 
@@ -399,22 +404,40 @@ func (xl xlObjects) PutObjectPart(bucket, object, uploadID string, partID int, s
 	} // else we read till EOF.
 
 	// Construct a tee reader for md5sum.
-	teeReader := io.TeeReader(data, md5Writer)
+	var reader io.Reader = io.TeeReader(data, md5Writer)
+
+	// Parts of compressed uploads are compressed too.
+	compressed := isCompressed(xlMeta.Meta)
+	var compressedReader *compressReader
+	if compressed {
+		compressedReader = newCompressReader(reader)
+		reader = compressedReader
+	}
 
 	// Erasure code data and write across all disks.
-	sizeWritten, checkSums, err := erasureCreateFile(onlineDisks, minioMetaBucket, tmpPartPath, teeReader, xlMeta.Erasure.BlockSize, xl.dataBlocks, xl.parityBlocks, bitRotAlgo, xl.writeQuorum)
+	algo := getBitRotAlgo(xlMeta.Meta)
+	sizeWritten, checkSums, err := erasureCreateFile(onlineDisks, minioMetaBucket, tmpPartPath, reader, xlMeta.Erasure.BlockSize, xl.dataBlocks, xl.parityBlocks, algo, xl.writeQuorum)
 	if err != nil {
 		return "", toObjectErr(err, bucket, object)
 	}
+	// Size of the data read, before compression.
+	actualSize := sizeWritten
+	if compressed {
+		actualSize = compressedReader.actualSize
+	}
 	// Should return IncompleteBody{} error when reader has fewer bytes
 	// than specified in request header.
-	if sizeWritten < size {
+	if actualSize < size {
 		return "", traceError(IncompleteBody{})
 	}
 
 	// For size == -1, perhaps client is sending in chunked encoding
 	// set the size as size that was actually written.
 	if size == -1 {
+		size = actualSize
+	}
+	if compressed {
+		// Compressed parts are stored with the compressed size.
 		size = sizeWritten
 	}
 
@@ -475,6 +498,11 @@ func (xl xlObjects) PutObjectPart(bucket, object, uploadID string, partID int, s
 
 	// Add the current part.
 	xlMeta.AddObjectPart(partID, partSuffix, newMD5Hex, size)
+	if compressed {
+		part := &xlMeta.Parts[objectPartIndex(xlMeta.Parts, partID)]
+		part.ActualSize = actualSize
+		part.CompressIndex = compressedReader.index.String()
+	}
 
 	for index, disk := range onlineDisks {
 		if disk == nil {
@@ -484,7 +512,7 @@ func (xl xlObjects) PutObjectPart(bucket, object, uploadID string, partID int, s
 		partsMetadata[index].Erasure.AddCheckSumInfo(checkSumInfo{
 			Name:      partSuffix,
 			Hash:      checkSums[index],
-			Algorithm: bitRotAlgo,
+			Algorithm: algo,
 		})
 	}
 
@@ -550,7 +578,7 @@ func (xl xlObjects) listObjectParts(bucket, object, uploadID string, partNumberM
 			PartNumber:   part.Number,
 			ETag:         part.ETag,
 			LastModified: fi.ModTime,
-			Size:         part.Size,
+			Size:         part.actualSize(),
 		})
 		count--
 		if count == 0 {
@@ -655,8 +683,8 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 
 	onlineDisks, modTime := listOnlineDisks(xl.storageDisks, partsMetadata, errs)
 
-	// Calculate full object size.
-	var objectSize int64
+	// Calculate full object size, as stored and before compression.
+	var objectSize, actualSize int64
 
 	// Pick one from the first valid metadata.
 	xlMeta := pickValidXLMeta(partsMetadata, modTime)
@@ -687,10 +715,10 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 		}
 
 		// All parts except the last part has to be atleast 5MB.
-		if (i < len(parts)-1) && !isMinAllowedPartSize(currentXLMeta.Parts[partIdx].Size) {
+		if (i < len(parts)-1) && !isMinAllowedPartSize(currentXLMeta.Parts[partIdx].actualSize()) {
 			return "", traceError(PartTooSmall{
 				PartNumber: part.PartNumber,
-				PartSize:   currentXLMeta.Parts[partIdx].Size,
+				PartSize:   currentXLMeta.Parts[partIdx].actualSize(),
 				PartETag:   part.ETag,
 			})
 		}
@@ -704,13 +732,17 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 
 		// Save for total object size.
 		objectSize += currentXLMeta.Parts[partIdx].Size
+		actualSize += currentXLMeta.Parts[partIdx].actualSize()
 
 		// Add incoming parts.
 		xlMeta.Parts[i] = objectPartInfo{
-			Number: part.PartNumber,
-			ETag:   part.ETag,
-			Size:   currentXLMeta.Parts[partIdx].Size,
-			Name:   fmt.Sprintf("part.%d", part.PartNumber),
+			Number:     part.PartNumber,
+			ETag:       part.ETag,
+			Size:       currentXLMeta.Parts[partIdx].Size,
+			ActualSize: currentXLMeta.Parts[partIdx].ActualSize,
+			Name:       fmt.Sprintf("part.%d", part.PartNumber),
+
+			CompressIndex: currentXLMeta.Parts[partIdx].CompressIndex,
 		}
 	}
 
@@ -725,6 +757,20 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 
 	// Save successfully calculated md5sum.
 	xlMeta.Meta["md5Sum"] = s3MD5
+	if isCompressed(xlMeta.Meta) {
+		xlMeta.Meta[actualSizeMetaKey] = strconv.FormatInt(actualSize, 10)
+		// The indexes of the parts are merged into the object's.
+		index, err := partsCompressIndex(xlMeta.Parts)
+		if err != nil {
+			return "", toObjectErr(traceError(err), bucket, object)
+		}
+		setCompressIndex(xlMeta.Meta, index)
+		for i := range xlMeta.Parts {
+			xlMeta.Parts[i].CompressIndex = ""
+		}
+	}
+	// Bit-rot algorithm of the parts is saved with their checksums.
+	delete(xlMeta.Meta, bitRotAlgoMetaKey)
 	uploadIDPath = path.Join(mpartMetaPrefix, bucket, object, uploadID)
 	tempUploadIDPath := path.Join(tmpMetaPrefix, uploadID)
 
@@ -762,7 +808,7 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 		// Prefetch the object from disk by triggering a fake GetObject call
 		// Unlike a regular single PutObject,  multipart PutObject is comes in
 		// stages and it is harder to cache.
-		go xl.GetObject(bucket, object, 0, objectSize, ioutil.Discard)
+		go xl.GetObject(bucket, object, 0, actualSize, ioutil.Discard)
 	}()
 
 	// Rename if an object already exists to temporary location.
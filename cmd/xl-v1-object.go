@@ -21,11 +21,11 @@ import (
 	"encoding/hex"
 	"io"
 	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/mf-00/minio/pkg/bpool"
 	"github.com/mf-00/minio/pkg/mimedb"
 	"github.com/mf-00/minio/pkg/objcache"
 )
@@ -92,6 +92,32 @@ func (xl xlObjects) GetObject(bucket, object string, startOffset int64, length i
 	// Reorder parts metadata based on erasure distribution order.
 	metaArr = getOrderedPartsMetadata(xlMeta.Erasure.Distribution, metaArr)
 
+	if !isCompressed(xlMeta.Meta) {
+		return xl.getObjectRange(bucket, object, xlMeta, metaArr, onlineDisks, startOffset, length, writer)
+	}
+
+	// Compressed objects are read from the closest frame indexed
+	// before the range and decompressed.
+	actualSize, err := getActualSize(xlMeta.Meta)
+	if err != nil {
+		return toObjectErr(traceError(err), bucket, object)
+	}
+	if startOffset > actualSize || length > actualSize || startOffset+length > actualSize {
+		return traceError(InvalidRange{startOffset, length, actualSize})
+	}
+	index, err := getCompressIndex(xlMeta.Meta)
+	if err != nil {
+		return toObjectErr(traceError(err), bucket, object)
+	}
+	err = decompressRange(writer, startOffset, length, index, func(compressedOffset int64, compressedWriter io.Writer) error {
+		return xl.getObjectRange(bucket, object, xlMeta, metaArr, onlineDisks, compressedOffset, xlMeta.Stat.Size-compressedOffset, compressedWriter)
+	})
+	return toObjectErr(err, bucket, object)
+}
+
+// getObjectRange - reads length bytes of the object data as stored,
+// starting at startOffset.
+func (xl xlObjects) getObjectRange(bucket, object string, xlMeta xlMetaV1, metaArr []xlMetaV1, onlineDisks []StorageAPI, startOffset int64, length int64, writer io.Writer) error {
 	// Reply back invalid range if the input offset and length fall out of range.
 	if startOffset > xlMeta.Stat.Size || length > xlMeta.Stat.Size {
 		return traceError(InvalidRange{startOffset, length, xlMeta.Stat.Size})
@@ -114,53 +140,100 @@ func (xl xlObjects) GetObject(bucket, object string, startOffset int64, length i
 		return traceError(InvalidRange{startOffset, length, xlMeta.Stat.Size})
 	}
 
-	// Save the writer.
-	mw := writer
-
 	// Object cache enabled block.
 	if xlMeta.Stat.Size > 0 && xl.objCacheEnabled {
-		// Validate if we have previous cache.
-		var cachedBuffer io.ReadSeeker
-		cachedBuffer, err = xl.objCache.Open(path.Join(bucket, object), modTime)
-		if err == nil { // Cache hit.
-			// Advance the buffer to offset as if it was read.
-			if _, err = cachedBuffer.Seek(startOffset, 0); err != nil { // Seek to the offset.
-				return traceError(err)
+		return xl.getCachedObject(bucket, object, xlMeta, metaArr, onlineDisks, startOffset, length, writer)
+	}
+
+	return xl.readObject(bucket, object, xlMeta, metaArr, onlineDisks, partIndex, partOffset, lastPartIndex, length, writer)
+}
+
+// getCachedObject - writes the requested range of the object from the
+// blocks found in cache, runs of blocks not in cache are read from the
+// disks at once and cached.
+func (xl xlObjects) getCachedObject(bucket, object string, xlMeta xlMetaV1, metaArr []xlMetaV1, onlineDisks []StorageAPI, startOffset int64, length int64, writer io.Writer) error {
+	key := path.Join(bucket, object)
+	// Blocks of a previous version of the object never match the md5Sum.
+	etag := xlMeta.Meta["md5Sum"]
+	size := xlMeta.Stat.Size
+	blockSize := xl.objCache.BlockSize()
+	endOffset := startOffset + length
+
+	// Reads the blocks from missStart to missEnd from the disks, caches
+	// them and writes the requested bytes to the client.
+	readBlocks := func(missStart, missEnd int64) error {
+		partIndex, partOffset, err := xlMeta.ObjectToPartOffset(missStart)
+		if err != nil {
+			return traceError(InvalidRange{missStart, missEnd - missStart, size})
+		}
+		lastPartIndex, _, err := xlMeta.ObjectToPartOffset(missEnd - 1)
+		if err != nil {
+			return traceError(InvalidRange{missStart, missEnd - missStart, size})
+		}
+		rangeStart, rangeEnd := missStart, missEnd
+		if rangeStart < startOffset {
+			rangeStart = startOffset
+		}
+		if rangeEnd > endOffset {
+			rangeEnd = endOffset
+		}
+		clientWriter := &rangeWriter{
+			writer: writer,
+			skip:   rangeStart - missStart,
+			limit:  rangeEnd - rangeStart,
+		}
+		cacheWriter := xl.objCache.NewWriter(key, etag, missStart, size)
+		return xl.readObject(bucket, object, xlMeta, metaArr, onlineDisks, partIndex, partOffset, lastPartIndex, missEnd-missStart, io.MultiWriter(cacheWriter, clientWriter))
+	}
+
+	// Start of the current run of blocks not in cache, -1 if none.
+	missStart := int64(-1)
+	blockOffset := startOffset - startOffset%blockSize
+	for ; blockOffset < endOffset; blockOffset += blockSize {
+		block, err := xl.objCache.Get(key, etag, blockOffset)
+		if err != nil { // Cache miss.
+			if missStart < 0 {
+				missStart = blockOffset
 			}
-			// Write the requested length.
-			if _, err = io.CopyN(writer, cachedBuffer, length); err != nil {
-				return traceError(err)
+			continue
+		} // Cache hit.
+		if missStart >= 0 {
+			if err = readBlocks(missStart, blockOffset); err != nil {
+				return err
 			}
-			return nil
-		} // Cache miss.
-		// For unknown error, return and error out.
-		if err != objcache.ErrKeyNotFoundInCache {
+			missStart = -1
+		}
+		// Write the requested part of the block.
+		blockStart, blockEnd := int64(0), int64(len(block))
+		if startOffset > blockOffset {
+			blockStart = startOffset - blockOffset
+		}
+		if endOffset-blockOffset < blockEnd {
+			blockEnd = endOffset - blockOffset
+		}
+		if _, err = writer.Write(block[blockStart:blockEnd]); err != nil {
 			return traceError(err)
-		} // Cache has not been found, fill the cache.
-
-		// Cache is only set if whole object is being read.
-		if startOffset == 0 && length == xlMeta.Stat.Size {
-			// Proceed to set the cache.
-			var newBuffer io.WriteCloser
-			// Create a new entry in memory of length.
-			newBuffer, err = xl.objCache.Create(path.Join(bucket, object), length)
-			if err == nil {
-				// Create a multi writer to write to both memory and client response.
-				mw = io.MultiWriter(newBuffer, writer)
-				defer newBuffer.Close()
-			}
-			// Ignore error if cache is full, proceed to write the object.
-			if err != nil && err != objcache.ErrCacheFull {
-				// For any other error return here.
-				return toObjectErr(traceError(err), bucket, object)
-			}
 		}
 	}
+	if missStart >= 0 {
+		// Read up to the end of the last block.
+		if blockOffset > size {
+			blockOffset = size
+		}
+		return readBlocks(missStart, blockOffset)
+	}
 
+	// Return success.
+	return nil
+}
+
+// readObject - reads length bytes of the object from the disks starting
+// at partOffset of the part at partIndex.
+func (xl xlObjects) readObject(bucket, object string, xlMeta xlMetaV1, metaArr []xlMetaV1, onlineDisks []StorageAPI, partIndex int, partOffset int64, lastPartIndex int, length int64, writer io.Writer) error {
 	totalBytesRead := int64(0)
 
 	chunkSize := getChunkSize(xlMeta.Erasure.BlockSize, xlMeta.Erasure.DataBlocks)
-	pool := bpool.NewBytePool(chunkSize, len(onlineDisks))
+	pool := getErasureBufPool(chunkSize)
 
 	// Read from all parts.
 	for ; partIndex <= lastPartIndex; partIndex++ {
@@ -194,13 +267,13 @@ func (xl xlObjects) GetObject(bucket, object string, startOffset int64, length i
 			// Set checksum algo only once, while it is possible to have
 			// different algos per block because of our `xl.json`.
 			// It is not a requirement, set this only once for all the disks.
-			if ckSumAlgo != "" {
+			if ckSumAlgo == "" {
 				ckSumAlgo = ckSumInfo.Algorithm
 			}
 		}
 
 		// Start erasure decoding and writing to the client.
-		n, err := erasureReadFile(mw, onlineDisks, bucket, pathJoin(object, partName), partOffset, readSize, partSize, xlMeta.Erasure.BlockSize, xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks, checkSums, ckSumAlgo, pool)
+		n, err := erasureReadFile(writer, onlineDisks, bucket, pathJoin(object, partName), partOffset, readSize, partSize, xlMeta.Erasure.BlockSize, xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks, checkSums, ckSumAlgo, pool)
 		if err != nil {
 			return toObjectErr(err, bucket, object)
 		}
@@ -381,6 +454,18 @@ func (xl xlObjects) getObjectInfo(bucket, object string) (objInfo ObjectInfo, er
 		// Return error.
 		return ObjectInfo{}, err
 	}
+	return newXLObjectInfo(bucket, object, xlStat, xlMetaMap)
+}
+
+// newXLObjectInfo - converts the stat info and the metadata of an
+// object to ObjectInfo.
+func newXLObjectInfo(bucket, object string, xlStat statInfo, xlMetaMap map[string]string) (objInfo ObjectInfo, err error) {
+	if isCompressed(xlMetaMap) {
+		// Report the size of the object before compression.
+		if xlStat.Size, err = getActualSize(xlMetaMap); err != nil {
+			return ObjectInfo{}, traceError(err)
+		}
+	}
 
 	objInfo = ObjectInfo{
 		IsDir:           false,
@@ -512,6 +597,10 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 	if metadata == nil {
 		metadata = make(map[string]string)
 	}
+	compressed := setCompressionMetadata(bucket, object, metadata)
+	// Bit-rot algorithm is saved with the checksums only.
+	algo := getBitRotAlgo(metadata)
+	delete(metadata, bitRotAlgoMetaKey)
 
 	uniqueID := getUUID()
 	tempErasureObj := path.Join(tmpMetaPrefix, uniqueID, "part.1")
@@ -523,24 +612,15 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 	md5Writer := md5.New()
 
 	// Proceed to set the cache.
-	var newBuffer io.WriteCloser
-
-	// If caching is enabled, proceed to set the cache.
-	if size > 0 && xl.objCacheEnabled {
-		// PutObject invalidates any previously cached object in memory.
-		xl.objCache.Delete(path.Join(bucket, object))
-
-		// Create a new entry in memory of size.
-		newBuffer, err = xl.objCache.Create(path.Join(bucket, object), size)
-		if err == nil {
-			// Create a multi writer to write to both memory and client response.
-			mw = io.MultiWriter(newBuffer, md5Writer)
-		}
-		// Ignore error if cache is full, proceed to write the object.
-		if err != nil && err != objcache.ErrCacheFull {
-			// For any other error return here.
-			return ObjectInfo{}, toObjectErr(traceError(err), bucket, object)
-		}
+	var cacheWriter *objcache.Writer
+
+	// If caching is enabled, proceed to set the cache. The cache holds
+	// the data as stored, compressed objects are cached when read.
+	if size > 0 && xl.objCacheEnabled && !compressed {
+		// Blocks are cached once the object is saved and its md5Sum known.
+		cacheWriter = xl.objCache.NewWriter(path.Join(bucket, object), "", 0, size)
+		// Create a multi writer to write to both the cache and md5.
+		mw = io.MultiWriter(cacheWriter, md5Writer)
 	} else {
 		mw = md5Writer
 	}
@@ -557,7 +637,12 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 	}
 
 	// Tee reader combines incoming data stream and md5, data read from input stream is written to md5.
-	teeReader := io.TeeReader(limitDataReader, mw)
+	var reader io.Reader = io.TeeReader(limitDataReader, mw)
+	var compressedReader *compressReader
+	if compressed {
+		compressedReader = newCompressReader(reader)
+		reader = compressedReader
+	}
 
 	// Initialize xl meta.
 	xlMeta := newXLMetaV1(object, xl.dataBlocks, xl.parityBlocks)
@@ -565,15 +650,20 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 	onlineDisks := getOrderedDisks(xlMeta.Erasure.Distribution, xl.storageDisks)
 
 	// Erasure code data and write across all disks.
-	sizeWritten, checkSums, err := erasureCreateFile(onlineDisks, minioMetaBucket, tempErasureObj, teeReader, xlMeta.Erasure.BlockSize, xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks, bitRotAlgo, xl.writeQuorum)
+	sizeWritten, checkSums, err := erasureCreateFile(onlineDisks, minioMetaBucket, tempErasureObj, reader, xlMeta.Erasure.BlockSize, xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks, algo, xl.writeQuorum)
 	if err != nil {
 		// Create file failed, delete temporary object.
 		xl.deleteObject(minioMetaTmpBucket, tempObj)
 		return ObjectInfo{}, toObjectErr(err, minioMetaBucket, tempErasureObj)
 	}
+	// Size of the data read, before compression.
+	actualSize := sizeWritten
+	if compressed {
+		actualSize = compressedReader.actualSize
+	}
 	// Should return IncompleteBody{} error when reader has fewer bytes
 	// than specified in request header.
-	if sizeWritten < size {
+	if actualSize < size {
 		// Short write, delete temporary object.
 		xl.deleteObject(minioMetaTmpBucket, tempObj)
 		return ObjectInfo{}, traceError(IncompleteBody{})
@@ -582,6 +672,12 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 	// For size == -1, perhaps client is sending in chunked encoding
 	// set the size as size that was actually written.
 	if size == -1 {
+		size = actualSize
+	}
+	if compressed {
+		metadata[actualSizeMetaKey] = strconv.FormatInt(size, 10)
+		setCompressIndex(metadata, compressedReader.index)
+		// Compressed objects are stored with the compressed size.
 		size = sizeWritten
 	}
 
@@ -667,7 +763,7 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 		partsMetadata[index].Erasure.AddCheckSumInfo(checkSumInfo{
 			Name:      "part.1",
 			Hash:      checkSums[index],
-			Algorithm: bitRotAlgo,
+			Algorithm: algo,
 		})
 	}
 
@@ -685,17 +781,21 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 	// Delete the temporary object.
 	xl.deleteObject(minioMetaTmpBucket, newUniqueID)
 
-	// Once we have successfully renamed the object, Close the buffer which would
-	// save the object on cache.
-	if size > 0 && xl.objCacheEnabled && newBuffer != nil {
-		newBuffer.Close()
+	if xl.objCacheEnabled {
+		// PutObject invalidates any previously cached object.
+		xl.objCache.Delete(path.Join(bucket, object))
+		// Once we have successfully renamed the object, save the
+		// object on cache.
+		if cacheWriter != nil {
+			cacheWriter.Commit(xlMeta.Meta["md5Sum"])
+		}
 	}
 
 	objInfo = ObjectInfo{
 		IsDir:           false,
 		Bucket:          bucket,
 		Name:            object,
-		Size:            xlMeta.Stat.Size,
+		Size:            actualSize,
 		ModTime:         xlMeta.Stat.ModTime,
 		MD5Sum:          xlMeta.Meta["md5Sum"],
 		ContentType:     xlMeta.Meta["content-type"],
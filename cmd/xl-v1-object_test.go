@@ -21,7 +21,10 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"io/ioutil"
+	"path"
 	"testing"
+
+	"github.com/mf-00/minio/pkg/objcache"
 )
 
 func TestRepeatPutObjectPart(t *testing.T) {
@@ -259,3 +262,167 @@ func TestPutObjectNoQuorum(t *testing.T) {
 	// Cleanup backend directories.
 	removeRoots(fsDirs)
 }
+
+// Tests ranges of objects are served from the cached blocks, and
+// blocks missing are read from the disks.
+func TestXLGetObjectCache(t *testing.T) {
+	// Create an instance of xl backend.
+	obj, fsDirs, err := prepareXL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cleanup backend directories.
+	defer removeRoots(fsDirs)
+
+	xl := obj.(xlObjects)
+	xl.objCache = objcache.New(globalMaxCacheSize, objcache.NoExpiry)
+	xl.objCacheEnabled = true
+
+	bucket := "bucket"
+	object := "object"
+	if err = xl.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+
+	// 2.5 blocks of data.
+	blockSize := xl.objCache.BlockSize()
+	data := make([]byte, 2*blockSize+blockSize/2)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	if _, err = xl.PutObject(bucket, object, int64(len(data)), bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	getObject := func(offset, length int64) {
+		var buffer bytes.Buffer
+		if gErr := xl.GetObject(bucket, object, offset, length, &buffer); gErr != nil {
+			t.Fatalf("GetObject(%d, %d) failed with %s", offset, length, gErr)
+		}
+		if !bytes.Equal(buffer.Bytes(), data[offset:offset+length]) {
+			t.Fatalf("GetObject(%d, %d) returned unexpected data", offset, length)
+		}
+	}
+
+	// Blocks are cached as the object is written.
+	getObject(blockSize+blockSize/2, 100)
+	if stats := xl.objCache.Stats(); stats.Hits != 1 || stats.Misses != 0 || stats.Blocks != 3 {
+		t.Fatalf("Unexpected cache stats %#v", stats)
+	}
+
+	// A range read caches the blocks it spans.
+	xl.objCache.Delete(path.Join(bucket, object))
+	getObject(blockSize+10, 200)
+	getObject(blockSize+20, 200)
+	if stats := xl.objCache.Stats(); stats.Hits != 2 || stats.Misses != 1 || stats.Blocks != 1 {
+		t.Fatalf("Unexpected cache stats %#v", stats)
+	}
+
+	// Blocks around the cached one are read from the disks.
+	getObject(0, int64(len(data)))
+	getObject(10, int64(len(data))-20)
+	if stats := xl.objCache.Stats(); stats.Hits != 6 || stats.Misses != 3 || stats.Blocks != 3 {
+		t.Fatalf("Unexpected cache stats %#v", stats)
+	}
+
+	// Overwriting the object replaces its cached blocks.
+	for i := range data {
+		data[i] = byte(i % 241)
+	}
+	if _, err = xl.PutObject(bucket, object, int64(len(data)), bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	getObject(0, int64(len(data)))
+	if stats := xl.objCache.Stats(); stats.Blocks != 3 {
+		t.Fatalf("Unexpected cache stats %#v", stats)
+	}
+
+	// Deleting the object drops its cached blocks.
+	if err = xl.DeleteObject(bucket, object); err != nil {
+		t.Fatal(err)
+	}
+	if stats := xl.objCache.Stats(); stats.Blocks != 0 || stats.MemorySize != 0 {
+		t.Fatalf("Unexpected cache stats %#v", stats)
+	}
+}
+
+// Tests objects are checksummed with the selected bit-rot algorithm
+// and verified with it when read.
+func TestXLBitRotAlgo(t *testing.T) {
+	defer func(algo string) { globalBitRotAlgo = algo }(globalBitRotAlgo)
+
+	obj, fsDirs, err := prepareXL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	xl := obj.(xlObjects)
+	// Disable caching so that objects are read from disks.
+	xl.objCacheEnabled = false
+
+	bucket := "bucket"
+	if err = obj.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("abcd")
+
+	// Server's algorithm, or the one selected for the object.
+	globalBitRotAlgo = sha256Algo
+	if _, err = obj.PutObject(bucket, "sha256", int64(len(data)), bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	metadata := map[string]string{bitRotAlgoMetaKey: blake2bAlgo}
+	if _, err = obj.PutObject(bucket, "blake2b", int64(len(data)), bytes.NewReader(data), metadata); err != nil {
+		t.Fatal(err)
+	}
+	metadata = map[string]string{bitRotAlgoMetaKey: highwayHashAlgo}
+	if _, err = obj.PutObject(bucket, "highwayhash", int64(len(data)), bytes.NewReader(data), metadata); err != nil {
+		t.Fatal(err)
+	}
+
+	// Multipart uploads keep the algorithm selected when initiated.
+	uploadID, err := obj.NewMultipartUpload(bucket, "multipart", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	globalBitRotAlgo = blake2bAlgo
+	md5Sum := md5.Sum(data)
+	md5Hex := hex.EncodeToString(md5Sum[:])
+	if _, err = obj.PutObjectPart(bucket, "multipart", uploadID, 1, int64(len(data)), bytes.NewReader(data), md5Hex); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = obj.CompleteMultipartUpload(bucket, "multipart", uploadID, []completePart{{PartNumber: 1, ETag: md5Hex}}); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		object string
+		algo   string
+	}{
+		{"sha256", sha256Algo},
+		{"blake2b", blake2bAlgo},
+		{"highwayhash", highwayHashAlgo},
+		{"multipart", sha256Algo},
+	}
+	for i, testCase := range testCases {
+		xlMeta, rErr := readXLMeta(xl.storageDisks[0], bucket, testCase.object)
+		if rErr != nil {
+			t.Fatalf("Test %d: %s", i+1, rErr)
+		}
+		if _, ok := xlMeta.Meta[bitRotAlgoMetaKey]; ok {
+			t.Errorf("Test %d: expected bit-rot algorithm not to be saved in metadata", i+1)
+		}
+		for _, sum := range xlMeta.Erasure.Checksum {
+			if sum.Algorithm != testCase.algo {
+				t.Errorf("Test %d: expected %s checksums, got %s", i+1, testCase.algo, sum.Algorithm)
+			}
+		}
+		buffer := new(bytes.Buffer)
+		if err = obj.GetObject(bucket, testCase.object, 0, int64(len(data)), buffer); err != nil {
+			t.Fatalf("Test %d: %s", i+1, err)
+		}
+		if !bytes.Equal(buffer.Bytes(), data) {
+			t.Errorf("Test %d: expected %q, got %q", i+1, data, buffer.Bytes())
+		}
+	}
+}
@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"hash/crc32"
+	"io"
 	"path"
 	"sync"
 	"time"
@@ -169,6 +170,10 @@ func parseXLParts(xlMetaBuf []byte) []objectPartInfo {
 		info.Name = p.Get("name").String()
 		info.ETag = p.Get("etag").String()
 		info.Size = p.Get("size").Int()
+		info.ActualSize = p.Get("actualSize").Int()
+		if compressIndex := p.Get("compressIndex"); compressIndex.Exists() {
+			info.CompressIndex = compressIndex.String()
+		}
 		partInfo[i] = info
 	}
 	return partInfo
@@ -312,3 +317,34 @@ func getOrderedDisks(distribution []int, disks []StorageAPI) (orderedDisks []Sto
 	}
 	return orderedDisks
 }
+
+// rangeWriter - writes limit bytes of the data written to it to the
+// underlying writer, after skipping the first skip bytes.
+type rangeWriter struct {
+	writer io.Writer
+	skip   int64
+	limit  int64
+}
+
+// Write - writes the part of p within the range.
+func (r *rangeWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if r.skip > 0 {
+		skip := r.skip
+		if skip > int64(len(p)) {
+			skip = int64(len(p))
+		}
+		p = p[skip:]
+		r.skip -= skip
+	}
+	if int64(len(p)) > r.limit {
+		p = p[:r.limit]
+	}
+	if len(p) > 0 {
+		if _, err := r.writer.Write(p); err != nil {
+			return 0, err
+		}
+		r.limit -= int64(len(p))
+	}
+	return n, nil
+}
@@ -271,6 +271,12 @@ func compareXLMetaV1(t *testing.T, unMarshalXLMeta, gjsonXLMeta xlMetaV1) {
 			if unMarshalXLMeta.Parts[i].Size != gjsonXLMeta.Parts[i].Size {
 				t.Errorf("Expected the size of part %d to be %v, got %v.", i+1, unMarshalXLMeta.Parts[i].Size, gjsonXLMeta.Parts[i].Size)
 			}
+			if unMarshalXLMeta.Parts[i].ActualSize != gjsonXLMeta.Parts[i].ActualSize {
+				t.Errorf("Expected the actual size of part %d to be %v, got %v.", i+1, unMarshalXLMeta.Parts[i].ActualSize, gjsonXLMeta.Parts[i].ActualSize)
+			}
+			if unMarshalXLMeta.Parts[i].CompressIndex != gjsonXLMeta.Parts[i].CompressIndex {
+				t.Errorf("Expected the compress index of part %d to be \"%s\", got \"%s\".", i+1, unMarshalXLMeta.Parts[i].CompressIndex, gjsonXLMeta.Parts[i].CompressIndex)
+			}
 		}
 	}
 
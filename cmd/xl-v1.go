@@ -155,7 +155,16 @@ func newXLObjects(disks, ignoredDisks []string) (ObjectLayer, error) {
 	dataBlocks, parityBlocks := len(newPosixDisks)/2, len(newPosixDisks)/2
 
 	// Initialize object cache.
-	objCache := objcache.New(globalMaxCacheSize, globalCacheExpiry)
+	var objCache *objcache.Cache
+	if globalCacheDir != "" && globalMaxCacheSize > 0 {
+		// Spill blocks evicted from memory to the cache directory.
+		objCache, err = objcache.NewWithDisk(globalMaxCacheSize, globalCacheDir, globalMaxCacheDiskSize, globalCacheExpiry)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to initialize cache directory %s, %s", globalCacheDir, err)
+		}
+	} else {
+		objCache = objcache.New(globalMaxCacheSize, globalCacheExpiry)
+	}
 
 	// Initialize list pool.
 	listPool := newTreeWalkPool(globalLookupTimeout)
@@ -14,7 +14,7 @@
  * limitations under the License.
  */
 
-// Package bpool implements a fixed size pool of byte slices.
+// Package bpool implements a bounded pool of byte slices.
 package bpool
 
 import (
@@ -22,48 +22,107 @@ import (
 	"sync"
 )
 
-// ErrBpoolNoFree - Normally this error should never be returned, this error
-// indicates a bug in the package consumer.
-var ErrBpoolNoFree = errors.New("no free byte slice in pool")
+// ErrGetAborted - the done channel was closed before enough slices of
+// the pool were free.
+var ErrGetAborted = errors.New("bpool: get aborted")
 
-// BytePool - temporary pool of byte slices.
+// ErrTooManySlices - more slices were asked for than the pool allocates.
+var ErrTooManySlices = errors.New("bpool: more slices asked for than the pool's limit")
+
+// BytePool - pool of byte slices of the same size, safe for concurrent
+// use. At most limit slices are allocated, Get blocks while all of them
+// are in use.
 type BytePool struct {
-	buf  [][]byte // array of byte slices
-	used []bool   // indicates if a buf[i] is in use
-	size int64    // size of buf[i]
-	mu   sync.Mutex
+	mu     sync.Mutex
+	free   [][]byte      // slices put back, reused by Get.
+	inUse  int           // slices returned by Get and not put back yet.
+	limit  int           // maximum number of slices allocated.
+	size   int64         // length of the slices.
+	freeCh chan struct{} // closed by Put to wake up waiting Gets.
+}
+
+// Get - returns a slice of the pool's size. Blocks while all the slices
+// are in use until one is put back, or returns ErrGetAborted once
+// doneCh is closed. A nil doneCh waits for as long as it takes, a
+// closed one returns without waiting.
+func (b *BytePool) Get(doneCh <-chan struct{}) ([]byte, error) {
+	bufs, err := b.GetN(1, doneCh)
+	if err != nil {
+		return nil, err
+	}
+	return bufs[0], nil
 }
 
-// Get - Returns an unused byte slice.
-func (b *BytePool) Get() (buf []byte, err error) {
+// GetN - same as Get, except that it returns n slices at once. Callers
+// needing several slices should get them with a single GetN, so that
+// they don't hold some of them while waiting for the others.
+func (b *BytePool) GetN(n int, doneCh <-chan struct{}) ([][]byte, error) {
+	if n > b.limit {
+		return nil, ErrTooManySlices
+	}
 	b.mu.Lock()
-	defer b.mu.Unlock()
-	for i := 0; i < len(b.used); i++ {
-		if !b.used[i] {
-			b.used[i] = true
-			if b.buf[i] == nil {
-				b.buf[i] = make([]byte, b.size)
-			}
-			return b.buf[i], nil
+	for b.inUse+n > b.limit {
+		if b.freeCh == nil {
+			b.freeCh = make(chan struct{})
 		}
+		freeCh := b.freeCh
+		b.mu.Unlock()
+		select {
+		case <-freeCh:
+		case <-doneCh:
+			return nil, ErrGetAborted
+		}
+		b.mu.Lock()
+	}
+	bufs := make([][]byte, n)
+	reused := 0
+	for ; reused < n && len(b.free) > 0; reused++ {
+		last := len(b.free) - 1
+		bufs[reused] = b.free[last]
+		b.free[last] = nil
+		b.free = b.free[:last]
 	}
-	return nil, ErrBpoolNoFree
+	b.inUse += n
+	b.mu.Unlock()
+
+	// Allocate the slices that could not be reused.
+	for i := reused; i < n; i++ {
+		bufs[i] = make([]byte, b.size)
+	}
+	return bufs, nil
 }
 
-// Reset - Marks all slices as unused.
-func (b *BytePool) Reset() {
+// Put - returns a slice from Get to the pool once no longer used, it
+// may have been resliced. Slices smaller than the pool's size are not
+// reused, they still count as put back.
+func (b *BytePool) Put(buf []byte) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	for i := 0; i < len(b.used); i++ {
-		b.used[i] = false
+	if b.inUse == 0 {
+		// Not from Get.
+		return
+	}
+	b.inUse--
+	if int64(cap(buf)) >= b.size {
+		b.free = append(b.free, buf[:b.size])
+	}
+	if b.freeCh != nil {
+		close(b.freeCh)
+		b.freeCh = nil
 	}
 }
 
+// Size - returns the length of the slices of the pool.
+func (b *BytePool) Size() int64 {
+	return b.size
+}
+
 // NewBytePool - Returns new pool.
 // size - length of each slice.
-// n - number of slices in the pool.
-func NewBytePool(size int64, n int) *BytePool {
-	used := make([]bool, n)
-	buf := make([][]byte, n)
-	return &BytePool{buf, used, size, sync.Mutex{}}
+// limit - maximum number of slices allocated by the pool.
+func NewBytePool(size int64, limit int) *BytePool {
+	if limit < 1 {
+		limit = 1
+	}
+	return &BytePool{limit: limit, size: size}
 }
@@ -16,7 +16,11 @@
 
 package bpool
 
-import "testing"
+import (
+	"sync"
+	"testing"
+	"time"
+)
 
 func TestBytePool(t *testing.T) {
 	size := int64(10)
@@ -25,29 +29,149 @@ func TestBytePool(t *testing.T) {
 	enBlocks := make([][]byte, n)
 
 	// Allocates all the 16 byte slices in the pool.
-	alloc := func() {
-		for i := range enBlocks {
-			var err error
-			enBlocks[i], err = pool.Get()
-			if err != nil {
-				t.Fatal("expected nil, got", err)
-			}
-			// Make sure the slice length is as expected.
-			if len(enBlocks[i]) != int(size) {
-				t.Fatalf("expected size %d, got %d", len(enBlocks[i]), size)
+	for i := range enBlocks {
+		buf, err := pool.Get(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Make sure the slice length is as expected.
+		if len(buf) != int(size) {
+			t.Fatalf("expected size %d, got %d", size, len(buf))
+		}
+		enBlocks[i] = buf
+	}
+	// Return resliced slices to the pool.
+	for i := range enBlocks {
+		pool.Put(enBlocks[i][:i%int(size)])
+	}
+	// Slices not from Get are ignored.
+	pool.Put(make([]byte, size))
+	// Slices put back are reused.
+	bufs, err := pool.GetN(n, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, buf := range bufs {
+		if len(buf) != int(size) {
+			t.Fatalf("expected size %d, got %d", size, len(buf))
+		}
+		reused := false
+		for j := range enBlocks {
+			if &buf[0] == &enBlocks[j][:1][0] {
+				reused = true
 			}
 		}
+		if !reused {
+			t.Fatalf("expected slice %d to be reused", i)
+		}
+	}
+	// No more slices than the limit can be asked for.
+	if _, err = pool.GetN(n+1, nil); err != ErrTooManySlices {
+		t.Fatalf("expected %v, got %v", ErrTooManySlices, err)
+	}
+}
+
+// Tests Get blocks once all the slices of the pool are in use.
+func TestBytePoolExhausted(t *testing.T) {
+	pool := NewBytePool(10, 4)
+	bufs, err := pool.GetN(4, nil)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	// Allocate everything in the pool.
-	alloc()
-	// Any Get() will fail when the pool does not have any free buffer.
-	_, err := pool.Get()
-	if err == nil {
-		t.Fatalf("expected %s, got nil", err)
+	// A closed done channel doesn't wait.
+	doneCh := make(chan struct{})
+	close(doneCh)
+	if _, err = pool.Get(doneCh); err != ErrGetAborted {
+		t.Fatalf("expected %v, got %v", ErrGetAborted, err)
 	}
-	// Reset - so that all the buffers are marked as unused.
-	pool.Reset()
-	// Allocation of all the buffers in the pool should succeed now.
-	alloc()
+
+	// Get is aborted once the done channel is closed.
+	doneCh = make(chan struct{})
+	errCh := make(chan error)
+	go func() {
+		_, gErr := pool.Get(doneCh)
+		errCh <- gErr
+	}()
+	select {
+	case err = <-errCh:
+		t.Fatalf("expected Get to block, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(doneCh)
+	if err = <-errCh; err != ErrGetAborted {
+		t.Fatalf("expected %v, got %v", ErrGetAborted, err)
+	}
+
+	// GetN waits until enough slices are put back.
+	bufCh := make(chan [][]byte)
+	go func() {
+		got, gErr := pool.GetN(2, nil)
+		if gErr != nil {
+			t.Error(gErr)
+		}
+		bufCh <- got
+	}()
+	pool.Put(bufs[0])
+	select {
+	case <-bufCh:
+		t.Fatal("expected GetN to block until 2 slices are put back")
+	case <-time.After(50 * time.Millisecond):
+	}
+	pool.Put(bufs[1])
+	got := <-bufCh
+	for _, buf := range got {
+		if &buf[0] != &bufs[0][0] && &buf[0] != &bufs[1][0] {
+			t.Fatal("expected the slices put back to be reused")
+		}
+	}
+}
+
+// Tests concurrent use of the pool.
+func TestBytePoolParallel(t *testing.T) {
+	pool := NewBytePool(1024, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				buf, err := pool.Get(nil)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				buf[0] = byte(i)
+				if buf[0] != byte(i) {
+					t.Errorf("slice used by another goroutine")
+				}
+				pool.Put(buf)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if pool.inUse != 0 || len(pool.free) > 8 {
+		t.Fatalf("expected no slice in use and at most 8 free, got %d and %d", pool.inUse, len(pool.free))
+	}
+}
+
+func BenchmarkBytePool(b *testing.B) {
+	pool := NewBytePool(1024*1024, 64)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			buf, _ := pool.Get(nil)
+			pool.Put(buf)
+		}
+	})
+}
+
+func BenchmarkMakeBytes(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			buf := make([]byte, 1024*1024)
+			buf[0] = 1
+		}
+	})
 }
@@ -15,13 +15,18 @@
  *
  */
 
-// Package objcache implements in memory caching methods.
+// Package objcache implements a block level object cache kept in
+// memory, spilling least recently used blocks to a disk directory.
 package objcache
 
 import (
-	"bytes"
+	"container/list"
+	"crypto/sha256"
 	"errors"
-	"io"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 )
@@ -32,34 +37,104 @@ var NoExpiry = time.Duration(0)
 // DefaultExpiry represents default time duration value when individual entries will be expired.
 var DefaultExpiry = time.Duration(72 * time.Hour) // 72hrs.
 
-// buffer represents the in memory cache of a single entry.
-// buffer carries value of the data and last accessed time.
-type buffer struct {
-	value        []byte    // Value of the entry.
+// DefaultBlockSize is the size of the blocks objects are cached in,
+// the last block of an object may be shorter.
+const DefaultBlockSize = 1024 * 1024 // 1MiB.
+
+// Blocks spilled to disk are saved under this directory of the
+// configured cache directory, it is emptied on startup.
+const diskCacheDir = "minio-objcache"
+
+// ErrKeyNotFoundInCache - key not found in cache.
+var ErrKeyNotFoundInCache = errors.New("Key not found in cache")
+
+// ErrCacheFull - cache is full.
+var ErrCacheFull = errors.New("Not enough space in cache")
+
+// ErrExcessData - excess data was attempted to be written on cache.
+var ErrExcessData = errors.New("Attempted excess write on cache")
+
+// blockKey identifies a block of an object, the ETag makes sure blocks
+// of a previous version of the object are never served.
+type blockKey struct {
+	key    string
+	etag   string
+	offset int64
+}
+
+// entry represents a single cached block, its value is either held in
+// memory or saved in a file of the disk directory.
+type entry struct {
+	block        blockKey
+	value        []byte    // Value of the block, nil if on disk.
+	size         uint64    // Length of the block.
+	onDisk       bool      // Set if the block was spilled to disk.
 	lastAccessed time.Time // Represents time when value was last accessed.
 }
 
-// Cache holds the required variables to compose an in memory cache system
-// which also provides expiring key mechanism and also maxSize.
+// Stats - cache usage statistics.
+type Stats struct {
+	// Blocks served from the cache, including the ones read from disk.
+	Hits uint64
+	// Blocks served from the disk directory.
+	DiskHits uint64
+	// Blocks not found in the cache.
+	Misses uint64
+	// Blocks removed to make space, or because they expired.
+	Evictions uint64
+	// Bytes of blocks held in memory and on disk.
+	MemorySize uint64
+	DiskSize   uint64
+	// Number of blocks cached.
+	Blocks int
+}
+
+// HitRatio - ratio of the blocks looked up which were found in the cache.
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Cache holds the required variables to compose a block level cache
+// system which also provides expiring key mechanism and also maxSize.
 type Cache struct {
 	// Mutex is used for handling the concurrent
 	// read/write requests for cache
 	mutex sync.Mutex
 
-	// maxSize is a total size for overall cache
+	// maxSize is a total size for overall cache in memory
 	maxSize uint64
 
 	// currentSize is a current size in memory
 	currentSize uint64
 
-	// OnEviction - callback function for eviction
+	// Directory and total size of the blocks spilled to disk,
+	// blocks are not spilled if diskDir is empty.
+	diskDir         string
+	maxDiskSize     uint64
+	currentDiskSize uint64
+
+	// Size of the cached blocks.
+	blockSize int64
+
+	// OnEviction - callback function called with the object key once
+	// none of its blocks are cached anymore.
 	OnEviction func(key string)
 
-	// totalEvicted counter to keep track of total expirys
-	totalEvicted int
+	// Counters of the cache usage.
+	hits, diskHits, misses, evictions uint64
+
+	// Blocks in memory and on disk, the most recently used first.
+	memoryLRU *list.List
+	diskLRU   *list.List
+
+	// map of blocks and their list elements.
+	entries map[blockKey]*list.Element
 
-	// map of objectName and its contents
-	entries map[string]*buffer
+	// Cached blocks of every object key.
+	objects map[string]map[blockKey]struct{}
 
 	// Expiry in time duration.
 	expiry time.Duration
@@ -68,124 +143,136 @@ type Cache struct {
 	stopGC chan struct{}
 }
 
-// New - Return a new cache with a given default expiry duration.
+// New - Return a new in memory cache with a given default expiry duration.
 // If the expiry duration is less than one (or NoExpiry),
 // the items in the cache never expire (by default), and must be deleted
 // manually.
 func New(maxSize uint64, expiry time.Duration) *Cache {
 	C := &Cache{
-		maxSize: maxSize,
-		entries: make(map[string]*buffer),
-		expiry:  expiry,
+		maxSize:   maxSize,
+		blockSize: DefaultBlockSize,
+		memoryLRU: list.New(),
+		diskLRU:   list.New(),
+		entries:   make(map[blockKey]*list.Element),
+		objects:   make(map[string]map[blockKey]struct{}),
+		expiry:    expiry,
 	}
 	// We have expiry start the janitor routine.
 	if expiry > 0 {
 		C.stopGC = make(chan struct{})
-
 		// Start garbage collection routine to expire objects.
 		C.startGC()
 	}
 	return C
 }
 
-// ErrKeyNotFoundInCache - key not found in cache.
-var ErrKeyNotFoundInCache = errors.New("Key not found in cache")
-
-// ErrCacheFull - cache is full.
-var ErrCacheFull = errors.New("Not enough space in cache")
-
-// ErrExcessData - excess data was attempted to be written on cache.
-var ErrExcessData = errors.New("Attempted excess write on cache")
-
-// Used for adding entry to the object cache. Implements io.WriteCloser
-type cacheBuffer struct {
-	*bytes.Buffer // Implements io.Writer
-	onClose       func() error
+// NewWithDisk - Return a new cache which spills blocks evicted from
+// memory to diskDir, using at most maxDiskSize bytes there.
+func NewWithDisk(maxSize uint64, diskDir string, maxDiskSize uint64, expiry time.Duration) (*Cache, error) {
+	dir := filepath.Join(diskDir, diskCacheDir)
+	// Blocks left over by a previous run are not indexed, remove them.
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	C := New(maxSize, expiry)
+	C.diskDir = dir
+	C.maxDiskSize = maxDiskSize
+	return C, nil
 }
 
-// On close, onClose() is called which checks if all object contents
-// have been written so that it can save the buffer to the cache.
-func (c cacheBuffer) Close() (err error) {
-	return c.onClose()
+// BlockSize - returns the size of the blocks objects are cached in.
+func (c *Cache) BlockSize() int64 {
+	return c.blockSize
 }
 
-// Create - validates if object size fits with in cache size limit and returns a io.WriteCloser
-// to which object contents can be written and finally Close()'d. During Close() we
-// checks if the amount of data written is equal to the size of the object, in which
-// case it saves the contents to object cache.
-func (c *Cache) Create(key string, size int64) (w io.WriteCloser, err error) {
-	// Recovers any panic generated and return errors appropriately.
-	defer func() {
-		if r := recover(); r != nil {
-			// Recover any panic and return ErrCacheFull.
-			err = ErrCacheFull
-		}
-	}() // Do not crash the server.
-
-	valueLen := uint64(size)
-	// Check if the size of the object is not bigger than the capacity of the cache.
-	if c.maxSize > 0 && valueLen > c.maxSize {
-		return nil, ErrCacheFull
+// Stats - returns the usage statistics of the cache.
+func (c *Cache) Stats() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return Stats{
+		Hits:       c.hits,
+		DiskHits:   c.diskHits,
+		Misses:     c.misses,
+		Evictions:  c.evictions,
+		MemorySize: c.currentSize,
+		DiskSize:   c.currentDiskSize,
+		Blocks:     len(c.entries),
 	}
+}
 
-	// Will hold the object contents.
-	buf := bytes.NewBuffer(make([]byte, 0, size))
+// Get - returns the block of the object version at offset, the returned
+// slice must not be modified. Returns ErrKeyNotFoundInCache if the block
+// is not cached.
+func (c *Cache) Get(key, etag string, offset int64) ([]byte, error) {
+	block := blockKey{key, etag, offset}
 
-	// Function called on close which saves the object contents
-	// to the object cache.
-	onClose := func() error {
-		c.mutex.Lock()
-		defer c.mutex.Unlock()
-		if size != int64(buf.Len()) {
-			// Full object not available hence do not save buf to object cache.
-			return io.ErrShortBuffer
-		}
-		if c.maxSize > 0 && c.currentSize+valueLen > c.maxSize {
-			return ErrExcessData
-		}
-		// Full object available in buf, save it to cache.
-		c.entries[key] = &buffer{
-			value:        buf.Bytes(),
-			lastAccessed: time.Now().UTC(), // Save last accessed time.
-		}
-		// Account for the memory allocated above.
-		c.currentSize += uint64(size)
-		return nil
+	c.mutex.Lock()
+	elem, ok := c.entries[block]
+	if !ok {
+		c.misses++
+		c.mutex.Unlock()
+		return nil, ErrKeyNotFoundInCache
+	}
+	e := elem.Value.(*entry)
+	e.lastAccessed = time.Now().UTC()
+	if !e.onDisk {
+		c.hits++
+		c.memoryLRU.MoveToFront(elem)
+		c.mutex.Unlock()
+		return e.value, nil
 	}
+	c.mutex.Unlock()
 
-	// Object contents that is written - cacheBuffer.Write(data)
-	// will be accumulated in buf which implements io.Writer.
-	return cacheBuffer{
-		buf,
-		onClose,
-	}, nil
-}
+	// Read the spilled block without holding the lock, it may be
+	// removed meanwhile in which case it is a miss.
+	value, err := ioutil.ReadFile(c.blockPath(block))
 
-// Open - open the in-memory file, returns an in memory read seeker.
-// returns an error ErrNotFoundInCache, if the key does not exist.
-// Returns ErrKeyNotFoundInCache if entry's lastAccessedTime is older
-// than objModTime.
-func (c *Cache) Open(key string, objModTime time.Time) (io.ReadSeeker, error) {
-	// Entry exists, return the readable buffer.
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	buf, ok := c.entries[key]
-	if !ok {
+	if err != nil || uint64(len(value)) != e.size || c.entries[block] != elem {
+		c.misses++
+		c.mutex.Unlock()
 		return nil, ErrKeyNotFoundInCache
 	}
-	// Check if buf is recent copy of the object on disk.
-	if buf.lastAccessed.Before(objModTime) {
-		c.delete(key)
-		return nil, ErrKeyNotFoundInCache
+	c.hits++
+	c.diskHits++
+	// Promote the block back to memory.
+	c.delete(elem, false)
+	evicted := c.put(block, value)
+	c.mutex.Unlock()
+	c.notifyEviction(evicted)
+	return value, nil
+}
+
+// Put - caches a block of the object version at offset, offset must be
+// a multiple of the block size. Returns ErrCacheFull if the block does
+// not fit in memory.
+func (c *Cache) Put(key, etag string, offset int64, value []byte) error {
+	if int64(len(value)) > c.blockSize || offset%c.blockSize != 0 {
+		return ErrExcessData
 	}
-	buf.lastAccessed = time.Now().UTC()
-	return bytes.NewReader(buf.value), nil
+	if c.maxSize > 0 && uint64(len(value)) > c.maxSize {
+		return ErrCacheFull
+	}
+	// Callers usually reuse their buffers, keep a copy.
+	v := make([]byte, len(value))
+	copy(v, value)
+
+	c.mutex.Lock()
+	evicted := c.put(blockKey{key, etag, offset}, v)
+	c.mutex.Unlock()
+	c.notifyEviction(evicted)
+	return nil
 }
 
-// Delete - delete deletes an entry from the cache.
+// Delete - deletes all the cached blocks of an object.
 func (c *Cache) Delete(key string) {
 	c.mutex.Lock()
-	c.delete(key)
+	for block := range c.objects[key] {
+		c.delete(c.entries[block], false)
+	}
 	c.mutex.Unlock()
 	if c.OnEviction != nil {
 		c.OnEviction(key)
@@ -196,18 +283,23 @@ func (c *Cache) Delete(key string) {
 func (c *Cache) gc() {
 	var evictedEntries []string
 	c.mutex.Lock()
-	for k, v := range c.entries {
-		if c.expiry > 0 && time.Now().UTC().Sub(v.lastAccessed) > c.expiry {
-			c.delete(k)
-			evictedEntries = append(evictedEntries, k)
+	now := time.Now().UTC()
+	for _, l := range []*list.List{c.memoryLRU, c.diskLRU} {
+		// Least recently used blocks are at the back.
+		for elem := l.Back(); elem != nil; {
+			prev := elem.Prev()
+			e := elem.Value.(*entry)
+			if now.Sub(e.lastAccessed) <= c.expiry {
+				break
+			}
+			if c.delete(elem, true) {
+				evictedEntries = append(evictedEntries, e.block.key)
+			}
+			elem = prev
 		}
 	}
 	c.mutex.Unlock()
-	for _, k := range evictedEntries {
-		if c.OnEviction != nil {
-			c.OnEviction(k)
-		}
-	}
+	c.notifyEviction(evictedEntries)
 }
 
 // StopGC sends a message to the expiry routine to stop
@@ -237,11 +329,98 @@ func (c *Cache) startGC() {
 	}()
 }
 
-// Deletes a requested entry from the cache.
-func (c *Cache) delete(key string) {
-	if buf, ok := c.entries[key]; ok {
-		delete(c.entries, key)
-		c.currentSize -= uint64(len(buf.value))
-		c.totalEvicted++
+// notifyEviction - calls OnEviction for the object keys without any
+// cached block left.
+func (c *Cache) notifyEviction(keys []string) {
+	if c.OnEviction == nil {
+		return
+	}
+	for _, key := range keys {
+		c.OnEviction(key)
+	}
+}
+
+// blockPath - returns the file a block is spilled to.
+func (c *Cache) blockPath(block blockKey) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d", block.key, block.etag, block.offset)))
+	return filepath.Join(c.diskDir, fmt.Sprintf("%x", sum))
+}
+
+// put - adds a block to memory, making space by spilling least recently
+// used blocks to disk or evicting them. Returns the object keys without
+// any cached block left.
+func (c *Cache) put(block blockKey, value []byte) (evicted []string) {
+	if elem, ok := c.entries[block]; ok {
+		c.delete(elem, false)
+	}
+	e := &entry{
+		block:        block,
+		value:        value,
+		size:         uint64(len(value)),
+		lastAccessed: time.Now().UTC(), // Save last accessed time.
+	}
+	c.entries[block] = c.memoryLRU.PushFront(e)
+	if c.objects[block.key] == nil {
+		c.objects[block.key] = make(map[blockKey]struct{})
+	}
+	c.objects[block.key][block] = struct{}{}
+	// Account for the memory allocated above.
+	c.currentSize += e.size
+
+	for c.maxSize > 0 && c.currentSize > c.maxSize {
+		elem := c.memoryLRU.Back()
+		if !c.spill(elem) && c.delete(elem, true) {
+			evicted = append(evicted, elem.Value.(*entry).block.key)
+		}
+	}
+	for c.currentDiskSize > c.maxDiskSize {
+		elem := c.diskLRU.Back()
+		if c.delete(elem, true) {
+			evicted = append(evicted, elem.Value.(*entry).block.key)
+		}
+	}
+	return evicted
+}
+
+// spill - moves a block from memory to disk, returns false if it could
+// not be saved there.
+func (c *Cache) spill(elem *list.Element) bool {
+	e := elem.Value.(*entry)
+	if c.diskDir == "" || e.size > c.maxDiskSize {
+		return false
+	}
+	if err := ioutil.WriteFile(c.blockPath(e.block), e.value, 0600); err != nil {
+		return false
+	}
+	c.memoryLRU.Remove(elem)
+	c.currentSize -= e.size
+	e.value = nil
+	e.onDisk = true
+	c.entries[e.block] = c.diskLRU.PushFront(e)
+	c.currentDiskSize += e.size
+	return true
+}
+
+// delete - removes a block from the cache, returns true if it was the
+// last cached block of its object.
+func (c *Cache) delete(elem *list.Element, evict bool) bool {
+	e := elem.Value.(*entry)
+	if e.onDisk {
+		c.diskLRU.Remove(elem)
+		c.currentDiskSize -= e.size
+		os.Remove(c.blockPath(e.block))
+	} else {
+		c.memoryLRU.Remove(elem)
+		c.currentSize -= e.size
+	}
+	delete(c.entries, e.block)
+	delete(c.objects[e.block.key], e.block)
+	if evict {
+		c.evictions++
+	}
+	if len(c.objects[e.block.key]) == 0 {
+		delete(c.objects, e.block.key)
+		return true
 	}
+	return false
 }
@@ -19,246 +19,255 @@ package objcache
 
 import (
 	"bytes"
-	"io"
 	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 )
 
-// TestObjectCache tests cases of object cache with expiry.
+// TestObjExpiry tests cases of object cache with expiry.
 func TestObjExpiry(t *testing.T) {
-	// Non exhaustive list of all object cache behavior cases.
-	testCases := []struct {
-		expiry    time.Duration
-		cacheSize uint64
-		err       error
-		closeErr  error
-	}{
-		{
-			expiry:    100 * time.Millisecond,
-			cacheSize: 1024,
-			err:       ErrKeyNotFoundInCache,
-			closeErr:  nil,
-		},
-	}
-
 	// Test case 1 validates running of GC.
-	testCase := testCases[0]
-	cache := New(testCase.cacheSize, testCase.expiry)
-	cache.OnEviction = func(key string) {}
-	w, err := cache.Create("test", 1)
-	if err != nil {
-		t.Errorf("Test case 1 expected to pass, failed instead %s", err)
+	cache := New(1024, 100*time.Millisecond)
+	defer cache.StopGC()
+	var evictedKey string
+	evictedCh := make(chan struct{}, 1)
+	cache.OnEviction = func(key string) {
+		evictedKey = key
+		evictedCh <- struct{}{}
 	}
-	// Write a byte.
-	w.Write([]byte("1"))
-	if err = w.Close(); err != nil {
+	if err := cache.Put("test", "etag", 0, []byte("1")); err != nil {
 		t.Errorf("Test case 1 expected to pass, failed instead %s", err)
 	}
-	// Wait for 500 millisecond.
-	time.Sleep(500 * time.Millisecond)
-	// Setting objModTime to the beginning of golang's time.Time to avoid deletion of stale entry.
-	fakeObjModTime := time.Time{}
-	_, err = cache.Open("test", fakeObjModTime)
-	if err != testCase.err {
-		t.Errorf("Test case 1 expected %s, got instead %s", testCase.err, err)
+	// Wait for the block to expire.
+	select {
+	case <-evictedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Test case 1 expected the block to expire")
+	}
+	if evictedKey != "test" {
+		t.Errorf("Test case 1 expected \"test\" to be evicted, got %s", evictedKey)
+	}
+	if _, err := cache.Get("test", "etag", 0); err != ErrKeyNotFoundInCache {
+		t.Errorf("Test case 1 expected %s, got instead %s", ErrKeyNotFoundInCache, err)
 	}
 }
 
 // TestObjCache - tests various cases for object cache behavior.
 func TestObjCache(t *testing.T) {
-	// Setting objModTime to the beginning of golang's time.Time to avoid deletion of stale entry.
-	fakeObjModTime := time.Time{}
-
-	// Non exhaustive list of all object cache behavior cases.
-	testCases := []struct {
-		expiry    time.Duration
-		cacheSize uint64
-		err       error
-		closeErr  error
-	}{
-		// Validate if a key is not found in cache and Open fails.
-		{
-			expiry:    NoExpiry,
-			cacheSize: 1024,
-			err:       ErrKeyNotFoundInCache,
-		},
-		// Validate if cache indicates that it is full and Create fails.
-		{
-			expiry:    NoExpiry,
-			cacheSize: 1,
-			err:       ErrCacheFull,
-		},
-		// Validate if Create succeeds but Close fails to write to buffer.
-		{
-			expiry:    NoExpiry,
-			cacheSize: 2,
-			closeErr:  io.ErrShortBuffer,
-		},
-		// Validate that Create and Close succeed, making sure to update the cache.
-		{
-			expiry:    NoExpiry,
-			cacheSize: 1024,
-		},
-		// Validate that Delete succeeds and Open fails with key not found in cache.
-		{
-			expiry:    NoExpiry,
-			cacheSize: 1024,
-			err:       ErrKeyNotFoundInCache,
-		},
-		// Validate OnEviction function is called upon entry delete.
-		{
-			expiry:    NoExpiry,
-			cacheSize: 1024,
-		},
-		// Validate error excess data.
-		{
-			expiry:    NoExpiry,
-			cacheSize: 5,
-			closeErr:  ErrExcessData,
-		},
-	}
-
-	// Test 1 validating Open failure.
-	testCase := testCases[0]
-	cache := New(testCase.cacheSize, testCase.expiry)
-	_, err := cache.Open("test", fakeObjModTime)
-	if testCase.err != err {
-		t.Errorf("Test case 2 expected to pass, failed instead %s", err)
+	// Test 1 validating Get failure.
+	cache := New(1024, NoExpiry)
+	if _, err := cache.Get("test", "etag", 0); err != ErrKeyNotFoundInCache {
+		t.Errorf("Test case 1 expected %s, got instead %s", ErrKeyNotFoundInCache, err)
 	}
 
-	// Test 2 validating Create failure.
-	testCase = testCases[1]
-	cache = New(testCase.cacheSize, testCase.expiry)
-	_, err = cache.Create("test", 2)
-	if testCase.err != err {
-		t.Errorf("Test case 2 expected to pass, failed instead %s", err)
+	// Test 2 validating Put failure when the block does not fit.
+	cache = New(1, NoExpiry)
+	if err := cache.Put("test", "etag", 0, []byte("He")); err != ErrCacheFull {
+		t.Errorf("Test case 2 expected %s, got instead %s", ErrCacheFull, err)
 	}
 
-	// Test 3 validating Create succeeds and returns a writer.
-	// Subsequently we Close() without writing any data, to receive
-	// `io.ErrShortBuffer`
-	testCase = testCases[2]
-	cache = New(testCase.cacheSize, testCase.expiry)
-	w, err := cache.Create("test", 1)
-	if testCase.err != err {
-		t.Errorf("Test case 3 expected to pass, failed instead %s", err)
-	}
-	if err = w.Close(); err != testCase.closeErr {
-		t.Errorf("Test case 3 expected to pass, failed instead %s", err)
+	// Test 3 validating Put failure for blocks not aligned.
+	cache = New(1024, NoExpiry)
+	if err := cache.Put("test", "etag", 1, []byte("H")); err != ErrExcessData {
+		t.Errorf("Test case 3 expected %s, got instead %s", ErrExcessData, err)
 	}
 
-	// Test 4 validates Create and Close succeeds successfully caching
-	// the writes.
-	testCase = testCases[3]
-	cache = New(testCase.cacheSize, testCase.expiry)
-	w, err = cache.Create("test", 5)
-	if testCase.err != err {
-		t.Errorf("Test case 4 expected to pass, failed instead %s", err)
-	}
-	// Write '5' bytes.
-	w.Write([]byte("Hello"))
-	// Close to successfully save into cache.
-	if err = w.Close(); err != nil {
+	// Test 4 validates Put and Get succeed.
+	cache = New(1024, NoExpiry)
+	value := []byte("Hello")
+	if err := cache.Put("test", "etag", 0, value); err != nil {
 		t.Errorf("Test case 4 expected to pass, failed instead %s", err)
 	}
-	r, err := cache.Open("test", fakeObjModTime)
+	// The cache keeps its own copy.
+	value[0] = 'J'
+	cbytes, err := cache.Get("test", "etag", 0)
 	if err != nil {
 		t.Errorf("Test case 4 expected to pass, failed instead %s", err)
 	}
-	// Reads everything stored for key "test".
-	cbytes, err := ioutil.ReadAll(r)
-	if err != nil {
-		t.Errorf("Test case 4 expected to pass, failed instead %s", err)
-	}
-	// Validate if read bytes match.
 	if !bytes.Equal(cbytes, []byte("Hello")) {
 		t.Errorf("Test case 4 expected to pass. wanted \"Hello\", got %s", string(cbytes))
 	}
+	// Blocks of another version of the object are not served.
+	if _, err = cache.Get("test", "etag2", 0); err != ErrKeyNotFoundInCache {
+		t.Errorf("Test case 4 expected %s, got instead %s", ErrKeyNotFoundInCache, err)
+	}
 
-	// Test 5 validates Delete succeeds and Open fails with err
-	testCase = testCases[4]
-	cache = New(testCase.cacheSize, testCase.expiry)
-	w, err = cache.Create("test", 5)
-	if err != nil {
-		t.Errorf("Test case 5 expected to pass, failed instead %s", err)
+	// Test 5 validates Delete removes all the blocks and calls OnEviction.
+	cache = New(1024, NoExpiry)
+	var deleteKey string
+	cache.OnEviction = func(key string) {
+		deleteKey = key
 	}
-	// Write '5' bytes.
-	w.Write([]byte("Hello"))
-	// Close to successfully save into cache.
-	if err = w.Close(); err != nil {
-		t.Errorf("Test case 5 expected to pass, failed instead %s", err)
+	cache.blockSize = 5
+	for offset, block := range []string{"Hello", "World"} {
+		if err = cache.Put("test", "etag", int64(offset*5), []byte(block)); err != nil {
+			t.Errorf("Test case 5 expected to pass, failed instead %s", err)
+		}
 	}
-	// Delete the cache entry.
 	cache.Delete("test")
-	_, err = cache.Open("test", fakeObjModTime)
-	if testCase.err != err {
-		t.Errorf("Test case 5 expected to pass, failed instead %s", err)
+	for _, offset := range []int64{0, 5} {
+		if _, err = cache.Get("test", "etag", offset); err != ErrKeyNotFoundInCache {
+			t.Errorf("Test case 5 expected %s, got instead %s", ErrKeyNotFoundInCache, err)
+		}
+	}
+	if deleteKey != "test" {
+		t.Errorf("Test case 5 expected to pass, wanted \"test\", got %s", deleteKey)
+	}
+	if stats := cache.Stats(); stats.MemorySize != 0 || stats.Blocks != 0 {
+		t.Errorf("Test case 5 expected an empty cache, got %#v", stats)
 	}
+}
 
-	// Test 6 validates OnEviction being called upon Delete is being invoked.
-	testCase = testCases[5]
-	cache = New(testCase.cacheSize, testCase.expiry)
-	w, err = cache.Create("test", 5)
+// TestLRU - tests the least recently used blocks are evicted first.
+func TestLRU(t *testing.T) {
+	cache := New(10, NoExpiry)
+	var evicted []string
+	cache.OnEviction = func(key string) {
+		evicted = append(evicted, key)
+	}
+	for _, key := range []string{"a", "b"} {
+		if err := cache.Put(key, "etag", 0, []byte("Hello")); err != nil {
+			t.Fatalf("Expected to pass, failed instead %s", err)
+		}
+	}
+	// Make "a" the most recently used.
+	if _, err := cache.Get("a", "etag", 0); err != nil {
+		t.Fatalf("Expected to pass, failed instead %s", err)
+	}
+	if err := cache.Put("c", "etag", 0, []byte("Hello")); err != nil {
+		t.Fatalf("Expected to pass, failed instead %s", err)
+	}
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Fatalf("Expected \"b\" to be evicted, got %v", evicted)
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, err := cache.Get(key, "etag", 0); err != nil {
+			t.Errorf("Expected %s to be cached, failed instead %s", key, err)
+		}
+	}
+	stats := cache.Stats()
+	if stats.MemorySize != 10 || stats.Evictions != 1 {
+		t.Errorf("Unexpected stats %#v", stats)
+	}
+	if stats.Hits != 3 || stats.Misses != 0 || stats.HitRatio() != 1 {
+		t.Errorf("Unexpected hit stats %#v", stats)
+	}
+}
+
+// TestDiskSpill - tests blocks evicted from memory are spilled to disk
+// and promoted back on access.
+func TestDiskSpill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "objcache-")
 	if err != nil {
-		t.Errorf("Test case 6 expected to pass, failed instead %s", err)
+		t.Fatal(err)
 	}
-	// Write '5' bytes.
-	w.Write([]byte("Hello"))
-	// Close to successfully save into cache.
-	if err = w.Close(); err != nil {
-		t.Errorf("Test case 6 expected to pass, failed instead %s", err)
+	defer os.RemoveAll(dir)
+
+	cache, err := NewWithDisk(5, dir, 10, NoExpiry)
+	if err != nil {
+		t.Fatal(err)
 	}
-	var deleteKey string
+	var evicted []string
 	cache.OnEviction = func(key string) {
-		deleteKey = key
+		evicted = append(evicted, key)
 	}
-	// Delete the cache entry.
-	cache.Delete("test")
-	if deleteKey != "test" {
-		t.Errorf("Test case 6 expected to pass, wanted \"test\", got %s", deleteKey)
+	for _, key := range []string{"a", "b", "c"} {
+		if err = cache.Put(key, "etag", 0, []byte(key+"aaaa")); err != nil {
+			t.Fatalf("Expected to pass, failed instead %s", err)
+		}
+	}
+	// "a" and "b" are on disk, "c" in memory.
+	stats := cache.Stats()
+	if stats.MemorySize != 5 || stats.DiskSize != 10 || len(evicted) != 0 {
+		t.Fatalf("Unexpected stats %#v, evicted %v", stats, evicted)
 	}
 
-	// Test 7 validates rejecting requests when excess data is being saved.
-	testCase = testCases[6]
-	cache = New(testCase.cacheSize, testCase.expiry)
-	w, err = cache.Create("test1", 5)
+	// Reading "a" from disk moves it to memory and spills "c".
+	value, err := cache.Get("a", "etag", 0)
 	if err != nil {
-		t.Errorf("Test case 7 expected to pass, failed instead %s", err)
+		t.Fatalf("Expected to pass, failed instead %s", err)
 	}
-	// Write '5' bytes.
-	w.Write([]byte("Hello"))
-	// Close to successfully save into cache.
-	if err = w.Close(); err != nil {
-		t.Errorf("Test case 7 expected to pass, failed instead %s", err)
+	if string(value) != "aaaaa" {
+		t.Fatalf("Expected \"aaaaa\", got %s", string(value))
 	}
-	w, err = cache.Create("test2", 1)
+	stats = cache.Stats()
+	if stats.DiskHits != 1 || stats.MemorySize != 5 || stats.DiskSize != 10 {
+		t.Fatalf("Unexpected stats %#v", stats)
+	}
+
+	// Disk is full, the least recently used block on disk is evicted.
+	if err = cache.Put("d", "etag", 0, []byte("ddddd")); err != nil {
+		t.Fatalf("Expected to pass, failed instead %s", err)
+	}
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Fatalf("Expected \"b\" to be evicted, got %v", evicted)
+	}
+	for _, key := range []string{"a", "c", "d"} {
+		if _, err = cache.Get(key, "etag", 0); err != nil {
+			t.Errorf("Expected %s to be cached, failed instead %s", key, err)
+		}
+	}
+
+	// Deleted blocks are removed from disk.
+	for _, key := range []string{"a", "c", "d"} {
+		cache.Delete(key)
+	}
+	files, err := ioutil.ReadDir(cache.diskDir)
 	if err != nil {
-		t.Errorf("Test case 7 expected to pass, failed instead %s", err)
+		t.Fatal(err)
 	}
-	// Write '1' byte.
-	w.Write([]byte("H"))
-	if err = w.Close(); err != testCase.closeErr {
-		t.Errorf("Test case 7 expected to fail, passed instead")
+	if len(files) != 0 {
+		t.Errorf("Expected no spilled blocks, found %d", len(files))
 	}
 }
 
-// TestStateEntryPurge - tests if objCache purges stale entry and returns ErrKeyNotFoundInCache.
-func TestStaleEntryPurge(t *testing.T) {
+// TestWriter - tests objects written are split in blocks.
+func TestWriter(t *testing.T) {
 	cache := New(1024, NoExpiry)
-	w, err := cache.Create("test", 5)
-	if err != nil {
-		t.Errorf("Test case expected to pass, failed instead %s", err)
+	cache.blockSize = 4
+
+	// Writes starting in the middle of a block skip to the next one.
+	w := cache.NewWriter("test", "etag", 2, 10)
+	w.Write([]byte("ll"))
+	w.Write([]byte("o Wo"))
+	w.Write([]byte("rl"))
+	testCases := []struct {
+		offset int64
+		value  string
+		err    error
+	}{
+		{0, "", ErrKeyNotFoundInCache},
+		{4, "o Wo", nil},
+		{8, "rl", nil},
 	}
-	// Write '5' bytes.
-	w.Write([]byte("Hello"))
-	// Close to successfully save into cache.
-	if err = w.Close(); err != nil {
-		t.Errorf("Test case expected to pass, failed instead %s", err)
+	for i, testCase := range testCases {
+		value, err := cache.Get("test", "etag", testCase.offset)
+		if err != testCase.err {
+			t.Errorf("Test case %d expected %v, got instead %v", i+1, testCase.err, err)
+		}
+		if string(value) != testCase.value {
+			t.Errorf("Test case %d expected %q, got instead %q", i+1, testCase.value, string(value))
+		}
 	}
 
-	_, err = cache.Open("test", time.Now().AddDate(0, 0, 1).UTC())
-	if err != ErrKeyNotFoundInCache {
-		t.Errorf("Test case expected to return ErrKeyNotFoundInCache, instead returned %s", err)
+	// Blocks written before the ETag is known are cached on Commit.
+	w = cache.NewWriter("new", "", 0, 6)
+	w.Write([]byte("Hello!"))
+	if _, err := cache.Get("new", "etag", 0); err != ErrKeyNotFoundInCache {
+		t.Errorf("Expected %s, got instead %s", ErrKeyNotFoundInCache, err)
+	}
+	w.Commit("etag")
+	value, err := cache.Get("new", "etag", 4)
+	if err != nil || string(value) != "o!" {
+		t.Errorf("Expected \"o!\", got %q, %v", string(value), err)
+	}
+
+	// Excess data is not cached.
+	w = cache.NewWriter("excess", "", 0, 2)
+	w.Write([]byte("Hello"))
+	w.Commit("etag")
+	if _, err = cache.Get("excess", "etag", 0); err != ErrKeyNotFoundInCache {
+		t.Errorf("Expected %s, got instead %s", ErrKeyNotFoundInCache, err)
 	}
 }
@@ -0,0 +1,152 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package objcache
+
+// pendingBlock - a block written before the ETag of the object is known.
+type pendingBlock struct {
+	offset int64
+	value  []byte
+}
+
+// Writer caches the contents of an object written to it starting at an
+// offset, splitting them in blocks. Writes never fail, data which cannot
+// be cached is dropped.
+type Writer struct {
+	cache  *Cache
+	key    string
+	etag   string
+	offset int64 // Offset of the next byte written.
+	size   int64 // Size of the object.
+
+	// Block being filled.
+	buf []byte
+
+	// Blocks kept until Commit when the ETag is not known yet.
+	pending     []pendingBlock
+	pendingSize uint64
+
+	// Set once nothing more can be cached.
+	failed bool
+}
+
+// NewWriter - returns a writer caching the contents of the object of
+// size bytes, written from offset on. Bytes before the first block
+// boundary are skipped. If the ETag is empty, blocks are only cached
+// once Commit is called with the ETag of the object.
+func (c *Cache) NewWriter(key, etag string, offset, size int64) *Writer {
+	return &Writer{
+		cache:  c,
+		key:    key,
+		etag:   etag,
+		offset: offset,
+		size:   size,
+	}
+}
+
+// Write - caches every block fully written.
+func (w *Writer) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 && !w.failed {
+		if w.offset+int64(len(w.buf)) >= w.size {
+			// Excess data, the object size is wrong.
+			w.fail()
+			break
+		}
+		blockSize := w.cache.blockSize
+		if skip := w.offset % blockSize; skip != 0 {
+			// Not at a block boundary, skip to the next one.
+			toSkip := blockSize - skip
+			if toSkip > int64(len(p)) {
+				toSkip = int64(len(p))
+			}
+			w.offset += toSkip
+			p = p[toSkip:]
+			continue
+		}
+		blockLen := blockSize
+		if w.size-w.offset < blockLen {
+			// Last block of the object.
+			blockLen = w.size - w.offset
+		}
+		if w.buf == nil {
+			w.buf = make([]byte, 0, blockLen)
+		}
+		toCopy := blockLen - int64(len(w.buf))
+		if toCopy > int64(len(p)) {
+			toCopy = int64(len(p))
+		}
+		w.buf = append(w.buf, p[:toCopy]...)
+		p = p[toCopy:]
+		if int64(len(w.buf)) == blockLen {
+			w.flush()
+		}
+	}
+	return n, nil
+}
+
+// Commit - caches the blocks written so far for the object version with
+// the given ETag, as well as all the blocks written afterwards.
+func (w *Writer) Commit(etag string) {
+	w.etag = etag
+	if w.failed {
+		return
+	}
+	for _, block := range w.pending {
+		w.cache.putBlock(w.key, w.etag, block.offset, block.value)
+	}
+	w.pending = nil
+	w.pendingSize = 0
+}
+
+// flush - caches the block filled, or keeps it until Commit.
+func (w *Writer) flush() {
+	value := w.buf
+	offset := w.offset
+	w.buf = nil
+	w.offset += int64(len(value))
+	if w.etag != "" {
+		w.cache.putBlock(w.key, w.etag, offset, value)
+		return
+	}
+	w.pending = append(w.pending, pendingBlock{offset, value})
+	w.pendingSize += uint64(len(value))
+	if w.cache.maxSize > 0 && w.pendingSize > w.cache.maxSize {
+		// The blocks would not fit in memory anyway.
+		w.fail()
+	}
+}
+
+// fail - stops caching, drops the pending blocks.
+func (w *Writer) fail() {
+	w.failed = true
+	w.buf = nil
+	w.pending = nil
+	w.pendingSize = 0
+}
+
+// putBlock - caches a block owned by the cache, unlike Put no copy of
+// the value is made.
+func (c *Cache) putBlock(key, etag string, offset int64, value []byte) {
+	if c.maxSize > 0 && uint64(len(value)) > c.maxSize {
+		return
+	}
+	c.mutex.Lock()
+	evicted := c.put(blockKey{key, etag, offset}, value)
+	c.mutex.Unlock()
+	c.notifyEviction(evicted)
+}